@@ -0,0 +1,694 @@
+// Бэктест стратегии из командной строки для скриптов и CI
+package main
+
+import (
+	"context"
+	"crypto-trading-bot/internal/backtest"
+	"crypto-trading-bot/internal/backtest/dataset"
+	"crypto-trading-bot/internal/backtest/montecarlo"
+	"crypto-trading-bot/internal/backtest/optimize"
+	"crypto-trading-bot/internal/backtest/report"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/config"
+	"crypto-trading-bot/internal/exchange/exchanges/mockexchange"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/processing/sampling"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/strategy/behaviortree"
+	"crypto-trading-bot/internal/strategy/dca"
+	"crypto-trading-bot/internal/strategy/grid"
+	"crypto-trading-bot/internal/strategy/pairs"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/pkg/pipeline"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// Коды завершения
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitBreached = 3 // бэктест выполнен, но нарушены пороги
+)
+
+const usage = `Usage:
+  backtest -config <file> -start <time> -end <time> [flags]
+  backtest -strategy <id> -start <time> -end <time> [flags]
+
+Конфигурация стратегии в формате strategies.config: {"type": ..., "settings": {...}},
+из файла -config или из таблицы strategies по -strategy. Прогоны стратегии из таблицы
+сохраняются в backtest_results вместе с исполнениями, оптимизацией и Monte Carlo анализом.
+Необязательные блоки конфигурации: costs — модели издержек, optimization и walk_forward —
+подбор параметров перед прогоном, monte_carlo — анализ сделок прогона.
+Оптимизация и Monte Carlo анализ поддерживают только стратегии на одном символе.
+Каждое сочетание -symbols и -intervals
+прогоняется отдельно, значения подставляются в settings.symbol и settings.interval.
+Стратегия с подписками на несколько символов одного интервала (pairs) получает их свечи
+единым потоком в порядке времени.
+
+Источники свечей (-source):
+  postgres   таблица market_data, подключение из config.yaml
+  dir        каталог -data с файлами <SYMBOL>_<interval>.csv или .parquet
+  synthetic  геометрическое броуновское движение, ряд определяется -seed
+
+Пороги (-threshold, можно несколько): показатель отчёта, оператор < <= > >= и значение,
+например -threshold "max_drawdown<=0.2" -threshold "sharpe>1".
+
+Коды завершения: 0 — пороги соблюдены, 1 — ошибка, 2 — неверные аргументы, 3 — нарушен порог.
+
+Flags:
+`
+
+// options — аргументы командной строки
+type options struct {
+	config     string
+	strategyID int
+	symbols    []string
+	intervals  []string
+	start      time.Time
+	end        time.Time
+	capital    float64
+	commission float64
+	spread     float64
+	source     string
+	data       string
+	seed       int64
+	out        string
+	formats    []string
+	thresholds []threshold
+	logLevel   string
+}
+
+// analysis — необязательные блоки конфигурации стратегии: подбор параметров и Monte Carlo анализ.
+// Незаданный блок равен nil.
+type analysis struct {
+	optimization settings.Settings
+	walkForward  settings.Settings
+	monteCarlo   settings.Settings
+}
+
+// store — репозитории для сохранения прогонов. Если поле равно nil, соответствующие данные не сохраняются.
+type store struct {
+	results       repositories.BacktestResultRepository
+	operations    repositories.TradeOperationRepository
+	optimizations repositories.OptimizationRepository
+}
+
+// threshold — граница показателя отчёта
+type threshold struct {
+	Metric string  `json:"metric"`
+	Op     string  `json:"op"`
+	Value  float64 `json:"value"`
+}
+
+func (t threshold) String() string {
+	return fmt.Sprintf("%s%s%v", t.Metric, t.Op, t.Value)
+}
+
+// summary — итог прогона одного символа и интервала
+type summary struct {
+	Symbol   string          `json:"symbol"`
+	Interval string          `json:"interval"`
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end"`
+	Initial  float64         `json:"initial_capital"`
+	Final    float64         `json:"final_capital"`
+	Metrics  *report.Metrics `json:"metrics"`
+	Breaches []string        `json:"breaches"` // нарушенные пороги
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run выполняет бэктесты по аргументам и возвращает код завершения
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	opts, err := parseOptions(args, stderr)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, err)
+		}
+		return exitUsage
+	}
+
+	log := logger.NewLogger(opts.logLevel)
+
+	// база нужна для свечей из market_data и для стратегий из таблицы strategies
+	var repo *repositories.Repository
+	if opts.source == "postgres" || opts.strategyID > 0 {
+		db, err := repositories.NewDB(config.LoadConfig())
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to connect to database: %v\n", err)
+			return exitError
+		}
+		repo = repositories.NewRepository(db, log)
+	}
+
+	strategyConfig, err := loadStrategyConfig(opts, repo)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	// прогоны стратегий из таблицы strategies сохраняются, прогоны конфигураций из файла — нет
+	st := &store{}
+	if opts.strategyID > 0 {
+		st = &store{results: repo.BacktestResults, operations: repo.TradeOperations, optimizations: repo.Optimizations}
+	}
+
+	registry := initRegistry()
+	strategies := initStrategies(registry)
+
+	brokerSettings := []settings.Settings{&settings.BacktestSettings{
+		InitialCapital: opts.capital,
+		Commission:     opts.commission,
+		Spread:         opts.spread,
+	}}
+	var backtestConfig struct {
+		Costs        json.RawMessage `json:"costs"`
+		Optimization json.RawMessage `json:"optimization"`
+		WalkForward  json.RawMessage `json:"walk_forward"`
+		MonteCarlo   json.RawMessage `json:"monte_carlo"`
+	}
+	if err := json.Unmarshal(strategyConfig, &backtestConfig); err != nil {
+		fmt.Fprintf(stderr, "strategy config: %v\n", err)
+		return exitError
+	}
+	costSettings, err := buildOptional(registry, "costs", backtestConfig.Costs)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if costSettings != nil {
+		brokerSettings = append(brokerSettings, costSettings)
+	}
+
+	a := &analysis{}
+	if a.optimization, err = buildOptional(registry, "optimization", backtestConfig.Optimization); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if a.walkForward, err = buildOptional(registry, "walk_forward", backtestConfig.WalkForward); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if a.monteCarlo, err = buildOptional(registry, "monte_carlo", backtestConfig.MonteCarlo); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	loader := newLoader(opts, repo)
+
+	if err := os.MkdirAll(opts.out, 0o755); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	var summaries []*summary
+	for _, symbol := range orEmpty(opts.symbols) {
+		for _, interval := range orEmpty(opts.intervals) {
+			s, err := runOne(ctx, opts, strategies, loader, brokerSettings, a, st, strategyConfig, symbol, interval, log)
+			if err != nil {
+				fmt.Fprintf(stderr, "%s %s: %v\n", symbol, interval, err)
+				return exitError
+			}
+			summaries = append(summaries, s)
+		}
+	}
+
+	printSummary(stdout, summaries)
+
+	if err := writeJSON(filepath.Join(opts.out, "summary.json"), summaries); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	for _, s := range summaries {
+		if len(s.Breaches) > 0 {
+			return exitBreached
+		}
+	}
+	return exitOK
+}
+
+func parseOptions(args []string, stderr io.Writer) (*options, error) {
+	opts := &options{}
+	fs := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	var symbols, intervals, start, end, formats string
+	fs.StringVar(&opts.config, "config", "", "файл конфигурации стратегии")
+	fs.IntVar(&opts.strategyID, "strategy", 0, "ID стратегии в таблице strategies, результаты сохраняются в базу")
+	fs.StringVar(&symbols, "symbols", "", "символы через запятую, по умолчанию из конфигурации")
+	fs.StringVar(&intervals, "intervals", "", "интервалы через запятую, по умолчанию из конфигурации")
+	fs.StringVar(&start, "start", "", "начало периода, RFC 3339 или 2006-01-02")
+	fs.StringVar(&end, "end", "", "конец периода, RFC 3339 или 2006-01-02")
+	fs.Float64Var(&opts.capital, "capital", 10000, "начальный капитал")
+	fs.Float64Var(&opts.commission, "commission", 0.001, "комиссия, доля от объёма сделки")
+	fs.Float64Var(&opts.spread, "spread", 0, "спред, доля от цены")
+	fs.StringVar(&opts.source, "source", "postgres", "источник свечей: postgres, dir, synthetic")
+	fs.StringVar(&opts.data, "data", "", "каталог со свечами для -source dir")
+	fs.Int64Var(&opts.seed, "seed", 1, "начальное значение генератора для -source synthetic")
+	fs.StringVar(&opts.out, "out", "backtest-results", "каталог для отчётов")
+	fs.StringVar(&formats, "formats", "html,json", "форматы отчётов через запятую: html, json")
+	fs.StringVar(&opts.logLevel, "log-level", "error", "уровень журнала")
+	fs.Func("threshold", "порог показателя, например max_drawdown<=0.2", func(value string) error {
+		t, err := parseThreshold(value)
+		if err != nil {
+			return err
+		}
+		opts.thresholds = append(opts.thresholds, t)
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if (opts.config == "") == (opts.strategyID <= 0) {
+		return nil, fmt.Errorf("either -config or -strategy is required")
+	}
+	opts.symbols = splitList(symbols)
+	opts.intervals = splitList(intervals)
+	opts.formats = splitList(formats)
+	for _, format := range opts.formats {
+		if format != "html" && format != "json" {
+			return nil, fmt.Errorf("unknown report format: %s", format)
+		}
+	}
+
+	var err error
+	if opts.start, err = parseTime(start); err != nil {
+		return nil, fmt.Errorf("-start: %w", err)
+	}
+	if opts.end, err = parseTime(end); err != nil {
+		return nil, fmt.Errorf("-end: %w", err)
+	}
+	if !opts.end.After(opts.start) {
+		return nil, fmt.Errorf("-end must be after -start")
+	}
+
+	switch opts.source {
+	case "postgres", "synthetic":
+	case "dir":
+		if opts.data == "" {
+			return nil, fmt.Errorf("-data is required for -source dir")
+		}
+	default:
+		return nil, fmt.Errorf("unknown source: %s", opts.source)
+	}
+
+	return opts, nil
+}
+
+// parseThreshold разбирает порог вида "metric<=value"
+func parseThreshold(value string) (threshold, error) {
+	for _, op := range []string{"<=", ">=", "<", ">"} {
+		metric, limit, found := strings.Cut(value, op)
+		if !found {
+			continue
+		}
+
+		t := threshold{Metric: strings.TrimSpace(metric), Op: op}
+		if _, err := optimize.Metric(&report.Metrics{}, t.Metric); err != nil {
+			return t, err
+		}
+		if _, err := fmt.Sscanf(strings.TrimSpace(limit), "%g", &t.Value); err != nil {
+			return t, fmt.Errorf("invalid threshold value: %q", limit)
+		}
+		return t, nil
+	}
+	return threshold{}, fmt.Errorf("invalid threshold %q, expected metric<=value", value)
+}
+
+// check сообщает, что значение показателя укладывается в порог
+func (t threshold) check(value float64) bool {
+	switch t.Op {
+	case "<":
+		return value < t.Value
+	case "<=":
+		return value <= t.Value
+	case ">":
+		return value > t.Value
+	case ">=":
+		return value >= t.Value
+	}
+	return false
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("time is required")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// orEmpty возвращает список или одно пустое значение: значение из конфигурации стратегии
+func orEmpty(list []string) []string {
+	if len(list) == 0 {
+		return []string{""}
+	}
+	return list
+}
+
+// loadStrategyConfig читает конфигурацию стратегии из файла -config или из таблицы strategies по -strategy
+func loadStrategyConfig(opts *options, repo *repositories.Repository) (json.RawMessage, error) {
+	if opts.strategyID <= 0 {
+		return os.ReadFile(opts.config)
+	}
+
+	s, err := repo.Strategy.GetStrategy(opts.strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load strategy %d: %w", opts.strategyID, err)
+	}
+	return s.Config, nil
+}
+
+// buildOptional создаёт настройки необязательного блока конфигурации стратегии, nil — если блока нет
+func buildOptional(registry *settings.SettingsRegistry, name string, raw json.RawMessage) (settings.Settings, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return registry.Build(name, raw)
+}
+
+func newLoader(opts *options, repo *repositories.Repository) dataset.Loader {
+	switch opts.source {
+	case "dir":
+		return dataset.NewDirLoader(opts.data)
+	case "synthetic":
+		return dataset.NewSyntheticLoader(opts.seed, 0, 0)
+	}
+	return repo.MarketData
+}
+
+// runOne прогоняет стратегию на данных её подписок и записывает отчёты.
+// Подписки на несколько символов одного интервала выдаются единым потоком.
+func runOne(ctx context.Context,
+	opts *options,
+	strategies *strategy.Registry,
+	loader dataset.Loader,
+	brokerSettings []settings.Settings,
+	a *analysis,
+	st *store,
+	strategyConfig json.RawMessage,
+	symbol string,
+	interval string,
+	log *logger.Logger) (*summary, error) {
+
+	params := optimize.Params{}
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+	if interval != "" {
+		params["interval"] = interval
+	}
+	strategyConfig, err := optimize.Apply(strategyConfig, params)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := strategies.Build(strategyConfig)
+	if err != nil {
+		return nil, err
+	}
+	subs := instance.Subscriptions()
+	if len(subs) == 0 {
+		return nil, fmt.Errorf("strategy has no subscriptions")
+	}
+
+	sourceSettings := &settings.HistoricalSourceSettings{
+		Symbol:    subs[0].Symbol,
+		Interval:  subs[0].Interval,
+		StartTime: opts.start,
+		EndTime:   opts.end,
+	}
+	data := make(map[string][]*types.MarketData, len(subs))
+	symbols := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		if sub.Interval != sourceSettings.Interval {
+			return nil, fmt.Errorf("subscriptions with different intervals are not supported")
+		}
+		list, err := loader.GetMarketDataPeriod(sub.Symbol, sub.Interval, opts.start, opts.end)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("no market data for %s in %v - %v", sub.Symbol, opts.start, opts.end)
+		}
+		data[sub.Symbol] = list
+		symbols = append(symbols, sub.Symbol)
+	}
+
+	if len(subs) > 1 && (a.optimization != nil || a.monteCarlo != nil) {
+		return nil, fmt.Errorf("optimization and monte carlo analysis support only single symbol strategies")
+	}
+	if a.optimization != nil {
+		if err := optimizeParams(ctx, opts, strategies, brokerSettings, a, st, strategyConfig, data[sourceSettings.Symbol], log); err != nil {
+			return nil, err
+		}
+	}
+
+	var source pipeline.Source = sampling.NewHistoricalSourceFromData(data[sourceSettings.Symbol], sourceSettings)
+	if len(subs) > 1 {
+		source = sampling.NewMultiSymbolSourceFromData(sourceSettings.Interval, data)
+	}
+	symbol = strings.Join(symbols, "-")
+
+	broker, err := backtest.NewBroker(brokerSettings...)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := json.Marshal(map[string]any{
+		"strategy": strategyConfig,
+		"source":   sourceSettings,
+		"symbols":  symbols,
+		"broker":   brokerSettings,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := backtest.NewRunner(st.results, st.operations, log).Run(ctx, opts.strategyID, instance, source, broker, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	equity, trades := backtest.Series(result.ID, broker)
+	r := report.Build(result, equity, trades)
+
+	if a.monteCarlo != nil {
+		analyzer, err := montecarlo.NewAnalyzer(strategies, brokerSettings, st.results, log, a.monteCarlo)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := analyzer.Run(ctx, result, trades, strategyConfig, data[sourceSettings.Symbol]); err != nil {
+			return nil, err
+		}
+	}
+
+	name := filepath.Join(opts.out, symbol+"_"+sourceSettings.Interval)
+	for _, format := range opts.formats {
+		if err := writeReport(name+"."+format, r, format); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &summary{
+		Symbol:   symbol,
+		Interval: sourceSettings.Interval,
+		Start:    result.StartTime,
+		End:      result.EndTime,
+		Initial:  result.InitialCapital,
+		Final:    result.FinalCapital,
+		Metrics:  &r.Metrics,
+		Breaches: []string{},
+	}
+	for _, t := range opts.thresholds {
+		value, _ := optimize.Metric(&r.Metrics, t.Metric)
+		if !t.check(value) {
+			s.Breaches = append(s.Breaches, fmt.Sprintf("%s = %.6g, expected %s%v", t.Metric, value, t.Op, t.Value))
+		}
+	}
+	return s, nil
+}
+
+// optimizeParams подбирает параметры стратегии на данных прогона: walk-forward анализом, если задан блок
+// walk_forward, иначе оптимизатором. Результаты оптимизации сохраняются в optimization_runs.
+func optimizeParams(ctx context.Context,
+	opts *options,
+	strategies *strategy.Registry,
+	brokerSettings []settings.Settings,
+	a *analysis,
+	st *store,
+	strategyConfig json.RawMessage,
+	data []*types.MarketData,
+	log *logger.Logger) error {
+
+	opt := *a.optimization.(*settings.OptimizationSettings)
+	// без явного списка параметров ищем по всем полям настроек стратегии с границами
+	if len(opt.Parameters) == 0 {
+		strategySettings, err := strategies.Settings(strategyConfig)
+		if err != nil {
+			return err
+		}
+		opt.Parameters = optimize.Genome(strategySettings)
+	}
+
+	if a.walkForward != nil {
+		wf, err := optimize.NewWalkForward(strategies, brokerSettings, log, a.walkForward, &opt)
+		if err != nil {
+			return err
+		}
+		result, err := wf.Run(ctx, strategyConfig, data, opts.start, opts.end)
+		if err != nil {
+			return err
+		}
+		log.Infof("Walk forward: %d windows, efficiency %.2f, out of sample return %.2f%%",
+			len(result.Windows), result.Efficiency, result.Report.Metrics.TotalReturn*100)
+		return nil
+	}
+
+	evaluator := optimize.NewBacktestEvaluator(strategies, data, brokerSettings, log)
+	optimizer, err := optimize.NewOptimizer(evaluator, st.optimizations, log, &opt)
+	if err != nil {
+		return err
+	}
+	_, err = optimizer.Run(ctx, opts.strategyID, strategyConfig, opts.start, opts.end)
+	return err
+}
+
+// printSummary выводит таблицу показателей по всем прогонам
+func printSummary(w io.Writer, summaries []*summary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SYMBOL\tINTERVAL\tTRADES\tRETURN\tCAGR\tSHARPE\tMAX DD\tWIN RATE\tPROFIT FACTOR\tFINAL\tSTATUS")
+	for _, s := range summaries {
+		status := "ok"
+		if len(s.Breaches) > 0 {
+			status = "FAIL: " + strings.Join(s.Breaches, "; ")
+		}
+		m := s.Metrics
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.2f%%\t%.2f%%\t%.2f\t%.2f%%\t%.2f%%\t%.2f\t%.2f\t%s\n",
+			s.Symbol, s.Interval, m.Trades, m.TotalReturn*100, m.CAGR*100, m.Sharpe,
+			m.MaxDrawdown*100, m.WinRate*100, m.ProfitFactor, s.Final, status)
+	}
+	tw.Flush()
+}
+
+func writeReport(path string, r *report.Report, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if format == "html" {
+		return r.WriteHTML(f)
+	}
+	return r.WriteJSON(f)
+}
+
+func writeJSON(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// initRegistry регистрирует настройки стратегий, доступных в бэктесте, издержек и анализа прогонов
+func initRegistry() *settings.SettingsRegistry {
+	reg := settings.NewSettingsRegistry()
+
+	reg.Register("rules", func() settings.Settings {
+		return &settings.RuleSettings{}
+	})
+
+	reg.Register("dca", func() settings.Settings {
+		return &settings.DCASettings{}
+	})
+
+	reg.Register("behavior_tree", func() settings.Settings {
+		return &settings.BehaviorTreeSettings{}
+	})
+
+	reg.Register("grid", func() settings.Settings {
+		return &settings.GridSettings{}
+	})
+
+	reg.Register("pairs", func() settings.Settings {
+		return &settings.PairsSettings{}
+	})
+
+	reg.Register("costs", func() settings.Settings {
+		return &settings.CostSettings{}
+	})
+
+	reg.Register("optimization", func() settings.Settings {
+		return &settings.OptimizationSettings{}
+	})
+
+	reg.Register("walk_forward", func() settings.Settings {
+		return &settings.WalkForwardSettings{}
+	})
+
+	reg.Register("monte_carlo", func() settings.Settings {
+		return &settings.MonteCarloSettings{}
+	})
+
+	return reg
+}
+
+// initStrategies регистрирует стратегии без сохранения состояния.
+// Сетка выставляет ордера на mockexchange, который исполняет их по свечам прогона.
+func initStrategies(registry *settings.SettingsRegistry) *strategy.Registry {
+	strategies := strategy.NewRegistry(registry)
+	strategies.Register("rules", strategy.NewRuleStrategy)
+	strategies.Register("dca", func(comps ...settings.Settings) (strategy.Strategy, error) {
+		return dca.NewStrategy(nil, nil, comps...)
+	})
+	nodes := behaviortree.NewNodeRegistry()
+	strategies.Register("behavior_tree", func(comps ...settings.Settings) (strategy.Strategy, error) {
+		return behaviortree.NewStrategy(nil, nodes, comps...)
+	})
+	strategies.Register("grid", func(comps ...settings.Settings) (strategy.Strategy, error) {
+		return grid.NewStrategy(mockexchange.NewMockExchange(clock.New()), nil, comps...)
+	})
+	strategies.Register("pairs", pairs.NewStrategy)
+	return strategies
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"crypto-trading-bot/internal/backtest/report"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/service/marketdata"
+	"crypto-trading-bot/internal/service/pairs"
+	"crypto-trading-bot/internal/settings"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const commandsUsage = `Usage:
+  bot                              запуск бота
+  bot schema [type]                JSON Schema всех или одного типа настроек
+  bot types                        зарегистрированные типы настроек
+  bot validate <type> <file|->     проверка настроек из файла или stdin
+  bot report <id> [html|json]      отчёт по сохранённому прогону бэктеста, по умолчанию html
+  bot coverage [days]              полнота свечей в market_data за последние дни, по умолчанию 7
+  bot pairs screen <interval> [days]
+                                   поиск коинтегрированных пар среди активных символов за последние дни, по умолчанию 30
+`
+
+// runCommand выполняет команду командной строки, не требующую подключения к базе данных.
+// Возвращает код завершения.
+func runCommand(reg *settings.SettingsRegistry, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	switch args[0] {
+	case "types":
+		for _, settingsType := range reg.Types() {
+			fmt.Fprintln(stdout, settingsType)
+		}
+		return 0
+
+	case "schema":
+		var value any = reg.Schemas()
+		if len(args) > 1 {
+			schema, err := reg.Schema(args[1])
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return 1
+			}
+			value = schema
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(value); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+
+	case "validate":
+		if len(args) != 3 {
+			fmt.Fprint(stderr, commandsUsage)
+			return 2
+		}
+
+		var data []byte
+		var err error
+		if args[2] == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(args[2])
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+
+		if err := reg.Validate(args[1], data); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		fmt.Fprintln(stdout, "ok")
+		return 0
+	}
+
+	fmt.Fprint(stderr, commandsUsage)
+	return 2
+}
+
+// runReport выводит отчёт по прогону бэктеста из backtest_results. Возвращает код завершения.
+func runReport(repo repositories.BacktestResultRepository, args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprint(stderr, commandsUsage)
+		return 2
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid backtest result id: %s\n", args[0])
+		return 2
+	}
+
+	format := "html"
+	if len(args) == 2 {
+		format = args[1]
+	}
+
+	r, err := report.Load(repo, id)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	switch format {
+	case "html":
+		err = r.WriteHTML(stdout)
+	case "json":
+		err = r.WriteJSON(stdout)
+	default:
+		fmt.Fprint(stderr, commandsUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runCoverage выводит полноту свечей по активным записям market_data_statuss. Возвращает код завершения.
+func runCoverage(scanner *marketdata.GapScanner, clk clock.Clock, args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 1 {
+		fmt.Fprint(stderr, commandsUsage)
+		return 2
+	}
+
+	days := 7
+	if len(args) == 1 {
+		var err error
+		if days, err = strconv.Atoi(args[0]); err != nil || days <= 0 {
+			fmt.Fprintf(stderr, "invalid number of days: %s\n", args[0])
+			return 2
+		}
+	}
+
+	end := clk.Now().UTC()
+	coverage, err := scanner.Coverage(end.AddDate(0, 0, -days), end)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EXCHANGE\tSYMBOL\tINTERVAL\tEXPECTED\tACTUAL\tGAPS\tMISSING\tCOVERAGE")
+	for _, c := range coverage {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%.2f%%\n",
+			c.Exchange, c.Symbol, c.TimeFrame, c.Expected, c.Actual, c.Gaps, c.Missing, c.Percent)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runPairs выполняет команды парной торговли. Возвращает код завершения.
+func runPairs(screener *pairs.Screener, clk clock.Clock, args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) < 2 || len(args) > 3 || args[0] != "screen" {
+		fmt.Fprint(stderr, commandsUsage)
+		return 2
+	}
+
+	days := 30
+	if len(args) == 3 {
+		var err error
+		if days, err = strconv.Atoi(args[2]); err != nil || days <= 0 {
+			fmt.Fprintf(stderr, "invalid number of days: %s\n", args[2])
+			return 2
+		}
+	}
+
+	end := clk.Now().UTC()
+	candidates, err := screener.ScreenActive(args[1], end.AddDate(0, 0, -days), end)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Y\tX\tBETA\tADF\tSIGNIFICANCE\tHALF LIFE\tCORRELATION\tBARS")
+	for _, c := range candidates {
+		significance := "-"
+		if c.Significance > 0 {
+			significance = fmt.Sprintf("%.0f%%", c.Significance*100)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.4f\t%.3f\t%s\t%.1f\t%.3f\t%d\n",
+			c.Y, c.X, c.Beta, c.ADFStat, significance, c.HalfLife, c.Correlation, c.Bars)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -3,32 +3,72 @@ package main
 import (
 	"context"
 
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/config"
+	"crypto-trading-bot/internal/exchange/exchanges/mockexchange"
 	"crypto-trading-bot/internal/logger"
-	"crypto-trading-bot/internal/processing"
-	"crypto-trading-bot/internal/processing/sampling"
 	"crypto-trading-bot/internal/repositories"
 	"crypto-trading-bot/internal/service/exchange"
 	"crypto-trading-bot/internal/service/marketdata"
+	"crypto-trading-bot/internal/service/pairs"
 	"crypto-trading-bot/internal/settings"
-	"crypto-trading-bot/pkg/pipeline"
-	"encoding/json"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/strategy/behaviortree"
+	"crypto-trading-bot/internal/strategy/dca"
+	"crypto-trading-bot/internal/strategy/grid"
+	pairstrategy "crypto-trading-bot/internal/strategy/pairs"
+	"crypto-trading-bot/internal/types"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 )
 
+const (
+	feedPollInterval = 10 * time.Second // период опроса новых свечей для стратегий
+	feedHistory      = 24 * time.Hour   // история для прогрева индикаторов при запуске стратегии
+
+	gapScanPeriod          = time.Hour              // период поиска пропусков в market_data
+	gapScanLookback        = 7 * 24 * time.Hour     // глубина поиска пропусков
+	gapBackfillRequestRate = 500 * time.Millisecond // минимальный интервал между запросами догрузки к бирже
+)
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		os.Exit(runReport(NewBasicServices().repo.BacktestResults, os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "pairs" {
+		basicServices := NewBasicServices()
+		os.Exit(runPairs(pairs.NewScreener(basicServices.marketDataService), basicServices.clock, os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "coverage" {
+		basicServices := NewBasicServices()
+		os.Exit(runCoverage(basicServices.gapScanner, basicServices.clock, os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(initRegistry(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// // === Запускаем менеджер ===
-	// if err := manager.LoadAndStartAll(); err != nil {
-	//     log.Printf("Warning: failed to start some strategies: %v", err)
-	// }
+	basicServices := NewBasicServices()
+	registry := initRegistry()
+	strategies := initStrategies(registry, basicServices.clock, basicServices.repo)
+
+	// регламентная загрузка свечей, из которых читает поставщик данных стратегий
+	go basicServices.marketDataService.RunSchudeler(ctx)
+	// поиск и догрузка пропусков в загруженных свечах
+	go basicServices.gapScanner.RunSchudeler(ctx, gapScanPeriod, gapScanLookback)
+
+	// === Запускаем менеджер ===
+	manager := newManager(basicServices, strategies)
+	if err := manager.LoadAndStartAll(ctx); err != nil {
+		log.Printf("Warning: failed to start some strategies: %v", err)
+	}
 
 	// === Перехватываем сигналы ===
 	c := make(chan os.Signal, 1)
@@ -43,9 +83,8 @@ func main() {
 		os.Exit(0)
 	}()
 
-	// ===  ===
-
-	run(ctx, cancel)
+	// схемы настроек для редакторов конфигураций
+	serveSettingsSchema(basicServices, registry)
 
 	// // === Запускаем веб-сервер ===
 	// r := gin.Default()
@@ -62,47 +101,56 @@ func main() {
 
 	log.Println("Graceful shutdown...")
 
-	// // Можно добавить дополнительную очистку
-	// manager.StopAll() // явная остановка всех стратегий (если нужно)
+	manager.StopAll()
 
 	log.Println("Bye!")
 
 }
 
-func run(ctx context.Context, cancel context.CancelFunc) {
-	basicServices := NewBasicServices()
-
-	basicServices.logger.Debugf("Запуск бектеста...")
-
-	registry := initRegistry()
-
-	histSourceJson := json.RawMessage(`{
-		"symbol": "BTCUSDT",
-		"interval": "1s",
-		"start_time" : "2025-07-23T00:00:00Z",
-		"end_time" : "2025-07-23T01:00:00Z"
-	}`)
-
-	comp, err := registry.Build("source", histSourceJson)
-	if err != nil {
-		fmt.Printf("Ошибка формирования компоненты %s", err)
+// serveSettingsSchema отдаёт схемы настроек по HTTP, если задан порт web.port
+func serveSettingsSchema(basicServices basicServices, registry *settings.SettingsRegistry) {
+	port := basicServices.conf.Web.Port
+	if port <= 0 {
 		return
 	}
 
-	_, err = sampling.NewHistoricalSource(basicServices.marketDataService, comp)
-	if err != nil {
-		fmt.Printf("Ошибка создания источника: %s", err)
-		return
-	}
+	go func() {
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", port), settings.NewSchemaHandler(registry)); err != nil {
+			basicServices.logger.Errorf("Settings API server error: %v", err)
+		}
+	}()
+}
 
-	symbSource := processing.NewSymbolSource([]processing.SymbolItem{
-		{"BTCUDST", "1s"},
-		{"BTCUDST", "5m"},
-		{"ETHUDST", "1m"},
+// initStrategies регистрирует типы стратегий, доступные боту. Состояние стратегий сохраняется в repo
+// и восстанавливается при перезапуске.
+// Биржи для исполнения ордеров в боте пока нет, сетка торгует на mockexchange по свечам стратегии.
+// mockexchange создаётся при каждом запуске стратегии, ордера уровней сетка при восстановлении отправляет в него заново.
+func initStrategies(registry *settings.SettingsRegistry, clk clock.Clock, repo *repositories.Repository) *strategy.Registry {
+	strategies := strategy.NewRegistry(registry)
+	strategies.Register("rules", strategy.NewRuleStrategy)
+	nodes := behaviortree.NewNodeRegistry()
+	strategies.Register("behavior_tree", func(comps ...settings.Settings) (strategy.Strategy, error) {
+		return behaviortree.NewStrategy(repo.BehaviorTrees, nodes, comps...)
+	})
+	strategies.Register("grid", func(comps ...settings.Settings) (strategy.Strategy, error) {
+		return grid.NewStrategy(mockexchange.NewMockExchange(clk), repo.GridLevels, comps...)
 	})
+	strategies.Register("dca", func(comps ...settings.Settings) (strategy.Strategy, error) {
+		return dca.NewStrategy(repo.TradeOperations, repo.DCADeals, comps...)
+	})
+	strategies.Register("pairs", pairstrategy.NewStrategy)
+	return strategies
+}
 
-	_pipeline := pipeline.New()
-	_pipeline.Process(ctx, symbSource, &processing.LoggerSink{})
+// newManager создаёт менеджер активных стратегий на свечах из market_data.
+// Исполнителя ордеров в боте пока нет, сигналы записываются в журнал.
+func newManager(basicServices basicServices, strategies *strategy.Registry) *strategy.Manager {
+	feed := strategy.NewPollingFeed(basicServices.marketDataService, basicServices.clock, basicServices.logger, feedPollInterval, feedHistory)
+	handler := strategy.SignalHandlerFunc(func(_ context.Context, signal *types.Signal) error {
+		basicServices.logger.Infof("Signal: %+v", signal)
+		return nil
+	})
+	return strategy.NewManager(basicServices.repo.Strategy, strategies, feed, handler, basicServices.logger, basicServices.clock)
 }
 
 func initRegistry() *settings.SettingsRegistry {
@@ -112,6 +160,74 @@ func initRegistry() *settings.SettingsRegistry {
 		return &settings.HistoricalSourceSettings{}
 	})
 
+	reg.Register("indicators", func() settings.Settings {
+		return &settings.IndicatorSettings{}
+	})
+
+	reg.Register("volume_profile", func() settings.Settings {
+		return &settings.VolumeProfileSettings{}
+	})
+
+	reg.Register("multi_timeframe", func() settings.Settings {
+		return &settings.MultiTimeframeSettings{}
+	})
+
+	reg.Register("validation", func() settings.Settings {
+		return &settings.ValidationSettings{}
+	})
+
+	reg.Register("behavior_tree", func() settings.Settings {
+		return &settings.BehaviorTreeSettings{}
+	})
+
+	reg.Register("level_signals", func() settings.Settings {
+		return &settings.LevelSignalSettings{}
+	})
+
+	reg.Register("rules", func() settings.Settings {
+		return &settings.RuleSettings{}
+	})
+
+	reg.Register("pairs", func() settings.Settings {
+		return &settings.PairsSettings{}
+	})
+
+	reg.Register("grid", func() settings.Settings {
+		return &settings.GridSettings{}
+	})
+
+	reg.Register("dca", func() settings.Settings {
+		return &settings.DCASettings{}
+	})
+
+	reg.Register("ensemble", func() settings.Settings {
+		return &settings.EnsembleSettings{}
+	})
+
+	reg.Register("backtest", func() settings.Settings {
+		return &settings.BacktestSettings{}
+	})
+
+	reg.Register("matching", func() settings.Settings {
+		return &settings.MatchingSettings{}
+	})
+
+	reg.Register("costs", func() settings.Settings {
+		return &settings.CostSettings{}
+	})
+
+	reg.Register("optimization", func() settings.Settings {
+		return &settings.OptimizationSettings{}
+	})
+
+	reg.Register("walk_forward", func() settings.Settings {
+		return &settings.WalkForwardSettings{}
+	})
+
+	reg.Register("monte_carlo", func() settings.Settings {
+		return &settings.MonteCarloSettings{}
+	})
+
 	// Добавляй сюда новые компоненты — система сама их подхватит
 	return reg
 }
@@ -119,7 +235,10 @@ func initRegistry() *settings.SettingsRegistry {
 type basicServices struct {
 	conf              *config.Config
 	logger            *logger.Logger
+	repo              *repositories.Repository
 	marketDataService marketdata.MarketDataService
+	gapScanner        *marketdata.GapScanner
+	clock             clock.Clock
 }
 
 func NewBasicServices() basicServices {
@@ -143,11 +262,18 @@ func NewBasicServices() basicServices {
 
 	exchangeService := exchange.NewEchangeService(repo, logger, exchanges)
 
-	marketDataService := marketdata.NewMarketDataService(cfg, repo, logger, exchanges, exchangeService)
+	clk := clock.New()
+
+	marketDataService := marketdata.NewMarketDataService(cfg, repo, logger, clk, exchanges, exchangeService)
+
+	gapScanner := marketdata.NewGapScanner(repo, logger, clk, exchanges, gapBackfillRequestRate)
 
 	return basicServices{
 		conf:              cfg,
 		logger:            logger,
+		repo:              repo,
 		marketDataService: marketDataService,
+		gapScanner:        gapScanner,
+		clock:             clk,
 	}
 }
@@ -3,6 +3,8 @@ package main
 
 import (
 	"context"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/engine"
 	"crypto-trading-bot/internal/engine/components"
 	"crypto-trading-bot/internal/engine/ecsx"
 	"crypto-trading-bot/internal/engine/systems"
@@ -45,7 +47,9 @@ func main() {
 
 	// =====================================================================
 
-	_exchange := mockexchange.NewMockExchange()
+	clk := clock.New()
+
+	_exchange := mockexchange.NewMockExchange(clk)
 	_exchange.DelayMin = 1 * time.Second
 	_exchange.DelayMax = 2 * time.Second
 
@@ -53,22 +57,44 @@ func main() {
 	em := ecsx.NewEntityManager()
 
 	em.Add(ecs.NewEntity("datasource1", []ecs.Component{
-		components.NewDataSource("BTCUSDT", "1m", components.GenerateTestCandles(10)),
+		components.NewLiveDataSource("BTCUSDT", "1m", components.GenerateTestCandles(clk, 10)),
 	}))
 
 	em.Add(ecs.NewEntity("datasource2", []ecs.Component{
-		components.NewDataSource("ETHUSDT", "1m", components.GenerateTestCandles(10)),
+		components.NewLiveDataSource("ETHUSDT", "1m", components.GenerateTestCandles(clk, 10)),
+	}))
+
+	em.Add(ecs.NewEntity("history", []ecs.Component{
+		components.NewDataSource("BTCUSDT", "1m", components.GenerateTestCandles(clk, 1000)),
 	}))
 
-	// em.Add(ecs.NewEntity("trader", []ecs.Component{
-	// 	components.NewPosition(time.Time{}),
-	// }))
+	// бектесты на истории с максимальной скоростью и сессия на живых данных в темпе реального времени
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("backtest%d", i)
+		em.Add(ecs.NewEntity(id, []ecs.Component{
+			components.NewSession(id, "history", components.SpeedMax),
+			components.NewPosition(time.Time{}),
+			components.NewWallet(10000, 0.001),
+			components.NewStrategy(newCrossTrader(5+i%20, 50+i)),
+		}))
+	}
+	em.Add(ecs.NewEntity("live", []ecs.Component{
+		components.NewSession("live", "datasource1", components.SpeedRealtime),
+		components.NewPosition(time.Time{}),
+		components.NewWallet(10000, 0.001),
+		components.NewStrategy(newCrossTrader(3, 8)),
+	}))
 
 	sm := ecs.NewSystemManager()
 
 	fmt.Println("🔄 Запуск...")
 	sm.Add(systems.NewStopSystem(ctx))
-	sm.Add(systems.NewMovementSystem())
+	sm.Add(systems.NewMovementSystem(clk))
+	sm.Add(systems.NewTradingSystem(clk))
+	sm.Add(systems.NewSessionSystem(em, func(r systems.SessionResult) {
+		fmt.Printf("🏁 %s: свечей %d, доходность %.2f%%, просадка %.2f%%, сделок %d, ошибка %v\n",
+			r.ID, r.Candles, r.Return*100, r.MaxDrawdown*100, r.Trades, r.Err)
+	}, false))
 
 	fetchdataSystem := systems.NewFetchDataSystem(ctx, em, _exchange)
 	sm.Add(fetchdataSystem)
@@ -81,7 +107,7 @@ func main() {
 	// go func() {
 	// 	time.Sleep(time.Second)
 	// 	_entity := ecs.NewEntity("datasource3", []ecs.Component{
-	// 		components.NewDataSource("ETHUSDT", "1m", components.GenerateTestCandles(10)),
+	// 		components.NewDataSource("ETHUSDT", "1m", components.GenerateTestCandles(clk, 10)),
 	// 	})
 	// 	em.Add(_entity)
 	// 	time.Sleep(time.Second)
@@ -92,3 +118,38 @@ func main() {
 	_engine.Run()
 
 }
+
+// crossTrader покупает, когда короткая средняя выше длинной, и продаёт при обратном пересечении
+type crossTrader struct {
+	fast, slow int
+	closes     []float64
+}
+
+func newCrossTrader(fast int, slow int) *crossTrader {
+	return &crossTrader{fast: fast, slow: slow}
+}
+
+func (t *crossTrader) OnCandle(candle engine.MarketData, wallet *components.Wallet) error {
+	t.closes = append(t.closes, candle.ClosePrice)
+	if len(t.closes) < t.slow {
+		return nil
+	}
+
+	fast, slow := average(t.closes[len(t.closes)-t.fast:]), average(t.closes[len(t.closes)-t.slow:])
+	switch {
+	case fast > slow && wallet.Quantity == 0:
+		// запас на округление, чтобы стоимость с комиссией не превысила остаток денег
+		return wallet.Buy(wallet.Cash * 0.999 / candle.ClosePrice / (1 + wallet.Commission))
+	case fast < slow && wallet.Quantity > 0:
+		return wallet.Sell(wallet.Quantity)
+	}
+	return nil
+}
+
+func average(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
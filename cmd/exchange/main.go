@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/exchange"
 	"crypto-trading-bot/internal/exchange/exchanges/binance"
 	"log"
@@ -13,7 +14,7 @@ import (
 
 func main() {
 	// Создаем экземпляр Binance
-	ex := binance.NewBinanceExchange()
+	ex := binance.NewBinanceExchange(clock.New())
 
 	// Подписываемся на свечи в реальном времени через WebSocket
 	log.Println("Подписываемся на свечи BTCUSDT 1m...")
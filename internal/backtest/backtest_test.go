@@ -0,0 +1,332 @@
+package backtest
+
+import (
+	"context"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/pkg/pipeline"
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sliceSource выдаёт свечи из среза
+type sliceSource struct {
+	data  []*types.MarketData
+	index int
+}
+
+func (s *sliceSource) Next(context.Context) bool {
+	s.index++
+	return s.index < len(s.data)
+}
+
+func (s *sliceSource) Error() error { return nil }
+
+func (s *sliceSource) Payload() pipeline.Payload {
+	md := s.data[s.index]
+	return &processing.TradingPayload{Symbol: md.Symbol, Interval: md.TimeFrame, CurrentPrice: md.ClosePrice, MarketData: md}
+}
+
+// scriptStrategy покупает на первой свече и продаёт на указанной
+type scriptStrategy struct {
+	candles int
+	sellAt  int
+}
+
+func (s *scriptStrategy) Subscriptions() []strategy.Subscription { return nil }
+
+func (s *scriptStrategy) OnCandle(_ context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	s.candles++
+	side := ""
+	switch s.candles {
+	case 1:
+		side = types.SideBuy
+	case s.sellAt:
+		side = types.SideSell
+	default:
+		return nil, nil
+	}
+	return []*types.Signal{{Symbol: payload.Symbol, Side: side, Type: types.OrderTypeMarket, Amount: 10}}, nil
+}
+
+type memoryOperations struct {
+	ops []*types.TradeOperation
+}
+
+func (r *memoryOperations) SaveTradeOperation(op *types.TradeOperation) error {
+	r.ops = append(r.ops, op)
+	return nil
+}
+func (r *memoryOperations) GetTradeOperations(int) ([]*types.TradeOperation, error) {
+	return r.ops, nil
+}
+func (r *memoryOperations) GetDealOperations(int, string) ([]*types.TradeOperation, error) {
+	return r.ops, nil
+}
+
+type memoryResults struct {
+	saved  *types.BacktestResult
+	equity []*types.BacktestEquity
+	trades []*types.BacktestTrade
+	mc     []*types.BacktestMonteCarlo
+}
+
+func (r *memoryResults) SaveBacktestResult(result *types.BacktestResult) error {
+	result.ID = 1
+	r.saved = result
+	return nil
+}
+func (r *memoryResults) GetBacktestResult(int) (*types.BacktestResult, error) { return r.saved, nil }
+func (r *memoryResults) GetBacktestResults(int) ([]*types.BacktestResult, error) {
+	return []*types.BacktestResult{r.saved}, nil
+}
+func (r *memoryResults) SaveBacktestSeries(_ int, equity []*types.BacktestEquity, trades []*types.BacktestTrade) error {
+	r.equity, r.trades = equity, trades
+	return nil
+}
+func (r *memoryResults) GetBacktestEquity(int) ([]*types.BacktestEquity, error) { return r.equity, nil }
+func (r *memoryResults) GetBacktestTrades(int) ([]*types.BacktestTrade, error)  { return r.trades, nil }
+func (r *memoryResults) SaveBacktestMonteCarlo(analyses []*types.BacktestMonteCarlo) error {
+	r.mc = append(r.mc, analyses...)
+	return nil
+}
+func (r *memoryResults) GetBacktestMonteCarlo(int) ([]*types.BacktestMonteCarlo, error) {
+	return r.mc, nil
+}
+
+func candles(closes ...float64) []*types.MarketData {
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	data := make([]*types.MarketData, len(closes))
+	for i, price := range closes {
+		data[i] = &types.MarketData{
+			Symbol: "BTCUSDT", TimeFrame: "1m", Timestamp: now.Add(time.Duration(i) * time.Minute),
+			OpenPrice: price, HightPrice: price, LowPrice: price, ClosePrice: price, Volume: 100,
+		}
+	}
+	return data
+}
+
+func TestBroker(t *testing.T) {
+	_, err := NewBroker()
+	assert.Error(t, err)
+
+	broker, err := NewBroker(&settings.BacktestSettings{InitialCapital: 1000, Commission: 0.001, Spread: 0.002})
+	assert.NoError(t, err)
+
+	data := candles(100, 110)
+	broker.Mark("BTCUSDT", data[0])
+
+	fill, err := broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideBuy, Type: types.OrderTypeMarket, Amount: 5})
+	assert.NoError(t, err)
+	assert.InDelta(t, 100.1, fill.Price, 1e-9)
+	assert.InDelta(t, 0.5005, fill.Commission, 1e-9)
+	assert.InDelta(t, 1000-500.5-0.5005, broker.Cash(), 1e-9)
+
+	// денег на покупку не хватает
+	_, err = broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideBuy, Amount: 10})
+	assert.ErrorIs(t, err, ErrRejected)
+
+	// продажа больше позиции разворачивает её в короткую
+	broker.Mark("BTCUSDT", data[1])
+	_, err = broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideSell, Type: types.OrderTypeLimit, Price: 110, Amount: 8})
+	assert.NoError(t, err)
+	assert.InDelta(t, -3.0, broker.Position("BTCUSDT").Quantity, 1e-9)
+	assert.InDelta(t, 110.0, broker.Position("BTCUSDT").AveragePrice, 1e-9)
+
+	if trades := broker.Trades(); assert.Len(t, trades, 1) {
+		assert.Equal(t, "long", trades[0].Side)
+		// (110 - 100.1) * 5 минус комиссии покупки и продажи закрытой части
+		assert.InDelta(t, 49.5-0.5005-0.88, trades[0].PnL, 1e-9)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	broker, _ := NewBroker(&settings.BacktestSettings{InitialCapital: 1000})
+	operations := &memoryOperations{}
+	results := &memoryResults{}
+	runner := NewRunner(results, operations, logger.NewLogger("error"))
+
+	source := &sliceSource{data: candles(50, 40, 45, 60, 55), index: -1}
+	result, err := runner.Run(context.Background(), 5, &scriptStrategy{sellAt: 4}, source, broker, json.RawMessage(`{"type":"test"}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5, result.StrategyID)
+	assert.InDelta(t, 1100.0, result.FinalCapital, 1e-9)
+	assert.InDelta(t, 100.0, result.TotalProfit, 1e-9)
+	assert.InDelta(t, 0.1, result.Drawdown, 1e-9)
+	assert.Equal(t, 1, result.SuccessfulTrades)
+	assert.Equal(t, 0, result.FailedTrades)
+	assert.Equal(t, source.data[0].Timestamp, result.StartTime)
+	assert.Equal(t, source.data[4].Timestamp, result.EndTime)
+	assert.Same(t, result, results.saved)
+	assert.Len(t, results.equity, 5)
+	if assert.Len(t, results.trades, 1) {
+		assert.Equal(t, 1, results.trades[0].ResultID)
+		assert.InDelta(t, 100.0, results.trades[0].PnL, 1e-9)
+	}
+
+	if assert.Len(t, operations.ops, 2) {
+		assert.Equal(t, 5, operations.ops[0].StrategyID)
+		// исполнения ссылаются на прогон, а не попадают в операции живой торговли
+		if assert.NotNil(t, operations.ops[0].BacktestResultID) {
+			assert.Equal(t, result.ID, *operations.ops[0].BacktestResultID)
+		}
+		assert.Equal(t, 50.0, operations.ops[0].Price)
+		assert.Equal(t, 60.0, operations.ops[1].Price)
+	}
+}
+
+func TestCostModels(t *testing.T) {
+	broker, err := NewBroker(
+		&settings.BacktestSettings{InitialCapital: 100000},
+		&settings.CostSettings{
+			Slippage: &settings.SlippageModel{Model: settings.SlippageFixed, Value: 0.001},
+			Fees: &settings.FeeModel{Maker: 0.001, Taker: 0.002, Tiers: []settings.FeeTier{
+				{Volume: 1000, Maker: 0, Taker: 0.001},
+			}},
+		})
+	assert.NoError(t, err)
+
+	broker.Mark("BTCUSDT", candles(100)[0])
+
+	fill, err := broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideBuy, Type: types.OrderTypeMarket, Amount: 5})
+	assert.NoError(t, err)
+	assert.InDelta(t, 100.1, fill.Price, 1e-9)
+	assert.InDelta(t, 0.5, fill.Slippage, 1e-9)
+	assert.InDelta(t, 500.5*0.002, fill.Commission, 1e-9)
+
+	_, err = broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideBuy, Type: types.OrderTypeMarket, Amount: 6})
+	assert.NoError(t, err)
+
+	// объём торгов превысил 1000, действует ставка уровня
+	fill, _ = broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideSell, Type: types.OrderTypeMarket, Amount: 1})
+	assert.InDelta(t, 99.9, fill.Price, 1e-9)
+	assert.InDelta(t, 99.9*0.001, fill.Commission, 1e-9)
+
+	fill, _ = broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideSell, Type: types.OrderTypeLimit, Price: 100, Amount: 1})
+	assert.Equal(t, 0.0, fill.Commission)
+	assert.Equal(t, 0.0, fill.Slippage)
+
+	costs := broker.Costs()
+	assert.InDelta(t, 0.5+0.6+0.1, costs.Slippage, 1e-9)
+	assert.InDelta(t, 500.5*0.002+600.6*0.002+99.9*0.001, costs.Commission, 1e-9)
+	assert.InDelta(t, costs.Commission, broker.Result(time.Time{}, time.Time{}).Commission, 1e-9)
+}
+
+func TestLimitOrders(t *testing.T) {
+	broker, err := NewBroker(&settings.BacktestSettings{InitialCapital: 10000, Commission: 0.001, Spread: 0.002})
+	assert.NoError(t, err)
+
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	bar := func(minute int, open, high, low, close float64) *types.MarketData {
+		return &types.MarketData{Symbol: "BTCUSDT", Timestamp: now.Add(time.Duration(minute) * time.Minute),
+			OpenPrice: open, HightPrice: high, LowPrice: low, ClosePrice: close, Volume: 100}
+	}
+
+	assert.Empty(t, broker.Mark("BTCUSDT", bar(0, 100, 102, 98, 100)))
+
+	// покупка ниже минимума свечи не исполняется, ордер ждёт
+	fill, err := broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideBuy, Type: types.OrderTypeLimit, Price: 90, Amount: 1})
+	assert.NoError(t, err)
+	assert.Nil(t, fill)
+	assert.Equal(t, 1, broker.Pending())
+	assert.Equal(t, 10000.0, broker.Cash())
+
+	// покупка выше максимума свечи исполняется по рынку, а не по худшей цене сигнала
+	fill, err = broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideBuy, Type: types.OrderTypeLimit, Price: 110, Amount: 1})
+	assert.NoError(t, err)
+	assert.InDelta(t, 100.1, fill.Price, 1e-9)
+	assert.InDelta(t, 0.1, fill.Spread, 1e-9)
+
+	// продажа выше рынка, но в пределах свечи, исполняется по своей цене
+	fill, err = broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideSell, Type: types.OrderTypeLimit, Price: 101, Amount: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 101.0, fill.Price)
+	assert.Equal(t, 0.0, fill.Spread)
+
+	// свеча не дошла до 90
+	assert.Empty(t, broker.Mark("BTCUSDT", bar(1, 100, 101, 91, 95)))
+	assert.Equal(t, 1, broker.Pending())
+
+	// свеча открылась ниже цены ордера: исполнение по цене открытия
+	fills := broker.Mark("BTCUSDT", bar(2, 88, 92, 87, 91))
+	if assert.Len(t, fills, 1) {
+		assert.Equal(t, 88.0, fills[0].Price)
+		assert.Equal(t, now.Add(2*time.Minute), fills[0].Time)
+		assert.InDelta(t, 0.088, fills[0].Commission, 1e-9)
+	}
+	assert.Equal(t, 0, broker.Pending())
+	assert.InDelta(t, 1.0, broker.Position("BTCUSDT").Quantity, 1e-9)
+}
+
+func TestImpactAndVolatility(t *testing.T) {
+	impact := SqrtImpact{Coefficient: 1}
+	assert.InDelta(t, 0.01, impact.Impact(25, Market{Volume: 100, Volatility: 0.02}), 1e-12)
+	assert.Equal(t, 0.0, impact.Impact(25, Market{Volatility: 0.02}))
+
+	broker, _ := NewBroker(
+		&settings.BacktestSettings{InitialCapital: 100000},
+		&settings.CostSettings{
+			Slippage:         &settings.SlippageModel{Model: settings.SlippageVolatility, Factor: 0.5},
+			Impact:           &settings.ImpactModel{Coefficient: 1},
+			VolatilityWindow: 2,
+		})
+	for _, md := range candles(100, 110, 99) {
+		broker.Mark("BTCUSDT", md)
+	}
+
+	market := broker.market("BTCUSDT")
+	assert.InDelta(t, 100.0, market.Volume, 1e-9)
+	assert.Greater(t, market.Volatility, 0.0)
+
+	fill, err := broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideBuy, Type: types.OrderTypeMarket, Amount: 1})
+	assert.NoError(t, err)
+	expected := 0.5*market.Volatility + market.Volatility*0.1
+	assert.InDelta(t, 99*(1+expected), fill.Price, 1e-9)
+	assert.InDelta(t, 99*market.Volatility*0.1, fill.Impact, 1e-9)
+}
+
+func TestLatency(t *testing.T) {
+	broker, err := NewBroker(
+		&settings.BacktestSettings{InitialCapital: 1000},
+		&settings.CostSettings{Latency: &settings.LatencyModel{Model: settings.LatencyFixed, Delay: "90s"}})
+	assert.NoError(t, err)
+
+	data := candles(100, 101, 102)
+	broker.Mark("BTCUSDT", data[0])
+
+	fill, err := broker.Execute(&types.Signal{Symbol: "BTCUSDT", Side: types.SideBuy, Type: types.OrderTypeMarket, Amount: 1})
+	assert.NoError(t, err)
+	assert.Nil(t, fill)
+	assert.Equal(t, 1, broker.Pending())
+
+	// задержка заканчивается внутри третьей свечи
+	assert.Empty(t, broker.Mark("BTCUSDT", data[1]))
+	fills := broker.Mark("BTCUSDT", data[2])
+	if assert.Len(t, fills, 1) {
+		assert.Equal(t, 102.0, fills[0].Price)
+		assert.Equal(t, 2*time.Minute, fills[0].Latency)
+		assert.Equal(t, data[2].Timestamp, fills[0].Time)
+	}
+	assert.Equal(t, 0, broker.Pending())
+	assert.Equal(t, 1, broker.Costs().Delayed)
+	assert.InDelta(t, 120.0, broker.Costs().Latency, 1e-9)
+
+	random := RandomLatency{Min: time.Second, Max: 2 * time.Second, Rand: rand.New(rand.NewSource(1))}
+	for range 100 {
+		delay := random.Latency()
+		assert.True(t, delay >= time.Second && delay <= 2*time.Second)
+	}
+
+	_, err = NewBroker(
+		&settings.BacktestSettings{InitialCapital: 1000},
+		&settings.CostSettings{Latency: &settings.LatencyModel{Model: settings.LatencyRandom, Min: "2s", Max: "1s"}})
+	assert.Error(t, err)
+}
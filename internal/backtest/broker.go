@@ -0,0 +1,553 @@
+package backtest
+
+import (
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrRejected — брокер отклонил сигнал: нулевое количество, нет цены или не хватает денег
+var ErrRejected = errors.New("order rejected")
+
+// Fill — исполнение сигнала брокером
+type Fill struct {
+	Symbol     string
+	Side       string
+	Type       string
+	Quantity   float64
+	Price      float64 // цена исполнения с учётом спреда, проскальзывания и влияния на рынок
+	Commission float64
+	Spread     float64 // издержки исполнения в валюте котировки
+	Slippage   float64
+	Impact     float64
+	Latency    time.Duration // задержка от сигнала до исполнения
+	Time       time.Time
+	Reason     string
+}
+
+// Position — открытая позиция по символу. Количество со знаком: больше нуля — длинная, меньше — короткая.
+type Position struct {
+	Symbol       string
+	Quantity     float64
+	AveragePrice float64
+	OpenTime     time.Time
+	PnL          float64 // результат текущей сделки с учётом комиссий
+	MaxQuantity  float64 // наибольший объём позиции за сделку
+}
+
+// Trade — закрытая сделка: от открытия позиции до возврата её объёма к нулю
+type Trade struct {
+	Symbol     string
+	Side       string // long, short
+	EntryTime  time.Time
+	ExitTime   time.Time
+	EntryPrice float64
+	ExitPrice  float64
+	Quantity   float64
+	PnL        float64 // с учётом комиссий
+}
+
+// EquityPoint — оценка капитала на момент времени
+type EquityPoint struct {
+	Time   time.Time
+	Equity float64
+}
+
+// Broker — симулированный брокер бэктеста. Рыночные сигналы исполняются по последней цене символа
+// хуже на половину спреда, проскальзывание и влияние на рынок. Лимитный сигнал исполняется по своей цене,
+// если её достигла текущая свеча символа; если цена лучше всей свечи — по рынку, но не хуже цены сигнала.
+// Недостигнутый лимитный ордер ждёт свечи, Low или High которой пересечёт его цену.
+// Комиссия берётся с объёма каждого исполнения: с лимитных ордеров по ставке мейкера, с остальных — тейкера.
+// При заданной модели задержки ордер исполняется на первой свече, закрытой после окончания задержки.
+// Короткие позиции допускаются без проверки обеспечения, покупка в длинную позицию — только на свободные деньги.
+type Broker struct {
+	settings    settings.BacktestSettings
+	models      costModels
+	cash        float64
+	positions   map[string]*Position
+	prices      map[string]float64
+	volumes     map[string]float64 // объём последней свечи символа
+	bars        map[string]*types.MarketData
+	returns     map[string][]float64 // доходности последних свечей символа для оценки волатильности
+	pending     []*pendingOrder      // ордера, ожидающие окончания задержки
+	resting     []*pendingOrder      // лимитные ордера, цена которых ещё не достигнута
+	traded      []tradedVolume       // объёмы исполнений для уровня комиссий
+	costs       types.BacktestCosts
+	latency     time.Duration // суммарная задержка отложенных ордеров
+	rejected    int
+	now         time.Time
+	fills       []*Fill
+	trades      []*Trade
+	equity      []EquityPoint
+	peak        float64
+	maxDrawdown float64
+}
+
+type pendingOrder struct {
+	signal *types.Signal
+	placed time.Time
+	due    time.Time
+}
+
+type tradedVolume struct {
+	time     time.Time
+	notional float64
+}
+
+// NewBroker создаёт брокера по настройкам backtest и, если заданы, costs
+func NewBroker(comps ...settings.Settings) (*Broker, error) {
+	b := &Broker{
+		positions: make(map[string]*Position),
+		prices:    make(map[string]float64),
+		volumes:   make(map[string]float64),
+		bars:      make(map[string]*types.MarketData),
+		returns:   make(map[string][]float64),
+	}
+
+	var costs *settings.CostSettings
+	for _, c := range comps {
+		switch val := c.(type) {
+		case *settings.BacktestSettings:
+			b.settings = *val
+		case *settings.CostSettings:
+			costs = val
+		}
+	}
+
+	if b.settings.InitialCapital <= 0 {
+		return nil, fmt.Errorf("backtest settings are not set")
+	}
+
+	models, err := newCostModels(costs, b.settings.Commission)
+	if err != nil {
+		return nil, err
+	}
+	b.models = models
+
+	b.cash = b.settings.InitialCapital
+	b.peak = b.cash
+
+	return b, nil
+}
+
+// Mark обновляет цену символа по свече, исполняет ордера символа, лимитная цена которых достигнута свечой
+// или задержка которых закончилась, и обновляет оценку капитала. Возвращает исполнения ожидавших ордеров.
+func (b *Broker) Mark(symbol string, md *types.MarketData) []*Fill {
+	if last := b.prices[symbol]; last > 0 && md.ClosePrice > 0 {
+		b.returns[symbol] = pushWindow(b.returns[symbol], math.Log(md.ClosePrice/last), b.models.window)
+	}
+	b.prices[symbol] = md.ClosePrice
+	b.volumes[symbol] = md.Volume
+	b.bars[symbol] = md
+	b.now = md.Timestamp
+
+	var fills []*Fill
+	resting := b.resting[:0]
+	for _, order := range b.resting {
+		if order.signal.Symbol != symbol {
+			resting = append(resting, order)
+			continue
+		}
+		price, ok := crossed(order.signal, md)
+		if !ok {
+			resting = append(resting, order)
+			continue
+		}
+		fill, err := b.fill(order.signal, price, true, md.Timestamp)
+		if err != nil {
+			b.rejected++
+			continue
+		}
+		fills = append(fills, fill)
+	}
+	b.resting = resting
+
+	pending := b.pending[:0]
+	for _, order := range b.pending {
+		if order.signal.Symbol != symbol || order.due.After(md.Timestamp) {
+			pending = append(pending, order)
+			continue
+		}
+		fill, err := b.execute(order.signal, md.Timestamp)
+		if err != nil {
+			b.rejected++
+			continue
+		}
+		if fill == nil {
+			b.resting = append(b.resting, order)
+			continue
+		}
+		fill.Latency = md.Timestamp.Sub(order.placed)
+		b.costs.Delayed++
+		b.latency += fill.Latency
+		fills = append(fills, fill)
+	}
+	b.pending = pending
+
+	equity := b.Equity()
+	b.peak = max(b.peak, equity)
+	if b.peak > 0 {
+		b.maxDrawdown = max(b.maxDrawdown, (b.peak-equity)/b.peak)
+	}
+
+	// несколько символов на одно время дают одну точку
+	if n := len(b.equity); n > 0 && b.equity[n-1].Time.Equal(b.now) {
+		b.equity[n-1].Equity = equity
+		return fills
+	}
+	b.equity = append(b.equity, EquityPoint{Time: b.now, Equity: equity})
+	return fills
+}
+
+// Execute исполняет сигнал стратегии. Если задана модель задержки или цена лимитного ордера не достигнута,
+// ордер откладывается и Execute возвращает nil без ошибки, исполнение вернёт Mark.
+func (b *Broker) Execute(signal *types.Signal) (*Fill, error) {
+	if signal.Amount <= 0 {
+		b.rejected++
+		return nil, fmt.Errorf("%w: zero amount", ErrRejected)
+	}
+
+	placed := b.now
+	if !signal.Time.IsZero() {
+		placed = signal.Time
+	}
+
+	if b.models.latency != nil {
+		if delay := b.models.latency.Latency(); delay > 0 {
+			b.pending = append(b.pending, &pendingOrder{signal: signal, placed: placed, due: placed.Add(delay)})
+			return nil, nil
+		}
+	}
+
+	fill, err := b.execute(signal, placed)
+	if err != nil {
+		b.rejected++
+		return nil, err
+	}
+	if fill == nil {
+		b.resting = append(b.resting, &pendingOrder{signal: signal, placed: placed})
+	}
+	return fill, nil
+}
+
+// execute исполняет ордер по текущему состоянию рынка символа.
+// Возвращает nil без ошибки, если текущая свеча не достигла цены лимитного ордера.
+func (b *Broker) execute(signal *types.Signal, at time.Time) (*Fill, error) {
+	if signal.Type != types.OrderTypeLimit {
+		return b.fill(signal, 0, false, at)
+	}
+
+	bar := b.bars[signal.Symbol]
+	if bar == nil {
+		return nil, nil
+	}
+
+	buy := signal.Side == types.SideBuy
+	switch {
+	case buy && signal.Price > bar.HightPrice, !buy && signal.Price < bar.LowPrice:
+		// цена лучше всей свечи: ордер исполняется по рынку
+		return b.fill(signal, signal.Price, false, at)
+	case buy && signal.Price >= bar.LowPrice, !buy && signal.Price <= bar.HightPrice:
+		return b.fill(signal, signal.Price, true, at)
+	}
+	return nil, nil
+}
+
+// fill исполняет ордер: мейкерский — по цене price, остальные — по рынку с издержками, но не хуже price, если она задана
+func (b *Broker) fill(signal *types.Signal, price float64, maker bool, at time.Time) (*Fill, error) {
+	fill := &Fill{
+		Symbol:   signal.Symbol,
+		Side:     signal.Side,
+		Type:     signal.Type,
+		Quantity: signal.Amount,
+		Price:    signal.Price,
+		Time:     at,
+		Reason:   signal.Reason,
+	}
+
+	if maker {
+		fill.Price = price
+	} else {
+		market := b.market(signal.Symbol)
+		if market.Price > 0 {
+			fill.Price = market.Price
+		}
+		base := fill.Price
+
+		spread := b.settings.Spread / 2
+		var slippage, impact float64
+		if b.models.slippage != nil {
+			slippage = b.models.slippage.Slippage(market)
+		}
+		if b.models.impact != nil {
+			impact = b.models.impact.Impact(signal.Amount, market)
+		}
+
+		notional := fill.Price * signal.Amount
+		fill.Spread, fill.Slippage, fill.Impact = notional*spread, notional*slippage, notional*impact
+
+		if signal.Side == types.SideBuy {
+			fill.Price *= 1 + spread + slippage + impact
+		} else {
+			fill.Price *= 1 - spread - slippage - impact
+		}
+
+		// цена ограничена лимитом, издержки исполнения уменьшаются в той же доле
+		if price > 0 && (signal.Side == types.SideBuy && fill.Price > price || signal.Side == types.SideSell && fill.Price < price) {
+			share := 0.0
+			if fill.Price != base {
+				share = math.Max((price-base)/(fill.Price-base), 0)
+			}
+			fill.Spread, fill.Slippage, fill.Impact = fill.Spread*share, fill.Slippage*share, fill.Impact*share
+			fill.Price = price
+		}
+	}
+	if fill.Price <= 0 {
+		return nil, fmt.Errorf("%w: no price for %s", ErrRejected, signal.Symbol)
+	}
+
+	notional := fill.Price * fill.Quantity
+	fill.Commission = notional * b.models.fees.Rate(maker, b.tradedVolume(at))
+
+	if err := b.apply(fill); err != nil {
+		return nil, err
+	}
+
+	b.traded = append(b.traded, tradedVolume{time: at, notional: notional})
+	b.costs.Commission += fill.Commission
+	b.costs.Spread += fill.Spread
+	b.costs.Slippage += fill.Slippage
+	b.costs.Impact += fill.Impact
+	return fill, nil
+}
+
+// market возвращает состояние рынка символа по последним свечам
+func (b *Broker) market(symbol string) Market {
+	market := Market{Price: b.prices[symbol], Volume: b.volumes[symbol]}
+
+	returns := b.returns[symbol]
+	if len(returns) > 1 {
+		var mean, variance float64
+		for _, r := range returns {
+			mean += r
+		}
+		mean /= float64(len(returns))
+		for _, r := range returns {
+			variance += (r - mean) * (r - mean)
+		}
+		market.Volatility = math.Sqrt(variance / float64(len(returns)-1))
+	}
+
+	return market
+}
+
+// tradedVolume возвращает объём исполнений за период модели комиссий до момента at
+func (b *Broker) tradedVolume(at time.Time) float64 {
+	from := at.Add(-b.models.fees.Period())
+	var volume float64
+	for i := len(b.traded) - 1; i >= 0 && b.traded[i].time.After(from); i-- {
+		volume += b.traded[i].notional
+	}
+	return volume
+}
+
+// apply изменяет деньги и позицию по исполнению
+func (b *Broker) apply(fill *Fill) error {
+	quantity := fill.Quantity
+	if fill.Side == types.SideSell {
+		quantity = -quantity
+	}
+
+	position := b.positions[fill.Symbol]
+	if position == nil {
+		position = &Position{Symbol: fill.Symbol}
+		b.positions[fill.Symbol] = position
+	}
+
+	cost := quantity*fill.Price + fill.Commission
+	if quantity > 0 && position.Quantity >= 0 && cost > b.cash {
+		return fmt.Errorf("%w: insufficient funds for %s: need %.8f, have %.8f", ErrRejected, fill.Symbol, cost, b.cash)
+	}
+
+	b.cash -= cost
+	b.fills = append(b.fills, fill)
+
+	if position.Quantity == 0 || sameSign(position.Quantity, quantity) {
+		b.increase(position, quantity, fill)
+		return nil
+	}
+
+	// уменьшение или разворот позиции
+	closed := math.Min(math.Abs(quantity), math.Abs(position.Quantity))
+	position.PnL += closed*(fill.Price-position.AveragePrice)*sign(position.Quantity) - fill.Commission
+	position.Quantity += quantity
+
+	if math.Abs(position.Quantity) < 1e-12 {
+		position.Quantity = 0
+	}
+	if position.Quantity == 0 || !sameSign(position.Quantity, -quantity) {
+		b.close(position, fill)
+	}
+	if position.Quantity != 0 && sameSign(position.Quantity, quantity) {
+		// остаток после разворота открывает новую сделку без повторного учёта комиссии
+		remainder := position.Quantity
+		position.Quantity = 0
+		b.increase(position, remainder, &Fill{Price: fill.Price, Time: fill.Time})
+	}
+	return nil
+}
+
+func (b *Broker) increase(position *Position, quantity float64, fill *Fill) {
+	if position.Quantity == 0 {
+		position.OpenTime = fill.Time
+		position.AveragePrice = 0
+		position.PnL = 0
+		position.MaxQuantity = 0
+	}
+
+	total := math.Abs(position.Quantity) + math.Abs(quantity)
+	position.AveragePrice = (math.Abs(position.Quantity)*position.AveragePrice + math.Abs(quantity)*fill.Price) / total
+	position.Quantity += quantity
+	position.PnL -= fill.Commission
+	position.MaxQuantity = max(position.MaxQuantity, math.Abs(position.Quantity))
+}
+
+func (b *Broker) close(position *Position, fill *Fill) {
+	side := "long"
+	if fill.Side == types.SideBuy {
+		side = "short"
+	}
+
+	b.trades = append(b.trades, &Trade{
+		Symbol:     position.Symbol,
+		Side:       side,
+		EntryTime:  position.OpenTime,
+		ExitTime:   fill.Time,
+		EntryPrice: position.AveragePrice,
+		ExitPrice:  fill.Price,
+		Quantity:   position.MaxQuantity,
+		PnL:        position.PnL,
+	})
+}
+
+// Cash возвращает свободные деньги
+func (b *Broker) Cash() float64 {
+	return b.cash
+}
+
+// Equity возвращает капитал: деньги плюс открытые позиции по последним ценам
+func (b *Broker) Equity() float64 {
+	equity := b.cash
+	for symbol, position := range b.positions {
+		equity += position.Quantity * b.prices[symbol]
+	}
+	return equity
+}
+
+// Position возвращает позицию символа, nil — позиции не было
+func (b *Broker) Position(symbol string) *Position {
+	return b.positions[symbol]
+}
+
+// Fills возвращает все исполнения
+func (b *Broker) Fills() []*Fill {
+	return b.fills
+}
+
+// Trades возвращает закрытые сделки
+func (b *Broker) Trades() []*Trade {
+	return b.trades
+}
+
+// EquityCurve возвращает оценки капитала по времени свечей
+func (b *Broker) EquityCurve() []EquityPoint {
+	return b.equity
+}
+
+// Pending возвращает количество ордеров, ожидающих окончания задержки или достижения лимитной цены
+func (b *Broker) Pending() int {
+	return len(b.pending) + len(b.resting)
+}
+
+// Rejected возвращает количество отклонённых ордеров
+func (b *Broker) Rejected() int {
+	return b.rejected
+}
+
+// Costs возвращает издержки исполнения
+func (b *Broker) Costs() types.BacktestCosts {
+	costs := b.costs
+	if costs.Delayed > 0 {
+		costs.Latency = (b.latency / time.Duration(costs.Delayed)).Seconds()
+	}
+	return costs
+}
+
+// MaxDrawdown возвращает максимальную просадку капитала, долю от пика
+func (b *Broker) MaxDrawdown() float64 {
+	return b.maxDrawdown
+}
+
+// Result возвращает итоги для backtest_results. Открытые позиции оцениваются по последним ценам.
+func (b *Broker) Result(start time.Time, end time.Time) *types.BacktestResult {
+	result := &types.BacktestResult{
+		StartTime:      start,
+		EndTime:        end,
+		InitialCapital: b.settings.InitialCapital,
+		FinalCapital:   b.Equity(),
+		Drawdown:       b.maxDrawdown,
+		BacktestCosts:  b.Costs(),
+	}
+	result.TotalProfit = result.FinalCapital - result.InitialCapital
+
+	for _, trade := range b.trades {
+		if trade.PnL > 0 {
+			result.SuccessfulTrades++
+		} else {
+			result.FailedTrades++
+		}
+	}
+
+	return result
+}
+
+// crossed проверяет, пересекла ли свеча цену лимитного ордера, и возвращает цену исполнения:
+// цену ордера или цену открытия, если свеча открылась с разрывом за ценой ордера
+func crossed(signal *types.Signal, md *types.MarketData) (float64, bool) {
+	if signal.Side == types.SideBuy {
+		if md.LowPrice > signal.Price {
+			return 0, false
+		}
+		if md.OpenPrice > 0 && md.OpenPrice < signal.Price {
+			return md.OpenPrice, true
+		}
+		return signal.Price, true
+	}
+
+	if md.HightPrice < signal.Price {
+		return 0, false
+	}
+	return max(signal.Price, md.OpenPrice), true
+}
+
+func pushWindow(window []float64, value float64, size int) []float64 {
+	window = append(window, value)
+	if len(window) > size {
+		window = window[len(window)-size:]
+	}
+	return window
+}
+
+func sign(x float64) float64 {
+	if x < 0 {
+		return -1
+	}
+	return 1
+}
+
+func sameSign(a float64, b float64) bool {
+	return (a > 0) == (b > 0)
+}
@@ -0,0 +1,205 @@
+package backtest
+
+import (
+	"crypto-trading-bot/internal/settings"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Market — состояние рынка символа на момент исполнения ордера
+type Market struct {
+	Price      float64 // последняя цена
+	Volume     float64 // объём последней свечи
+	Volatility float64 // стандартное отклонение логарифмической доходности свечи
+}
+
+// SlippageModel рассчитывает проскальзывание рыночного ордера, долю цены
+type SlippageModel interface {
+	Slippage(market Market) float64
+}
+
+// ImpactModel рассчитывает влияние ордера объёмом quantity на цену, долю цены
+type ImpactModel interface {
+	Impact(quantity float64, market Market) float64
+}
+
+// LatencyModel возвращает задержку от сигнала до исполнения ордера
+type LatencyModel interface {
+	Latency() time.Duration
+}
+
+// FeeModel возвращает ставку комиссии, долю объёма сделки. volume — объём торгов за период расчёта скидки.
+type FeeModel interface {
+	Rate(maker bool, volume float64) float64
+	Period() time.Duration
+}
+
+type FixedSlippage struct {
+	Value float64
+}
+
+func (m FixedSlippage) Slippage(Market) float64 {
+	return m.Value
+}
+
+type VolatilitySlippage struct {
+	Factor float64
+}
+
+func (m VolatilitySlippage) Slippage(market Market) float64 {
+	return m.Factor * market.Volatility
+}
+
+// SqrtImpact — влияние по закону квадратного корня от доли ордера в объёме свечи.
+// На свечах без объёма влияние не рассчитывается.
+type SqrtImpact struct {
+	Coefficient float64
+}
+
+func (m SqrtImpact) Impact(quantity float64, market Market) float64 {
+	if market.Volume <= 0 {
+		return 0
+	}
+	return m.Coefficient * market.Volatility * math.Sqrt(quantity/market.Volume)
+}
+
+type FixedLatency struct {
+	Delay time.Duration
+}
+
+func (m FixedLatency) Latency() time.Duration {
+	return m.Delay
+}
+
+// RandomLatency — задержка, равномерно распределённая в [Min, Max]
+type RandomLatency struct {
+	Min  time.Duration
+	Max  time.Duration
+	Rand *rand.Rand
+}
+
+func (m RandomLatency) Latency() time.Duration {
+	if m.Max <= m.Min {
+		return m.Min
+	}
+	return m.Min + time.Duration(m.Rand.Int63n(int64(m.Max-m.Min)+1))
+}
+
+// TieredFees — комиссии мейкера и тейкера. Действует уровень с наибольшим объёмом, не превышающим объём торгов.
+type TieredFees struct {
+	Maker  float64
+	Taker  float64
+	Tiers  []settings.FeeTier // по возрастанию объёма
+	Window time.Duration
+}
+
+func (m TieredFees) Rate(maker bool, volume float64) float64 {
+	rateMaker, rateTaker := m.Maker, m.Taker
+	for _, tier := range m.Tiers {
+		if volume < tier.Volume {
+			break
+		}
+		rateMaker, rateTaker = tier.Maker, tier.Taker
+	}
+	if maker {
+		return rateMaker
+	}
+	return rateTaker
+}
+
+func (m TieredFees) Period() time.Duration {
+	return m.Window
+}
+
+// costModels — модели издержек брокера, nil — модель не применяется
+type costModels struct {
+	slippage SlippageModel
+	impact   ImpactModel
+	latency  LatencyModel
+	fees     FeeModel
+	window   int // количество свечей для оценки волатильности
+}
+
+const defaultVolatilityWindow = 20
+
+// newCostModels создаёт модели по настройкам. Без модели комиссий используется постоянная ставка commission.
+func newCostModels(s *settings.CostSettings, commission float64) (costModels, error) {
+	models := costModels{
+		fees:   TieredFees{Maker: commission, Taker: commission},
+		window: defaultVolatilityWindow,
+	}
+	if s == nil {
+		return models, nil
+	}
+
+	if s.VolatilityWindow > 0 {
+		models.window = s.VolatilityWindow
+	}
+
+	if m := s.Slippage; m != nil {
+		switch m.Model {
+		case settings.SlippageFixed:
+			models.slippage = FixedSlippage{Value: m.Value}
+		case settings.SlippageVolatility:
+			models.slippage = VolatilitySlippage{Factor: m.Factor}
+		default:
+			return models, fmt.Errorf("unknown slippage model: %s", m.Model)
+		}
+	}
+
+	if m := s.Impact; m != nil {
+		models.impact = SqrtImpact{Coefficient: m.Coefficient}
+	}
+
+	if m := s.Latency; m != nil {
+		switch m.Model {
+		case settings.LatencyFixed:
+			delay, err := parseDuration(m.Delay, "latency delay")
+			if err != nil {
+				return models, err
+			}
+			models.latency = FixedLatency{Delay: delay}
+		case settings.LatencyRandom:
+			low, err := parseDuration(m.Min, "latency min")
+			if err != nil {
+				return models, err
+			}
+			high, err := parseDuration(m.Max, "latency max")
+			if err != nil {
+				return models, err
+			}
+			if high < low {
+				return models, fmt.Errorf("invalid latency range: %s - %s", m.Min, m.Max)
+			}
+			models.latency = RandomLatency{Min: low, Max: high, Rand: rand.New(rand.NewSource(m.Seed))}
+		default:
+			return models, fmt.Errorf("unknown latency model: %s", m.Model)
+		}
+	}
+
+	if m := s.Fees; m != nil {
+		period := 30 * 24 * time.Hour
+		if m.Period != "" {
+			var err error
+			if period, err = parseDuration(m.Period, "fee period"); err != nil {
+				return models, err
+			}
+		}
+		tiers := append([]settings.FeeTier(nil), m.Tiers...)
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].Volume < tiers[j].Volume })
+		models.fees = TieredFees{Maker: m.Maker, Taker: m.Taker, Tiers: tiers, Window: period}
+	}
+
+	return models, nil
+}
+
+func parseDuration(value string, name string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,103 @@
+package dataset
+
+import (
+	"crypto-trading-bot/internal/types"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadCSV читает свечи из CSV с заголовком. Обязательные колонки: timestamp, open, high, low, close;
+// необязательные: volume, buy_volume, sell_volume. timestamp — время закрытия свечи в RFC 3339
+// или в миллисекундах Unix.
+func ReadCSV(path string, symbol string, interval string) ([]*types.MarketData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := parseCSV(f, symbol, interval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+func parseCSV(r io.Reader, symbol string, interval string) ([]*types.MarketData, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"timestamp", "open", "high", "low", "close"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("column %s is missing", name)
+		}
+	}
+
+	var data []*types.MarketData
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		md := &types.MarketData{Symbol: symbol, TimeFrame: interval}
+		if md.Timestamp, err = parseTimestamp(record[columns["timestamp"]]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		fields := []struct {
+			name     string
+			value    *float64
+			optional bool
+		}{
+			{"open", &md.OpenPrice, false},
+			{"high", &md.HightPrice, false},
+			{"low", &md.LowPrice, false},
+			{"close", &md.ClosePrice, false},
+			{"volume", &md.Volume, true},
+			{"buy_volume", &md.BuyVolume, true},
+			{"sell_volume", &md.SellVolume, true},
+		}
+		for _, field := range fields {
+			i, ok := columns[field.name]
+			if !ok && field.optional {
+				continue
+			}
+			if *field.value, err = strconv.ParseFloat(strings.TrimSpace(record[i]), 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s: %w", line, field.name, err)
+			}
+		}
+
+		data = append(data, md)
+	}
+
+	return data, nil
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,68 @@
+package dataset
+
+import (
+	"crypto-trading-bot/internal/types"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Loader загружает свечи символа и интервала за период [start, end] в порядке времени.
+// Сигнатура совпадает с repositories.MarketDataRepository и marketdata.MarketDataService,
+// поэтому свечи из базы загружаются репозиторием напрямую.
+type Loader interface {
+	GetMarketDataPeriod(symbol string, interval string, start time.Time, end time.Time) ([]*types.MarketData, error)
+}
+
+var _ Loader = (*DirLoader)(nil)
+
+// DirLoader читает свечи из каталога с файлами <SYMBOL>_<interval>.csv или <SYMBOL>_<interval>.parquet
+type DirLoader struct {
+	dir string
+}
+
+func NewDirLoader(dir string) *DirLoader {
+	return &DirLoader{dir: dir}
+}
+
+// GetMarketDataPeriod implements Loader.
+func (l *DirLoader) GetMarketDataPeriod(symbol string, interval string, start time.Time, end time.Time) ([]*types.MarketData, error) {
+	base := filepath.Join(l.dir, symbol+"_"+interval)
+
+	var data []*types.MarketData
+	var err error
+	switch {
+	case exists(base + ".csv"):
+		data, err = ReadCSV(base+".csv", symbol, interval)
+	case exists(base + ".parquet"):
+		data, err = ReadParquet(base+".parquet", symbol, interval)
+	default:
+		return nil, fmt.Errorf("no %s.csv or %s.parquet in %s", symbol+"_"+interval, symbol+"_"+interval, l.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return period(data, start, end), nil
+}
+
+func exists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// period сортирует свечи по времени и оставляет свечи периода [start, end], нулевые границы не ограничивают
+func period(data []*types.MarketData, start time.Time, end time.Time) []*types.MarketData {
+	sort.SliceStable(data, func(i, j int) bool { return data[i].Timestamp.Before(data[j].Timestamp) })
+
+	result := data[:0]
+	for _, md := range data {
+		if (!start.IsZero() && md.Timestamp.Before(start)) || (!end.IsZero() && md.Timestamp.After(end)) {
+			continue
+		}
+		result = append(result, md)
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const candlesCSV = `timestamp,open,high,low,close,volume
+2025-01-01T00:02:00Z,101,103,100,102,7
+1735689660000,100,102,99,101,5
+2025-01-01T00:03:00Z,102,104,101,103,9
+`
+
+func TestParseCSV(t *testing.T) {
+	data, err := parseCSV(strings.NewReader(candlesCSV), "BTCUSDT", "1m")
+	assert.NoError(t, err)
+	assert.Len(t, data, 3)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC), data[1].Timestamp)
+	assert.Equal(t, "BTCUSDT", data[1].Symbol)
+	assert.Equal(t, 102.0, data[0].ClosePrice)
+	assert.Equal(t, 5.0, data[1].Volume)
+
+	_, err = parseCSV(strings.NewReader("timestamp,open,high,low\n"), "BTCUSDT", "1m")
+	assert.Error(t, err)
+	_, err = parseCSV(strings.NewReader("timestamp,open,high,low,close\nyesterday,1,1,1,1\n"), "BTCUSDT", "1m")
+	assert.Error(t, err)
+}
+
+func TestDirLoader(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "BTCUSDT_1m.csv"), []byte(candlesCSV), 0o644))
+
+	loader := NewDirLoader(dir)
+	start := time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC)
+	end := time.Date(2025, 1, 1, 0, 2, 0, 0, time.UTC)
+
+	// свечи сортируются по времени и ограничиваются периодом
+	data, err := loader.GetMarketDataPeriod("BTCUSDT", "1m", start, end)
+	assert.NoError(t, err)
+	if assert.Len(t, data, 2) {
+		assert.Equal(t, 101.0, data[0].ClosePrice)
+		assert.Equal(t, 102.0, data[1].ClosePrice)
+	}
+
+	// тот же ряд в Parquet
+	assert.NoError(t, WriteParquet(filepath.Join(dir, "ETHUSDT_1m.parquet"), data))
+	parquetData, err := loader.GetMarketDataPeriod("ETHUSDT", "1m", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, parquetData, 2) {
+		assert.Equal(t, "ETHUSDT", parquetData[0].Symbol)
+		assert.Equal(t, data[0].Timestamp, parquetData[0].Timestamp)
+		assert.Equal(t, data[1].HightPrice, parquetData[1].HightPrice)
+		assert.Equal(t, data[1].Volume, parquetData[1].Volume)
+	}
+
+	_, err = loader.GetMarketDataPeriod("XRPUSDT", "1m", start, end)
+	assert.Error(t, err)
+}
+
+func TestSyntheticLoader(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	loader := NewSyntheticLoader(42, 0, 0)
+	data, err := loader.GetMarketDataPeriod("BTCUSDT", "1h", start, end)
+	assert.NoError(t, err)
+	assert.Len(t, data, 24)
+	assert.Equal(t, start.Add(time.Hour), data[0].Timestamp)
+	assert.Equal(t, 100.0, data[0].OpenPrice)
+	for i, md := range data {
+		assert.GreaterOrEqual(t, md.HightPrice, max(md.OpenPrice, md.ClosePrice))
+		assert.LessOrEqual(t, md.LowPrice, min(md.OpenPrice, md.ClosePrice))
+		assert.InDelta(t, md.Volume, md.BuyVolume+md.SellVolume, 1e-9)
+		if i > 0 {
+			assert.Equal(t, data[i-1].ClosePrice, md.OpenPrice)
+		}
+	}
+
+	// ряд воспроизводится и различается по символам
+	again, _ := NewSyntheticLoader(42, 0, 0).GetMarketDataPeriod("BTCUSDT", "1h", start, end)
+	assert.Equal(t, data, again)
+	other, _ := loader.GetMarketDataPeriod("ETHUSDT", "1h", start, end)
+	assert.NotEqual(t, data[5].ClosePrice, other[5].ClosePrice)
+
+	_, err = loader.GetMarketDataPeriod("BTCUSDT", "1h", time.Time{}, end)
+	assert.Error(t, err)
+}
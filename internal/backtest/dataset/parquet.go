@@ -0,0 +1,64 @@
+package dataset
+
+import (
+	"crypto-trading-bot/internal/types"
+	"fmt"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetCandle — строка файла Parquet со свечой, timestamp — время закрытия свечи в миллисекундах Unix
+type parquetCandle struct {
+	Timestamp  int64   `parquet:"timestamp"`
+	Open       float64 `parquet:"open"`
+	High       float64 `parquet:"high"`
+	Low        float64 `parquet:"low"`
+	Close      float64 `parquet:"close"`
+	Volume     float64 `parquet:"volume,optional"`
+	BuyVolume  float64 `parquet:"buy_volume,optional"`
+	SellVolume float64 `parquet:"sell_volume,optional"`
+}
+
+// ReadParquet читает свечи из файла Parquet с колонками как у ReadCSV
+func ReadParquet(path string, symbol string, interval string) ([]*types.MarketData, error) {
+	rows, err := parquet.ReadFile[parquetCandle](path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	data := make([]*types.MarketData, len(rows))
+	for i, row := range rows {
+		data[i] = &types.MarketData{
+			Symbol:     symbol,
+			TimeFrame:  interval,
+			Timestamp:  time.UnixMilli(row.Timestamp).UTC(),
+			OpenPrice:  row.Open,
+			HightPrice: row.High,
+			LowPrice:   row.Low,
+			ClosePrice: row.Close,
+			Volume:     row.Volume,
+			BuyVolume:  row.BuyVolume,
+			SellVolume: row.SellVolume,
+		}
+	}
+	return data, nil
+}
+
+// WriteParquet записывает свечи в файл Parquet в формате ReadParquet
+func WriteParquet(path string, data []*types.MarketData) error {
+	rows := make([]parquetCandle, len(data))
+	for i, md := range data {
+		rows[i] = parquetCandle{
+			Timestamp:  md.Timestamp.UnixMilli(),
+			Open:       md.OpenPrice,
+			High:       md.HightPrice,
+			Low:        md.LowPrice,
+			Close:      md.ClosePrice,
+			Volume:     md.Volume,
+			BuyVolume:  md.BuyVolume,
+			SellVolume: md.SellVolume,
+		}
+	}
+	return parquet.WriteFile(path, rows)
+}
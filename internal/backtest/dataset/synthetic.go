@@ -0,0 +1,73 @@
+package dataset
+
+import (
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/internal/utils"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"time"
+)
+
+var _ Loader = (*SyntheticLoader)(nil)
+
+// SyntheticLoader генерирует свечи геометрического броуновского движения.
+// Ряд определяется seed, символом и интервалом: повторная загрузка даёт те же свечи.
+// Отметки времени — границы интервала после start, как время закрытия свечей.
+type SyntheticLoader struct {
+	seed       int64
+	price      float64 // цена открытия первой свечи
+	volatility float64 // стандартное отклонение логарифмической доходности за час
+}
+
+// NewSyntheticLoader создаёт генератор. price по умолчанию 100, volatility — 0.01.
+func NewSyntheticLoader(seed int64, price float64, volatility float64) *SyntheticLoader {
+	if price <= 0 {
+		price = 100
+	}
+	if volatility <= 0 {
+		volatility = 0.01
+	}
+	return &SyntheticLoader{seed: seed, price: price, volatility: volatility}
+}
+
+// GetMarketDataPeriod implements Loader.
+func (l *SyntheticLoader) GetMarketDataPeriod(symbol string, interval string, start time.Time, end time.Time) ([]*types.MarketData, error) {
+	step, err := utils.IntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	if start.IsZero() || end.IsZero() {
+		return nil, fmt.Errorf("synthetic data requires start and end of period")
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(symbol + "_" + interval))
+	rng := rand.New(rand.NewSource(l.seed ^ int64(h.Sum64())))
+
+	sigma := l.volatility * math.Sqrt(step.Hours())
+	price := l.price
+
+	var data []*types.MarketData
+	for t := start.Truncate(step).Add(step); !t.After(end); t = t.Add(step) {
+		open := price
+		price = open * math.Exp(sigma*rng.NormFloat64())
+		volume := 100 * (1 + math.Abs(rng.NormFloat64()))
+		buy := volume * rng.Float64()
+
+		data = append(data, &types.MarketData{
+			Symbol:     symbol,
+			TimeFrame:  interval,
+			Timestamp:  t,
+			OpenPrice:  open,
+			HightPrice: math.Max(open, price) * (1 + math.Abs(rng.NormFloat64())*sigma/2),
+			LowPrice:   math.Min(open, price) * (1 - math.Abs(rng.NormFloat64())*sigma/2),
+			ClosePrice: price,
+			Volume:     volume,
+			BuyVolume:  buy,
+			SellVolume: volume - buy,
+		})
+	}
+	return data, nil
+}
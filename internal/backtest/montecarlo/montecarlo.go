@@ -0,0 +1,384 @@
+package montecarlo
+
+import (
+	"context"
+	"crypto-trading-bot/internal/backtest/optimize"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// Distribution — распределение показателя по симуляциям
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	P5     float64 `json:"p5"`
+	P25    float64 `json:"p25"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	P95    float64 `json:"p95"`
+	Lower  float64 `json:"lower"` // нижняя граница доверительного интервала
+	Upper  float64 `json:"upper"` // верхняя граница доверительного интервала
+}
+
+// Probability — доля симуляций с событием и её доверительный интервал Уилсона
+type Probability struct {
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Simulation — итог одной симуляции
+type Simulation struct {
+	FinalEquity float64
+	MaxDrawdown float64 // доля от пика
+	Ruined      bool    // капитал опускался до уровня разорения
+}
+
+// Analysis — итог симуляций одного метода
+type Analysis struct {
+	Method      string       `json:"method"`
+	Simulations int          `json:"simulations"`
+	FinalEquity Distribution `json:"final_equity"`
+	MaxDrawdown Distribution `json:"max_drawdown"`
+	RiskOfRuin  Probability  `json:"risk_of_ruin"`
+}
+
+// Analyzer проверяет устойчивость результата прогона бэктеста к порядку и составу сделок
+// и к шуму входных свечей.
+//
+// Методы shuffle, bootstrap и skip строят кривую капитала из результатов закрытых сделок прогона
+// без повторного бэктеста, поэтому просадка считается по капиталу на моменты закрытия сделок.
+// Метод noise прогоняет стратегию заново на свечах с шумом цен и берёт просадку по кривой капитала брокера.
+type Analyzer struct {
+	settings   settings.MonteCarloSettings
+	strategies *strategy.Registry
+	broker     []settings.Settings
+	repo       repositories.BacktestResultRepository
+	logger     *logger.Logger
+}
+
+// NewAnalyzer создаёт анализ. broker — настройки backtest и costs для прогонов метода noise.
+// Если repo равен nil, результаты не сохраняются.
+func NewAnalyzer(strategies *strategy.Registry,
+	broker []settings.Settings,
+	repo repositories.BacktestResultRepository,
+	logger *logger.Logger,
+	comps ...settings.Settings) (*Analyzer, error) {
+
+	a := &Analyzer{strategies: strategies, broker: broker, repo: repo, logger: logger}
+	for _, c := range comps {
+		if val, ok := c.(*settings.MonteCarloSettings); ok {
+			a.settings = *val
+		}
+	}
+
+	if len(a.settings.Methods) == 0 || a.settings.Simulations <= 0 {
+		return nil, fmt.Errorf("monte carlo settings are not set")
+	}
+	if a.settings.Confidence <= 0 || a.settings.Confidence >= 1 {
+		return nil, fmt.Errorf("invalid monte carlo confidence: %v", a.settings.Confidence)
+	}
+	if a.settings.RuinLevel <= 0 || a.settings.RuinLevel > 1 {
+		return nil, fmt.Errorf("invalid monte carlo ruin level: %v", a.settings.RuinLevel)
+	}
+
+	return a, nil
+}
+
+// Run анализирует завершённый прогон и сохраняет результаты рядом с его записью backtest_results.
+// config и data — настройки стратегии и свечи прогона, нужны только методу noise.
+func (a *Analyzer) Run(ctx context.Context,
+	result *types.BacktestResult,
+	trades []*types.BacktestTrade,
+	config json.RawMessage,
+	data []*types.MarketData) ([]*Analysis, error) {
+
+	analyses, err := a.Analyze(ctx, result, trades, config, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.repo != nil {
+		rows := make([]*types.BacktestMonteCarlo, 0, len(analyses))
+		for _, analysis := range analyses {
+			row, err := a.row(result.ID, analysis)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+		if err := a.repo.SaveBacktestMonteCarlo(rows); err != nil {
+			return nil, err
+		}
+	}
+
+	return analyses, nil
+}
+
+// Analyze выполняет симуляции всех методов настроек. Каждый метод использует свой генератор
+// с начальным значением из настроек, поэтому его результат не зависит от остальных методов.
+func (a *Analyzer) Analyze(ctx context.Context,
+	result *types.BacktestResult,
+	trades []*types.BacktestTrade,
+	config json.RawMessage,
+	data []*types.MarketData) ([]*Analysis, error) {
+
+	pnl := make([]float64, len(trades))
+	for i, trade := range trades {
+		pnl[i] = trade.PnL
+	}
+	floor := result.InitialCapital * (1 - a.settings.RuinLevel)
+
+	var analyses []*Analysis
+	for _, method := range a.settings.Methods {
+		rng := rand.New(rand.NewSource(a.settings.Seed))
+		simulations := make([]Simulation, 0, a.settings.Simulations)
+
+		for i := 0; i < a.settings.Simulations; i++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			var simulation Simulation
+			switch method {
+			case settings.MonteCarloShuffle:
+				simulation = simulate(result.InitialCapital, floor, shuffle(rng, pnl))
+			case settings.MonteCarloBootstrap:
+				simulation = simulate(result.InitialCapital, floor, bootstrap(rng, pnl))
+			case settings.MonteCarloSkip:
+				simulation = simulate(result.InitialCapital, floor, skip(rng, pnl, a.settings.SkipRate))
+			case settings.MonteCarloNoise:
+				var err error
+				if simulation, err = a.noise(ctx, rng, floor, config, data); err != nil {
+					return nil, fmt.Errorf("noise simulation %d: %w", i, err)
+				}
+			default:
+				return nil, fmt.Errorf("unknown monte carlo method: %s", method)
+			}
+			simulations = append(simulations, simulation)
+		}
+
+		analysis := a.summarize(method, simulations)
+		analyses = append(analyses, analysis)
+
+		a.logger.Infof("Monte Carlo %s of backtest %d: final equity %.2f [%.2f, %.2f], max drawdown %.2f%% [%.2f%%, %.2f%%], risk of ruin %.2f%%",
+			method, result.ID, analysis.FinalEquity.Median, analysis.FinalEquity.Lower, analysis.FinalEquity.Upper,
+			analysis.MaxDrawdown.Median*100, analysis.MaxDrawdown.Lower*100, analysis.MaxDrawdown.Upper*100,
+			analysis.RiskOfRuin.Value*100)
+	}
+
+	return analyses, nil
+}
+
+// noise прогоняет стратегию на копии свечей, цены которых умножены на логнормальный шум
+func (a *Analyzer) noise(ctx context.Context, rng *rand.Rand, floor float64, config json.RawMessage, data []*types.MarketData) (Simulation, error) {
+	if len(data) == 0 {
+		return Simulation{}, fmt.Errorf("no market data for noise method")
+	}
+
+	result, broker, err := optimize.NewBacktestEvaluator(a.strategies, perturb(rng, data, a.settings.Noise), a.broker, a.logger).Backtest(ctx, config)
+	if err != nil {
+		return Simulation{}, err
+	}
+
+	simulation := Simulation{FinalEquity: result.FinalCapital, MaxDrawdown: result.Drawdown}
+	for _, point := range broker.EquityCurve() {
+		if point.Equity <= floor {
+			simulation.Ruined = true
+			break
+		}
+	}
+	return simulation, nil
+}
+
+func (a *Analyzer) summarize(method string, simulations []Simulation) *Analysis {
+	equity := make([]float64, len(simulations))
+	drawdown := make([]float64, len(simulations))
+	ruined := 0
+	for i, s := range simulations {
+		equity[i] = s.FinalEquity
+		drawdown[i] = s.MaxDrawdown
+		if s.Ruined {
+			ruined++
+		}
+	}
+
+	return &Analysis{
+		Method:      method,
+		Simulations: len(simulations),
+		FinalEquity: distribution(equity, a.settings.Confidence),
+		MaxDrawdown: distribution(drawdown, a.settings.Confidence),
+		RiskOfRuin:  wilson(ruined, len(simulations), a.settings.Confidence),
+	}
+}
+
+func (a *Analyzer) row(resultID int, analysis *Analysis) (*types.BacktestMonteCarlo, error) {
+	finalEquity, err := json.Marshal(analysis.FinalEquity)
+	if err != nil {
+		return nil, err
+	}
+	maxDrawdown, err := json.Marshal(analysis.MaxDrawdown)
+	if err != nil {
+		return nil, err
+	}
+	riskOfRuin, err := json.Marshal(analysis.RiskOfRuin)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.BacktestMonteCarlo{
+		ResultID:    resultID,
+		Method:      analysis.Method,
+		Simulations: analysis.Simulations,
+		Seed:        a.settings.Seed,
+		Confidence:  a.settings.Confidence,
+		RuinLevel:   a.settings.RuinLevel,
+		FinalEquity: finalEquity,
+		MaxDrawdown: maxDrawdown,
+		RiskOfRuin:  riskOfRuin,
+	}, nil
+}
+
+// simulate строит кривую капитала по результатам сделок в заданном порядке
+func simulate(initial float64, floor float64, pnl []float64) Simulation {
+	equity, peak := initial, initial
+	simulation := Simulation{}
+	for _, p := range pnl {
+		equity += p
+		peak = math.Max(peak, equity)
+		if peak > 0 {
+			simulation.MaxDrawdown = math.Max(simulation.MaxDrawdown, (peak-equity)/peak)
+		}
+		if equity <= floor {
+			simulation.Ruined = true
+		}
+	}
+	simulation.FinalEquity = equity
+	return simulation
+}
+
+// shuffle возвращает сделки в случайном порядке
+func shuffle(rng *rand.Rand, pnl []float64) []float64 {
+	result := slices.Clone(pnl)
+	rng.Shuffle(len(result), func(i, j int) {
+		result[i], result[j] = result[j], result[i]
+	})
+	return result
+}
+
+// bootstrap возвращает столько же сделок, выбранных с возвращением
+func bootstrap(rng *rand.Rand, pnl []float64) []float64 {
+	if len(pnl) == 0 {
+		return nil
+	}
+	result := make([]float64, len(pnl))
+	for i := range result {
+		result[i] = pnl[rng.Intn(len(pnl))]
+	}
+	return result
+}
+
+// skip возвращает сделки, каждая из которых пропущена с вероятностью rate
+func skip(rng *rand.Rand, pnl []float64, rate float64) []float64 {
+	result := make([]float64, 0, len(pnl))
+	for _, p := range pnl {
+		if rng.Float64() >= rate {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// perturb возвращает копии свечей, каждая цена которых умножена на exp(level·N(0, 1)).
+// High и Low расширяются до остальных цен, чтобы свеча осталась согласованной.
+func perturb(rng *rand.Rand, data []*types.MarketData, level float64) []*types.MarketData {
+	result := make([]*types.MarketData, len(data))
+	for i, md := range data {
+		noisy := *md
+		for _, price := range []*float64{&noisy.OpenPrice, &noisy.HightPrice, &noisy.LowPrice, &noisy.ClosePrice} {
+			*price *= math.Exp(level * rng.NormFloat64())
+		}
+		noisy.HightPrice, noisy.LowPrice =
+			max(noisy.OpenPrice, noisy.HightPrice, noisy.LowPrice, noisy.ClosePrice),
+			min(noisy.OpenPrice, noisy.HightPrice, noisy.LowPrice, noisy.ClosePrice)
+		result[i] = &noisy
+	}
+	return result
+}
+
+// distribution рассчитывает статистики выборки. Доверительный интервал — перцентили (1-c)/2 и (1+c)/2.
+func distribution(values []float64, confidence float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	var mean float64
+	for _, v := range sorted {
+		mean += v
+	}
+	mean /= float64(len(sorted))
+
+	var variance float64
+	for _, v := range sorted {
+		variance += (v - mean) * (v - mean)
+	}
+	if len(sorted) > 1 {
+		variance /= float64(len(sorted) - 1)
+	}
+
+	return Distribution{
+		Mean:   mean,
+		StdDev: math.Sqrt(variance),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		P5:     percentile(sorted, 0.05),
+		P25:    percentile(sorted, 0.25),
+		Median: percentile(sorted, 0.5),
+		P75:    percentile(sorted, 0.75),
+		P95:    percentile(sorted, 0.95),
+		Lower:  percentile(sorted, (1-confidence)/2),
+		Upper:  percentile(sorted, (1+confidence)/2),
+	}
+}
+
+// percentile возвращает перцентиль отсортированной выборки с линейной интерполяцией
+func percentile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// wilson рассчитывает долю успехов и её доверительный интервал Уилсона
+func wilson(successes int, total int, confidence float64) Probability {
+	if total == 0 {
+		return Probability{}
+	}
+
+	n := float64(total)
+	p := float64(successes) / n
+	z := math.Sqrt2 * math.Erfinv(confidence)
+
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+
+	return Probability{
+		Value: p,
+		Lower: math.Max(0, center-margin),
+		Upper: math.Min(1, center+margin),
+	}
+}
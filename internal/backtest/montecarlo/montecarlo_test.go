@@ -0,0 +1,214 @@
+package montecarlo
+
+import (
+	"context"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAnalyzer(t *testing.T, s *settings.MonteCarloSettings) *Analyzer {
+	a, err := NewAnalyzer(bandRegistry(), []settings.Settings{&settings.BacktestSettings{InitialCapital: 1000}}, nil, logger.NewLogger("error"), s)
+	assert.NoError(t, err)
+	return a
+}
+
+func tradeList(pnl ...float64) []*types.BacktestTrade {
+	trades := make([]*types.BacktestTrade, len(pnl))
+	for i, p := range pnl {
+		trades[i] = &types.BacktestTrade{Symbol: "BTCUSDT", Side: "long", PnL: p}
+	}
+	return trades
+}
+
+func TestTradeMethods(t *testing.T) {
+	a := newAnalyzer(t, &settings.MonteCarloSettings{
+		Methods:     []string{settings.MonteCarloShuffle, settings.MonteCarloBootstrap, settings.MonteCarloSkip},
+		Simulations: 500,
+		Seed:        7,
+		Confidence:  0.9,
+		RuinLevel:   0.5,
+		SkipRate:    0.3,
+	})
+	result := &types.BacktestResult{ID: 1, InitialCapital: 1000, FinalCapital: 1150}
+
+	analyses, err := a.Analyze(context.Background(), result, tradeList(100, -50, -50, -50, 200), nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, analyses, 3)
+
+	// перестановка сделок не меняет итоговый капитал, но меняет просадку
+	shuffled := analyses[0]
+	assert.Equal(t, settings.MonteCarloShuffle, shuffled.Method)
+	assert.InDelta(t, 1150.0, shuffled.FinalEquity.Min, 1e-9)
+	assert.InDelta(t, 1150.0, shuffled.FinalEquity.Max, 1e-9)
+	assert.InDelta(t, 0.0, shuffled.FinalEquity.StdDev, 1e-9)
+	// худший порядок: три убытка подряд в начале, до роста капитала
+	assert.InDelta(t, 0.15, shuffled.MaxDrawdown.Max, 1e-9)
+	assert.Less(t, shuffled.MaxDrawdown.Min, shuffled.MaxDrawdown.Max)
+	assert.Equal(t, 0.0, shuffled.RiskOfRuin.Value)
+
+	// выборка с возвращением и пропуск сделок дают разброс итогового капитала
+	for _, analysis := range analyses[1:] {
+		assert.Greater(t, analysis.FinalEquity.StdDev, 0.0)
+		assert.LessOrEqual(t, analysis.FinalEquity.Lower, analysis.FinalEquity.Median)
+		assert.LessOrEqual(t, analysis.FinalEquity.Median, analysis.FinalEquity.Upper)
+		assert.LessOrEqual(t, analysis.FinalEquity.Min, analysis.FinalEquity.Lower)
+		assert.GreaterOrEqual(t, analysis.FinalEquity.Max, analysis.FinalEquity.Upper)
+	}
+
+	// одинаковое начальное значение генератора даёт одинаковый результат
+	again, err := a.Analyze(context.Background(), result, tradeList(100, -50, -50, -50, 200), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, analyses, again)
+}
+
+func TestRiskOfRuin(t *testing.T) {
+	a := newAnalyzer(t, &settings.MonteCarloSettings{
+		Methods:     []string{settings.MonteCarloBootstrap},
+		Simulations: 2000,
+		Confidence:  0.95,
+		RuinLevel:   0.5,
+	})
+	result := &types.BacktestResult{InitialCapital: 1000}
+
+	// разорение только при двух убытках по 300 подряд: вероятность (1/2)^2
+	analyses, err := a.Analyze(context.Background(), result, tradeList(-300, 300), nil, nil)
+	assert.NoError(t, err)
+	ruin := analyses[0].RiskOfRuin
+	assert.InDelta(t, 0.25, ruin.Value, 0.05)
+	assert.Less(t, ruin.Lower, ruin.Value)
+	assert.Greater(t, ruin.Upper, ruin.Value)
+}
+
+func TestNoise(t *testing.T) {
+	data := sine(600)
+	config := json.RawMessage(`{"type": "band", "settings": {"symbol": "BTCUSDT", "buy": 92, "sell": 108}}`)
+	result := &types.BacktestResult{ID: 1, InitialCapital: 1000}
+
+	// без шума каждый прогон повторяет исходный
+	a := newAnalyzer(t, &settings.MonteCarloSettings{
+		Methods:     []string{settings.MonteCarloNoise},
+		Simulations: 3,
+		Confidence:  0.9,
+		RuinLevel:   0.5,
+	})
+	analyses, err := a.Analyze(context.Background(), result, nil, config, data)
+	assert.NoError(t, err)
+	exact := analyses[0].FinalEquity
+	assert.Greater(t, exact.Mean, 1000.0)
+	assert.InDelta(t, 0.0, exact.StdDev, 1e-9)
+
+	a = newAnalyzer(t, &settings.MonteCarloSettings{
+		Methods:     []string{settings.MonteCarloNoise},
+		Simulations: 20,
+		Seed:        3,
+		Confidence:  0.9,
+		RuinLevel:   0.5,
+		Noise:       0.01,
+	})
+	analyses, err = a.Analyze(context.Background(), result, nil, config, data)
+	assert.NoError(t, err)
+	noisy := analyses[0]
+	assert.Equal(t, 20, noisy.Simulations)
+	assert.Greater(t, noisy.FinalEquity.StdDev, 0.0)
+	assert.Equal(t, 0.0, noisy.RiskOfRuin.Value)
+
+	// исходные свечи не изменяются
+	assert.Equal(t, sine(600), data)
+}
+
+func TestPerturbKeepsCandlesConsistent(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, md := range perturb(rng, sine(100), 0.05) {
+		assert.GreaterOrEqual(t, md.HightPrice, max(md.OpenPrice, md.ClosePrice))
+		assert.LessOrEqual(t, md.LowPrice, min(md.OpenPrice, md.ClosePrice))
+		assert.Greater(t, md.LowPrice, 0.0)
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	d := distribution([]float64{5, 1, 4, 2, 3}, 0.5)
+	assert.InDelta(t, 3.0, d.Mean, 1e-9)
+	assert.InDelta(t, math.Sqrt(2.5), d.StdDev, 1e-9)
+	assert.InDelta(t, 3.0, d.Median, 1e-9)
+	assert.InDelta(t, 2.0, d.Lower, 1e-9)
+	assert.InDelta(t, 4.0, d.Upper, 1e-9)
+	assert.InDelta(t, 1.2, d.P5, 1e-9)
+
+	p := wilson(0, 100, 0.95)
+	assert.Equal(t, 0.0, p.Value)
+	assert.Equal(t, 0.0, p.Lower)
+	assert.InDelta(t, 0.037, p.Upper, 0.001)
+}
+
+func TestInvalidSettings(t *testing.T) {
+	_, err := NewAnalyzer(nil, nil, nil, logger.NewLogger("error"))
+	assert.Error(t, err)
+
+	_, err = NewAnalyzer(nil, nil, nil, logger.NewLogger("error"), &settings.MonteCarloSettings{
+		Methods: []string{settings.MonteCarloShuffle}, Simulations: 10, Confidence: 1.5, RuinLevel: 0.5,
+	})
+	assert.Error(t, err)
+}
+
+// bandSettings — настройки тестовой стратегии: покупка ниже Buy, продажа выше Sell
+type bandSettings struct {
+	Symbol string  `json:"symbol"`
+	Buy    float64 `json:"buy"`
+	Sell   float64 `json:"sell"`
+}
+
+func (bandSettings) SettingsType() string { return "band" }
+
+type bandStrategy struct {
+	settings bandSettings
+	holding  bool
+}
+
+func (s *bandStrategy) Subscriptions() []strategy.Subscription {
+	return []strategy.Subscription{{Symbol: s.settings.Symbol, Interval: "1m"}}
+}
+
+func (s *bandStrategy) OnCandle(_ context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	price := payload.MarketData.ClosePrice
+	switch {
+	case !s.holding && price <= s.settings.Buy:
+		s.holding = true
+		return []*types.Signal{{Symbol: payload.Symbol, Side: types.SideBuy, Type: types.OrderTypeMarket, Amount: 1}}, nil
+	case s.holding && price >= s.settings.Sell:
+		s.holding = false
+		return []*types.Signal{{Symbol: payload.Symbol, Side: types.SideSell, Type: types.OrderTypeMarket, Amount: 1}}, nil
+	}
+	return nil, nil
+}
+
+func bandRegistry() *strategy.Registry {
+	reg := settings.NewSettingsRegistry()
+	reg.Register("band", func() settings.Settings { return &bandSettings{} })
+	strategies := strategy.NewRegistry(reg)
+	strategies.Register("band", func(comps ...settings.Settings) (strategy.Strategy, error) {
+		return &bandStrategy{settings: *comps[0].(*bandSettings)}, nil
+	})
+	return strategies
+}
+
+// sine возвращает минутные свечи синусоиды от 90 до 110
+func sine(n int) []*types.MarketData {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var data []*types.MarketData
+	for i := range n {
+		price := 100 + 10*math.Sin(float64(i)/20)
+		data = append(data, &types.MarketData{Symbol: "BTCUSDT", TimeFrame: "1m", Timestamp: now.Add(time.Duration(i) * time.Minute),
+			OpenPrice: price, HightPrice: price, LowPrice: price, ClosePrice: price, Volume: 10})
+	}
+	return data
+}
@@ -0,0 +1,76 @@
+package optimize
+
+import (
+	"context"
+	"crypto-trading-bot/internal/backtest"
+	"crypto-trading-bot/internal/backtest/report"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/processing/sampling"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"fmt"
+)
+
+var _ Evaluator = (*BacktestEvaluator)(nil)
+
+// BacktestEvaluator прогоняет стратегию на общих для всех прогонов свечах в памяти.
+// Свечи должны относиться к первой подписке стратегии, каждый прогон создаёт свой источник и брокера.
+type BacktestEvaluator struct {
+	strategies *strategy.Registry
+	data       []*types.MarketData
+	broker     []settings.Settings
+	runner     *backtest.Runner
+}
+
+// NewBacktestEvaluator создаёт исполнителя прогонов. broker — настройки backtest и costs для брокера каждого прогона.
+func NewBacktestEvaluator(strategies *strategy.Registry, data []*types.MarketData, broker []settings.Settings, logger *logger.Logger) *BacktestEvaluator {
+	return &BacktestEvaluator{
+		strategies: strategies,
+		data:       data,
+		broker:     broker,
+		runner:     backtest.NewRunner(nil, nil, logger),
+	}
+}
+
+// Evaluate implements Evaluator.
+func (e *BacktestEvaluator) Evaluate(ctx context.Context, config json.RawMessage) (*report.Metrics, error) {
+	result, broker, err := e.Backtest(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	equity, trades := backtest.Series(0, broker)
+	return &report.Build(result, equity, trades).Metrics, nil
+}
+
+// Backtest выполняет прогон конфигурации и возвращает итоги и брокера с кривой капитала и сделками
+func (e *BacktestEvaluator) Backtest(ctx context.Context, config json.RawMessage) (*types.BacktestResult, *backtest.Broker, error) {
+	instance, err := e.strategies.Build(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subs := instance.Subscriptions()
+	if len(subs) == 0 {
+		return nil, nil, fmt.Errorf("strategy has no subscriptions")
+	}
+
+	source := sampling.NewHistoricalSourceFromData(e.data, &settings.HistoricalSourceSettings{
+		Symbol:   subs[0].Symbol,
+		Interval: subs[0].Interval,
+	})
+
+	broker, err := backtest.NewBroker(e.broker...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := e.runner.Run(ctx, 0, instance, source, broker, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, broker, nil
+}
@@ -0,0 +1,519 @@
+package optimize
+
+import (
+	"context"
+	"crypto-trading-bot/internal/backtest/report"
+	"crypto-trading-bot/internal/settings"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Параметры эволюционного поиска по умолчанию
+const (
+	defaultTournament = 2
+	defaultCrossover  = 0.9
+	mutationScale     = 0.1 // стандартное отклонение мутации числового гена в долях диапазона
+)
+
+// checkpoint — сохранённое поколение эволюционного поиска
+type checkpoint struct {
+	Generation  int           `json:"generation"`
+	Fingerprint string        `json:"fingerprint"` // хеш настроек поиска и базовой конфигурации
+	Population  []string      `json:"population"`  // ключи наборов параметров поколения
+	History     []*savedTrial `json:"history"`     // все выполненные прогоны
+}
+
+type savedTrial struct {
+	Params  Params          `json:"params"`
+	Metrics *report.Metrics `json:"metrics,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// initGenetic проверяет настройки эволюционного поиска и подставляет значения по умолчанию
+func (o *Optimizer) initGenetic() error {
+	if o.settings.Genetic == nil {
+		return fmt.Errorf("genetic settings are not set")
+	}
+
+	g := *o.settings.Genetic
+	if g.Population < 2 || g.Generations < 1 {
+		return fmt.Errorf("invalid genetic population %d or generations %d", g.Population, g.Generations)
+	}
+	if g.Tournament <= 0 {
+		g.Tournament = defaultTournament
+	}
+	if g.Crossover == 0 {
+		g.Crossover = defaultCrossover
+	}
+	if g.Mutation == 0 {
+		g.Mutation = 1 / float64(len(o.settings.Parameters))
+	}
+	o.settings.Genetic = &g
+	return nil
+}
+
+// genetic выполняет эволюционный поиск и возвращает все выполненные прогоны.
+// Поколение 0 — случайные наборы параметров. Каждое следующее поколение отбирается NSGA-II
+// из родителей и потомков: по фронтам Парето, внутри последнего фронта — по расстоянию скученности.
+// При одной цели фронты совпадают с местами по целевому показателю.
+//
+// Генератор каждого поколения создаётся заново из Seed и номера поколения,
+// поэтому продолжение с сохранённого поколения даёт тот же результат, что и поиск без остановки.
+func (o *Optimizer) genetic(ctx context.Context, config json.RawMessage) ([]*Trial, error) {
+	g := o.settings.Genetic
+
+	fingerprint, err := o.fingerprint(config)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string]*Trial)
+	var history []*Trial
+	var population []*Trial
+
+	cp, err := o.loadCheckpoint(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	next := 1
+	if cp != nil {
+		for _, saved := range cp.History {
+			trial := o.restore(saved)
+			cache[trial.Params.key()] = trial
+			history = append(history, trial)
+		}
+		for _, key := range cp.Population {
+			if trial, ok := cache[key]; ok {
+				population = append(population, trial)
+			}
+		}
+		next = cp.Generation + 1
+		o.logger.Infof("Genetic optimization resumed from generation %d", cp.Generation)
+	} else {
+		rng := o.generationRand(0)
+		population = o.evaluateCached(ctx, config, o.random(rng, g.Population, nil), cache, &history)
+		if err := o.saveCheckpoint(0, fingerprint, population, history); err != nil {
+			return nil, err
+		}
+	}
+
+	for generation := next; generation <= g.Generations; generation++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rng := o.generationRand(generation)
+		fitness := o.fitness(population)
+
+		children := make([]Params, 0, g.Population)
+		for len(children) < g.Population {
+			a := o.tournament(rng, population, fitness)
+			b := o.tournament(rng, population, fitness)
+			x, y := o.crossover(rng, a.Params, b.Params)
+			children = append(children, o.mutate(rng, x), o.mutate(rng, y))
+		}
+		offspring := o.evaluateCached(ctx, config, children[:g.Population], cache, &history)
+
+		population = o.survive(append(population, offspring...), g.Population)
+		if err := o.saveCheckpoint(generation, fingerprint, population, history); err != nil {
+			return nil, err
+		}
+
+		best := o.best(population)
+		o.logger.Debugf("Genetic optimization generation %d: %d trials, best %s = %.6f",
+			generation, len(history), o.settings.Objective, best.Objective)
+	}
+
+	return history, nil
+}
+
+func (o *Optimizer) generationRand(generation int) *rand.Rand {
+	return rand.New(rand.NewSource(o.settings.Seed + int64(generation)*1_000_003))
+}
+
+// evaluateCached выполняет прогоны только для новых наборов параметров, повторы берутся из cache
+func (o *Optimizer) evaluateCached(ctx context.Context, config json.RawMessage, points []Params, cache map[string]*Trial, history *[]*Trial) []*Trial {
+	var fresh []Params
+	queued := make(map[string]bool)
+	for _, p := range points {
+		key := p.key()
+		if cache[key] == nil && !queued[key] {
+			queued[key] = true
+			fresh = append(fresh, p)
+		}
+	}
+
+	for _, trial := range o.evaluate(ctx, config, fresh) {
+		cache[trial.Params.key()] = trial
+		*history = append(*history, trial)
+	}
+
+	trials := make([]*Trial, 0, len(points))
+	for _, p := range points {
+		if trial := cache[p.key()]; trial != nil {
+			trials = append(trials, trial)
+		}
+	}
+	return trials
+}
+
+// objectives возвращает цели прогона, приведённые к максимизации. У прогона с ошибкой все цели — минус бесконечность.
+func (o *Optimizer) objectives(t *Trial) []float64 {
+	values := make([]float64, 0, len(o.settings.Objectives)+1)
+	values = append(values, t.score(o.settings.Minimize))
+	for i, objective := range o.settings.Objectives {
+		if t.Err != nil {
+			values = append(values, math.Inf(-1))
+			continue
+		}
+		v := t.Objectives[i]
+		if objective.Minimize {
+			v = -v
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// dominates сообщает, что прогон a не хуже b по всем целям и лучше хотя бы по одной
+func (o *Optimizer) dominates(a *Trial, b *Trial) bool {
+	va, vb := o.objectives(a), o.objectives(b)
+	better := false
+	for i := range va {
+		if va[i] < vb[i] {
+			return false
+		}
+		if va[i] > vb[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// fronts разбивает прогоны на фронты Парето. Прогоны, выполнившие ограничения, идут раньше нарушивших,
+// прогоны с ошибкой образуют последний фронт.
+func (o *Optimizer) fronts(trials []*Trial) [][]*Trial {
+	groups := make([][]*Trial, 3)
+	for _, t := range trials {
+		groups[t.group()] = append(groups[t.group()], t)
+	}
+
+	var fronts [][]*Trial
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if i == 2 {
+			fronts = append(fronts, group)
+			continue
+		}
+		fronts = append(fronts, o.nonDominatedSort(group)...)
+	}
+	return fronts
+}
+
+// nonDominatedSort — быстрая недоминируемая сортировка NSGA-II
+func (o *Optimizer) nonDominatedSort(trials []*Trial) [][]*Trial {
+	n := len(trials)
+	dominated := make([][]int, n) // прогоны, которые доминирует i
+	counts := make([]int, n)      // количество прогонов, доминирующих i
+
+	var current []int
+	for i := range n {
+		for j := range n {
+			if i == j {
+				continue
+			}
+			if o.dominates(trials[i], trials[j]) {
+				dominated[i] = append(dominated[i], j)
+			} else if o.dominates(trials[j], trials[i]) {
+				counts[i]++
+			}
+		}
+		if counts[i] == 0 {
+			current = append(current, i)
+		}
+	}
+
+	var fronts [][]*Trial
+	for len(current) > 0 {
+		front := make([]*Trial, len(current))
+		var next []int
+		for k, i := range current {
+			front[k] = trials[i]
+			for _, j := range dominated[i] {
+				counts[j]--
+				if counts[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		sort.Ints(next)
+		fronts = append(fronts, front)
+		current = next
+	}
+	return fronts
+}
+
+// crowding рассчитывает расстояние скученности прогонов фронта, у крайних прогонов — бесконечность
+func (o *Optimizer) crowding(front []*Trial) map[*Trial]float64 {
+	distance := make(map[*Trial]float64, len(front))
+	if len(front) == 0 {
+		return distance
+	}
+
+	m := len(o.objectives(front[0]))
+	sorted := append([]*Trial(nil), front...)
+	for k := range m {
+		value := func(t *Trial) float64 { return o.objectives(t)[k] }
+		sort.SliceStable(sorted, func(i, j int) bool { return value(sorted[i]) < value(sorted[j]) })
+
+		lo, hi := value(sorted[0]), value(sorted[len(sorted)-1])
+		distance[sorted[0]] = math.Inf(1)
+		distance[sorted[len(sorted)-1]] = math.Inf(1)
+		if hi == lo || math.IsInf(hi-lo, 0) || math.IsNaN(hi-lo) {
+			continue
+		}
+		for i := 1; i < len(sorted)-1; i++ {
+			distance[sorted[i]] += (value(sorted[i+1]) - value(sorted[i-1])) / (hi - lo)
+		}
+	}
+	return distance
+}
+
+// rankedTrial — место прогона в поколении для турнирного отбора
+type rankedTrial struct {
+	front    int
+	crowding float64
+}
+
+// fitness рассчитывает номер фронта и расстояние скученности каждого прогона поколения
+func (o *Optimizer) fitness(population []*Trial) map[*Trial]rankedTrial {
+	result := make(map[*Trial]rankedTrial, len(population))
+	for i, front := range o.fronts(population) {
+		for t, d := range o.crowding(front) {
+			result[t] = rankedTrial{front: i, crowding: d}
+		}
+	}
+	return result
+}
+
+// tournament выбирает лучший из Tournament случайных прогонов: по фронту, затем по расстоянию скученности
+func (o *Optimizer) tournament(rng *rand.Rand, population []*Trial, fitness map[*Trial]rankedTrial) *Trial {
+	best := population[rng.Intn(len(population))]
+	for range o.settings.Genetic.Tournament - 1 {
+		candidate := population[rng.Intn(len(population))]
+		a, b := fitness[candidate], fitness[best]
+		if a.front < b.front || (a.front == b.front && a.crowding > b.crowding) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// crossover выполняет равномерное скрещивание: с вероятностью Crossover каждый ген потомки получают
+// от случайного родителя, иначе потомки повторяют родителей
+func (o *Optimizer) crossover(rng *rand.Rand, a Params, b Params) (Params, Params) {
+	x, y := make(Params, len(o.space)), make(Params, len(o.space))
+	mix := rng.Float64() < o.settings.Genetic.Crossover
+	for _, d := range o.space {
+		if mix && rng.Float64() < 0.5 {
+			x[d.Path], y[d.Path] = b[d.Path], a[d.Path]
+		} else {
+			x[d.Path], y[d.Path] = a[d.Path], b[d.Path]
+		}
+	}
+	return x, y
+}
+
+// mutate с вероятностью Mutation изменяет каждый ген: числовой — гауссовым сдвигом с привязкой
+// к шагу и границам, categorical — заменой на другое значение
+func (o *Optimizer) mutate(rng *rand.Rand, p Params) Params {
+	for _, d := range o.space {
+		if rng.Float64() >= o.settings.Genetic.Mutation {
+			continue
+		}
+
+		if d.Type == settings.ParameterCategorical {
+			if len(d.Values) > 1 {
+				current := d.index(p[d.Path])
+				next := rng.Intn(len(d.Values) - 1)
+				if next >= current && current >= 0 {
+					next++
+				}
+				p[d.Path] = d.Values[next]
+			}
+			continue
+		}
+
+		x := d.number(p[d.Path]) + rng.NormFloat64()*mutationScale*(d.Max-d.Min)
+		x = math.Max(d.Min, math.Min(d.Max, x))
+		if d.Step > 0 {
+			x = d.Min + math.Round((x-d.Min)/d.Step)*d.Step
+			if x > d.Max {
+				x -= d.Step
+			}
+		}
+		p[d.Path] = d.value(x)
+	}
+	return p
+}
+
+// survive отбирает size различных прогонов: целыми фронтами, последний фронт — по убыванию расстояния скученности
+func (o *Optimizer) survive(candidates []*Trial, size int) []*Trial {
+	seen := make(map[*Trial]bool, len(candidates))
+	unique := candidates[:0:0]
+	for _, t := range candidates {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+
+	var survivors []*Trial
+	for _, front := range o.fronts(unique) {
+		if len(survivors)+len(front) <= size {
+			survivors = append(survivors, front...)
+			continue
+		}
+		distance := o.crowding(front)
+		sorted := append([]*Trial(nil), front...)
+		sort.SliceStable(sorted, func(i, j int) bool { return distance[sorted[i]] > distance[sorted[j]] })
+		survivors = append(survivors, sorted[:size-len(survivors)]...)
+		break
+	}
+	return survivors
+}
+
+// best возвращает лучший прогон по целевому показателю
+func (o *Optimizer) best(trials []*Trial) *Trial {
+	best := trials[0]
+	for _, t := range trials[1:] {
+		if t.group() < best.group() || (t.group() == best.group() && t.score(o.settings.Minimize) > best.score(o.settings.Minimize)) {
+			best = t
+		}
+	}
+	return best
+}
+
+// fingerprint возвращает хеш настроек, от которых зависит ход поиска.
+// Количество поколений в хеш не входит, чтобы законченный поиск можно было продолжить.
+func (o *Optimizer) fingerprint(config json.RawMessage) (string, error) {
+	g := *o.settings.Genetic
+	g.Checkpoint, g.Generations = "", 0
+	data, err := json.Marshal(map[string]any{
+		"parameters":  o.settings.Parameters,
+		"seed":        o.settings.Seed,
+		"objective":   o.settings.Objective,
+		"minimize":    o.settings.Minimize,
+		"objectives":  o.settings.Objectives,
+		"constraints": o.settings.Constraints,
+		"genetic":     g,
+		"config":      config,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func checkpointPath(dir string, generation int) string {
+	return filepath.Join(dir, fmt.Sprintf("generation_%04d.json", generation))
+}
+
+// saveCheckpoint записывает поколение в каталог Checkpoint через временный файл
+func (o *Optimizer) saveCheckpoint(generation int, fingerprint string, population []*Trial, history []*Trial) error {
+	dir := o.settings.Genetic.Checkpoint
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	cp := checkpoint{Generation: generation, Fingerprint: fingerprint}
+	for _, t := range population {
+		cp.Population = append(cp.Population, t.Params.key())
+	}
+	for _, t := range history {
+		saved := &savedTrial{Params: t.Params, Metrics: t.Metrics}
+		if t.Err != nil {
+			saved.Error = t.Err.Error()
+		}
+		cp.History = append(cp.History, saved)
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	path := checkpointPath(dir, generation)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// loadCheckpoint читает последнее сохранённое поколение. Если сохранений нет, возвращает nil.
+func (o *Optimizer) loadCheckpoint(fingerprint string) (*checkpoint, error) {
+	dir := o.settings.Genetic.Checkpoint
+	if dir == "" {
+		return nil, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "generation_*.json"))
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	data, err := os.ReadFile(files[len(files)-1])
+	if err != nil {
+		return nil, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", files[len(files)-1], err)
+	}
+	if cp.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("checkpoint %s was made with other optimization settings", files[len(files)-1])
+	}
+	return &cp, nil
+}
+
+// restore восстанавливает прогон из сохранения, значения параметров приводятся к типам пространства поиска
+func (o *Optimizer) restore(saved *savedTrial) *Trial {
+	params := make(Params, len(saved.Params))
+	for _, d := range o.space {
+		v, ok := saved.Params[d.Path]
+		if !ok {
+			continue
+		}
+		if d.Type == settings.ParameterCategorical {
+			if i := d.index(v); i >= 0 {
+				v = d.Values[i]
+			}
+		} else {
+			v = d.value(d.number(v))
+		}
+		params[d.Path] = v
+	}
+
+	trial := &Trial{Params: params, Metrics: saved.Metrics}
+	if saved.Error != "" {
+		trial.Err = errors.New(saved.Error)
+	} else {
+		o.assess(trial)
+	}
+	return trial
+}
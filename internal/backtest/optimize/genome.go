@@ -0,0 +1,89 @@
+package optimize
+
+import (
+	"crypto-trading-bot/internal/settings"
+	"math"
+	"sort"
+)
+
+// Genome выводит параметры поиска из структуры настроек стратегии по их JSON Schema.
+// В геном попадают числовые поля с нижней и верхней границей в тегах validate,
+// поля с перечнем допустимых значений (oneof) и логические поля, включая поля вложенных структур.
+// Строгие границы (gt, lt) сужаются: для целых на единицу, для дробных на малую долю диапазона.
+func Genome(s settings.Settings) []settings.OptimizationParameter {
+	var params []settings.OptimizationParameter
+	addGenes(&params, "", settings.SchemaOf(s))
+	return params
+}
+
+func addGenes(params *[]settings.OptimizationParameter, prefix string, schema *settings.Schema) {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property := schema.Properties[name]
+		path := prefix + name
+
+		switch {
+		case len(property.Enum) > 0:
+			*params = append(*params, settings.OptimizationParameter{
+				Path: path, Type: settings.ParameterCategorical, Values: property.Enum,
+			})
+		case property.Type == "boolean":
+			*params = append(*params, settings.OptimizationParameter{
+				Path: path, Type: settings.ParameterCategorical, Values: []any{false, true},
+			})
+		case property.Type == "integer" || property.Type == "number":
+			if p, ok := numericGene(path, property); ok {
+				*params = append(*params, p)
+			}
+		case property.Type == "object" && property.Properties != nil:
+			addGenes(params, path+".", property)
+		}
+	}
+}
+
+func numericGene(path string, schema *settings.Schema) (settings.OptimizationParameter, bool) {
+	integer := schema.Type == "integer"
+	lower, upper := math.Inf(-1), math.Inf(1)
+
+	switch {
+	case schema.Minimum != nil && integer:
+		lower = math.Ceil(*schema.Minimum)
+	case schema.Minimum != nil:
+		lower = *schema.Minimum
+	case schema.ExclusiveMinimum != nil && integer:
+		lower = math.Floor(*schema.ExclusiveMinimum) + 1
+	case schema.ExclusiveMinimum != nil:
+		lower = *schema.ExclusiveMinimum
+	}
+	switch {
+	case schema.Maximum != nil && integer:
+		upper = math.Floor(*schema.Maximum)
+	case schema.Maximum != nil:
+		upper = *schema.Maximum
+	case schema.ExclusiveMaximum != nil && integer:
+		upper = math.Ceil(*schema.ExclusiveMaximum) - 1
+	case schema.ExclusiveMaximum != nil:
+		upper = *schema.ExclusiveMaximum
+	}
+	if math.IsInf(lower, 0) || math.IsInf(upper, 0) || upper < lower {
+		return settings.OptimizationParameter{}, false
+	}
+
+	if integer {
+		return settings.OptimizationParameter{Path: path, Type: settings.ParameterInt, Min: lower, Max: upper}, true
+	}
+
+	shift := (upper - lower) * 1e-6
+	if schema.ExclusiveMinimum != nil {
+		lower += shift
+	}
+	if schema.ExclusiveMaximum != nil {
+		upper -= shift
+	}
+	return settings.OptimizationParameter{Path: path, Type: settings.ParameterFloat, Min: lower, Max: upper}, upper > lower
+}
@@ -0,0 +1,458 @@
+package optimize
+
+import (
+	"context"
+	"crypto-trading-bot/internal/backtest/report"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// quadratic — целевая функция с максимумом в period = 37 при mode = "b"
+type quadratic struct{}
+
+func (quadratic) Evaluate(_ context.Context, config json.RawMessage) (*report.Metrics, error) {
+	var cfg struct {
+		Settings struct {
+			Period float64 `json:"period"`
+			Mode   string  `json:"mode"`
+		} `json:"settings"`
+	}
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return nil, err
+	}
+	x := cfg.Settings.Period
+	sharpe := -(x - 37) * (x - 37)
+	if cfg.Settings.Mode != "b" {
+		sharpe -= 100
+	}
+	return &report.Metrics{Sharpe: sharpe, MaxDrawdown: x / 100}, nil
+}
+
+var baseConfig = json.RawMessage(`{"type": "test", "settings": {"symbol": "BTCUSDT", "period": 10, "mode": "a"}}`)
+
+func newOptimizer(t *testing.T, s *settings.OptimizationSettings) *Optimizer {
+	o, err := NewOptimizer(quadratic{}, nil, logger.NewLogger("error"), s)
+	assert.NoError(t, err)
+	return o
+}
+
+func TestApply(t *testing.T) {
+	config := json.RawMessage(`{"type": "rules", "settings": {"amount": 0.01, "indicators": [{"period": 14}, {"period": 50}]}}`)
+
+	applied, err := Apply(config, Params{"indicators.1.period": 30, "risk.stop": 0.02})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "rules", "settings": {"amount": 0.01, "indicators": [{"period": 14}, {"period": 30}], "risk": {"stop": 0.02}}}`, string(applied))
+
+	_, err = Apply(config, Params{"indicators.5.period": 1})
+	assert.Error(t, err)
+}
+
+func TestGridSearchWithConstraint(t *testing.T) {
+	o := newOptimizer(t, &settings.OptimizationSettings{
+		Method: settings.OptimizationGrid,
+		Parameters: []settings.OptimizationParameter{
+			{Path: "period", Type: settings.ParameterInt, Min: 30, Max: 40, Step: 2},
+			{Path: "mode", Type: settings.ParameterCategorical, Values: []any{"a", "b"}},
+		},
+		Workers:     3,
+		Objective:   "sharpe",
+		Constraints: []settings.OptimizationConstraint{{Metric: "max_drawdown", Op: "lt", Value: 0.35}},
+	})
+
+	trials, err := o.Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	assert.Len(t, trials, 12)
+
+	// 36 и 38 ближе к оптимуму, но нарушают ограничение просадки
+	assert.Equal(t, Params{"period": 34, "mode": "b"}, trials[0].Params)
+	assert.True(t, trials[5].Feasible)
+	assert.False(t, trials[6].Feasible)
+	assert.Equal(t, "b", trials[6].Params["mode"])
+
+	run, err := o.Run(context.Background(), 3, baseConfig, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, run.Results, 12)
+	assert.Equal(t, 1, run.Results[0].Rank)
+	assert.JSONEq(t, `{"mode": "b", "period": 34}`, string(run.Results[0].Params))
+}
+
+func TestRandomSearchIsDeterministic(t *testing.T) {
+	s := &settings.OptimizationSettings{
+		Method:     settings.OptimizationRandom,
+		Parameters: []settings.OptimizationParameter{{Path: "period", Type: settings.ParameterFloat, Min: 0, Max: 100}},
+		Iterations: 20,
+		Workers:    4,
+		Seed:       42,
+		Objective:  "sharpe",
+	}
+
+	first, err := newOptimizer(t, s).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	second, _ := newOptimizer(t, s).Optimize(context.Background(), baseConfig)
+	assert.Len(t, first, 20)
+	for i := range first {
+		assert.Equal(t, first[i].Params, second[i].Params)
+	}
+}
+
+func TestBayesianSearch(t *testing.T) {
+	s := &settings.OptimizationSettings{
+		Method: settings.OptimizationBayesian,
+		Parameters: []settings.OptimizationParameter{
+			{Path: "period", Type: settings.ParameterInt, Min: 0, Max: 200},
+			{Path: "mode", Type: settings.ParameterCategorical, Values: []any{"a", "b", "c"}},
+		},
+		Iterations: 40,
+		Workers:    4,
+		Seed:       7,
+		Objective:  "sharpe",
+		Top:        5,
+	}
+
+	trials, err := newOptimizer(t, s).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	assert.Len(t, trials, 40)
+	assert.Equal(t, "b", trials[0].Params["mode"])
+	assert.InDelta(t, 37, trials[0].Params["period"], 10)
+
+	run, err := newOptimizer(t, s).Run(context.Background(), 1, baseConfig, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, run.Results, 5)
+}
+
+func TestInvalidSettings(t *testing.T) {
+	_, err := NewOptimizer(quadratic{}, nil, logger.NewLogger("error"), &settings.OptimizationSettings{
+		Method:     settings.OptimizationGrid,
+		Parameters: []settings.OptimizationParameter{{Path: "period", Type: settings.ParameterInt, Max: 1}},
+		Objective:  "unknown",
+	})
+	assert.Error(t, err)
+
+	o := newOptimizer(t, &settings.OptimizationSettings{
+		Method:     settings.OptimizationGrid,
+		Parameters: []settings.OptimizationParameter{{Path: "period", Type: settings.ParameterFloat, Max: 1}},
+		Objective:  "sharpe",
+	})
+	_, err = o.Optimize(context.Background(), baseConfig)
+	assert.Error(t, err)
+}
+
+// thresholdSettings — настройки тестовой стратегии: покупка ниже Buy, продажа выше Sell
+type thresholdSettings struct {
+	Symbol string  `json:"symbol"`
+	Buy    float64 `json:"buy"`
+	Sell   float64 `json:"sell"`
+}
+
+func (thresholdSettings) SettingsType() string { return "threshold" }
+
+type thresholdStrategy struct {
+	settings thresholdSettings
+	holding  bool
+}
+
+func (s *thresholdStrategy) Subscriptions() []strategy.Subscription {
+	return []strategy.Subscription{{Symbol: s.settings.Symbol, Interval: "1m"}}
+}
+
+func (s *thresholdStrategy) OnCandle(_ context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	price := payload.MarketData.ClosePrice
+	switch {
+	case !s.holding && price <= s.settings.Buy:
+		s.holding = true
+		return []*types.Signal{{Symbol: payload.Symbol, Side: types.SideBuy, Type: types.OrderTypeMarket, Amount: 1}}, nil
+	case s.holding && price >= s.settings.Sell:
+		s.holding = false
+		return []*types.Signal{{Symbol: payload.Symbol, Side: types.SideSell, Type: types.OrderTypeMarket, Amount: 1}}, nil
+	}
+	return nil, nil
+}
+
+func thresholdRegistry() *strategy.Registry {
+	reg := settings.NewSettingsRegistry()
+	reg.Register("threshold", func() settings.Settings { return &thresholdSettings{} })
+	strategies := strategy.NewRegistry(reg)
+	strategies.Register("threshold", func(comps ...settings.Settings) (strategy.Strategy, error) {
+		return &thresholdStrategy{settings: *comps[0].(*thresholdSettings)}, nil
+	})
+	return strategies
+}
+
+var (
+	thresholdConfig = json.RawMessage(`{"type": "threshold", "settings": {"symbol": "BTCUSDT"}}`)
+	thresholdSearch = &settings.OptimizationSettings{
+		Method: settings.OptimizationGrid,
+		Parameters: []settings.OptimizationParameter{
+			{Path: "buy", Type: settings.ParameterInt, Min: 91, Max: 99, Step: 4},
+			{Path: "sell", Type: settings.ParameterInt, Min: 101, Max: 109, Step: 4},
+		},
+		Workers:   4,
+		Objective: "total_return",
+	}
+	brokerSettings = []settings.Settings{&settings.BacktestSettings{InitialCapital: 1000}}
+)
+
+// sine возвращает минутные свечи синусоиды от 90 до 110
+func sine(n int) []*types.MarketData {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var data []*types.MarketData
+	for i := range n {
+		price := 100 + 10*math.Sin(float64(i)/20)
+		data = append(data, &types.MarketData{Symbol: "BTCUSDT", TimeFrame: "1m", Timestamp: now.Add(time.Duration(i) * time.Minute),
+			OpenPrice: price, HightPrice: price, LowPrice: price, ClosePrice: price, Volume: 10})
+	}
+	return data
+}
+
+func TestBacktestEvaluator(t *testing.T) {
+	evaluator := NewBacktestEvaluator(thresholdRegistry(), sine(600), brokerSettings, logger.NewLogger("error"))
+	o, err := NewOptimizer(evaluator, nil, logger.NewLogger("error"), thresholdSearch)
+	assert.NoError(t, err)
+
+	trials, err := o.Optimize(context.Background(), thresholdConfig)
+	assert.NoError(t, err)
+	assert.Len(t, trials, 9)
+	for _, trial := range trials {
+		assert.NoError(t, trial.Err)
+	}
+	// самая широкая полоса даёт наибольшую прибыль на полном размахе синусоиды
+	assert.Equal(t, Params{"buy": 91, "sell": 109}, trials[0].Params)
+	assert.Greater(t, trials[0].Objective, 0.0)
+}
+
+func TestWalkForwardWindows(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Hour)
+
+	rolling, err := NewWalkForward(nil, nil, logger.NewLogger("error"), thresholdSearch,
+		&settings.WalkForwardSettings{Mode: settings.WalkForwardRolling, InSample: "4h", OutOfSample: "2h"})
+	assert.NoError(t, err)
+	windows := rolling.Windows(start, end)
+	if assert.Len(t, windows, 3) {
+		assert.Equal(t, start.Add(2*time.Hour), windows[1].InSampleStart)
+		assert.Equal(t, start.Add(6*time.Hour), windows[1].OutOfSampleStart)
+		assert.Equal(t, end, windows[2].OutOfSampleEnd)
+	}
+
+	anchored, _ := NewWalkForward(nil, nil, logger.NewLogger("error"), thresholdSearch,
+		&settings.WalkForwardSettings{Mode: settings.WalkForwardAnchored, InSample: "4h", OutOfSample: "4h", Step: "3h"})
+	windows = anchored.Windows(start, end)
+	if assert.Len(t, windows, 2) {
+		assert.Equal(t, start, windows[1].InSampleStart)
+		assert.Equal(t, start.Add(7*time.Hour), windows[1].InSampleEnd)
+		// последнее проверочное окно обрезано концом периода
+		assert.Equal(t, end, windows[1].OutOfSampleEnd)
+	}
+
+	_, err = NewWalkForward(nil, nil, logger.NewLogger("error"), &settings.WalkForwardSettings{Mode: settings.WalkForwardRolling, InSample: "4h", OutOfSample: "2h"})
+	assert.Error(t, err)
+}
+
+func TestWalkForward(t *testing.T) {
+	data := sine(1200)
+	start, end := data[0].Timestamp, data[len(data)-1].Timestamp.Add(time.Minute)
+
+	wf, err := NewWalkForward(thresholdRegistry(), brokerSettings, logger.NewLogger("error"), thresholdSearch,
+		&settings.WalkForwardSettings{Mode: settings.WalkForwardRolling, InSample: "6h", OutOfSample: "4h"})
+	assert.NoError(t, err)
+
+	result, err := wf.Run(context.Background(), thresholdConfig, data, start, end)
+	assert.NoError(t, err)
+	if !assert.Len(t, result.Windows, 4) {
+		return
+	}
+
+	// каждое окно проверяется на своих свечах, сшитая кривая покрывает все проверочные окна
+	assert.Len(t, result.Report.Equity, len(data)-360)
+	assert.Equal(t, result.Windows[0].OutOfSampleStart, result.Report.Start)
+
+	growth := 1.0
+	for _, w := range result.Windows {
+		growth *= 1 + w.OutOfSample.TotalReturn
+	}
+	assert.InDelta(t, 1000*growth, result.Report.FinalCapital, 1e-6)
+	assert.InDelta(t, growth-1, result.Report.Metrics.TotalReturn, 1e-9)
+
+	assert.Contains(t, result.Stability, "buy")
+	assert.Contains(t, result.Stability, "sell")
+	assert.Greater(t, result.Stability["buy"].ModeShare, 0.0)
+	assert.False(t, math.IsNaN(result.Efficiency))
+}
+
+func geneticSearch(checkpoint string, generations int) *settings.OptimizationSettings {
+	return &settings.OptimizationSettings{
+		Method: settings.OptimizationGenetic,
+		Parameters: []settings.OptimizationParameter{
+			{Path: "period", Type: settings.ParameterInt, Min: 0, Max: 200},
+			{Path: "mode", Type: settings.ParameterCategorical, Values: []any{"a", "b", "c"}},
+		},
+		Workers:   4,
+		Seed:      11,
+		Objective: "sharpe",
+		Genetic:   &settings.GeneticSettings{Population: 16, Generations: generations, Checkpoint: checkpoint},
+	}
+}
+
+func TestGeneticSearch(t *testing.T) {
+	first, err := newOptimizer(t, geneticSearch("", 12)).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", first[0].Params["mode"])
+	assert.InDelta(t, 37, first[0].Params["period"], 3)
+	assert.Equal(t, 0, first[0].Front)
+
+	// одинаковый seed даёт одинаковый поиск
+	second, err := newOptimizer(t, geneticSearch("", 12)).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, len(first), len(second))
+	for i := range first {
+		assert.Equal(t, first[i].Params, second[i].Params)
+	}
+}
+
+func TestGeneticCheckpoint(t *testing.T) {
+	full, err := newOptimizer(t, geneticSearch(t.TempDir(), 8)).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+
+	// поиск, прерванный после 4 поколений и продолженный с сохранения, совпадает с поиском без остановки
+	dir := t.TempDir()
+	_, err = newOptimizer(t, geneticSearch(dir, 4)).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	resumed, err := newOptimizer(t, geneticSearch(dir, 8)).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, len(full), len(resumed))
+	for i := range full {
+		assert.Equal(t, full[i].Params, resumed[i].Params)
+		assert.Equal(t, full[i].Objective, resumed[i].Objective)
+	}
+
+	// продолжение с сохранения, где categorical параметр принимает числовые значения
+	numeric := func(generations int) *settings.OptimizationSettings {
+		s := geneticSearch(dir, generations)
+		s.Parameters = append(s.Parameters, settings.OptimizationParameter{Path: "window", Type: settings.ParameterCategorical, Values: []any{10, 20, 50}})
+		s.Genetic.Mutation = 1
+		return s
+	}
+	dir = t.TempDir()
+	_, err = newOptimizer(t, numeric(4)).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	resumed, err = newOptimizer(t, numeric(8)).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+	for _, trial := range resumed {
+		assert.Contains(t, []any{10, 20, 50}, trial.Params["window"])
+	}
+
+	// сохранение другого поиска не подходит
+	other := geneticSearch(dir, 8)
+	other.Seed = 12
+	_, err = newOptimizer(t, other).Optimize(context.Background(), baseConfig)
+	assert.Error(t, err)
+}
+
+func TestGeneticMutateNumericCategorical(t *testing.T) {
+	s := geneticSearch("", 1)
+	s.Parameters = []settings.OptimizationParameter{{Path: "window", Type: settings.ParameterCategorical, Values: []any{10, 20, 50}}}
+	s.Genetic.Mutation = 1
+	o := newOptimizer(t, s)
+
+	// мутация всегда заменяет значение другим допустимым
+	rng := rand.New(rand.NewSource(1))
+	for range 100 {
+		p := o.mutate(rng, Params{"window": 20})
+		assert.Contains(t, []any{10, 50}, p["window"])
+	}
+}
+
+func TestGeneticMultiObjective(t *testing.T) {
+	s := geneticSearch("", 10)
+	s.Objectives = []settings.OptimizationObjective{{Metric: "max_drawdown", Minimize: true}}
+
+	trials, err := newOptimizer(t, s).Optimize(context.Background(), baseConfig)
+	assert.NoError(t, err)
+
+	o := newOptimizer(t, s)
+	var front []*Trial
+	for _, trial := range trials {
+		if trial.Front == 1 {
+			front = append(front, trial)
+		}
+	}
+	assert.Greater(t, len(front), 3)
+	assert.Equal(t, front, trials[:len(front)])
+
+	// прогоны фронта не доминируют друг друга, прогон каждого следующего фронта доминируется прогоном предыдущего
+	for _, a := range front {
+		for _, b := range front {
+			assert.False(t, o.dominates(a, b))
+		}
+	}
+	for _, trial := range trials[len(front):] {
+		dominated := false
+		for _, other := range trials {
+			if other.Front == trial.Front-1 && o.dominates(other, trial) {
+				dominated = true
+				break
+			}
+		}
+		assert.True(t, dominated)
+	}
+
+	// лучший sharpe и наименьшая просадка входят во фронт
+	assert.Equal(t, "b", front[0].Params["mode"])
+	assert.InDelta(t, 37, front[0].Params["period"], 3)
+	minDrawdown := front[0].Objectives[0]
+	for _, trial := range front {
+		minDrawdown = math.Min(minDrawdown, trial.Objectives[0])
+	}
+	for _, trial := range trials {
+		assert.GreaterOrEqual(t, trial.Objectives[0], minDrawdown)
+	}
+}
+
+// genomeSettings — настройки с генами разных типов
+type genomeSettings struct {
+	Symbol  string  `json:"symbol" validate:"required"`
+	Period  int     `json:"period" validate:"gte=2,lte=50"`
+	Factor  float64 `json:"factor" validate:"gt=0,lt=1"`
+	Offset  int     `json:"offset" validate:"gt=0,lt=10"`
+	Mode    string  `json:"mode" validate:"oneof=fast slow"`
+	Trail   bool    `json:"trail"`
+	Amount  float64 `json:"amount" validate:"gt=0"`
+	Filters struct {
+		Window int `json:"window" validate:"min=1,max=5"`
+	} `json:"filters"`
+}
+
+func (genomeSettings) SettingsType() string { return "genome" }
+
+func TestGenome(t *testing.T) {
+	params := Genome(genomeSettings{})
+
+	paths := make([]string, len(params))
+	for i, p := range params {
+		paths[i] = p.Path
+	}
+	// symbol и amount без верхней границы в геном не входят
+	assert.Equal(t, []string{"factor", "filters.window", "mode", "offset", "period", "trail"}, paths)
+
+	assert.Equal(t, settings.ParameterFloat, params[0].Type)
+	assert.Greater(t, params[0].Min, 0.0)
+	assert.Less(t, params[0].Max, 1.0)
+	assert.Equal(t, settings.OptimizationParameter{Path: "filters.window", Type: settings.ParameterInt, Min: 1, Max: 5}, params[1])
+	assert.Equal(t, []any{"fast", "slow"}, params[2].Values)
+	assert.Equal(t, settings.OptimizationParameter{Path: "offset", Type: settings.ParameterInt, Min: 1, Max: 9}, params[3])
+	assert.Equal(t, settings.OptimizationParameter{Path: "period", Type: settings.ParameterInt, Min: 2, Max: 50}, params[4])
+	assert.Equal(t, []any{false, true}, params[5].Values)
+
+	_, err := newSpace(params)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,493 @@
+package optimize
+
+import (
+	"context"
+	"crypto-trading-bot/internal/backtest/report"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Evaluator выполняет бэктест конфигурации стратегии и возвращает показатели отчёта.
+// Вызывается одновременно из нескольких горутин.
+type Evaluator interface {
+	Evaluate(ctx context.Context, config json.RawMessage) (*report.Metrics, error)
+}
+
+// Trial — прогон с одним набором параметров
+type Trial struct {
+	Params     Params
+	Metrics    *report.Metrics
+	Objective  float64   // значение целевого показателя
+	Objectives []float64 // значения дополнительных целей
+	Front      int       // номер фронта Парето при нескольких целях, с 1; 0 — одна цель
+	Feasible   bool      // прогон выполнен и все ограничения соблюдены
+	Err        error
+}
+
+// score возвращает оценку прогона для сравнения: больше — лучше
+func (t *Trial) score(minimize bool) float64 {
+	if t.Err != nil {
+		return math.Inf(-1)
+	}
+	if minimize {
+		return -t.Objective
+	}
+	return t.Objective
+}
+
+// Optimizer подбирает параметры стратегии перебором, случайным поиском, TPE или эволюционным поиском.
+// Прогоны выполняются пулом из Workers горутин. Прогоны ранжируются по целевому показателю:
+// сначала прогоны, выполнившие все ограничения, затем нарушившие, последними — завершившиеся ошибкой.
+// При нескольких целях внутри групп прогоны упорядочены по фронтам Парето.
+type Optimizer struct {
+	settings  settings.OptimizationSettings
+	space     []dimension
+	evaluator Evaluator
+	repo      repositories.OptimizationRepository
+	logger    *logger.Logger
+}
+
+// NewOptimizer создаёт оптимизатор. Если repo равен nil, результаты не сохраняются.
+func NewOptimizer(evaluator Evaluator, repo repositories.OptimizationRepository, logger *logger.Logger, comps ...settings.Settings) (*Optimizer, error) {
+	o := &Optimizer{evaluator: evaluator, repo: repo, logger: logger}
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.OptimizationSettings); ok {
+			o.settings = *val
+		}
+	}
+
+	if len(o.settings.Parameters) == 0 {
+		return nil, fmt.Errorf("optimization settings are not set")
+	}
+	switch o.settings.Method {
+	case settings.OptimizationGrid:
+	case settings.OptimizationGenetic:
+		if err := o.initGenetic(); err != nil {
+			return nil, err
+		}
+	default:
+		if o.settings.Iterations <= 0 {
+			return nil, fmt.Errorf("optimization iterations are not set")
+		}
+	}
+	if o.settings.Workers <= 0 {
+		o.settings.Workers = runtime.NumCPU()
+	}
+
+	if _, err := Metric(&report.Metrics{}, o.settings.Objective); err != nil {
+		return nil, err
+	}
+	for _, objective := range o.settings.Objectives {
+		if _, err := Metric(&report.Metrics{}, objective.Metric); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range o.settings.Constraints {
+		if _, err := Metric(&report.Metrics{}, c.Metric); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if o.space, err = newSpace(o.settings.Parameters); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// Optimize ищет параметры для базовой конфигурации стратегии и возвращает прогоны по месту
+func (o *Optimizer) Optimize(ctx context.Context, config json.RawMessage) ([]*Trial, error) {
+	rng := rand.New(rand.NewSource(o.settings.Seed))
+
+	var trials []*Trial
+	switch o.settings.Method {
+	case settings.OptimizationGrid:
+		points, err := o.grid()
+		if err != nil {
+			return nil, err
+		}
+		trials = o.evaluate(ctx, config, points)
+
+	case settings.OptimizationRandom:
+		trials = o.evaluate(ctx, config, o.random(rng, o.settings.Iterations, nil))
+
+	case settings.OptimizationBayesian:
+		// начальные точки случайные, дальше пачками по числу исполнителей
+		initial := min(o.settings.Iterations, max(2*len(o.space), 5))
+		trials = o.evaluate(ctx, config, o.random(rng, initial, nil))
+		for len(trials) < o.settings.Iterations && ctx.Err() == nil {
+			n := min(o.settings.Workers, o.settings.Iterations-len(trials))
+			trials = append(trials, o.evaluate(ctx, config, o.propose(rng, trials, n))...)
+		}
+
+	case settings.OptimizationGenetic:
+		var err error
+		if trials, err = o.genetic(ctx, config); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown optimization method: %s", o.settings.Method)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(o.settings.Objectives) > 0 {
+		for i, front := range o.fronts(trials) {
+			for _, t := range front {
+				t.Front = i + 1
+			}
+		}
+	}
+	o.rank(trials)
+	return trials, nil
+}
+
+// Run выполняет оптимизацию и сохраняет лучшие Top прогонов
+func (o *Optimizer) Run(ctx context.Context, strategyID int, config json.RawMessage, start time.Time, end time.Time) (*types.OptimizationRun, error) {
+	trials, err := o.Optimize(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := json.Marshal(map[string]any{"optimization": o.settings, "strategy": config})
+	if err != nil {
+		return nil, err
+	}
+
+	run := &types.OptimizationRun{
+		StrategyID: strategyID,
+		Method:     o.settings.Method,
+		Objective:  o.settings.Objective,
+		StartTime:  start,
+		EndTime:    end,
+		Settings:   snapshot,
+	}
+
+	if o.settings.Top > 0 && len(trials) > o.settings.Top {
+		trials = trials[:o.settings.Top]
+	}
+	for i, trial := range trials {
+		result, err := trial.result(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	if len(trials) > 0 {
+		best := trials[0]
+		o.logger.Infof("Optimization of strategy %d: best %s = %.6f (feasible %t) with %s",
+			strategyID, o.settings.Objective, best.Objective, best.Feasible, best.Params.key())
+	}
+
+	if o.repo != nil {
+		if err := o.repo.SaveOptimizationRun(run); err != nil {
+			return nil, err
+		}
+	}
+
+	return run, nil
+}
+
+func (t *Trial) result(rank int) (*types.OptimizationResult, error) {
+	params, err := json.Marshal(t.Params)
+	if err != nil {
+		return nil, err
+	}
+	metrics := []byte(`{}`)
+	if t.Metrics != nil {
+		if metrics, err = json.Marshal(t.Metrics); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &types.OptimizationResult{
+		Rank:      rank,
+		Params:    params,
+		Objective: t.Objective,
+		Front:     t.Front,
+		Feasible:  t.Feasible,
+		Metrics:   metrics,
+	}
+	if t.Err != nil {
+		result.Error = t.Err.Error()
+	}
+	return result, nil
+}
+
+// evaluate выполняет прогоны пулом исполнителей, порядок результатов совпадает с порядком точек
+func (o *Optimizer) evaluate(ctx context.Context, config json.RawMessage, points []Params) []*Trial {
+	trials := make([]*Trial, len(points))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(o.settings.Workers, len(points)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				trials[i] = o.trial(ctx, config, points[i])
+			}
+		}()
+	}
+
+	for i := range points {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// прогоны, не начатые из-за отмены
+	result := trials[:0]
+	for _, trial := range trials {
+		if trial != nil {
+			result = append(result, trial)
+		}
+	}
+	return result
+}
+
+func (o *Optimizer) trial(ctx context.Context, config json.RawMessage, params Params) *Trial {
+	trial := &Trial{Params: params}
+
+	applied, err := Apply(config, params)
+	if err == nil {
+		trial.Metrics, err = o.evaluator.Evaluate(ctx, applied)
+	}
+	if err != nil {
+		trial.Err = err
+		o.logger.Debugf("Optimization trial %s failed: %v", params.key(), err)
+		return trial
+	}
+
+	o.assess(trial)
+	return trial
+}
+
+// assess рассчитывает цели прогона и проверяет ограничения по его показателям
+func (o *Optimizer) assess(trial *Trial) {
+	trial.Objective, _ = Metric(trial.Metrics, o.settings.Objective)
+	trial.Objectives = make([]float64, len(o.settings.Objectives))
+	for i, objective := range o.settings.Objectives {
+		trial.Objectives[i], _ = Metric(trial.Metrics, objective.Metric)
+	}
+	trial.Feasible = true
+	for _, c := range o.settings.Constraints {
+		value, _ := Metric(trial.Metrics, c.Metric)
+		if !satisfies(value, c.Op, c.Value) {
+			trial.Feasible = false
+			break
+		}
+	}
+}
+
+// group возвращает группу прогона: 0 — выполнил ограничения, 1 — нарушил, 2 — завершился ошибкой
+func (t *Trial) group() int {
+	switch {
+	case t.Err != nil:
+		return 2
+	case !t.Feasible:
+		return 1
+	}
+	return 0
+}
+
+// rank сортирует прогоны: выполнившие ограничения, нарушившие, с ошибкой;
+// внутри групп — по фронту Парето, если он рассчитан, затем по целевому показателю
+func (o *Optimizer) rank(trials []*Trial) {
+	sort.SliceStable(trials, func(i, j int) bool {
+		a, b := trials[i], trials[j]
+		if ga, gb := a.group(), b.group(); ga != gb {
+			return ga < gb
+		}
+		if a.Front != b.Front {
+			return a.Front < b.Front
+		}
+		return a.score(o.settings.Minimize) > b.score(o.settings.Minimize)
+	})
+}
+
+// grid возвращает все сочетания значений параметров
+func (o *Optimizer) grid() ([]Params, error) {
+	points := []Params{{}}
+	for _, d := range o.space {
+		values, err := d.values()
+		if err != nil {
+			return nil, err
+		}
+
+		next := make([]Params, 0, len(points)*len(values))
+		for _, point := range points {
+			for _, v := range values {
+				p := make(Params, len(point)+1)
+				for k, pv := range point {
+					p[k] = pv
+				}
+				p[d.Path] = v
+				next = append(next, p)
+			}
+		}
+		points = next
+	}
+	return points, nil
+}
+
+// random возвращает n случайных наборов параметров, по возможности не повторяющих seen и друг друга
+func (o *Optimizer) random(rng *rand.Rand, n int, seen map[string]bool) []Params {
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+
+	points := make([]Params, 0, n)
+	for len(points) < n {
+		var p Params
+		for attempt := 0; attempt < 100; attempt++ {
+			p = o.sample(rng)
+			if !seen[p.key()] {
+				break
+			}
+		}
+		seen[p.key()] = true
+		points = append(points, p)
+	}
+	return points
+}
+
+func (o *Optimizer) sample(rng *rand.Rand) Params {
+	p := make(Params, len(o.space))
+	for _, d := range o.space {
+		p[d.Path] = d.sample(rng)
+	}
+	return p
+}
+
+// Параметры TPE: доля лучших прогонов и количество кандидатов на одну точку
+const (
+	tpeGamma      = 0.25
+	tpeCandidates = 24
+)
+
+// propose выбирает n новых точек: из случайных кандидатов берётся кандидат с наибольшим
+// отношением плотностей l(x)/g(x), где l — оценка плотности по лучшим прогонам, g — по остальным
+func (o *Optimizer) propose(rng *rand.Rand, trials []*Trial, n int) []Params {
+	ranked := append([]*Trial(nil), trials...)
+	o.rank(ranked)
+
+	good := max(1, int(math.Ceil(tpeGamma*float64(len(ranked)))))
+	if good >= len(ranked) {
+		return o.random(rng, n, seenKeys(trials))
+	}
+
+	seen := seenKeys(trials)
+	points := make([]Params, 0, n)
+	for len(points) < n {
+		var best Params
+		bestScore := math.Inf(-1)
+		for range tpeCandidates {
+			candidate := o.sample(rng)
+			if seen[candidate.key()] {
+				continue
+			}
+			score := 0.0
+			for _, d := range o.space {
+				x := d.number(candidate[d.Path])
+				score += math.Log(d.density(x, ranked[:good])) - math.Log(d.density(x, ranked[good:]))
+			}
+			if score > bestScore {
+				best, bestScore = candidate, score
+			}
+		}
+		if best == nil {
+			// все кандидаты уже проверены, пространство почти исчерпано
+			best = o.random(rng, 1, seen)[0]
+		}
+		seen[best.key()] = true
+		points = append(points, best)
+	}
+	return points
+}
+
+// density оценивает плотность значения x по прогонам: смесь гауссовых ядер с равномерным априорным распределением,
+// для categorical — частота значения со сглаживанием
+func (d dimension) density(x float64, trials []*Trial) float64 {
+	n := float64(len(trials))
+
+	if d.Type == settings.ParameterCategorical {
+		count := 0.0
+		for _, t := range trials {
+			if d.number(t.Params[d.Path]) == x {
+				count++
+			}
+		}
+		return (count + 1) / (n + float64(len(d.Values)))
+	}
+
+	span := d.Max - d.Min
+	if span == 0 {
+		return 1
+	}
+	bandwidth := span * 0.2
+	sum := 1 / span
+	for _, t := range trials {
+		z := (x - d.number(t.Params[d.Path])) / bandwidth
+		sum += math.Exp(-z*z/2) / (bandwidth * math.Sqrt(2*math.Pi))
+	}
+	return sum / (n + 1)
+}
+
+func seenKeys(trials []*Trial) map[string]bool {
+	seen := make(map[string]bool, len(trials))
+	for _, t := range trials {
+		seen[t.Params.key()] = true
+	}
+	return seen
+}
+
+// Metric возвращает показатель отчёта по имени поля в JSON
+func Metric(metrics *report.Metrics, name string) (float64, error) {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return 0, err
+	}
+	var values map[string]float64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return 0, err
+	}
+	value, ok := values[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown metric: %s", name)
+	}
+	return value, nil
+}
+
+func satisfies(value float64, op string, limit float64) bool {
+	switch op {
+	case "lt":
+		return value < limit
+	case "lte":
+		return value <= limit
+	case "gt":
+		return value > limit
+	case "gte":
+		return value >= limit
+	}
+	return false
+}
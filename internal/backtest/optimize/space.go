@@ -0,0 +1,177 @@
+package optimize
+
+import (
+	"bytes"
+	"crypto-trading-bot/internal/settings"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Params — значения параметров прогона: путь в настройках стратегии -> значение
+type Params map[string]any
+
+// key возвращает ключ набора параметров для поиска повторов
+func (p Params) key() string {
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+// dimension — параметр пространства поиска
+type dimension struct {
+	settings.OptimizationParameter
+}
+
+func newSpace(params []settings.OptimizationParameter) ([]dimension, error) {
+	space := make([]dimension, 0, len(params))
+	for _, p := range params {
+		switch p.Type {
+		case settings.ParameterCategorical:
+			if len(p.Values) == 0 {
+				return nil, fmt.Errorf("parameter %s: values are not set", p.Path)
+			}
+		case settings.ParameterInt:
+			if p.Step == 0 {
+				p.Step = 1
+			}
+			if p.Min != math.Trunc(p.Min) || p.Max != math.Trunc(p.Max) || p.Step != math.Trunc(p.Step) {
+				return nil, fmt.Errorf("parameter %s: integer bounds expected", p.Path)
+			}
+		case settings.ParameterFloat:
+		default:
+			return nil, fmt.Errorf("parameter %s: unknown type %s", p.Path, p.Type)
+		}
+		if p.Max < p.Min {
+			return nil, fmt.Errorf("parameter %s: max < min", p.Path)
+		}
+		space = append(space, dimension{p})
+	}
+	return space, nil
+}
+
+// values возвращает значения параметра для перебора
+func (d dimension) values() ([]any, error) {
+	if d.Type == settings.ParameterCategorical {
+		return d.Values, nil
+	}
+	if d.Step <= 0 {
+		return nil, fmt.Errorf("parameter %s: step is required for grid search", d.Path)
+	}
+
+	var values []any
+	for i := 0; ; i++ {
+		v := d.Min + float64(i)*d.Step
+		if v > d.Max+d.Step*1e-9 {
+			break
+		}
+		values = append(values, d.value(v))
+	}
+	return values, nil
+}
+
+// sample возвращает случайное значение параметра
+func (d dimension) sample(rng *rand.Rand) any {
+	if d.Type == settings.ParameterCategorical {
+		return d.Values[rng.Intn(len(d.Values))]
+	}
+	if d.Step > 0 {
+		steps := int(math.Floor((d.Max-d.Min)/d.Step + 1e-9))
+		return d.value(d.Min + float64(rng.Intn(steps+1))*d.Step)
+	}
+	return d.value(d.Min + rng.Float64()*(d.Max-d.Min))
+}
+
+// value приводит число к типу параметра
+func (d dimension) value(v float64) any {
+	if d.Type == settings.ParameterInt {
+		return int(math.Round(v))
+	}
+	// убираем погрешность накопления шага
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 12, 64), 64)
+	return rounded
+}
+
+// number возвращает числовое значение параметра, для categorical — номер значения
+func (d dimension) number(v any) float64 {
+	if d.Type == settings.ParameterCategorical {
+		return float64(d.index(v))
+	}
+	switch x := v.(type) {
+	case int:
+		return float64(x)
+	case float64:
+		return x
+	}
+	return -1
+}
+
+// index возвращает номер значения categorical параметра или -1, если значения нет среди допустимых.
+// Значения сравниваются по записи, поэтому 20 из сохранения совпадает с 20 из настроек и не считается номером
+func (d dimension) index(v any) int {
+	for i, value := range d.Values {
+		if fmt.Sprint(value) == fmt.Sprint(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Apply подставляет значения параметров в настройки конфигурации стратегии {"type": ..., "settings": {...}}
+func Apply(config json.RawMessage, params Params) (json.RawMessage, error) {
+	var cfg map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.UseNumber()
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal strategy config: %w", err)
+	}
+
+	root, ok := cfg["settings"].(map[string]any)
+	if !ok {
+		root = make(map[string]any)
+		cfg["settings"] = root
+	}
+
+	for path, value := range params {
+		if err := setPath(root, strings.Split(path, "."), value); err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", path, err)
+		}
+	}
+
+	return json.Marshal(cfg)
+}
+
+// setPath записывает значение по пути. Недостающие объекты создаются, элементы массивов должны существовать.
+func setPath(node any, path []string, value any) error {
+	key := path[0]
+	last := len(path) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		if last {
+			n[key] = value
+			return nil
+		}
+		child, exists := n[key]
+		if !exists || child == nil {
+			child = make(map[string]any)
+			n[key] = child
+		}
+		return setPath(child, path[1:], value)
+
+	case []any:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(n) {
+			return fmt.Errorf("invalid array index %q", key)
+		}
+		if last {
+			n[index] = value
+			return nil
+		}
+		return setPath(n[index], path[1:], value)
+	}
+
+	return fmt.Errorf("%q is not an object or array", key)
+}
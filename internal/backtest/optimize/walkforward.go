@@ -0,0 +1,309 @@
+package optimize
+
+import (
+	"context"
+	"crypto-trading-bot/internal/backtest"
+	"crypto-trading-bot/internal/backtest/report"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// Window — пара окон walk-forward анализа, интервалы полуоткрытые [start, end)
+type Window struct {
+	InSampleStart    time.Time `json:"in_sample_start"`
+	InSampleEnd      time.Time `json:"in_sample_end"`
+	OutOfSampleStart time.Time `json:"out_of_sample_start"`
+	OutOfSampleEnd   time.Time `json:"out_of_sample_end"`
+}
+
+// WindowResult — параметры, выбранные на окне in-sample, и их показатели на обоих окнах
+type WindowResult struct {
+	Window
+	Params      Params          `json:"params"`
+	InSample    *report.Metrics `json:"in_sample"`
+	OutOfSample *report.Metrics `json:"out_of_sample"`
+	Efficiency  float64         `json:"efficiency"` // доходность в единицу времени out-of-sample к in-sample
+}
+
+// Stability — изменчивость параметра между окнами
+type Stability struct {
+	Mean      float64 `json:"mean,omitempty"`      // числовые параметры
+	StdDev    float64 `json:"std_dev,omitempty"`   // числовые параметры
+	Variation float64 `json:"variation,omitempty"` // коэффициент вариации: отклонение к модулю среднего
+	Mode      any     `json:"mode"`                // самое частое значение
+	ModeShare float64 `json:"mode_share"`          // доля окон с самым частым значением
+	Changes   int     `json:"changes"`             // количество смен значения между соседними окнами
+}
+
+// WalkForwardResult — итог walk-forward анализа
+type WalkForwardResult struct {
+	Windows    []*WindowResult      `json:"windows"`
+	Efficiency float64              `json:"efficiency"` // средняя доходность в единицу времени out-of-sample к in-sample
+	Stability  map[string]Stability `json:"stability"`
+	Report     *report.Report       `json:"out_of_sample_report"` // показатели по сшитой кривой капитала out-of-sample
+}
+
+// WalkForward оптимизирует параметры на каждом окне in-sample и проверяет лучшие параметры
+// на следующем окне out-of-sample. Кривые капитала окон out-of-sample сшиваются с переносом капитала:
+// каждое окно начинает с начального капитала, результат масштабируется на рост капитала предыдущих окон.
+//
+// Каждое окно прогоняется на своих свечах без разогрева, индикаторам с длинным периодом
+// в начале окна данных не хватает так же, как в начале обычного бэктеста.
+type WalkForward struct {
+	settings     settings.WalkForwardSettings
+	inSample     time.Duration
+	outOfSample  time.Duration
+	step         time.Duration
+	optimization *settings.OptimizationSettings
+	strategies   *strategy.Registry
+	broker       []settings.Settings
+	logger       *logger.Logger
+}
+
+// NewWalkForward создаёт анализ. comps — настройки walk_forward и optimization,
+// broker — настройки backtest и costs для брокера каждого прогона.
+func NewWalkForward(strategies *strategy.Registry, broker []settings.Settings, logger *logger.Logger, comps ...settings.Settings) (*WalkForward, error) {
+	w := &WalkForward{strategies: strategies, broker: broker, logger: logger}
+
+	for _, c := range comps {
+		switch val := c.(type) {
+		case *settings.WalkForwardSettings:
+			w.settings = *val
+		case *settings.OptimizationSettings:
+			w.optimization = val
+		}
+	}
+
+	if w.settings.Mode == "" {
+		return nil, fmt.Errorf("walk forward settings are not set")
+	}
+	if w.optimization == nil {
+		return nil, fmt.Errorf("optimization settings are not set")
+	}
+
+	var err error
+	if w.inSample, err = positiveDuration(w.settings.InSample, "in sample"); err != nil {
+		return nil, err
+	}
+	if w.outOfSample, err = positiveDuration(w.settings.OutOfSample, "out of sample"); err != nil {
+		return nil, err
+	}
+	w.step = w.outOfSample
+	if w.settings.Step != "" {
+		if w.step, err = positiveDuration(w.settings.Step, "step"); err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+func positiveDuration(value string, name string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid walk forward %s: %q", name, value)
+	}
+	return d, nil
+}
+
+// Windows разбивает период на окна. Последнее проверочное окно может быть короче заданного.
+func (w *WalkForward) Windows(start time.Time, end time.Time) []Window {
+	var windows []Window
+	for offset := time.Duration(0); ; offset += w.step {
+		window := Window{
+			InSampleStart: start.Add(offset),
+			InSampleEnd:   start.Add(offset + w.inSample),
+		}
+		if w.settings.Mode == settings.WalkForwardAnchored {
+			window.InSampleStart = start
+		}
+		window.OutOfSampleStart = window.InSampleEnd
+		window.OutOfSampleEnd = window.InSampleEnd.Add(w.outOfSample)
+
+		if !window.OutOfSampleStart.Before(end) {
+			return windows
+		}
+		if window.OutOfSampleEnd.After(end) {
+			window.OutOfSampleEnd = end
+		}
+		windows = append(windows, window)
+	}
+}
+
+// Run выполняет анализ конфигурации стратегии на свечах data, упорядоченных по времени
+func (w *WalkForward) Run(ctx context.Context, config json.RawMessage, data []*types.MarketData, start time.Time, end time.Time) (*WalkForwardResult, error) {
+	result := &WalkForwardResult{}
+
+	var initial float64
+	growth := 1.0
+	var equity []*types.BacktestEquity
+	var trades []*types.BacktestTrade
+
+	for _, window := range w.Windows(start, end) {
+		inData := slice(data, window.InSampleStart, window.InSampleEnd)
+		outData := slice(data, window.OutOfSampleStart, window.OutOfSampleEnd)
+		if len(inData) == 0 || len(outData) == 0 {
+			w.logger.Debugf("Walk forward window %v - %v skipped: no data", window.InSampleStart, window.OutOfSampleEnd)
+			continue
+		}
+
+		optimizer, err := NewOptimizer(NewBacktestEvaluator(w.strategies, inData, w.broker, w.logger), nil, w.logger, w.optimization)
+		if err != nil {
+			return nil, err
+		}
+		trials, err := optimizer.Optimize(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		if len(trials) == 0 || trials[0].Err != nil {
+			return nil, fmt.Errorf("no successful trials in window %v - %v", window.InSampleStart, window.InSampleEnd)
+		}
+		best := trials[0]
+
+		applied, err := Apply(config, best.Params)
+		if err != nil {
+			return nil, err
+		}
+		outResult, broker, err := NewBacktestEvaluator(w.strategies, outData, w.broker, w.logger).Backtest(ctx, applied)
+		if err != nil {
+			return nil, err
+		}
+		outEquity, outTrades := backtest.Series(0, broker)
+		outReport := report.Build(outResult, outEquity, outTrades)
+
+		windowResult := &WindowResult{
+			Window:      window,
+			Params:      best.Params,
+			InSample:    best.Metrics,
+			OutOfSample: &outReport.Metrics,
+		}
+		windowResult.Efficiency = efficiency(
+			best.Metrics.TotalReturn, window.InSampleEnd.Sub(window.InSampleStart),
+			outReport.Metrics.TotalReturn, window.OutOfSampleEnd.Sub(window.OutOfSampleStart))
+		result.Windows = append(result.Windows, windowResult)
+
+		// сшивка: окно начинает с капитала, накопленного предыдущими окнами
+		initial = outResult.InitialCapital
+		for _, point := range outEquity {
+			equity = append(equity, &types.BacktestEquity{Timestamp: point.Timestamp, Equity: point.Equity * growth})
+		}
+		for _, trade := range outTrades {
+			scaled := *trade
+			scaled.PnL *= growth
+			trades = append(trades, &scaled)
+		}
+		growth *= outResult.FinalCapital / outResult.InitialCapital
+
+		w.logger.Infof("Walk forward window %v - %v: params %s, in sample return %.2f%%, out of sample return %.2f%%",
+			window.OutOfSampleStart, window.OutOfSampleEnd, best.Params.key(),
+			best.Metrics.TotalReturn*100, outReport.Metrics.TotalReturn*100)
+	}
+
+	if len(result.Windows) == 0 {
+		return nil, fmt.Errorf("no walk forward windows with data in %v - %v", start, end)
+	}
+
+	first, last := result.Windows[0], result.Windows[len(result.Windows)-1]
+	result.Report = report.Build(&types.BacktestResult{
+		StartTime:      first.OutOfSampleStart,
+		EndTime:        last.OutOfSampleEnd,
+		InitialCapital: initial,
+		FinalCapital:   initial * growth,
+	}, equity, trades)
+	result.Efficiency = walkForwardEfficiency(result.Windows)
+	result.Stability = w.stability(result.Windows)
+
+	return result, nil
+}
+
+// WriteJSON записывает итог анализа в JSON
+func (r *WalkForwardResult) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// slice возвращает свечи с временем в [from, to)
+func slice(data []*types.MarketData, from time.Time, to time.Time) []*types.MarketData {
+	i := sort.Search(len(data), func(i int) bool { return !data[i].Timestamp.Before(from) })
+	j := sort.Search(len(data), func(i int) bool { return !data[i].Timestamp.Before(to) })
+	return data[i:j]
+}
+
+// efficiency сравнивает доходность в единицу времени на проверочном и оптимизационном окнах.
+// При неположительной доходности оптимизации эффективность не определена и равна нулю.
+func efficiency(inReturn float64, inPeriod time.Duration, outReturn float64, outPeriod time.Duration) float64 {
+	if inReturn <= 0 || inPeriod <= 0 || outPeriod <= 0 {
+		return 0
+	}
+	return (outReturn / outPeriod.Hours()) / (inReturn / inPeriod.Hours())
+}
+
+func walkForwardEfficiency(windows []*WindowResult) float64 {
+	var inRate, outRate float64
+	for _, w := range windows {
+		inRate += w.InSample.TotalReturn / w.InSampleEnd.Sub(w.InSampleStart).Hours()
+		outRate += w.OutOfSample.TotalReturn / w.OutOfSampleEnd.Sub(w.OutOfSampleStart).Hours()
+	}
+	if inRate <= 0 {
+		return 0
+	}
+	return outRate / inRate
+}
+
+// stability рассчитывает изменчивость каждого параметра по окнам
+func (w *WalkForward) stability(windows []*WindowResult) map[string]Stability {
+	result := make(map[string]Stability)
+	for _, p := range w.optimization.Parameters {
+		var s Stability
+		counts := make(map[string]int)
+		var values []float64
+		var previous string
+
+		for i, window := range windows {
+			value := window.Params[p.Path]
+			key := fmt.Sprint(value)
+			counts[key]++
+			if counts[key] > counts[fmt.Sprint(s.Mode)] {
+				s.Mode = value
+			}
+			if i > 0 && key != previous {
+				s.Changes++
+			}
+			previous = key
+
+			switch v := value.(type) {
+			case int:
+				values = append(values, float64(v))
+			case float64:
+				values = append(values, v)
+			}
+		}
+		s.ModeShare = float64(counts[fmt.Sprint(s.Mode)]) / float64(len(windows))
+
+		if p.Type != settings.ParameterCategorical && len(values) > 0 {
+			for _, v := range values {
+				s.Mean += v
+			}
+			s.Mean /= float64(len(values))
+			for _, v := range values {
+				s.StdDev += (v - s.Mean) * (v - s.Mean)
+			}
+			s.StdDev = math.Sqrt(s.StdDev / float64(len(values)))
+			if s.Mean != 0 {
+				s.Variation = s.StdDev / math.Abs(s.Mean)
+			}
+		}
+
+		result[p.Path] = s
+	}
+	return result
+}
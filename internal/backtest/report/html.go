@@ -0,0 +1,249 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	chartWidth  = 900
+	chartHeight = 240
+)
+
+// chart — линейный график в координатах SVG
+type chart struct {
+	Points string
+	Min    string
+	Max    string
+	Start  string
+	End    string
+}
+
+type bar struct {
+	X, Y, Width, Height float64
+	Label               string
+	Count               int
+}
+
+type monthRow struct {
+	Year   int
+	Months [12]*float64
+	Total  float64
+}
+
+// WriteHTML записывает отчёт автономной HTML-страницей: графики встроены в SVG, внешних ресурсов нет
+func (r *Report) WriteHTML(w io.Writer) error {
+	equity := make([]float64, len(r.Equity))
+	drawdown := make([]float64, len(r.Equity))
+	times := make([]time.Time, len(r.Equity))
+	for i, point := range r.Equity {
+		equity[i], drawdown[i], times[i] = point.Equity, -point.Drawdown*100, point.Time
+	}
+
+	data := map[string]any{
+		"Report":    r,
+		"Equity":    lineChart(times, equity, "%.2f"),
+		"Drawdown":  lineChart(times, drawdown, "%.2f%%"),
+		"Durations": histogram(r.Durations.Histogram),
+		"Monthly":   monthRows(r.Monthly),
+		"Months":    []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	}
+	return reportTemplate.Execute(w, data)
+}
+
+func lineChart(times []time.Time, values []float64, format string) *chart {
+	if len(values) == 0 {
+		return nil
+	}
+
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+	span := high - low
+	if span == 0 {
+		span = 1
+	}
+	first, last := times[0], times[len(times)-1]
+	period := float64(last.Sub(first))
+
+	var points strings.Builder
+	for i, v := range values {
+		x := 0.0
+		if period > 0 {
+			x = float64(times[i].Sub(first)) / period * chartWidth
+		}
+		y := chartHeight - (v-low)/span*chartHeight
+		fmt.Fprintf(&points, "%.1f,%.1f ", x, y)
+	}
+
+	return &chart{
+		Points: strings.TrimSpace(points.String()),
+		Min:    fmt.Sprintf(format, low),
+		Max:    fmt.Sprintf(format, high),
+		Start:  first.Format(time.DateTime),
+		End:    last.Format(time.DateTime),
+	}
+}
+
+func histogram(buckets []Bucket) []bar {
+	highest := 1
+	for _, b := range buckets {
+		highest = max(highest, b.Count)
+	}
+
+	width := float64(chartWidth) / float64(max(len(buckets), 1))
+	bars := make([]bar, len(buckets))
+	for i, b := range buckets {
+		height := float64(b.Count) / float64(highest) * (chartHeight - 20)
+		bars[i] = bar{
+			X:      float64(i)*width + 4,
+			Y:      chartHeight - 20 - height,
+			Width:  width - 8,
+			Height: height,
+			Label:  b.Label,
+			Count:  b.Count,
+		}
+	}
+	return bars
+}
+
+// monthRows раскладывает месячные доходности по годам, итог года — произведение месячных доходностей
+func monthRows(monthly []MonthlyReturn) []*monthRow {
+	var rows []*monthRow
+	for _, m := range monthly {
+		if len(rows) == 0 || rows[len(rows)-1].Year != m.Year {
+			rows = append(rows, &monthRow{Year: m.Year, Total: 1})
+		}
+		row := rows[len(rows)-1]
+		ret := m.Return
+		row.Months[m.Month-1] = &ret
+		row.Total *= 1 + ret
+	}
+	for _, row := range rows {
+		row.Total--
+	}
+	return rows
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(v float64) string { return fmt.Sprintf("%.2f%%", v*100) },
+	"number":  func(v float64) string { return fmt.Sprintf("%.4f", v) },
+	"money":   func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"duration": func(seconds float64) string {
+		return (time.Duration(seconds) * time.Second).String()
+	},
+	"datetime": func(t time.Time) string { return t.Format(time.DateTime) },
+	"sign": func(v float64) string {
+		if v < 0 {
+			return "neg"
+		}
+		return "pos"
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Backtest {{.Report.ResultID}}</title>
+<style>
+body { font-family: sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 20px; } h2 { font-size: 16px; margin-top: 28px; }
+table { border-collapse: collapse; font-size: 13px; }
+td, th { border: 1px solid #ddd; padding: 4px 8px; text-align: right; }
+th { background: #f4f4f4; } td.label { text-align: left; }
+.pos { color: #17803d; } .neg { color: #c0392b; }
+svg { border: 1px solid #ddd; background: #fcfcfc; }
+.axis { font-size: 11px; fill: #666; }
+.grid { display: flex; gap: 32px; flex-wrap: wrap; }
+</style>
+</head>
+<body>
+{{- $r := .Report}}
+<h1>Backtest {{$r.ResultID}}, strategy {{$r.StrategyID}}</h1>
+<p>{{datetime $r.Start}} — {{datetime $r.End}}, capital {{money $r.InitialCapital}} → {{money $r.FinalCapital}}</p>
+
+<div class="grid">
+<table>
+<tr><th colspan="2">Returns</th></tr>
+<tr><td class="label">Total return</td><td class="{{sign $r.Metrics.TotalReturn}}">{{percent $r.Metrics.TotalReturn}}</td></tr>
+<tr><td class="label">CAGR</td><td class="{{sign $r.Metrics.CAGR}}">{{percent $r.Metrics.CAGR}}</td></tr>
+<tr><td class="label">Sharpe</td><td>{{number $r.Metrics.Sharpe}}</td></tr>
+<tr><td class="label">Sortino</td><td>{{number $r.Metrics.Sortino}}</td></tr>
+<tr><td class="label">Calmar</td><td>{{number $r.Metrics.Calmar}}</td></tr>
+<tr><td class="label">Max drawdown</td><td class="neg">{{percent $r.Metrics.MaxDrawdown}}</td></tr>
+<tr><td class="label">Max drawdown duration</td><td>{{duration $r.Metrics.MaxDrawdownDuration}}</td></tr>
+</table>
+<table>
+<tr><th colspan="2">Trades</th></tr>
+<tr><td class="label">Trades</td><td>{{$r.Metrics.Trades}}</td></tr>
+<tr><td class="label">Win rate</td><td>{{percent $r.Metrics.WinRate}}</td></tr>
+<tr><td class="label">Profit factor</td><td>{{number $r.Metrics.ProfitFactor}}</td></tr>
+<tr><td class="label">Expectancy</td><td class="{{sign $r.Metrics.Expectancy}}">{{money $r.Metrics.Expectancy}}</td></tr>
+<tr><td class="label">Average win / loss</td><td>{{money $r.Metrics.AverageWin}} / {{money $r.Metrics.AverageLoss}}</td></tr>
+<tr><td class="label">Exposure</td><td>{{percent $r.Metrics.Exposure}}</td></tr>
+</table>
+<table>
+<tr><th colspan="2">Costs</th></tr>
+<tr><td class="label">Commission</td><td>{{money $r.Costs.Commission}}</td></tr>
+<tr><td class="label">Spread</td><td>{{money $r.Costs.Spread}}</td></tr>
+<tr><td class="label">Slippage</td><td>{{money $r.Costs.Slippage}}</td></tr>
+<tr><td class="label">Impact</td><td>{{money $r.Costs.Impact}}</td></tr>
+<tr><td class="label">Delayed orders</td><td>{{$r.Costs.Delayed}}</td></tr>
+<tr><td class="label">Average latency</td><td>{{printf "%.3fs" $r.Costs.Latency}}</td></tr>
+</table>
+</div>
+
+{{- define "chart"}}
+{{- if .}}
+<svg width="960" height="270" viewBox="-50 -10 960 270">
+<polyline fill="none" stroke="#2c6fbb" stroke-width="1.5" points="{{.Points}}"/>
+<text class="axis" x="-46" y="4">{{.Max}}</text>
+<text class="axis" x="-46" y="240">{{.Min}}</text>
+<text class="axis" x="0" y="256">{{.Start}}</text>
+<text class="axis" x="780" y="256">{{.End}}</text>
+</svg>
+{{- else}}
+<p>No data</p>
+{{- end}}
+{{- end}}
+
+<h2>Equity</h2>
+{{template "chart" .Equity}}
+
+<h2>Drawdown</h2>
+{{template "chart" .Drawdown}}
+
+<h2>Monthly returns</h2>
+<table>
+<tr><th>Year</th>{{range .Months}}<th>{{.}}</th>{{end}}<th>Year</th></tr>
+{{- range .Monthly}}
+<tr><td class="label">{{.Year}}</td>
+{{- range .Months}}<td{{if .}} class="{{sign .}}"{{end}}>{{if .}}{{percent .}}{{end}}</td>{{end}}
+<td class="{{sign .Total}}">{{percent .Total}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Trade duration</h2>
+<p>min {{duration $r.Durations.Min}}, median {{duration $r.Durations.Median}}, mean {{duration $r.Durations.Mean}}, max {{duration $r.Durations.Max}}</p>
+<svg width="900" height="250" viewBox="0 0 900 250">
+{{- range .Durations}}
+<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" fill="#2c6fbb"/>
+<text class="axis" x="{{.X}}" y="236">{{.Label}}: {{.Count}}</text>
+{{- end}}
+</svg>
+
+<h2>Trades</h2>
+<table>
+<tr><th>Symbol</th><th>Side</th><th>Entry</th><th>Exit</th><th>Entry price</th><th>Exit price</th><th>Quantity</th><th>PnL</th></tr>
+{{- range $r.Trades}}
+<tr><td class="label">{{.Symbol}}</td><td class="label">{{.Side}}</td><td>{{datetime .EntryTime}}</td><td>{{datetime .ExitTime}}</td>
+<td>{{number .EntryPrice}}</td><td>{{number .ExitPrice}}</td><td>{{number .Quantity}}</td><td class="{{sign .PnL}}">{{money .PnL}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,379 @@
+package report
+
+import (
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+const year = time.Duration(365.25 * 24 * float64(time.Hour))
+
+// Report — показатели прогона бэктеста, рассчитанные по сохранённой кривой капитала и сделкам
+type Report struct {
+	ResultID       int             `json:"result_id"`
+	StrategyID     int             `json:"strategy_id"`
+	Start          time.Time       `json:"start"`
+	End            time.Time       `json:"end"`
+	InitialCapital float64         `json:"initial_capital"`
+	FinalCapital   float64         `json:"final_capital"`
+	Metrics        Metrics         `json:"metrics"`
+	Costs          Costs           `json:"costs"`
+	Durations      Durations       `json:"trade_durations"`
+	Monthly        []MonthlyReturn `json:"monthly_returns"`
+	Equity         []Point         `json:"equity"`
+	Trades         []Trade         `json:"trades"`
+	Config         json.RawMessage `json:"config"`
+}
+
+type Metrics struct {
+	TotalReturn         float64 `json:"total_return"`
+	CAGR                float64 `json:"cagr"`
+	Sharpe              float64 `json:"sharpe"`
+	Sortino             float64 `json:"sortino"`
+	Calmar              float64 `json:"calmar"`
+	MaxDrawdown         float64 `json:"max_drawdown"`          // доля от пика
+	MaxDrawdownDuration float64 `json:"max_drawdown_duration"` // секунды от пика до восстановления или конца прогона
+	Trades              int     `json:"trades"`
+	WinRate             float64 `json:"win_rate"`
+	ProfitFactor        float64 `json:"profit_factor"` // 0, если убыточных сделок нет
+	Expectancy          float64 `json:"expectancy"`    // средний результат сделки
+	AverageWin          float64 `json:"average_win"`
+	AverageLoss         float64 `json:"average_loss"`
+	Exposure            float64 `json:"exposure"` // доля времени прогона с открытой позицией
+}
+
+type Costs struct {
+	Commission float64 `json:"commission"`
+	Spread     float64 `json:"spread"`
+	Slippage   float64 `json:"slippage"`
+	Impact     float64 `json:"impact"`
+	Delayed    int     `json:"delayed_orders"`
+	Latency    float64 `json:"average_latency"`
+}
+
+// Durations — распределение длительности сделок, секунды
+type Durations struct {
+	Min       float64  `json:"min"`
+	Median    float64  `json:"median"`
+	Mean      float64  `json:"mean"`
+	Max       float64  `json:"max"`
+	Histogram []Bucket `json:"histogram"`
+}
+
+type Bucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+type MonthlyReturn struct {
+	Year   int     `json:"year"`
+	Month  int     `json:"month"`
+	Return float64 `json:"return"`
+}
+
+type Point struct {
+	Time     time.Time `json:"time"`
+	Equity   float64   `json:"equity"`
+	Drawdown float64   `json:"drawdown"` // доля от предыдущего пика
+}
+
+type Trade struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	EntryTime  time.Time `json:"entry_time"`
+	ExitTime   time.Time `json:"exit_time"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	Quantity   float64   `json:"quantity"`
+	PnL        float64   `json:"pnl"`
+}
+
+// Границы корзин гистограммы длительности сделок
+var durationBuckets = []struct {
+	label string
+	limit time.Duration
+}{
+	{"< 1h", time.Hour},
+	{"1h - 4h", 4 * time.Hour},
+	{"4h - 1d", 24 * time.Hour},
+	{"1d - 1w", 7 * 24 * time.Hour},
+	{"≥ 1w", math.MaxInt64},
+}
+
+// Load строит отчёт по прогону из backtest_results, backtest_equity и backtest_trades
+func Load(repo repositories.BacktestResultRepository, id int) (*Report, error) {
+	result, err := repo.GetBacktestResult(id)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("backtest result %d not found", id)
+	}
+
+	equity, err := repo.GetBacktestEquity(id)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := repo.GetBacktestTrades(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return Build(result, equity, trades), nil
+}
+
+// Build рассчитывает отчёт. Кривая капитала должна быть упорядочена по времени.
+func Build(result *types.BacktestResult, equity []*types.BacktestEquity, trades []*types.BacktestTrade) *Report {
+	r := &Report{
+		ResultID:       result.ID,
+		StrategyID:     result.StrategyID,
+		Start:          result.StartTime,
+		End:            result.EndTime,
+		InitialCapital: result.InitialCapital,
+		FinalCapital:   result.FinalCapital,
+		Costs: Costs{
+			Commission: result.Commission,
+			Spread:     result.Spread,
+			Slippage:   result.Slippage,
+			Impact:     result.Impact,
+			Delayed:    result.Delayed,
+			Latency:    result.Latency,
+		},
+		Config: result.Config,
+	}
+
+	r.buildEquity(equity)
+	r.buildReturns(equity)
+	r.buildTrades(trades)
+	r.Monthly = monthlyReturns(result.InitialCapital, equity)
+
+	return r
+}
+
+// buildEquity рассчитывает кривую просадки, максимальную просадку и её длительность
+func (r *Report) buildEquity(equity []*types.BacktestEquity) {
+	peak := r.InitialCapital
+	peakTime := r.Start
+	var longest time.Duration
+
+	for _, point := range equity {
+		if point.Equity >= peak {
+			longest = max(longest, point.Timestamp.Sub(peakTime))
+			peak, peakTime = point.Equity, point.Timestamp
+		}
+
+		var drawdown float64
+		if peak > 0 {
+			drawdown = (peak - point.Equity) / peak
+		}
+		r.Metrics.MaxDrawdown = max(r.Metrics.MaxDrawdown, drawdown)
+		r.Equity = append(r.Equity, Point{Time: point.Timestamp, Equity: point.Equity, Drawdown: drawdown})
+	}
+
+	// просадка, не восстановленная к концу прогона
+	if n := len(equity); n > 0 && equity[n-1].Equity < peak {
+		longest = max(longest, equity[n-1].Timestamp.Sub(peakTime))
+	}
+	r.Metrics.MaxDrawdownDuration = longest.Seconds()
+}
+
+// buildReturns рассчитывает доходность и коэффициенты по доходностям между точками кривой капитала.
+// Коэффициенты приводятся к году по медианному шагу кривой, безрисковая ставка равна нулю.
+func (r *Report) buildReturns(equity []*types.BacktestEquity) {
+	m := &r.Metrics
+	if r.InitialCapital > 0 {
+		m.TotalReturn = r.FinalCapital/r.InitialCapital - 1
+	}
+
+	if years := float64(r.End.Sub(r.Start)) / float64(year); years > 0 && r.InitialCapital > 0 {
+		if r.FinalCapital > 0 {
+			m.CAGR = math.Pow(r.FinalCapital/r.InitialCapital, 1/years) - 1
+		} else {
+			m.CAGR = -1
+		}
+	}
+	if m.MaxDrawdown > 0 {
+		m.Calmar = m.CAGR / m.MaxDrawdown
+	}
+
+	var returns []float64
+	var steps []time.Duration
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1].Equity <= 0 {
+			continue
+		}
+		returns = append(returns, equity[i].Equity/equity[i-1].Equity-1)
+		steps = append(steps, equity[i].Timestamp.Sub(equity[i-1].Timestamp))
+	}
+	if len(returns) < 2 {
+		return
+	}
+
+	slices.Sort(steps)
+	step := steps[len(steps)/2]
+	if step <= 0 {
+		return
+	}
+	annualization := math.Sqrt(float64(year) / float64(step))
+
+	var mean float64
+	for _, ret := range returns {
+		mean += ret
+	}
+	mean /= float64(len(returns))
+
+	var variance, downside float64
+	for _, ret := range returns {
+		variance += (ret - mean) * (ret - mean)
+		if ret < 0 {
+			downside += ret * ret
+		}
+	}
+	if std := math.Sqrt(variance / float64(len(returns)-1)); std > 0 {
+		m.Sharpe = mean / std * annualization
+	}
+	if deviation := math.Sqrt(downside / float64(len(returns))); deviation > 0 {
+		m.Sortino = mean / deviation * annualization
+	}
+}
+
+// buildTrades рассчитывает показатели сделок, время в позиции и распределение длительности
+func (r *Report) buildTrades(trades []*types.BacktestTrade) {
+	m := &r.Metrics
+	m.Trades = len(trades)
+
+	r.Durations.Histogram = make([]Bucket, len(durationBuckets))
+	for i, bucket := range durationBuckets {
+		r.Durations.Histogram[i].Label = bucket.label
+	}
+	if len(trades) == 0 {
+		return
+	}
+
+	var wins, losses int
+	var profit, loss, total float64
+	durations := make([]time.Duration, 0, len(trades))
+	for _, trade := range trades {
+		r.Trades = append(r.Trades, Trade{
+			Symbol:     trade.Symbol,
+			Side:       trade.Side,
+			EntryTime:  trade.EntryTime,
+			ExitTime:   trade.ExitTime,
+			EntryPrice: trade.EntryPrice,
+			ExitPrice:  trade.ExitPrice,
+			Quantity:   trade.Quantity,
+			PnL:        trade.PnL,
+		})
+
+		total += trade.PnL
+		if trade.PnL > 0 {
+			wins++
+			profit += trade.PnL
+		} else {
+			losses++
+			loss -= trade.PnL
+		}
+
+		duration := trade.ExitTime.Sub(trade.EntryTime)
+		durations = append(durations, duration)
+		for i, bucket := range durationBuckets {
+			if duration < bucket.limit {
+				r.Durations.Histogram[i].Count++
+				break
+			}
+		}
+	}
+
+	m.WinRate = float64(wins) / float64(len(trades))
+	m.Expectancy = total / float64(len(trades))
+	if wins > 0 {
+		m.AverageWin = profit / float64(wins)
+	}
+	if losses > 0 {
+		m.AverageLoss = -loss / float64(losses)
+	}
+	if loss > 0 {
+		m.ProfitFactor = profit / loss
+	}
+
+	slices.Sort(durations)
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+	n := len(durations)
+	r.Durations.Min = durations[0].Seconds()
+	r.Durations.Max = durations[n-1].Seconds()
+	r.Durations.Mean = (sum / time.Duration(n)).Seconds()
+	r.Durations.Median = durations[n/2].Seconds()
+	if n%2 == 0 {
+		r.Durations.Median = ((durations[n/2-1] + durations[n/2]) / 2).Seconds()
+	}
+
+	if period := r.End.Sub(r.Start); period > 0 {
+		m.Exposure = float64(exposure(trades)) / float64(period)
+	}
+}
+
+// exposure возвращает время с открытой позицией: объединение интервалов сделок
+func exposure(trades []*types.BacktestTrade) time.Duration {
+	intervals := make([][2]time.Time, 0, len(trades))
+	for _, trade := range trades {
+		intervals = append(intervals, [2]time.Time{trade.EntryTime, trade.ExitTime})
+	}
+	slices.SortFunc(intervals, func(a, b [2]time.Time) int { return a[0].Compare(b[0]) })
+
+	var total time.Duration
+	var start, end time.Time
+	for i, interval := range intervals {
+		if i == 0 || interval[0].After(end) {
+			total += end.Sub(start)
+			start, end = interval[0], interval[1]
+			continue
+		}
+		if interval[1].After(end) {
+			end = interval[1]
+		}
+	}
+	return total + end.Sub(start)
+}
+
+// monthlyReturns рассчитывает доходность по календарным месяцам UTC.
+// База месяца — капитал на конец предыдущего месяца, для первого месяца — начальный капитал.
+func monthlyReturns(initial float64, equity []*types.BacktestEquity) []MonthlyReturn {
+	var result []MonthlyReturn
+	base := initial
+
+	for i, point := range equity {
+		t := point.Timestamp.UTC()
+		last := i == len(equity)-1
+		if !last {
+			next := equity[i+1].Timestamp.UTC()
+			last = next.Year() != t.Year() || next.Month() != t.Month()
+		}
+		if !last {
+			continue
+		}
+
+		var ret float64
+		if base > 0 {
+			ret = point.Equity/base - 1
+		}
+		result = append(result, MonthlyReturn{Year: t.Year(), Month: int(t.Month()), Return: ret})
+		base = point.Equity
+	}
+
+	return result
+}
+
+// WriteJSON записывает отчёт в JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
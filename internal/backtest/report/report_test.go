@@ -0,0 +1,106 @@
+package report
+
+import (
+	"bytes"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)
+
+func day(n int) time.Time {
+	return start.Add(time.Duration(n) * 24 * time.Hour)
+}
+
+func sample() (*types.BacktestResult, []*types.BacktestEquity, []*types.BacktestTrade) {
+	values := []float64{1000, 1100, 990, 1045, 1200}
+	equity := make([]*types.BacktestEquity, len(values))
+	for i, v := range values {
+		equity[i] = &types.BacktestEquity{ResultID: 7, Timestamp: day(i), Equity: v}
+	}
+
+	trades := []*types.BacktestTrade{
+		{Symbol: "BTCUSDT", Side: "long", EntryTime: day(0), ExitTime: day(1), PnL: 100},
+		{Symbol: "BTCUSDT", Side: "short", EntryTime: day(1).Add(12 * time.Hour), ExitTime: day(2), PnL: -110},
+		{Symbol: "BTCUSDT", Side: "long", EntryTime: day(2).Add(time.Hour), ExitTime: day(2).Add(3 * time.Hour), PnL: 210},
+	}
+
+	result := &types.BacktestResult{
+		ID: 7, StrategyID: 3, StartTime: day(0), EndTime: day(4),
+		InitialCapital: 1000, FinalCapital: 1200,
+		BacktestCosts: types.BacktestCosts{Commission: 5, Slippage: 1.5},
+	}
+	return result, equity, trades
+}
+
+func TestBuild(t *testing.T) {
+	r := Build(sample())
+	m := r.Metrics
+
+	assert.InDelta(t, 0.2, m.TotalReturn, 1e-12)
+	assert.InDelta(t, math.Pow(1.2, 365.25/4)-1, m.CAGR, 1e-6)
+	assert.InDelta(t, 0.1, m.MaxDrawdown, 1e-12)
+	// пик 1100 на первый день восстановлен на четвёртый
+	assert.InDelta(t, (3 * 24 * time.Hour).Seconds(), m.MaxDrawdownDuration, 1e-9)
+	assert.InDelta(t, m.CAGR/0.1, m.Calmar, 1e-6)
+	assert.Greater(t, m.Sharpe, 0.0)
+	assert.Greater(t, m.Sortino, m.Sharpe)
+
+	assert.Equal(t, 3, m.Trades)
+	assert.InDelta(t, 2.0/3, m.WinRate, 1e-12)
+	assert.InDelta(t, 310.0/110, m.ProfitFactor, 1e-12)
+	assert.InDelta(t, 200.0/3, m.Expectancy, 1e-12)
+	assert.InDelta(t, -110.0, m.AverageLoss, 1e-12)
+	// в позиции 24ч + 12ч + 2ч из 96ч
+	assert.InDelta(t, 38.0/96, m.Exposure, 1e-12)
+
+	assert.Equal(t, (2 * time.Hour).Seconds(), r.Durations.Min)
+	assert.Equal(t, (12 * time.Hour).Seconds(), r.Durations.Median)
+	assert.Equal(t, []Bucket{{"< 1h", 0}, {"1h - 4h", 1}, {"4h - 1d", 1}, {"1d - 1w", 1}, {"≥ 1w", 0}}, r.Durations.Histogram)
+
+	// январь заканчивается на 1100, февраль — 1200
+	if assert.Len(t, r.Monthly, 2) {
+		assert.Equal(t, 2025, r.Monthly[0].Year)
+		assert.Equal(t, 1, r.Monthly[0].Month)
+		assert.InDelta(t, 0.1, r.Monthly[0].Return, 1e-12)
+		assert.Equal(t, 2, r.Monthly[1].Month)
+		assert.InDelta(t, 1200.0/1100-1, r.Monthly[1].Return, 1e-12)
+	}
+
+	assert.InDelta(t, 0.1, r.Equity[2].Drawdown, 1e-12)
+	assert.Equal(t, 5.0, r.Costs.Commission)
+}
+
+func TestEmptyRun(t *testing.T) {
+	result := &types.BacktestResult{ID: 1, InitialCapital: 1000, FinalCapital: 1000}
+	r := Build(result, nil, nil)
+
+	assert.Equal(t, Metrics{}, r.Metrics)
+	assert.NoError(t, r.WriteHTML(&bytes.Buffer{}))
+}
+
+func TestWrite(t *testing.T) {
+	r := Build(sample())
+
+	var html bytes.Buffer
+	assert.NoError(t, r.WriteHTML(&html))
+	page := html.String()
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"))
+	assert.Equal(t, 3, strings.Count(page, "<svg"))
+	assert.NotContains(t, page, "<script")
+	assert.Contains(t, page, "10.00%")
+
+	var data bytes.Buffer
+	assert.NoError(t, r.WriteJSON(&data))
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(data.Bytes(), &decoded))
+	assert.Contains(t, decoded, "metrics")
+	assert.Contains(t, decoded, "monthly_returns")
+	assert.Len(t, decoded["equity"], 5)
+}
@@ -0,0 +1,170 @@
+package backtest
+
+import (
+	"context"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/pkg/pipeline"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Runner прогоняет свечи источника через стратегию и исполняет её сигналы симулированным брокером.
+// Итоги записываются в backtest_results, кривая капитала и закрытые сделки для отчёта —
+// в backtest_equity и backtest_trades, исполнения — в trade_operations со ссылкой на прогон,
+// чтобы их не принимали за операции живой торговли.
+//
+// Состояние стратегии из базы не восстанавливается: стратегии с сохраняемым состоянием
+// для бэктеста нужно создавать без репозитория, иначе прогон изменит состояние живой стратегии.
+type Runner struct {
+	results    repositories.BacktestResultRepository
+	operations repositories.TradeOperationRepository
+	logger     *logger.Logger
+}
+
+// NewRunner создаёт исполнителя. Если results или operations равны nil, соответствующие данные не сохраняются.
+// Исполнения без итогов прогона не сохраняются: им не на что ссылаться.
+func NewRunner(results repositories.BacktestResultRepository, operations repositories.TradeOperationRepository, logger *logger.Logger) *Runner {
+	return &Runner{results: results, operations: operations, logger: logger}
+}
+
+// Run выполняет бэктест стратегии strategyID. config — снимок настроек прогона для backtest_results.config.
+func (r *Runner) Run(ctx context.Context,
+	strategyID int,
+	instance strategy.Strategy,
+	source pipeline.Source,
+	broker *Broker,
+	config json.RawMessage) (*types.BacktestResult, error) {
+
+	var start, end time.Time
+	var fills []*Fill
+
+	for source.Next(ctx) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		payload, ok := source.Payload().(*processing.TradingPayload)
+		if !ok {
+			return nil, fmt.Errorf("invalid payload type: %T", payload)
+		}
+
+		md := payload.MarketData
+		if md == nil {
+			payload.MarkAsProcessed()
+			continue
+		}
+		if start.IsZero() {
+			start = md.Timestamp
+		}
+		end = md.Timestamp
+
+		// исполнения отложенных ордеров предшествуют сигналам этой свечи
+		fills = append(fills, broker.Mark(payload.Symbol, md)...)
+
+		signals, err := instance.OnCandle(ctx, payload)
+		payload.MarkAsProcessed()
+		if err != nil {
+			return nil, fmt.Errorf("strategy failed at %s: %w", md.Timestamp, err)
+		}
+
+		for _, signal := range signals {
+			fill, err := broker.Execute(signal)
+			if errors.Is(err, ErrRejected) {
+				r.logger.Debugf("Backtest of strategy %d: %s %s %v rejected: %v", strategyID, signal.Side, signal.Symbol, signal.Amount, err)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if fill == nil {
+				// ордер отложен моделью задержки
+				continue
+			}
+			fills = append(fills, fill)
+		}
+	}
+
+	if err := source.Error(); err != nil {
+		return nil, err
+	}
+
+	result := broker.Result(start, end)
+	result.StrategyID = strategyID
+	result.Config = config
+	if len(result.Config) == 0 {
+		result.Config = json.RawMessage(`{}`)
+	}
+
+	r.logger.Infof("Backtest of strategy %d [%v - %v]: capital %.2f -> %.2f, drawdown %.2f%%, trades %d/%d, rejected %d, not executed %d",
+		strategyID, start, end, result.InitialCapital, result.FinalCapital, result.Drawdown*100,
+		result.SuccessfulTrades, result.SuccessfulTrades+result.FailedTrades, broker.Rejected(), broker.Pending())
+	r.logger.Infof("Backtest of strategy %d costs: commission %.2f, spread %.2f, slippage %.2f, impact %.2f, delayed %d, average latency %.3fs",
+		strategyID, result.Commission, result.Spread, result.Slippage, result.Impact, result.Delayed, result.Latency)
+
+	if r.results != nil {
+		if err := r.results.SaveBacktestResult(result); err != nil {
+			return nil, err
+		}
+		equity, trades := Series(result.ID, broker)
+		if err := r.results.SaveBacktestSeries(result.ID, equity, trades); err != nil {
+			return nil, err
+		}
+		for _, fill := range fills {
+			if err := r.record(result, fill); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Runner) record(result *types.BacktestResult, fill *Fill) error {
+	if r.operations == nil {
+		return nil
+	}
+
+	return r.operations.SaveTradeOperation(&types.TradeOperation{
+		StrategyID:       result.StrategyID,
+		BacktestResultID: &result.ID,
+		Symbol:           fill.Symbol,
+		OrderType:        fill.Type,
+		Side:             fill.Side,
+		Quantity:         fill.Quantity,
+		Price:            fill.Price,
+		Timestamp:        fill.Time,
+		Status:           types.TradeStatusFilled,
+		Reason:           fill.Reason,
+	})
+}
+
+// Series переводит кривую капитала и сделки брокера в записи прогона resultID
+func Series(resultID int, broker *Broker) ([]*types.BacktestEquity, []*types.BacktestTrade) {
+	equity := make([]*types.BacktestEquity, 0, len(broker.EquityCurve()))
+	for _, point := range broker.EquityCurve() {
+		equity = append(equity, &types.BacktestEquity{ResultID: resultID, Timestamp: point.Time, Equity: point.Equity})
+	}
+
+	trades := make([]*types.BacktestTrade, 0, len(broker.Trades()))
+	for _, trade := range broker.Trades() {
+		trades = append(trades, &types.BacktestTrade{
+			ResultID:   resultID,
+			Symbol:     trade.Symbol,
+			Side:       trade.Side,
+			EntryTime:  trade.EntryTime,
+			ExitTime:   trade.ExitTime,
+			EntryPrice: trade.EntryPrice,
+			ExitPrice:  trade.ExitPrice,
+			Quantity:   trade.Quantity,
+			PnL:        trade.PnL,
+		})
+	}
+
+	return equity, trades
+}
@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestSimulatedTimers(t *testing.T) {
+	c := NewSimulated(start)
+	assert.Equal(t, start, c.Now())
+
+	late := c.NewTimer(3 * time.Second)
+	early := c.NewTimer(time.Second)
+	ticker := c.NewTicker(2 * time.Second)
+	assert.Equal(t, 3, c.Waiters())
+
+	next, ok := c.Next()
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(time.Second), next)
+
+	// таймеры срабатывают со своим сроком, даже если время продвинуто дальше
+	c.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(5*time.Second), c.Now())
+	assert.Equal(t, start.Add(time.Second), <-early.C())
+	assert.Equal(t, start.Add(3*time.Second), <-late.C())
+	// тики за 2 и 4 секунды: второй пропущен, буфер канала на одно значение
+	assert.Equal(t, start.Add(2*time.Second), <-ticker.C())
+	assert.Equal(t, 1, c.Waiters())
+
+	// остановленный таймер не срабатывает
+	stopped := c.NewTimer(time.Second)
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+	ticker.Stop()
+	assert.False(t, c.Step())
+	assert.Empty(t, stopped.C())
+
+	// перезапуск таймера отсчитывает срок от текущего времени
+	assert.False(t, stopped.Reset(time.Minute))
+	assert.True(t, c.Step())
+	assert.Equal(t, start.Add(5*time.Second+time.Minute), <-stopped.C())
+}
+
+func TestSimulatedSleep(t *testing.T) {
+	c := NewSimulated(start)
+	done := make(chan time.Time)
+
+	go func() {
+		c.Sleep(time.Hour)
+		done <- c.Now()
+	}()
+
+	c.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("sleep finished before the clock advanced")
+	default:
+	}
+
+	c.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), <-done)
+}
+
+func TestSimulatedSetDoesNotGoBack(t *testing.T) {
+	c := NewSimulated(start)
+	c.Set(start.Add(-time.Hour))
+	assert.Equal(t, start, c.Now())
+	assert.Equal(t, time.Duration(0), c.Since(start))
+	assert.Equal(t, start, <-c.After(0))
+}
+
+func TestScaled(t *testing.T) {
+	c := NewScaled(start, 1000)
+
+	ticker := c.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	// 10 модельных секунд — 10 мс системного времени
+	began := time.Now()
+	first := <-ticker.C()
+	second := <-ticker.C()
+	assert.Less(t, time.Since(began), time.Second)
+	assert.False(t, first.Before(start.Add(10*time.Second)))
+	assert.False(t, second.Before(first.Add(10*time.Second)))
+
+	fired := <-c.After(time.Minute)
+	assert.False(t, fired.Before(start.Add(time.Minute)))
+
+	timer := c.NewTimer(time.Hour)
+	assert.True(t, timer.Stop())
+}
+
+func TestReal(t *testing.T) {
+	c := New()
+	before := time.Now()
+	assert.False(t, c.Now().Before(before))
+
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	assert.GreaterOrEqual(t, c.Since(before), time.Millisecond)
+}
@@ -0,0 +1,126 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Scaled — время, идущее в speed раз быстрее системного, начиная с момента start.
+// Подходит для ускоренного прогона имитации в реальном времени: таймеры и тикеры
+// срабатывают через d/speed системного времени, а каналы получают модельное время.
+type Scaled struct {
+	start  time.Time // модельное время в момент создания
+	origin time.Time // системное время в момент создания
+	speed  float64
+}
+
+// NewScaled создаёт часы, показывающие start и идущие со скоростью speed (speed > 0)
+func NewScaled(start time.Time, speed float64) *Scaled {
+	if speed <= 0 {
+		panic("non-positive clock speed")
+	}
+	return &Scaled{start: start, origin: time.Now(), speed: speed}
+}
+
+func (s *Scaled) Now() time.Time {
+	return s.start.Add(time.Duration(float64(time.Since(s.origin)) * s.speed))
+}
+
+func (s *Scaled) Since(t time.Time) time.Duration {
+	return s.Now().Sub(t)
+}
+
+func (s *Scaled) Sleep(d time.Duration) {
+	time.Sleep(s.real(d))
+}
+
+func (s *Scaled) After(d time.Duration) <-chan time.Time {
+	return s.NewTimer(d).C()
+}
+
+func (s *Scaled) NewTimer(d time.Duration) Timer {
+	t := &scaledTimer{clock: s, ch: make(chan time.Time, 1)}
+	t.start(d)
+	return t
+}
+
+func (s *Scaled) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	t := &scaledTimer{clock: s, ch: make(chan time.Time, 1), period: d}
+	t.start(d)
+	return &scaledTicker{t}
+}
+
+// real переводит модельную длительность в системную
+func (s *Scaled) real(d time.Duration) time.Duration {
+	return time.Duration(float64(d) / s.speed)
+}
+
+// scaledTimer — системный таймер, отправляющий в канал модельное время.
+// Для тикера после срабатывания перезапускается на следующий период.
+type scaledTimer struct {
+	clock  *Scaled
+	ch     chan time.Time
+	mu     sync.Mutex
+	timer  *time.Timer
+	period time.Duration
+}
+
+// start запускает системный таймер; fire ждёт окончания запуска на мьютексе
+func (t *scaledTimer) start(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timer = time.AfterFunc(t.clock.real(d), t.fire)
+}
+
+func (t *scaledTimer) fire() {
+	select {
+	case t.ch <- t.clock.Now():
+	default:
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.period > 0 {
+		t.timer.Reset(t.clock.real(t.period))
+	}
+}
+
+func (t *scaledTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *scaledTimer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.period = 0
+	return t.timer.Stop()
+}
+
+func (t *scaledTimer) Reset(d time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timer.Reset(t.clock.real(d))
+}
+
+type scaledTicker struct {
+	*scaledTimer
+}
+
+func (t *scaledTicker) Stop() {
+	t.scaledTimer.Stop()
+}
+
+func (t *scaledTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.period = d
+	t.timer.Reset(t.clock.real(d))
+}
+
+var _ Clock = (*Scaled)(nil)
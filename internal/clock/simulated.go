@@ -0,0 +1,252 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Simulated — модельное время, которое идёт только при вызове Advance, Set или Step.
+// Таймеры и тикеры срабатывают в порядке сроков, и в момент срабатывания Now
+// возвращает срок таймера, поэтому последовательность событий не зависит от скорости выполнения.
+// Как и у time.Timer, канал таймера имеет буфер на одно значение: необработанные тики тикера пропускаются.
+type Simulated struct {
+	mu      sync.Mutex
+	cond    *sync.Cond // оповещает о появлении новых ожидающих таймеров
+	now     time.Time
+	seq     int
+	waiters map[*waiter]struct{}
+}
+
+// waiter — таймер или тикер, ожидающий своего срока
+type waiter struct {
+	when   time.Time
+	period time.Duration // период тикера, 0 для таймера
+	seq    int           // порядок создания для таймеров с одинаковым сроком
+	ch     chan time.Time
+}
+
+// NewSimulated создаёт модельные часы, показывающие start
+func NewSimulated(start time.Time) *Simulated {
+	s := &Simulated{
+		now:     start,
+		waiters: make(map[*waiter]struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *Simulated) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+func (s *Simulated) Since(t time.Time) time.Duration {
+	return s.Now().Sub(t)
+}
+
+// Sleep блокируется, пока модельное время не продвинется на d
+func (s *Simulated) Sleep(d time.Duration) {
+	<-s.After(d)
+}
+
+func (s *Simulated) After(d time.Duration) <-chan time.Time {
+	return s.NewTimer(d).C()
+}
+
+func (s *Simulated) NewTimer(d time.Duration) Timer {
+	return &simulatedTimer{clock: s, w: s.add(d, 0)}
+}
+
+func (s *Simulated) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return &simulatedTicker{clock: s, w: s.add(d, d)}
+}
+
+// Advance продвигает время на d, по пути срабатывают все таймеры со сроком не позже нового времени
+func (s *Simulated) Advance(d time.Duration) {
+	s.Set(s.Now().Add(d))
+}
+
+// Set продвигает время до t. Время назад не идёт: более раннее t игнорируется.
+func (s *Simulated) Set(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		w := s.next()
+		if w == nil || w.when.After(t) {
+			break
+		}
+		s.fire(w)
+	}
+	if t.After(s.now) {
+		s.now = t
+	}
+}
+
+// Step продвигает время до ближайшего срока и срабатывает таймеры с этим сроком.
+// Возвращает false, если ожидающих таймеров нет.
+func (s *Simulated) Step() bool {
+	s.mu.Lock()
+	w := s.next()
+	s.mu.Unlock()
+
+	if w == nil {
+		return false
+	}
+	s.Set(w.when)
+	return true
+}
+
+// Next возвращает ближайший срок среди ожидающих таймеров
+func (s *Simulated) Next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w := s.next(); w != nil {
+		return w.when, true
+	}
+	return time.Time{}, false
+}
+
+// Waiters возвращает число ожидающих таймеров и тикеров
+func (s *Simulated) Waiters() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiters)
+}
+
+// BlockUntil блокируется, пока число ожидающих таймеров и тикеров не станет не меньше n.
+// Позволяет дождаться, что горутина заснула, прежде чем продвигать время.
+func (s *Simulated) BlockUntil(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.waiters) < n {
+		s.cond.Wait()
+	}
+}
+
+func (s *Simulated) add(d time.Duration, period time.Duration) *waiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	w := &waiter{when: s.now.Add(d), period: period, seq: s.seq, ch: make(chan time.Time, 1)}
+	if d <= 0 && period == 0 {
+		// таймер с истёкшим сроком срабатывает сразу, как time.NewTimer
+		w.ch <- s.now
+		return w
+	}
+	s.waiters[w] = struct{}{}
+	s.cond.Broadcast()
+	return w
+}
+
+// next возвращает ожидающий таймер с ближайшим сроком
+func (s *Simulated) next() *waiter {
+	list := make([]*waiter, 0, len(s.waiters))
+	for w := range s.waiters {
+		list = append(list, w)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].when.Equal(list[j].when) {
+			return list[i].when.Before(list[j].when)
+		}
+		return list[i].seq < list[j].seq
+	})
+	return list[0]
+}
+
+// fire срабатывает таймер: время встаёт на его срок, тикер переносится на следующий период
+func (s *Simulated) fire(w *waiter) {
+	if w.when.After(s.now) {
+		s.now = w.when
+	}
+	select {
+	case w.ch <- s.now:
+	default:
+	}
+
+	if w.period > 0 {
+		w.when = w.when.Add(w.period)
+		return
+	}
+	delete(s.waiters, w)
+}
+
+// stop снимает таймер с ожидания, возвращает false, если он уже сработал или остановлен
+func (s *Simulated) stop(w *waiter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, active := s.waiters[w]
+	delete(s.waiters, w)
+	return active
+}
+
+// reset переносит срок таймера на d от текущего времени
+func (s *Simulated) reset(w *waiter, d time.Duration, period time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, active := s.waiters[w]
+	w.when = s.now.Add(d)
+	w.period = period
+	if d <= 0 && period == 0 {
+		delete(s.waiters, w)
+		select {
+		case w.ch <- s.now:
+		default:
+		}
+		return active
+	}
+	s.waiters[w] = struct{}{}
+	s.cond.Broadcast()
+	return active
+}
+
+type simulatedTimer struct {
+	clock *Simulated
+	w     *waiter
+}
+
+func (t *simulatedTimer) C() <-chan time.Time {
+	return t.w.ch
+}
+
+func (t *simulatedTimer) Stop() bool {
+	return t.clock.stop(t.w)
+}
+
+func (t *simulatedTimer) Reset(d time.Duration) bool {
+	return t.clock.reset(t.w, d, 0)
+}
+
+type simulatedTicker struct {
+	clock *Simulated
+	w     *waiter
+}
+
+func (t *simulatedTicker) C() <-chan time.Time {
+	return t.w.ch
+}
+
+func (t *simulatedTicker) Stop() {
+	t.clock.stop(t.w)
+}
+
+func (t *simulatedTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.clock.reset(t.w, d, d)
+}
+
+var _ Clock = (*Simulated)(nil)
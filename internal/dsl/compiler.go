@@ -0,0 +1,302 @@
+package dsl
+
+import (
+	"crypto-trading-bot/internal/indicators"
+	"crypto-trading-bot/internal/types"
+	"math"
+)
+
+// Type — тип значения выражения
+type Type int
+
+const (
+	Number Type = iota
+	Bool
+)
+
+func (t Type) String() string {
+	if t == Bool {
+		return "bool"
+	}
+	return "number"
+}
+
+// Env — значения, доступные выражению на текущей свече
+type Env interface {
+	// MarketData возвращает текущую свечу
+	MarketData() *types.MarketData
+	// Lookup возвращает значение по имени, например индикатор, рассчитанный на предыдущих этапах конвейера
+	Lookup(name string) (float64, bool)
+}
+
+// evalFunc вычисляет узел. Логические значения представлены как 1 и 0,
+// значение, которое ещё нельзя рассчитать (индикатор на прогреве), — как NaN.
+// Логика трёхзначная: сравнение с NaN и not NaN дают NaN, and и or — по Клини
+// (false and NaN = false, true or NaN = true, в остальных случаях с NaN — NaN).
+type evalFunc func(env Env) float64
+
+type compiler struct {
+	indicators map[string]indicators.Indicator // по имени индикатора: одинаковые вызовы используют один экземпляр
+	order      []indicators.Indicator
+	vars       map[string]bool // имена значений, которые окружение предоставляет выражению
+}
+
+func (c *compiler) compile(n node) (evalFunc, Type, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		value := n.value
+		return func(Env) float64 { return value }, Number, nil
+
+	case *boolNode:
+		value := boolValue(n.value)
+		return func(Env) float64 { return value }, Bool, nil
+
+	case *identNode:
+		ident, err := c.ident(n)
+		if err != nil {
+			return nil, 0, err
+		}
+		return ident, Number, nil
+
+	case *callNode:
+		return c.call(n)
+
+	case *unaryNode:
+		x, typ, err := c.compile(n.x)
+		if err != nil {
+			return nil, 0, err
+		}
+		if n.op == "not" {
+			if typ != Bool {
+				return nil, 0, errorf(n.pos, "operator not expects bool, got %s", typ)
+			}
+			return func(env Env) float64 {
+				value := x(env)
+				if math.IsNaN(value) {
+					return value
+				}
+				return boolValue(value == 0)
+			}, Bool, nil
+		}
+		if typ != Number {
+			return nil, 0, errorf(n.pos, "operator - expects number, got %s", typ)
+		}
+		return func(env Env) float64 { return -x(env) }, Number, nil
+
+	case *binaryNode:
+		return c.binary(n)
+	}
+
+	return nil, 0, errorf(n.position(), "unsupported expression")
+}
+
+func (c *compiler) binary(n *binaryNode) (evalFunc, Type, error) {
+	left, leftType, err := c.compile(n.left)
+	if err != nil {
+		return nil, 0, err
+	}
+	right, rightType, err := c.compile(n.right)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch n.op {
+	case "and", "or":
+		if leftType != Bool || rightType != Bool {
+			return nil, 0, errorf(n.pos, "operator %s expects bool operands, got %s and %s", n.op, leftType, rightType)
+		}
+		if n.op == "and" {
+			return func(env Env) float64 {
+				a, b := left(env), right(env)
+				switch {
+				case a == 0 || b == 0:
+					return 0
+				case math.IsNaN(a) || math.IsNaN(b):
+					return math.NaN()
+				}
+				return 1
+			}, Bool, nil
+		}
+		return func(env Env) float64 {
+			a, b := left(env), right(env)
+			switch {
+			case a == 1 || b == 1:
+				return 1
+			case math.IsNaN(a) || math.IsNaN(b):
+				return math.NaN()
+			}
+			return 0
+		}, Bool, nil
+	}
+
+	if leftType != Number || rightType != Number {
+		return nil, 0, errorf(n.pos, "operator %s expects number operands, got %s and %s", n.op, leftType, rightType)
+	}
+
+	var compare func(a, b float64) bool
+	switch n.op {
+	case "+":
+		return func(env Env) float64 { return left(env) + right(env) }, Number, nil
+	case "-":
+		return func(env Env) float64 { return left(env) - right(env) }, Number, nil
+	case "*":
+		return func(env Env) float64 { return left(env) * right(env) }, Number, nil
+	case "/":
+		return func(env Env) float64 { return left(env) / right(env) }, Number, nil
+	case "<":
+		compare = func(a, b float64) bool { return a < b }
+	case "<=":
+		compare = func(a, b float64) bool { return a <= b }
+	case ">":
+		compare = func(a, b float64) bool { return a > b }
+	case ">=":
+		compare = func(a, b float64) bool { return a >= b }
+	case "==":
+		compare = func(a, b float64) bool { return a == b }
+	case "!=":
+		compare = func(a, b float64) bool { return a != b }
+	default:
+		return nil, 0, errorf(n.pos, "unknown operator %s", n.op)
+	}
+
+	// сравнение с нерассчитанным значением тоже не рассчитано
+	return func(env Env) float64 {
+		a, b := left(env), right(env)
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return math.NaN()
+		}
+		return boolValue(compare(a, b))
+	}, Bool, nil
+}
+
+// ident — поле свечи (open, high, low, close, volume, typical, price) или объявленное значение из окружения
+func (c *compiler) ident(n *identNode) (evalFunc, error) {
+	name := n.name
+	if name == "price" {
+		name = string(indicators.SourceClose)
+	}
+
+	if src, err := indicators.ParseSource(name); err == nil {
+		return func(env Env) float64 {
+			md := env.MarketData()
+			if md == nil {
+				return math.NaN()
+			}
+			return src.Value(md)
+		}, nil
+	}
+
+	// опечатка в имени иначе дала бы условие, которое никогда не выполняется
+	if !c.vars[name] {
+		return nil, errorf(n.pos, "unknown identifier %q", name)
+	}
+
+	return func(env Env) float64 {
+		if value, ok := env.Lookup(name); ok {
+			return value
+		}
+		return math.NaN()
+	}, nil
+}
+
+// call — встроенная функция (abs, min, max) или индикатор: rsi(14), sma(close, 50), macd(12, 26, 9).signal
+func (c *compiler) call(n *callNode) (evalFunc, Type, error) {
+	switch n.name {
+	case "abs", "min", "max":
+		if n.field != "" {
+			return nil, 0, errorf(n.pos, "function %s has no output %q", n.name, n.field)
+		}
+		return c.builtin(n)
+	}
+
+	src := indicators.Source("")
+	args := n.args
+	if len(args) > 0 {
+		if ident, ok := args[0].(*identNode); ok {
+			s, err := indicators.ParseSource(ident.name)
+			if err != nil {
+				return nil, 0, errorf(ident.pos, "unknown price source %q", ident.name)
+			}
+			src, args = s, args[1:]
+		}
+	}
+
+	params := make([]float64, 0, len(args))
+	for _, arg := range args {
+		number, ok := arg.(*numberNode)
+		if !ok {
+			return nil, 0, errorf(arg.position(), "indicator %s parameters must be numbers", n.name)
+		}
+		params = append(params, number.value)
+	}
+
+	ind, err := indicators.New(n.name, src, params...)
+	if err != nil {
+		return nil, 0, errorf(n.pos, "%v", err)
+	}
+
+	if existing, ok := c.indicators[ind.Name()]; ok {
+		ind = existing
+	} else {
+		c.indicators[ind.Name()] = ind
+		c.order = append(c.order, ind)
+	}
+
+	key := ind.Name()
+	if n.field != "" {
+		key += "_" + n.field
+		if _, ok := ind.Values()[key]; !ok {
+			return nil, 0, errorf(n.pos, "indicator %s has no output %q", n.name, n.field)
+		}
+	}
+
+	return func(Env) float64 {
+		if !ind.Ready() {
+			return math.NaN()
+		}
+		return ind.Values()[key]
+	}, Number, nil
+}
+
+func (c *compiler) builtin(n *callNode) (evalFunc, Type, error) {
+	args := make([]evalFunc, 0, len(n.args))
+	for _, arg := range n.args {
+		f, typ, err := c.compile(arg)
+		if err != nil {
+			return nil, 0, err
+		}
+		if typ != Number {
+			return nil, 0, errorf(arg.position(), "function %s expects number arguments, got %s", n.name, typ)
+		}
+		args = append(args, f)
+	}
+
+	if n.name == "abs" {
+		if len(args) != 1 {
+			return nil, 0, errorf(n.pos, "function abs expects 1 argument, got %d", len(args))
+		}
+		return func(env Env) float64 { return math.Abs(args[0](env)) }, Number, nil
+	}
+
+	if len(args) < 2 {
+		return nil, 0, errorf(n.pos, "function %s expects at least 2 arguments, got %d", n.name, len(args))
+	}
+	pick := math.Min
+	if n.name == "max" {
+		pick = math.Max
+	}
+	return func(env Env) float64 {
+		result := args[0](env)
+		for _, arg := range args[1:] {
+			result = pick(result, arg(env))
+		}
+		return result
+	}, Number, nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
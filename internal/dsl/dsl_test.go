@@ -0,0 +1,120 @@
+package dsl
+
+import (
+	"crypto-trading-bot/internal/types"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEnv struct {
+	md     *types.MarketData
+	values map[string]float64
+}
+
+func (e *testEnv) MarketData() *types.MarketData { return e.md }
+
+func (e *testEnv) Lookup(name string) (float64, bool) {
+	value, ok := e.values[name]
+	return value, ok
+}
+
+func TestCompileAndEval(t *testing.T) {
+	p, err := CompileCondition("rsi(14) < 30 and close > sma(close, 3) and cluster_level_dist < 0.2%", "cluster_level_dist")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 15, p.WarmUp())
+
+	env := &testEnv{values: map[string]float64{"cluster_level_dist": 0.001}}
+	// цена падает: RSI = 0, последняя свеча выше средней за 3 свечи невозможна, поэтому условие ложно
+	for i := 0; i < 20; i++ {
+		env.md = &types.MarketData{ClosePrice: 100 - float64(i)}
+		p.Update(env.md)
+		assert.False(t, p.Test(env))
+	}
+
+	// отскок: RSI ещё ниже 30, цена выше средней
+	env.md = &types.MarketData{ClosePrice: 83}
+	p.Update(env.md)
+	assert.True(t, p.Test(env))
+
+	// значения нет в окружении — условие ложно
+	delete(env.values, "cluster_level_dist")
+	assert.False(t, p.Test(env))
+}
+
+func TestWarmUpLogic(t *testing.T) {
+	env := &testEnv{md: &types.MarketData{ClosePrice: 5}}
+	cases := []struct {
+		src  string
+		want bool
+	}{
+		// rsi(14) на прогреве: отрицание не превращает отсутствие данных в сигнал
+		{"not (rsi(14) > 70)", false},
+		{"not not (rsi(14) > 70)", false},
+		{"rsi(14) > 70 and price < 10", false},
+		{"not (rsi(14) > 70 and price < 10)", false},
+		{"rsi(14) > 70 and price > 10", false},
+		{"not (rsi(14) > 70 and price > 10)", true},
+		{"rsi(14) > 70 or price > 10", false},
+		{"not (rsi(14) > 70 or price > 10)", false},
+		{"rsi(14) > 70 or price < 10", true},
+	}
+
+	for _, c := range cases {
+		p, err := CompileCondition(c.src)
+		if assert.NoError(t, err, c.src) {
+			p.Update(env.md)
+			assert.Equal(t, c.want, p.Test(env), c.src)
+		}
+	}
+
+	p, err := CompileCondition("not (rsi(14) > 70)")
+	if assert.NoError(t, err) {
+		p.Update(env.md)
+		assert.True(t, math.IsNaN(p.Eval(env)))
+	}
+}
+
+func TestArithmeticAndOutputs(t *testing.T) {
+	p, err := Compile("max(abs(-2) * 3, 1 + 2) / 2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, Number, p.Type())
+		assert.Equal(t, 3.0, p.Eval(&testEnv{}))
+	}
+
+	p, err = CompileCondition("macd(3, 5, 2).signal > -1 or not (price >= 10)")
+	if assert.NoError(t, err) {
+		env := &testEnv{md: &types.MarketData{ClosePrice: 5}}
+		assert.True(t, p.Test(env), "индикатор на прогреве, но вторая часть условия истинна")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"rsi(14 < 30", "column 12: expected \")\" or \",\", got end of expression"},
+		{"close > ", "column 9: unexpected end of expression"},
+		{"close = 1", "column 7: unexpected \"=\", use == or !="},
+		{"1 < 2 < 3", "comparisons cannot be chained"},
+		{"close and true", "column 7: operator and expects bool operands, got number and bool"},
+		{"rsi(close > 1)", "parameters must be numbers"},
+		{"rsi(14, 2)", "expected 1 parameters, got 2"},
+		{"foo(1)", "unknown indicator: foo"},
+		{"macd(12, 26, 9).upper", "has no output \"upper\""},
+		{"sma(price2, 3)", "unknown price source"},
+		{"close + 1", "expression must be a condition, got number"},
+		{"close # 1", "unexpected character"},
+		{"cluster_level_dst < 0.2%", "column 1: unknown identifier \"cluster_level_dst\""},
+		{"rsi(14) < 30 and closee > 1", "column 18: unknown identifier \"closee\""},
+	}
+
+	for _, tt := range tests {
+		_, err := CompileCondition(tt.src, "cluster_level_dist")
+		assert.ErrorContains(t, err, tt.err, tt.src)
+	}
+}
@@ -0,0 +1,288 @@
+package dsl
+
+// Грамматика, по возрастанию приоритета:
+//
+//	expr       = or
+//	or         = and { "or" and }
+//	and        = not { "and" not }
+//	not        = "not" not | comparison
+//	comparison = additive [ ("<" | "<=" | ">" | ">=" | "==" | "!=") additive ]
+//	additive   = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/") unary }
+//	unary      = "-" unary | primary
+//	primary    = number | "true" | "false" | ident [ "(" [ expr { "," expr } ] ")" [ "." ident ] ] | "(" expr ")"
+
+type node interface {
+	position() int
+}
+
+type numberNode struct {
+	pos   int
+	value float64
+}
+
+type boolNode struct {
+	pos   int
+	value bool
+}
+
+type identNode struct {
+	pos  int
+	name string
+}
+
+type callNode struct {
+	pos   int
+	name  string
+	args  []node
+	field string // выход индикатора после точки, например macd(12, 26, 9).signal
+}
+
+type unaryNode struct {
+	pos int
+	op  string
+	x   node
+}
+
+type binaryNode struct {
+	pos   int
+	op    string
+	left  node
+	right node
+}
+
+func (n *numberNode) position() int { return n.pos }
+func (n *boolNode) position() int   { return n.pos }
+func (n *identNode) position() int  { return n.pos }
+func (n *callNode) position() int   { return n.pos }
+func (n *unaryNode) position() int  { return n.pos }
+func (n *binaryNode) position() int { return n.pos }
+
+type parser struct {
+	tokens []token
+	index  int
+}
+
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, errorf(tok.pos, "unexpected %s", tok)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.index]
+	if tok.kind != tokenEOF {
+		p.index++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && tok.text == word
+}
+
+func (p *parser) isOperator(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, errorf(tok.pos, "expected %s, got %s", what, tok)
+	}
+	return tok, nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		tok := p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		tok := p.next()
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.isKeyword("not") {
+		tok := p.next()
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: tok.pos, op: "not", x: x}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator("<", "<=", ">", ">=", "==", "!=") {
+		tok := p.next()
+		right, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}
+		if p.isOperator("<", "<=", ">", ">=", "==", "!=") {
+			return nil, errorf(p.peek().pos, "comparisons cannot be chained, use and")
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) additive() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+", "-") {
+		tok := p.next()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*", "/") {
+		tok := p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: tok.text, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.isOperator("-") {
+		tok := p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: tok.pos, op: "-", x: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return &numberNode{pos: tok.pos, value: tok.value}, nil
+
+	case tokenLParen:
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, "\")\""); err != nil {
+			return nil, err
+		}
+		return x, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return &boolNode{pos: tok.pos, value: tok.text == "true"}, nil
+		case "and", "or", "not":
+			return nil, errorf(tok.pos, "unexpected %s", tok)
+		}
+
+		if p.peek().kind != tokenLParen {
+			return &identNode{pos: tok.pos, name: tok.text}, nil
+		}
+
+		p.next()
+		call := &callNode{pos: tok.pos, name: tok.text}
+		if p.peek().kind != tokenRParen {
+			for {
+				arg, err := p.or()
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, arg)
+				if p.peek().kind != tokenComma {
+					break
+				}
+				p.next()
+			}
+		}
+		if _, err := p.expect(tokenRParen, "\")\" or \",\""); err != nil {
+			return nil, err
+		}
+
+		if p.peek().kind == tokenDot {
+			p.next()
+			field, err := p.expect(tokenIdent, "indicator output name")
+			if err != nil {
+				return nil, err
+			}
+			call.field = field.text
+		}
+		return call, nil
+	}
+
+	return nil, errorf(tok.pos, "unexpected %s", tok)
+}
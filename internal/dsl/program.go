@@ -0,0 +1,91 @@
+// Язык выражений для правил стратегий, например:
+//
+//	rsi(14) < 30 and close > sma(close, 50) and cluster_level_dist < 0.2%
+//
+// Выражение разбирается, проверяется по типам и компилируется один раз, затем вычисляется на каждой свече.
+// Индикаторы из вызовов (rsi(14), sma(close, 50), macd(12, 26, 9).signal) рассчитываются самой программой,
+// остальные имена берутся из окружения, например из индикаторов payload, и должны быть объявлены при компиляции.
+package dsl
+
+import (
+	"crypto-trading-bot/internal/indicators"
+	"crypto-trading-bot/internal/types"
+	"fmt"
+)
+
+// Program — скомпилированное выражение. Программа хранит состояние индикаторов,
+// поэтому для каждого потока свечей нужен свой экземпляр.
+type Program struct {
+	src        string
+	typ        Type
+	eval       evalFunc
+	indicators []indicators.Indicator
+}
+
+// Compile разбирает и компилирует выражение. vars — имена значений, которые окружение
+// предоставляет выражению помимо полей свечи; другие имена считаются ошибкой.
+func Compile(src string, vars ...string) (*Program, error) {
+	tree, err := parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("parse %q: %w", src, err)
+	}
+
+	c := &compiler{indicators: make(map[string]indicators.Indicator), vars: make(map[string]bool, len(vars))}
+	for _, name := range vars {
+		c.vars[name] = true
+	}
+	eval, typ, err := c.compile(tree)
+	if err != nil {
+		return nil, fmt.Errorf("compile %q: %w", src, err)
+	}
+
+	return &Program{src: src, typ: typ, eval: eval, indicators: c.order}, nil
+}
+
+// CompileCondition компилирует выражение, результат которого должен быть логическим
+func CompileCondition(src string, vars ...string) (*Program, error) {
+	p, err := Compile(src, vars...)
+	if err != nil {
+		return nil, err
+	}
+	if p.typ != Bool {
+		return nil, fmt.Errorf("compile %q: %w", src, errorf(1, "expression must be a condition, got %s", p.typ))
+	}
+	return p, nil
+}
+
+func (p *Program) String() string {
+	return p.src
+}
+
+// Type возвращает тип результата выражения
+func (p *Program) Type() Type {
+	return p.typ
+}
+
+// WarmUp возвращает количество свечей до готовности всех индикаторов выражения
+func (p *Program) WarmUp() int {
+	warmUp := 0
+	for _, ind := range p.indicators {
+		warmUp = max(warmUp, ind.WarmUp())
+	}
+	return warmUp
+}
+
+// Update добавляет свечу в индикаторы выражения. Вызывается один раз на свечу до Eval.
+func (p *Program) Update(md *types.MarketData) {
+	for _, ind := range p.indicators {
+		ind.Update(md)
+	}
+}
+
+// Eval вычисляет выражение. Логический результат возвращается как 1 или 0,
+// NaN означает, что значение ещё нельзя рассчитать.
+func (p *Program) Eval(env Env) float64 {
+	return p.eval(env)
+}
+
+// Test вычисляет условие. Нерассчитанное условие (NaN, индикаторы на прогреве) — нет сигнала.
+func (p *Program) Test(env Env) bool {
+	return p.eval(env) == 1
+}
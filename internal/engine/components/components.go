@@ -3,4 +3,7 @@ package components
 const (
 	MaskDatasource = uint64(1 << 0)
 	MaskPosition   = uint64(1 << 1)
+	MaskSession    = uint64(1 << 2)
+	MaskWallet     = uint64(1 << 3)
+	MaskStrategy   = uint64(1 << 4)
 )
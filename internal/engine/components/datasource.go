@@ -2,6 +2,7 @@
 package components
 
 import (
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/engine"
 	"crypto-trading-bot/internal/exchange"
 	"math"
@@ -12,6 +13,7 @@ import (
 type DataSource struct {
 	Symbol         string
 	Interval       string
+	Live           bool // данные дополняются свечами биржи, сессии на таком источнике не завершаются по концу данных
 	data           []engine.MarketData
 	indexTimestamp map[time.Time]int // индекс записи по времени
 	//fetchDataCommands []exchange.FetchDataCommand // команды для получения данных
@@ -36,6 +38,13 @@ func NewDataSource(symbol string, interval string, data []engine.MarketData) *Da
 	return s
 }
 
+// NewLiveDataSource создаёт источник, который дополняется свечами с биржи (fetchDataSystem)
+func NewLiveDataSource(symbol string, interval string, data []engine.MarketData) *DataSource {
+	s := NewDataSource(symbol, interval, data)
+	s.Live = true
+	return s
+}
+
 func (c *DataSource) Mask() uint64 {
 	return MaskDatasource
 }
@@ -60,20 +69,40 @@ func (c *DataSource) NextPosition(timestamp time.Time) (time.Time, bool) {
 
 }
 
-// Генерация тестовых данных: тренд + шум
-func GenerateTestCandles(n int) []engine.MarketData {
+// Candle возвращает свечу на отметке времени
+func (c *DataSource) Candle(timestamp time.Time) (engine.MarketData, bool) {
+	ind, ok := c.indexTimestamp[timestamp]
+	if !ok {
+		return engine.MarketData{}, false
+	}
+	return c.data[ind], true
+}
+
+// Append добавляет свечу в конец данных. Свеча не позже последней игнорируется.
+func (c *DataSource) Append(md engine.MarketData) {
+	if n := len(c.data); n > 0 && !md.Timestamp.After(c.data[n-1].Timestamp) {
+		return
+	}
+	c.indexTimestamp[md.Timestamp] = len(c.data)
+	c.data = append(c.data, md)
+}
+
+// Генерация тестовых данных: тренд + шум.
+// Свечи начинаются с текущего времени часов clk, им же задаётся шум: при модельных часах ряд воспроизводим.
+func GenerateTestCandles(clk clock.Clock, n int) []engine.MarketData {
 	candles := make([]engine.MarketData, n)
 	base := 40000.0
 	trend := 10.0
 	noise := 2000.0
 
-	rand.New(rand.NewSource(time.Now().UnixNano()))
+	now := clk.Now()
+	rng := rand.New(rand.NewSource(now.UnixNano()))
 
 	for i := 0; i < n; i++ {
 		price := base + trend*float64(i) + noise*math.Sin(float64(i)/20)
-		price += (rand.Float64() - 0.5) * 1000 // шум
+		price += (rng.Float64() - 0.5) * 1000 // шум
 		candles[i] = engine.MarketData{
-			Timestamp:  time.Now().Add(time.Second * time.Duration(i)),
+			Timestamp:  now.Add(time.Second * time.Duration(i)),
 			ClosePrice: price,
 		}
 	}
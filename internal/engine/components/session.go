@@ -0,0 +1,76 @@
+// торговая сессия: реальная торговля или бектест на одном источнике данных
+package components
+
+import "time"
+
+// Скорость сессии
+const (
+	SpeedMax      = 0.0 // без пауз: позиция сдвигается на каждом такте движка
+	SpeedRealtime = 1.0 // в темпе реального времени: минутная свеча раз в минуту
+)
+
+// Session связывает позицию сущности с источником данных и задаёт темп её движения.
+// Сессия завершается, когда данные исторического источника закончились или стратегия вернула ошибку.
+type Session struct {
+	ID     string
+	Source string  // ID сущности с компонентом DataSource
+	Speed  float64 // во сколько раз быстрее реального времени, SpeedMax — без пауз
+
+	Pending bool // позиция сдвинута, свеча ещё не обработана стратегией
+
+	Started  time.Time // время часов движка при первом сдвиге
+	Finished time.Time // время часов движка при завершении
+	First    time.Time // время первой свечи
+	Candles  int       // число пройденных свечей
+	Err      error     // причина аварийного завершения
+
+	moved time.Time // время часов движка при последнем сдвиге
+	done  bool
+}
+
+func NewSession(id string, source string, speed float64) *Session {
+	return &Session{
+		ID:     id,
+		Source: source,
+		Speed:  speed,
+	}
+}
+
+func (c *Session) Mask() uint64 {
+	return MaskSession
+}
+
+// Due сообщает, пора ли сдвигать позицию на gap времени данных.
+// При скорости Speed между сдвигами проходит gap/Speed по часам движка.
+func (c *Session) Due(now time.Time, gap time.Duration) bool {
+	if c.Speed <= SpeedMax || c.moved.IsZero() {
+		return true
+	}
+	return !now.Before(c.moved.Add(time.Duration(float64(gap) / c.Speed)))
+}
+
+// Moved отмечает сдвиг позиции на свечу timestamp
+func (c *Session) Moved(now time.Time, timestamp time.Time) {
+	if c.Started.IsZero() {
+		c.Started = now
+		c.First = timestamp
+	}
+	c.moved = now
+	c.Candles++
+	c.Pending = true
+}
+
+// Finish завершает сессию, err — причина аварийного завершения
+func (c *Session) Finish(now time.Time, err error) {
+	if c.done {
+		return
+	}
+	c.done = true
+	c.Finished = now
+	c.Err = err
+}
+
+// Done сообщает, что сессия завершена
+func (c *Session) Done() bool {
+	return c.done
+}
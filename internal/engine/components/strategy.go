@@ -0,0 +1,31 @@
+// торговая логика сессии
+package components
+
+import "crypto-trading-bot/internal/engine"
+
+// Trader принимает решения по очередной свече и торгует через кошелёк сессии.
+// Ошибка завершает сессию.
+type Trader interface {
+	OnCandle(candle engine.MarketData, wallet *Wallet) error
+}
+
+// TraderFunc — функция, реализующая Trader
+type TraderFunc func(candle engine.MarketData, wallet *Wallet) error
+
+func (f TraderFunc) OnCandle(candle engine.MarketData, wallet *Wallet) error {
+	return f(candle, wallet)
+}
+
+type Strategy struct {
+	Trader Trader
+}
+
+func NewStrategy(trader Trader) *Strategy {
+	return &Strategy{
+		Trader: trader,
+	}
+}
+
+func (c *Strategy) Mask() uint64 {
+	return MaskStrategy
+}
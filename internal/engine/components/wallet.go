@@ -0,0 +1,82 @@
+// кошелёк торговой сессии
+package components
+
+import "fmt"
+
+// Wallet — средства сессии: деньги и количество актива, оценённые по последней цене
+type Wallet struct {
+	Initial    float64 // начальный капитал
+	Cash       float64
+	Quantity   float64 // количество актива
+	Commission float64 // комиссия, доля от объёма сделки
+	Price      float64 // последняя цена актива
+	Trades     int     // число сделок
+
+	peak        float64 // максимум капитала
+	maxDrawdown float64
+}
+
+func NewWallet(cash float64, commission float64) *Wallet {
+	return &Wallet{
+		Initial:    cash,
+		Cash:       cash,
+		Commission: commission,
+		peak:       cash,
+	}
+}
+
+func (c *Wallet) Mask() uint64 {
+	return MaskWallet
+}
+
+// Equity возвращает капитал: деньги и актив по последней цене
+func (c *Wallet) Equity() float64 {
+	return c.Cash + c.Quantity*c.Price
+}
+
+// MaxDrawdown возвращает максимальную просадку капитала, долю от пика
+func (c *Wallet) MaxDrawdown() float64 {
+	return c.maxDrawdown
+}
+
+// Mark оценивает кошелёк по новой цене и обновляет просадку
+func (c *Wallet) Mark(price float64) {
+	c.Price = price
+
+	equity := c.Equity()
+	if equity > c.peak {
+		c.peak = equity
+	}
+	if c.peak > 0 {
+		c.maxDrawdown = max(c.maxDrawdown, (c.peak-equity)/c.peak)
+	}
+}
+
+// Buy покупает amount актива по последней цене
+func (c *Wallet) Buy(amount float64) error {
+	if amount <= 0 {
+		return fmt.Errorf("invalid amount: %v", amount)
+	}
+	cost := amount * c.Price * (1 + c.Commission)
+	if cost > c.Cash {
+		return fmt.Errorf("insufficient funds: need %.2f, have %.2f", cost, c.Cash)
+	}
+	c.Cash -= cost
+	c.Quantity += amount
+	c.Trades++
+	return nil
+}
+
+// Sell продаёт amount актива по последней цене
+func (c *Wallet) Sell(amount float64) error {
+	if amount <= 0 {
+		return fmt.Errorf("invalid amount: %v", amount)
+	}
+	if amount > c.Quantity {
+		return fmt.Errorf("insufficient quantity: need %v, have %v", amount, c.Quantity)
+	}
+	c.Cash += amount * c.Price * (1 - c.Commission)
+	c.Quantity -= amount
+	c.Trades++
+	return nil
+}
@@ -64,9 +64,8 @@ func (e *CustomEntitityManager) ProcessEvents() {
 			// Сначала уведомляем системы
 			for _, listener := range e.listeners {
 				listener.OnEntityRemoved(event.Entity)
-
-				e.Remove(event.Entity)
 			}
+			e.EntityManager.Remove(event.Entity)
 		}
 	}
 
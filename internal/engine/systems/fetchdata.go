@@ -2,6 +2,7 @@ package systems
 
 import (
 	"context"
+	"crypto-trading-bot/internal/engine"
 	"crypto-trading-bot/internal/engine/components"
 	"crypto-trading-bot/internal/engine/ecsx"
 	"crypto-trading-bot/internal/exchange"
@@ -53,6 +54,17 @@ func (s *fetchDataSystem) Process(em ecs.EntityManager) (state int) {
 					log.Printf("✅ Получено %s: %s %s O=%.2f H=%.2f L=%.2f C=%.2f V=%.2f",
 						c.Timestamp.Format("15:04:05"), c.Symbol, c.Interval, c.Open, c.High, c.Low, c.Close, c.Volume)
 
+					datasource.Append(engine.MarketData{
+						Timestamp:  c.Timestamp,
+						Symbol:     c.Symbol,
+						TimeFrame:  c.Interval,
+						OpenPrice:  c.Open,
+						HightPrice: c.High,
+						LowPrice:   c.Low,
+						ClosePrice: c.Close,
+						Volume:     c.Volume,
+					})
+
 				}
 			}
 		}
@@ -71,6 +83,9 @@ func (s *fetchDataSystem) Teardown() {
 	for _, dataComp := range s.em.FilterByMask(components.MaskDatasource) {
 
 		datasource := dataComp.Get(components.MaskDatasource).(*components.DataSource)
+		if !datasource.Live {
+			continue
+		}
 
 		s.subscribes[dataSourceKey(datasource)]--
 		if s.subscribes[dataSourceKey(datasource)] == 0 {
@@ -82,7 +97,11 @@ func (s *fetchDataSystem) Teardown() {
 
 func (s *fetchDataSystem) OnEntityAdded(entity *ecs.Entity, _components []ecs.Component) {
 
-	datasource := entity.Get(components.MaskDatasource).(*components.DataSource)
+	// подписка нужна только источникам, которые дополняются с биржи
+	datasource, ok := entity.Get(components.MaskDatasource).(*components.DataSource)
+	if !ok || !datasource.Live {
+		return
+	}
 
 	if s.subscribes[dataSourceKey(datasource)] == 0 {
 
@@ -100,7 +119,10 @@ func (s *fetchDataSystem) OnEntityAdded(entity *ecs.Entity, _components []ecs.Co
 }
 
 func (s *fetchDataSystem) OnEntityRemoved(entity *ecs.Entity) {
-	datasource := entity.Get(components.MaskDatasource).(*components.DataSource)
+	datasource, ok := entity.Get(components.MaskDatasource).(*components.DataSource)
+	if !ok || !datasource.Live {
+		return
+	}
 	if s.subscribes[dataSourceKey(datasource)] == 0 {
 		fmt.Printf("[fetchDataSystem] Ошибка. Не было подписки на %s %s\n", datasource.Symbol, datasource.Interval)
 	}
@@ -2,36 +2,65 @@
 package systems
 
 import (
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/engine/components"
 	"fmt"
 
 	"github.com/andygeiss/ecs"
 )
 
+// movementSystem сдвигает позиции сессий по их источникам данных.
+// Каждая сессия движется только по своему источнику (Session.Source) и в своём темпе (Session.Speed).
+// Сессия на историческом источнике завершается, когда данные закончились.
 type movementSystem struct {
+	clock clock.Clock
 }
 
-func NewMovementSystem() ecs.System {
-	return &movementSystem{}
+func NewMovementSystem(clk clock.Clock) ecs.System {
+	return &movementSystem{
+		clock: clk,
+	}
 }
 
 func (s *movementSystem) Process(em ecs.EntityManager) (state int) {
 
-	// сдвигаем позиции в источнике данных
-
+	// источники данных по ID сущности, чтобы не искать источник для каждой сессии
+	sources := make(map[string]*components.DataSource)
 	for _, dataComp := range em.FilterByMask(components.MaskDatasource) {
+		sources[dataComp.Id] = dataComp.Get(components.MaskDatasource).(*components.DataSource)
+	}
 
-		datasource := dataComp.Get(components.MaskDatasource).(*components.DataSource)
+	now := s.clock.Now()
 
-		for _, posComp := range em.FilterByMask(components.MaskPosition) {
-			position := posComp.Get(components.MaskPosition).(*components.Position)
+	for _, sessionComp := range em.FilterByMask(components.MaskSession | components.MaskPosition) {
+		session := sessionComp.Get(components.MaskSession).(*components.Session)
+		position := sessionComp.Get(components.MaskPosition).(*components.Position)
+
+		// свеча ещё не обработана стратегией или сессия завершена
+		if session.Pending || session.Done() {
+			continue
+		}
 
-			if next, ok := datasource.NextPosition(position.Timestamp); ok {
-				position.SetPosition(next)
-				fmt.Printf("Позиция перемещена: %s\n", next.Format("2006-01-02 15:04:05"))
+		datasource, ok := sources[session.Source]
+		if !ok {
+			session.Finish(now, fmt.Errorf("data source %s not found", session.Source))
+			continue
+		}
+
+		next, ok := datasource.NextPosition(position.Timestamp)
+		if !ok {
+			if !datasource.Live {
+				session.Finish(now, nil)
 			}
+			continue
+		}
 
+		if !position.Timestamp.IsZero() && !session.Due(now, next.Sub(position.Timestamp)) {
+			continue
 		}
+
+		position.SetPosition(next)
+		session.Moved(now, next)
 	}
 
 	return ecs.StateEngineContinue
@@ -0,0 +1,103 @@
+// Завершение торговых сессий
+package systems
+
+import (
+	"crypto-trading-bot/internal/engine/components"
+	"time"
+
+	"github.com/andygeiss/ecs"
+)
+
+// SessionResult — итог завершённой сессии
+type SessionResult struct {
+	ID          string
+	Source      string
+	Start       time.Time     // первая свеча
+	End         time.Time     // последняя пройденная свеча
+	Candles     int           // число пройденных свечей
+	Duration    time.Duration // время работы сессии по часам движка
+	Initial     float64       // начальный капитал
+	Final       float64       // итоговый капитал
+	Return      float64       // доходность, доля от начального капитала
+	MaxDrawdown float64
+	Trades      int
+	Err         error // причина аварийного завершения
+}
+
+// sessionSystem сообщает итоги завершённых сессий и удаляет их сущности.
+// При остановке движка сообщает итоги оставшихся сессий, в том числе не успевших завершиться.
+type sessionSystem struct {
+	em           ecs.EntityManager
+	report       func(SessionResult)
+	stopWhenDone bool // остановить движок, когда не останется сессий
+}
+
+func NewSessionSystem(em ecs.EntityManager, report func(SessionResult), stopWhenDone bool) ecs.System {
+	return &sessionSystem{
+		em:           em,
+		report:       report,
+		stopWhenDone: stopWhenDone,
+	}
+}
+
+func (s *sessionSystem) Process(em ecs.EntityManager) (state int) {
+
+	sessions := em.FilterByMask(components.MaskSession | components.MaskPosition)
+	active := len(sessions)
+
+	for _, sessionComp := range sessions {
+		session := sessionComp.Get(components.MaskSession).(*components.Session)
+		if !session.Done() {
+			continue
+		}
+
+		s.report(sessionResult(sessionComp))
+		em.Remove(sessionComp)
+		active--
+	}
+
+	if s.stopWhenDone && active == 0 {
+		return ecs.StateEngineStop
+	}
+	return ecs.StateEngineContinue
+}
+
+func (s *sessionSystem) Setup() {}
+
+func (s *sessionSystem) Teardown() {
+	for _, sessionComp := range s.em.FilterByMask(components.MaskSession | components.MaskPosition) {
+		s.report(sessionResult(sessionComp))
+	}
+}
+
+func sessionResult(entity *ecs.Entity) SessionResult {
+	session := entity.Get(components.MaskSession).(*components.Session)
+	position := entity.Get(components.MaskPosition).(*components.Position)
+
+	result := SessionResult{
+		ID:      session.ID,
+		Source:  session.Source,
+		Start:   session.First,
+		End:     position.Timestamp,
+		Candles: session.Candles,
+		Err:     session.Err,
+	}
+	if session.Done() && !session.Started.IsZero() {
+		result.Duration = session.Finished.Sub(session.Started)
+	}
+
+	if wallet, ok := entity.Get(components.MaskWallet).(*components.Wallet); ok {
+		result.Initial = wallet.Initial
+		result.Final = wallet.Equity()
+		result.MaxDrawdown = wallet.MaxDrawdown()
+		result.Trades = wallet.Trades
+		if wallet.Initial > 0 {
+			result.Return = result.Final/wallet.Initial - 1
+		}
+	}
+
+	return result
+}
+
+// Проверка соответствия интерфейсу
+var _ ecs.System = (*sessionSystem)(nil)
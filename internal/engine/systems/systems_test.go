@@ -0,0 +1,195 @@
+package systems
+
+import (
+	"context"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/engine"
+	"crypto-trading-bot/internal/engine/components"
+	"crypto-trading-bot/internal/engine/ecsx"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andygeiss/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// candles возвращает минутные свечи с ценой закрытия 100, 101, ...
+func candles(n int) []engine.MarketData {
+	data := make([]engine.MarketData, n)
+	for i := range data {
+		data[i] = engine.MarketData{Timestamp: start.Add(time.Duration(i) * time.Minute), ClosePrice: 100 + float64(i)}
+	}
+	return data
+}
+
+// holdTrader покупает на первой свече и держит позицию
+var holdTrader = components.TraderFunc(func(candle engine.MarketData, wallet *components.Wallet) error {
+	if wallet.Quantity == 0 {
+		return wallet.Buy(10)
+	}
+	return nil
+})
+
+func session(id string, source string, speed float64, trader components.Trader) *ecs.Entity {
+	return ecs.NewEntity(id, []ecs.Component{
+		components.NewSession(id, source, speed),
+		components.NewPosition(time.Time{}),
+		components.NewWallet(10000, 0),
+		components.NewStrategy(trader),
+	})
+}
+
+func TestConcurrentBacktestSessions(t *testing.T) {
+	clk := clock.New()
+	em := ecsx.NewEntityManager()
+
+	// три источника разной длины и по сто сессий на каждом
+	lengths := map[string]int{"short": 5, "medium": 20, "long": 50}
+	for id, n := range lengths {
+		em.Add(ecs.NewEntity(id, []ecs.Component{components.NewDataSource("BTCUSDT", "1m", candles(n))}))
+		for i := range 100 {
+			em.Add(session(fmt.Sprintf("%s-%d", id, i), id, components.SpeedMax, holdTrader))
+		}
+	}
+
+	var results []SessionResult
+	sm := ecs.NewSystemManager()
+	sm.Add(NewMovementSystem(clk), NewTradingSystem(clk), NewSessionSystem(em, func(r SessionResult) {
+		results = append(results, r)
+	}, true))
+
+	e := ecsx.NewCustomEngine(em, sm)
+	e.Setup()
+	e.Run()
+	e.Teardown()
+
+	assert.Len(t, results, 300)
+	assert.Empty(t, em.FilterByMask(components.MaskSession))
+
+	reported := map[string]bool{}
+	for i, r := range results {
+		n := lengths[r.Source]
+		assert.NoError(t, r.Err)
+		assert.Equal(t, n, r.Candles, r.ID)
+		assert.Equal(t, start, r.Start)
+		assert.Equal(t, start.Add(time.Duration(n-1)*time.Minute), r.End)
+		assert.Equal(t, 1, r.Trades)
+		// куплено 10 по 100, итог по цене последней свечи
+		assert.InDelta(t, 9000+10*(100+float64(n-1)), r.Final, 1e-9)
+		assert.InDelta(t, r.Final/10000-1, r.Return, 1e-12)
+
+		// сессии завершаются независимо: короткие раньше длинных
+		reported[r.Source] = true
+		if r.Source == "short" {
+			assert.False(t, reported["medium"] || reported["long"], i)
+		}
+		if r.Source == "medium" {
+			assert.False(t, reported["long"], i)
+		}
+	}
+}
+
+func TestRealtimeReplay(t *testing.T) {
+	clk := clock.NewSimulated(start)
+	em := ecs.NewEntityManager()
+	em.Add(ecs.NewEntity("data", []ecs.Component{components.NewDataSource("BTCUSDT", "1m", candles(3))}))
+	// минута данных за секунду
+	em.Add(session("replay", "data", 60, holdTrader))
+	em.Add(session("fast", "data", components.SpeedMax, holdTrader))
+
+	movement := NewMovementSystem(clk)
+	trading := NewTradingSystem(clk)
+	tick := func() {
+		movement.Process(em)
+		trading.Process(em)
+	}
+	candlesOf := func(id string) int {
+		return em.Get(id).Get(components.MaskSession).(*components.Session).Candles
+	}
+
+	tick()
+	assert.Equal(t, 1, candlesOf("replay"))
+	tick()
+	assert.Equal(t, 1, candlesOf("replay"))
+	assert.Equal(t, 2, candlesOf("fast"))
+
+	clk.Advance(999 * time.Millisecond)
+	tick()
+	assert.Equal(t, 1, candlesOf("replay"))
+
+	clk.Advance(time.Millisecond)
+	tick()
+	assert.Equal(t, 2, candlesOf("replay"))
+
+	clk.Advance(time.Second)
+	tick()
+	tick()
+	assert.Equal(t, 3, candlesOf("replay"))
+	replay := em.Get("replay").Get(components.MaskSession).(*components.Session)
+	assert.True(t, replay.Done())
+	assert.Equal(t, 2*time.Second, replay.Finished.Sub(replay.Started))
+}
+
+func TestLiveSessionWaitsForData(t *testing.T) {
+	clk := clock.New()
+	em := ecs.NewEntityManager()
+	source := components.NewLiveDataSource("BTCUSDT", "1m", candles(2))
+	em.Add(ecs.NewEntity("live", []ecs.Component{source}))
+	em.Add(session("s", "live", components.SpeedMax, holdTrader))
+
+	var results []SessionResult
+	sessions := NewSessionSystem(em, func(r SessionResult) { results = append(results, r) }, false)
+	systems := []ecs.System{NewMovementSystem(clk), NewTradingSystem(clk), sessions}
+	tick := func() {
+		for _, system := range systems {
+			assert.Equal(t, ecs.StateEngineContinue, system.Process(em))
+		}
+	}
+
+	for range 5 {
+		tick()
+	}
+	assert.Empty(t, results)
+
+	source.Append(engine.MarketData{Timestamp: start.Add(2 * time.Minute), ClosePrice: 110})
+	// свеча не позже последней игнорируется
+	source.Append(engine.MarketData{Timestamp: start.Add(time.Minute), ClosePrice: 1})
+	tick()
+	tick()
+
+	// при остановке движка сообщается итог незавершённой сессии
+	sessions.Teardown()
+	assert.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 3, results[0].Candles)
+	assert.InDelta(t, 9000+10*110.0, results[0].Final, 1e-9)
+}
+
+func TestSessionErrors(t *testing.T) {
+	clk := clock.New()
+	em := ecs.NewEntityManager()
+	em.Add(ecs.NewEntity("data", []ecs.Component{components.NewDataSource("BTCUSDT", "1m", candles(10))}))
+	em.Add(session("broke", "data", components.SpeedMax, components.TraderFunc(func(engine.MarketData, *components.Wallet) error {
+		return fmt.Errorf("strategy failed")
+	})))
+	em.Add(session("orphan", "missing", components.SpeedMax, holdTrader))
+
+	results := map[string]SessionResult{}
+	sm := ecs.NewSystemManager()
+	sm.Add(NewStopSystem(context.Background()), NewMovementSystem(clk), NewTradingSystem(clk), NewSessionSystem(em, func(r SessionResult) {
+		results[r.ID] = r
+	}, true))
+
+	e := ecs.NewDefaultEngine(em, sm)
+	e.Setup()
+	e.Run()
+	e.Teardown()
+
+	assert.EqualError(t, results["broke"].Err, "strategy failed")
+	assert.Equal(t, 1, results["broke"].Candles)
+	assert.EqualError(t, results["orphan"].Err, "data source missing not found")
+	assert.Equal(t, 0, results["orphan"].Candles)
+}
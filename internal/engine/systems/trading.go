@@ -0,0 +1,70 @@
+// Передача свечей стратегиям сессий
+package systems
+
+import (
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/engine/components"
+	"fmt"
+
+	"github.com/andygeiss/ecs"
+)
+
+// tradingSystem передаёт стратегии сессии свечу, на которую сдвинулась позиция,
+// предварительно оценив кошелёк по цене закрытия. Сессия без кошелька или стратегии только движется по данным.
+type tradingSystem struct {
+	clock clock.Clock
+}
+
+func NewTradingSystem(clk clock.Clock) ecs.System {
+	return &tradingSystem{
+		clock: clk,
+	}
+}
+
+func (s *tradingSystem) Process(em ecs.EntityManager) (state int) {
+
+	sources := make(map[string]*components.DataSource)
+	for _, dataComp := range em.FilterByMask(components.MaskDatasource) {
+		sources[dataComp.Id] = dataComp.Get(components.MaskDatasource).(*components.DataSource)
+	}
+
+	for _, sessionComp := range em.FilterByMask(components.MaskSession | components.MaskPosition) {
+		session := sessionComp.Get(components.MaskSession).(*components.Session)
+		if !session.Pending || session.Done() {
+			continue
+		}
+		session.Pending = false
+
+		wallet, hasWallet := sessionComp.Get(components.MaskWallet).(*components.Wallet)
+		strategy, hasStrategy := sessionComp.Get(components.MaskStrategy).(*components.Strategy)
+		if !hasWallet || !hasStrategy {
+			continue
+		}
+
+		position := sessionComp.Get(components.MaskPosition).(*components.Position)
+		datasource, ok := sources[session.Source]
+		if !ok {
+			session.Finish(s.clock.Now(), fmt.Errorf("data source %s not found", session.Source))
+			continue
+		}
+		candle, ok := datasource.Candle(position.Timestamp)
+		if !ok {
+			session.Finish(s.clock.Now(), fmt.Errorf("no candle at %v in %s", position.Timestamp, session.Source))
+			continue
+		}
+
+		wallet.Mark(candle.ClosePrice)
+		if err := strategy.Trader.OnCandle(candle, wallet); err != nil {
+			session.Finish(s.clock.Now(), err)
+		}
+	}
+
+	return ecs.StateEngineContinue
+}
+
+func (s *tradingSystem) Setup() {}
+
+func (s *tradingSystem) Teardown() {}
+
+// Проверка соответствия интерфейсу
+var _ ecs.System = (*tradingSystem)(nil)
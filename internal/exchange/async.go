@@ -1,19 +1,23 @@
 package exchange
 
 import (
+	"crypto-trading-bot/internal/clock"
 	"sync"
 	"time"
 )
 
 type AsyncManager struct {
 	mu       sync.RWMutex
+	clock    clock.Clock
 	results  map[CommandID]interface{}
 	timeouts map[CommandID]time.Time
 	ttl      time.Duration
 }
 
-func NewAsyncManager(ttl time.Duration) *AsyncManager {
+// NewAsyncManager создаёт хранилище результатов команд. Срок жизни результата ttl отсчитывается по часам clk.
+func NewAsyncManager(clk clock.Clock, ttl time.Duration) *AsyncManager {
 	return &AsyncManager{
+		clock:    clk,
 		results:  make(map[CommandID]interface{}),
 		timeouts: make(map[CommandID]time.Time),
 		ttl:      ttl,
@@ -24,14 +28,14 @@ func (am *AsyncManager) StoreResult(cmdID CommandID, result interface{}) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 	am.results[cmdID] = result
-	am.timeouts[cmdID] = time.Now().Add(am.ttl)
+	am.timeouts[cmdID] = am.clock.Now().Add(am.ttl)
 }
 
 func (am *AsyncManager) GetResult(cmdID CommandID) (interface{}, bool) {
 	am.mu.RLock()
 	defer am.mu.RUnlock()
 
-	if expiry, exists := am.timeouts[cmdID]; exists && time.Now().Before(expiry) {
+	if expiry, exists := am.timeouts[cmdID]; exists && am.clock.Now().Before(expiry) {
 		if result, ok := am.results[cmdID]; ok {
 			return result, true
 		}
@@ -42,7 +46,7 @@ func (am *AsyncManager) GetResult(cmdID CommandID) (interface{}, bool) {
 func (am *AsyncManager) Cleanup() {
 	am.mu.Lock()
 	defer am.mu.Unlock()
-	now := time.Now()
+	now := am.clock.Now()
 	for id, expiry := range am.timeouts {
 		if now.After(expiry) {
 			delete(am.results, id)
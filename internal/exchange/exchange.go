@@ -1,8 +1,9 @@
 package exchange
 
 import (
+	"crypto-trading-bot/internal/clock"
 	"fmt"
-	"time"
+	"sync/atomic"
 )
 
 // Exchange — интерфейс, который должны реализовать все биржи
@@ -16,12 +17,29 @@ type Exchange interface {
 
 	// Получение данных по ID — неблокирующее
 	PopCandle(cmdID CommandID) (Candle, bool, error) // (результат, есть_ли_результат)
+	PopOrder(cmdID CommandID) (Order, bool, error)   // очередное изменение статуса ордера
 
 	// Опционально: подписка на стримы через WebSocket
 	SubscribeCandles(symbol string, interval string) CommandID
 	UnsubscribeCandles(symbol string, interval string)
 }
 
-func GetCmdID(prefix, symbol, interval string) CommandID {
-	return CommandID(fmt.Sprintf("%s_%s_%s_%d", prefix, symbol, interval, time.Now().UnixNano()))
+// Simulator — биржа, исполняющая лимитные ордера по свечам (имитация и бэктесты)
+type Simulator interface {
+	MatchCandle(candle Candle)
+}
+
+// OrderTracker — биржа, у которой можно запросить ордер по его ID, например после перезапуска,
+// когда ID команды выставления потерян. Текущее состояние ордера возвращается через PopOrder,
+// ордер, которого биржа не знает, возвращается со статусом canceled.
+type OrderTracker interface {
+	FetchOrderAsync(symbol string, orderID string) CommandID
+}
+
+var cmdSeq atomic.Int64
+
+// GetCmdID формирует ID команды по времени часов clk. Порядковый номер делает ID уникальным,
+// даже если модельное время между командами не изменилось.
+func GetCmdID(clk clock.Clock, prefix, symbol, interval string) CommandID {
+	return CommandID(fmt.Sprintf("%s_%s_%s_%d_%d", prefix, symbol, interval, clk.Now().UnixNano(), cmdSeq.Add(1)))
 }
@@ -1,6 +1,7 @@
 package binance
 
 import (
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/exchange"
 	"crypto/tls"
 	"encoding/json"
@@ -16,6 +17,7 @@ import (
 )
 
 type BinanceExchange struct {
+	clock      clock.Clock
 	httpClient *http.Client
 	wsConn     *websocket.Conn
 	asyncMgr   *exchange.AsyncManager
@@ -23,17 +25,18 @@ type BinanceExchange struct {
 	mu         sync.RWMutex
 }
 
-func NewBinanceExchange() *BinanceExchange {
+func NewBinanceExchange(clk clock.Clock) *BinanceExchange {
 	return &BinanceExchange{
+		clock:      clk,
 		httpClient: &http.Client{Timeout: 10 * time.Second},
-		asyncMgr:   exchange.NewAsyncManager(5 * time.Minute),
+		asyncMgr:   exchange.NewAsyncManager(clk, 5*time.Minute),
 		candlesSub: make(map[string]func(exchange.Candle)),
 	}
 }
 
 // Пример асинхронной команды
 func (b *BinanceExchange) FetchCandlesAsync(symbol, interval string, limit int) exchange.CommandID {
-	cmdID := exchange.GetCmdID("candles", symbol, interval)
+	cmdID := exchange.GetCmdID(b.clock, "candles", symbol, interval)
 
 	go func() {
 		candles, err := b.fetchCandlesHTTP(symbol, interval, limit)
@@ -99,7 +102,7 @@ func (b *BinanceExchange) SubscribeCandles(symbol, interval string) exchange.Com
 	stream := fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), interval)
 	wsURL := "wss://stream.binance.com:9443/ws/" + stream
 
-	cmdID := exchange.GetCmdID("candles", symbol, interval)
+	cmdID := exchange.GetCmdID(b.clock, "candles", symbol, interval)
 
 	handler := func(candle exchange.Candle, err error) {
 		if err != nil {
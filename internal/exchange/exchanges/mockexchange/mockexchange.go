@@ -1,6 +1,7 @@
 package mockexchange
 
 import (
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/exchange"
 	"fmt"
 	"math/rand"
@@ -8,13 +9,24 @@ import (
 	"time"
 )
 
+// bookOrder — лимитный ордер в книге имитации
+type bookOrder struct {
+	cmdID exchange.CommandID
+	order exchange.Order
+}
+
 type MockExchange struct {
 	//asyncMgr *exchange.AsyncManager
-	dataQueues map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Candle]
-	//orderQueues map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Order]
-	results map[exchange.CommandID]interface{}
-	mu      sync.RWMutex
-	err     error
+	clock       clock.Clock
+	dataQueues  map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Candle]
+	orderQueues map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Order]
+	book        map[string]*bookOrder // ID -> открытый лимитный ордер
+	prices      map[string]float64    // symbol -> последняя цена
+	now         time.Time             // время последней свечи MatchCandle
+	orderSeq    int
+	results     map[exchange.CommandID]interface{}
+	mu          sync.RWMutex
+	err         error
 
 	// Настройки для тестов
 	DelayMin time.Duration // Минимальная задержка имитации
@@ -22,15 +34,19 @@ type MockExchange struct {
 	ErrRate  float64       // Вероятность ошибки (0.0 - 1.0)
 }
 
-func NewMockExchange() *MockExchange {
+// NewMockExchange создаёт имитацию биржи. Задержки ответов, время свечей и поток свечей подписки идут по часам clk.
+func NewMockExchange(clk clock.Clock) *MockExchange {
 	return &MockExchange{
-		//asyncMgr: exchange.NewAsyncManager(10 * time.Minute),
-		dataQueues: make(map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Candle]),
-		//orderQueues: make(map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Order]),
-		results:  make(map[exchange.CommandID]interface{}),
-		DelayMin: 50 * time.Millisecond,
-		DelayMax: 500 * time.Millisecond,
-		ErrRate:  0.0, // по умолчанию ошибок нет
+		//asyncMgr: exchange.NewAsyncManager(clk, 10 * time.Minute),
+		clock:       clk,
+		dataQueues:  make(map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Candle]),
+		orderQueues: make(map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Order]),
+		book:        make(map[string]*bookOrder),
+		prices:      make(map[string]float64),
+		results:     make(map[exchange.CommandID]interface{}),
+		DelayMin:    50 * time.Millisecond,
+		DelayMax:    500 * time.Millisecond,
+		ErrRate:     0.0, // по умолчанию ошибок нет
 	}
 }
 
@@ -53,26 +69,26 @@ func (m *MockExchange) shouldError() bool {
 // ————————————————————————————————————————————————————————————————
 
 func (m *MockExchange) FetchCandlesAsync(symbol string, interval string, limit int) exchange.CommandID {
-	cmdID := exchange.GetCmdID("mock_candles", symbol, interval)
+	cmdID := exchange.GetCmdID(m.clock, "mock_candles", symbol, interval)
 
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
 				//m.asyncMgr.StoreResult(cmdID, fmt.Errorf("panic in mock: %v", r))
-				m.err = fmt.Errorf("panic in mock: %v", r)
+				m.setErr(fmt.Errorf("panic in mock: %v", r))
 			}
 		}()
 
-		time.Sleep(m.randomDelay())
+		m.clock.Sleep(m.randomDelay())
 
 		if m.shouldError() {
 			//m.asyncMgr.StoreResult(cmdID, fmt.Errorf("simulated error fetching candles for %s", symbol))
-			m.err = fmt.Errorf("simulated error fetching candles for %s", symbol)
+			m.setErr(fmt.Errorf("simulated error fetching candles for %s", symbol))
 			return
 		}
 
 		var candles []*exchange.Record[exchange.Candle]
-		now := time.Now()
+		now := m.clock.Now()
 		for i := 0; i < limit; i++ {
 			ts := now.Add(time.Duration(-i) * time.Minute)
 			candles = append(candles, &exchange.Record[exchange.Candle]{
@@ -91,48 +107,114 @@ func (m *MockExchange) FetchCandlesAsync(symbol string, interval string, limit i
 		}
 
 		//m.asyncMgr.StoreResult(cmdID, candles)
-		if m.dataQueues[cmdID] == nil {
-			m.dataQueues[cmdID] = exchange.NewPriorityQueueManager[exchange.Candle]()
-		}
-		m.dataQueues[cmdID].PushBatch(candles...)
+		m.pushCandles(cmdID, candles...)
 	}()
 
 	return cmdID
 }
 
+// PlaceOrderAsync принимает ордер сразу, без задержки: рыночный исполняется по последней цене символа,
+// лимитный попадает в книгу и исполняется в MatchCandle. Если ID не задан, он генерируется.
 func (m *MockExchange) PlaceOrderAsync(order exchange.Order) exchange.CommandID {
-	cmdID := exchange.CommandID(fmt.Sprintf("mock_order_%s_%s", order.Symbol, time.Now().Format("20060102150405.999")))
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	// go func() {
-	// 	time.Sleep(m.randomDelay())
+	m.orderSeq++
+	cmdID := exchange.CommandID(fmt.Sprintf("mock_order_%s_%d", order.Symbol, m.orderSeq))
+	if order.ID == "" {
+		order.ID = fmt.Sprintf("mock_order_id_%d", m.orderSeq)
+	}
+	order.Time = m.now
+	if order.Time.IsZero() {
+		order.Time = m.clock.Now()
+	}
 
-	// 	if m.shouldError() {
-	// 		//m.asyncMgr.StoreResult(cmdID, fmt.Errorf("simulated order rejection"))
-	// 		m.err = fmt.Errorf("simulated order rejection")
-	// 		return
-	// 	}
+	switch {
+	case m.shouldError():
+		order.Status = exchange.OrderStatusRejected
+	case order.Type == exchange.OrderTypeMarket:
+		if price, ok := m.prices[order.Symbol]; ok {
+			order.Price = price
+		}
+		order.Status = exchange.OrderStatusFilled
+		order.Filled, order.AveragePrice = order.Amount, order.Price
+	default:
+		order.Status = exchange.OrderStatusOpen
+		m.book[order.ID] = &bookOrder{cmdID: cmdID, order: order}
+	}
 
-	// 	// Имитируем успешный ордер
-	// 	filledOrder := order
-	// 	filledOrder.ID = "mock_order_id_" + time.Now().Format("20060102150405")
-	// 	filledOrder.Status = "filled"
+	m.pushOrder(cmdID, order)
 
-	// 	//m.asyncMgr.StoreResult(cmdID, filledOrder)
-	// 	if m.orderQueues[cmdID] == nil {
-	// 		m.orderQueues[cmdID] = exchange.NewPriorityQueueManager[exchange.Order]()
-	// 	}
-	// 	m.orderQueues[cmdID].PushBatch(&exchange.Record[exchange.Order]{
-	// 		Timestamp: time.Now(),
-	// 		Data:      filledOrder,
-	// 	})
+	return cmdID
+}
 
-	// }()
+// MatchCandle исполняет лимитные ордера символа, цена которых попала в диапазон свечи.
+// Реализует exchange.Simulator.
+func (m *MockExchange) MatchCandle(candle exchange.Candle) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	return cmdID
+	m.prices[candle.Symbol] = candle.Close
+	m.now = candle.Timestamp
+
+	for id, item := range m.book {
+		order := item.order
+		if order.Symbol != candle.Symbol {
+			continue
+		}
+		if (order.Side == "buy" && candle.Low <= order.Price) || (order.Side == "sell" && candle.High >= order.Price) {
+			order.Status = exchange.OrderStatusFilled
+			order.Filled, order.AveragePrice = order.Amount, order.Price
+			order.Time = candle.Timestamp
+			m.pushOrder(item.cmdID, order)
+			delete(m.book, id)
+		}
+	}
+}
+
+// OpenOrders возвращает открытые лимитные ордера символа
+func (m *MockExchange) OpenOrders(symbol string) []exchange.Order {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var orders []exchange.Order
+	for _, item := range m.book {
+		if item.order.Symbol == symbol {
+			orders = append(orders, item.order)
+		}
+	}
+	return orders
+}
+
+// pushCandles добавляет свечи в очередь команды. Вызывается из горутин имитации, поэтому берёт блокировку сам.
+func (m *MockExchange) pushCandles(cmdID exchange.CommandID, candles ...*exchange.Record[exchange.Candle]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dataQueues[cmdID] == nil {
+		m.dataQueues[cmdID] = exchange.NewPriorityQueueManager[exchange.Candle]()
+	}
+	m.dataQueues[cmdID].PushBatch(candles...)
+}
+
+func (m *MockExchange) setErr(err error) {
+	m.mu.Lock()
+	m.err = err
+	m.mu.Unlock()
+}
+
+func (m *MockExchange) pushOrder(cmdID exchange.CommandID, order exchange.Order) {
+	if m.orderQueues[cmdID] == nil {
+		m.orderQueues[cmdID] = exchange.NewPriorityQueueManager[exchange.Order]()
+	}
+	m.orderQueues[cmdID].PushBatch(&exchange.Record[exchange.Order]{
+		Timestamp: order.Time,
+		Data:      order,
+	})
 }
 
 func (m *MockExchange) FetchOpenPositionsAsync(symbol string) exchange.CommandID {
-	cmdID := exchange.CommandID(fmt.Sprintf("mock_positions_%s_%d", symbol, time.Now().UnixNano()))
+	cmdID := exchange.GetCmdID(m.clock, "mock_positions", symbol, "")
 
 	// go func() {
 	// 	time.Sleep(m.randomDelay())
@@ -160,7 +242,7 @@ func (m *MockExchange) FetchOpenPositionsAsync(symbol string) exchange.CommandID
 }
 
 func (m *MockExchange) ClosePositionAsync(symbol string, side string) exchange.CommandID {
-	cmdID := exchange.CommandID(fmt.Sprintf("mock_close_%s_%s_%d", symbol, side, time.Now().UnixNano()))
+	cmdID := exchange.GetCmdID(m.clock, "mock_close", symbol, side)
 
 	// go func() {
 	// 	time.Sleep(m.randomDelay())
@@ -184,7 +266,7 @@ func (m *MockExchange) ClosePositionAsync(symbol string, side string) exchange.C
 }
 
 func (m *MockExchange) FetchBalanceAsync(asset string) exchange.CommandID {
-	cmdID := exchange.CommandID(fmt.Sprintf("mock_balance_%s_%d", asset, time.Now().UnixNano()))
+	cmdID := exchange.GetCmdID(m.clock, "mock_balance", asset, "")
 
 	// go func() {
 	// 	time.Sleep(m.randomDelay())
@@ -210,12 +292,15 @@ func (m *MockExchange) FetchBalanceAsync(asset string) exchange.CommandID {
 //		return m.asyncMgr.GetResult(cmdID)
 //	}
 func (m *MockExchange) PopCandle(cmdID exchange.CommandID) (exchange.Candle, bool, error) {
+	m.mu.RLock()
+	err := m.err
+	q, ok := m.dataQueues[cmdID]
+	m.mu.RUnlock()
 
-	if m.err != nil {
-		return exchange.Candle{}, false, m.err
+	if err != nil {
+		return exchange.Candle{}, false, err
 	}
 
-	q, ok := m.dataQueues[cmdID]
 	if !ok {
 		return exchange.Candle{}, false, fmt.Errorf("Не нашёл очередь для %s", cmdID)
 	}
@@ -229,6 +314,22 @@ func (m *MockExchange) PopCandle(cmdID exchange.CommandID) (exchange.Candle, boo
 	return record.Data, ok, nil
 }
 
+func (m *MockExchange) PopOrder(cmdID exchange.CommandID) (exchange.Order, bool, error) {
+	m.mu.RLock()
+	q, ok := m.orderQueues[cmdID]
+	m.mu.RUnlock()
+	if !ok {
+		return exchange.Order{}, false, fmt.Errorf("Не нашёл очередь для %s", cmdID)
+	}
+
+	record, ok := q.PopOne()
+	if !ok {
+		return exchange.Order{}, ok, nil
+	}
+
+	return record.Data, ok, nil
+}
+
 // ————————————————————————————————————————————————————————————————
 // WebSocket имитация (опционально, если нужно тестировать стримы)
 // ————————————————————————————————————————————————————————————————
@@ -246,16 +347,13 @@ func (m *MockExchange) SubscribeCandles(symbol string, interval string) exchange
 	mockCandleStreamMu.Lock()
 	defer mockCandleStreamMu.Unlock()
 
-	cmdID := exchange.GetCmdID("mock_candles", symbol, interval)
+	cmdID := exchange.GetCmdID(m.clock, "mock_candles", symbol, interval)
 
 	handler := func(candle exchange.Candle, err error) {
 		if err != nil {
-			m.err = err
+			m.setErr(err)
 		} else {
-			if m.dataQueues[cmdID] == nil {
-				m.dataQueues[cmdID] = exchange.NewPriorityQueueManager[exchange.Candle]()
-			}
-			m.dataQueues[cmdID].PushBatch(&exchange.Record[exchange.Candle]{
+			m.pushCandles(cmdID, &exchange.Record[exchange.Candle]{
 				Timestamp: candle.Timestamp,
 				Data:      candle,
 			})
@@ -290,10 +388,10 @@ func (m *MockExchange) UnsubscribeCandles(symbol string, interval string) {
 }
 
 func (m *MockExchange) mockCandleStream(symbol string) {
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := m.clock.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for range ticker.C() {
 		mockCandleStreamMu.RLock()
 		handlers := make([]candleHandler, len(mockCandleStreamHandlers))
 		copy(handlers, mockCandleStreamHandlers)
@@ -305,7 +403,7 @@ func (m *MockExchange) mockCandleStream(symbol string) {
 
 		candle := exchange.Candle{
 			Symbol:    symbol, // можно рандомизировать
-			Timestamp: time.Now(),
+			Timestamp: m.clock.Now(),
 			Open:      50000 + rand.Float64()*100,
 			High:      50100 + rand.Float64()*50,
 			Low:       49900 + rand.Float64()*50,
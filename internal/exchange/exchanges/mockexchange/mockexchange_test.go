@@ -1,13 +1,47 @@
 package mockexchange
 
 import (
+	"crypto-trading-bot/internal/clock"
 	"fmt"
 	"testing"
 	"time"
 )
 
+func TestMockExchange_FetchCandlesAsyncSimulatedClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewSimulated(start)
+	ex := NewMockExchange(clk)
+	ex.DelayMin = time.Minute
+	ex.DelayMax = time.Minute
+
+	cmdID := ex.FetchCandlesAsync("BTCUSDT", "1m", 3)
+
+	// Ответ придёт только после продвижения модельного времени на задержку
+	clk.BlockUntil(1)
+	if _, ok, _ := ex.PopCandle(cmdID); ok {
+		t.Fatal("Expected no result before the clock advanced")
+	}
+	clk.Advance(time.Minute)
+
+	var candles []time.Time
+	deadline := time.Now().Add(time.Second)
+	for len(candles) < 3 && time.Now().Before(deadline) {
+		if candle, ok, _ := ex.PopCandle(cmdID); ok {
+			candles = append(candles, candle.Timestamp)
+			continue
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Время свечей берётся из модельных часов
+	want := []time.Time{start.Add(-time.Minute), start, start.Add(time.Minute)}
+	if fmt.Sprint(candles) != fmt.Sprint(want) {
+		t.Fatalf("Expected candles at %v, got %v", want, candles)
+	}
+}
+
 func TestMockExchange_FetchCandlesAsync(t *testing.T) {
-	ex := NewMockExchange()
+	ex := NewMockExchange(clock.New())
 	ex.DelayMin = 10 * time.Millisecond
 	ex.DelayMax = 50 * time.Millisecond
 
@@ -33,7 +67,7 @@ func TestMockExchange_FetchCandlesAsync(t *testing.T) {
 }
 
 // func TestMockExchange_PlaceOrderAsync_ErrorSimulation(t *testing.T) {
-// 	ex := NewMockExchange()
+// 	ex := NewMockExchange(clock.New())
 // 	ex.DelayMin = 10 * time.Millisecond
 // 	ex.DelayMax = 30 * time.Millisecond
 // 	ex.ErrRate = 1.0 // всегда ошибка
@@ -57,7 +91,7 @@ func TestMockExchange_FetchCandlesAsync(t *testing.T) {
 // }
 
 func TestMockExchange_SubscribeCandles(t *testing.T) {
-	ex := NewMockExchange()
+	ex := NewMockExchange(clock.New())
 
 	cmdID := ex.SubscribeCandles("BTCUSDT", "1s")
 
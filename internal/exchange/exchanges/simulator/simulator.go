@@ -0,0 +1,398 @@
+package simulator
+
+import (
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/exchange"
+	"crypto-trading-bot/internal/settings"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	_ exchange.Exchange  = (*Simulator)(nil)
+	_ exchange.Simulator = (*Simulator)(nil)
+)
+
+// Simulator исполняет ордера по свечам, переданным в MatchCandle, с тем же асинхронным контрактом,
+// что и биржа: PlaceOrderAsync возвращает ID команды, изменения статуса ордера забираются PopOrder.
+//
+// Ордер, выставленный после свечи, исполняется начиная со следующей свечи. Рыночный ордер исполняется
+// по цене открытия. Лимитные и стоп-ордера проверяются вдоль пути цены внутри свечи (настройка Path):
+// цена проходит точки open, high, low, close в заданном порядке, и между точками принимает все промежуточные значения.
+// Объём исполнений за свечу ограничен долей Participation от объёма свечи, остаток исполняется на следующих свечах.
+type Simulator struct {
+	settings settings.MatchingSettings
+	clock    clock.Clock
+	mu       sync.Mutex
+	seq      int
+	orders   []*simOrder        // активные ордера в порядке выставления
+	prices   map[string]float64 // symbol -> последняя цена закрытия
+	queues   map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Order]
+	candles  map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Candle]
+	streams  map[string][]exchange.CommandID // symbol_interval -> подписки на свечи
+	history  map[string][]exchange.Candle    // symbol_interval -> полученные свечи
+	now      time.Time
+}
+
+type simOrder struct {
+	cmdID      exchange.CommandID
+	order      exchange.Order
+	triggered  bool // стоп-ордер активирован
+	marketable bool // лимитный ордер при выставлении был исполним сразу и исполняется по лучшей цене
+}
+
+func NewSimulator(clk clock.Clock, comps ...settings.Settings) (*Simulator, error) {
+	s := &Simulator{
+		clock:   clk,
+		prices:  make(map[string]float64),
+		queues:  make(map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Order]),
+		candles: make(map[exchange.CommandID]*exchange.PriorityQueueManager[exchange.Candle]),
+		streams: make(map[string][]exchange.CommandID),
+		history: make(map[string][]exchange.Candle),
+	}
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.MatchingSettings); ok {
+			s.settings = *val
+		}
+	}
+
+	if s.settings.Path == "" {
+		return nil, fmt.Errorf("matching settings are not set")
+	}
+
+	return s, nil
+}
+
+// PlaceOrderAsync принимает ордер. Ордер с неверными параметрами и post-only ордер,
+// который исполнился бы сразу по последней цене, отклоняются.
+func (s *Simulator) PlaceOrderAsync(order exchange.Order) exchange.CommandID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	cmdID := exchange.CommandID(fmt.Sprintf("sim_order_%s_%d", order.Symbol, s.seq))
+	if order.ID == "" {
+		order.ID = fmt.Sprintf("sim_%d", s.seq)
+	}
+	order.Filled, order.AveragePrice = 0, 0
+	order.Time = s.now
+
+	item := &simOrder{cmdID: cmdID, order: order}
+
+	last, known := s.prices[order.Symbol]
+	if known && (order.Type == exchange.OrderTypeLimit) {
+		item.marketable = crosses(order.Side, order.Price, last)
+	}
+
+	switch {
+	case !validOrder(order):
+		order.Status = exchange.OrderStatusRejected
+	case order.PostOnly && (order.Type != exchange.OrderTypeLimit || item.marketable):
+		order.Status = exchange.OrderStatusRejected
+	default:
+		order.Status = exchange.OrderStatusOpen
+		item.order.Status = order.Status
+		s.orders = append(s.orders, item)
+	}
+
+	s.push(cmdID, order)
+	return cmdID
+}
+
+// CancelOrder отменяет активный ордер, возвращает false, если ордера нет
+func (s *Simulator) CancelOrder(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, item := range s.orders {
+		if item.order.ID == id {
+			item.order.Status = exchange.OrderStatusCanceled
+			item.order.Time = s.now
+			s.push(item.cmdID, item.order)
+			s.orders = append(s.orders[:i], s.orders[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// OpenOrders возвращает активные ордера символа
+func (s *Simulator) OpenOrders(symbol string) []exchange.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var orders []exchange.Order
+	for _, item := range s.orders {
+		if item.order.Symbol == symbol {
+			orders = append(orders, item.order)
+		}
+	}
+	return orders
+}
+
+// MatchCandle исполняет активные ордера символа по свече. Реализует exchange.Simulator.
+func (s *Simulator) MatchCandle(candle exchange.Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.now = candle.Timestamp
+
+	capacity := math.Inf(1)
+	if s.settings.Participation > 0 {
+		capacity = s.settings.Participation * candle.Volume
+	}
+
+	active := s.orders[:0]
+	for _, item := range s.orders {
+		if item.order.Symbol != candle.Symbol {
+			active = append(active, item)
+			continue
+		}
+
+		price, filled := s.match(item, candle)
+		if filled && capacity > 0 {
+			quantity := math.Min(item.order.Amount-item.order.Filled, capacity)
+			capacity -= quantity
+			s.fill(item, price, quantity)
+		}
+
+		if item.order.Status != exchange.OrderStatusFilled {
+			active = append(active, item)
+		}
+	}
+	s.orders = active
+
+	s.prices[candle.Symbol] = candle.Close
+
+	key := candle.Symbol + "_" + candle.Interval
+	s.history[key] = append(s.history[key], candle)
+	for _, cmdID := range s.streams[key] {
+		s.candles[cmdID].PushBatch(&exchange.Record[exchange.Candle]{Timestamp: candle.Timestamp, Data: candle})
+	}
+}
+
+// match определяет, исполняется ли ордер на свече и по какой цене
+func (s *Simulator) match(item *simOrder, candle exchange.Candle) (float64, bool) {
+	// активированный на прошлых свечах стоп-маркет — рыночный ордер, остаток исполняется по открытию
+	if item.order.Type == exchange.OrderTypeMarket || (item.order.Type == exchange.OrderTypeStopMarket && item.triggered) {
+		return candle.Open, true
+	}
+
+	ohlc := []float64{candle.Open, candle.High, candle.Low, candle.Close}
+	olhc := []float64{candle.Open, candle.Low, candle.High, candle.Close}
+
+	switch s.settings.Path {
+	case settings.MatchingPathOHLC:
+		return s.walk(item, ohlc, true)
+	case settings.MatchingPathOLHC:
+		return s.walk(item, olhc, true)
+	}
+
+	// худший случай: ордер исполняется, только если исполняется на обоих путях, по худшей из двух цен.
+	// Активация стопа учитывается, если она произошла на любом из путей.
+	priceA, filledA := s.walk(item, ohlc, false)
+	priceB, filledB := s.walk(item, olhc, false)
+	if !item.triggered {
+		_, _ = s.walk(item, ohlc, true)
+	}
+	if !item.triggered {
+		_, _ = s.walk(item, olhc, true)
+	}
+	if !filledA || !filledB {
+		return 0, false
+	}
+	if item.order.Side == "buy" {
+		return math.Max(priceA, priceB), true
+	}
+	return math.Min(priceA, priceB), true
+}
+
+// walk проводит ордер по пути цены. Если update равен true, активация стоп-ордера сохраняется.
+func (s *Simulator) walk(item *simOrder, path []float64, update bool) (float64, bool) {
+	order := item.order
+	triggered := item.triggered
+	defer func() {
+		if update {
+			item.triggered = triggered
+		}
+	}()
+
+	isStop := order.Type == exchange.OrderTypeStopMarket || order.Type == exchange.OrderTypeStopLimit
+	buy := order.Side == "buy"
+
+	for i, point := range path {
+		from := point
+		if i > 0 {
+			from = path[i-1]
+		}
+		lo, hi := math.Min(from, point), math.Max(from, point)
+
+		if isStop && !triggered {
+			if (buy && hi < order.StopPrice) || (!buy && lo > order.StopPrice) {
+				continue
+			}
+			triggered = true
+
+			// при гэпе через стоп активация происходит по цене открытия
+			at := order.StopPrice
+			if i == 0 {
+				at = point
+			}
+			if order.Type == exchange.OrderTypeStopMarket {
+				return at, true
+			}
+
+			// стоп-лимит после активации становится лимитным ордером на оставшейся части отрезка
+			if crosses(order.Side, order.Price, at) {
+				return at, true
+			}
+			lo, hi = math.Min(at, point), math.Max(at, point)
+			if (buy && lo <= order.Price) || (!buy && hi >= order.Price) {
+				return order.Price, true
+			}
+			continue
+		}
+
+		if isStop || order.Type == exchange.OrderTypeLimit {
+			// исполнимый при выставлении ордер получает цену открытия, если она не хуже лимита
+			if i == 0 && item.marketable && crosses(order.Side, order.Price, point) {
+				return point, true
+			}
+			if (buy && lo <= order.Price) || (!buy && hi >= order.Price) {
+				return order.Price, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func (s *Simulator) fill(item *simOrder, price float64, quantity float64) {
+	order := &item.order
+	order.AveragePrice = (order.AveragePrice*order.Filled + price*quantity) / (order.Filled + quantity)
+	order.Filled += quantity
+	order.Time = s.now
+
+	order.Status = exchange.OrderStatusPartiallyFilled
+	if order.Amount-order.Filled <= order.Amount*1e-12 {
+		order.Filled = order.Amount
+		order.Status = exchange.OrderStatusFilled
+	}
+
+	s.push(item.cmdID, *order)
+}
+
+func (s *Simulator) push(cmdID exchange.CommandID, order exchange.Order) {
+	if s.queues[cmdID] == nil {
+		s.queues[cmdID] = exchange.NewPriorityQueueManager[exchange.Order]()
+	}
+	s.queues[cmdID].PushBatch(&exchange.Record[exchange.Order]{Timestamp: order.Time, Data: order})
+}
+
+func (s *Simulator) PopOrder(cmdID exchange.CommandID) (exchange.Order, bool, error) {
+	s.mu.Lock()
+	q, ok := s.queues[cmdID]
+	s.mu.Unlock()
+	if !ok {
+		return exchange.Order{}, false, fmt.Errorf("unknown command: %s", cmdID)
+	}
+
+	record, ok := q.PopOne()
+	if !ok {
+		return exchange.Order{}, false, nil
+	}
+	return record.Data, true, nil
+}
+
+// FetchCandlesAsync возвращает последние limit свечей, переданных в MatchCandle
+func (s *Simulator) FetchCandlesAsync(symbol string, interval string, limit int) exchange.CommandID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmdID := exchange.GetCmdID(s.clock, "sim_candles", symbol, interval)
+	q := exchange.NewPriorityQueueManager[exchange.Candle]()
+	history := s.history[symbol+"_"+interval]
+	for _, candle := range history[max(len(history)-limit, 0):] {
+		q.PushBatch(&exchange.Record[exchange.Candle]{Timestamp: candle.Timestamp, Data: candle})
+	}
+	s.candles[cmdID] = q
+
+	return cmdID
+}
+
+// SubscribeCandles подписывает на свечи, передаваемые в MatchCandle
+func (s *Simulator) SubscribeCandles(symbol string, interval string) exchange.CommandID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cmdID := exchange.GetCmdID(s.clock, "sim_stream", symbol, interval)
+	s.candles[cmdID] = exchange.NewPriorityQueueManager[exchange.Candle]()
+	key := symbol + "_" + interval
+	s.streams[key] = append(s.streams[key], cmdID)
+
+	return cmdID
+}
+
+func (s *Simulator) UnsubscribeCandles(symbol string, interval string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.streams, symbol+"_"+interval)
+}
+
+func (s *Simulator) PopCandle(cmdID exchange.CommandID) (exchange.Candle, bool, error) {
+	s.mu.Lock()
+	q, ok := s.candles[cmdID]
+	s.mu.Unlock()
+	if !ok {
+		return exchange.Candle{}, false, fmt.Errorf("unknown command: %s", cmdID)
+	}
+
+	record, ok := q.PopOne()
+	if !ok {
+		return exchange.Candle{}, false, nil
+	}
+	return record.Data, true, nil
+}
+
+// Позиции и балансы симулятор не ведёт, команды не возвращают результата
+
+func (s *Simulator) FetchOpenPositionsAsync(symbol string) exchange.CommandID {
+	return exchange.GetCmdID(s.clock, "sim_positions", symbol, "")
+}
+
+func (s *Simulator) ClosePositionAsync(symbol string, side string) exchange.CommandID {
+	return exchange.GetCmdID(s.clock, "sim_close", symbol, side)
+}
+
+func (s *Simulator) FetchBalanceAsync(asset string) exchange.CommandID {
+	return exchange.GetCmdID(s.clock, "sim_balance", asset, "")
+}
+
+// crosses сообщает, исполним ли лимитный ордер по цене price
+func crosses(side string, limit float64, price float64) bool {
+	if side == "buy" {
+		return price <= limit
+	}
+	return price >= limit
+}
+
+func validOrder(order exchange.Order) bool {
+	if order.Amount <= 0 || (order.Side != "buy" && order.Side != "sell") {
+		return false
+	}
+	switch order.Type {
+	case exchange.OrderTypeMarket:
+		return true
+	case exchange.OrderTypeLimit:
+		return order.Price > 0
+	case exchange.OrderTypeStopMarket:
+		return order.StopPrice > 0
+	case exchange.OrderTypeStopLimit:
+		return order.StopPrice > 0 && order.Price > 0
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package simulator
+
+import (
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/exchange"
+	"crypto-trading-bot/internal/settings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newSimulator(t *testing.T, path string, participation float64) *Simulator {
+	sim, err := NewSimulator(clock.NewSimulated(start), &settings.MatchingSettings{Path: path, Participation: participation})
+	assert.NoError(t, err)
+	return sim
+}
+
+func candle(i int, open, high, low, close, volume float64) exchange.Candle {
+	return exchange.Candle{
+		Symbol:    "BTCUSDT",
+		Interval:  "1m",
+		Timestamp: start.Add(time.Duration(i) * time.Minute),
+		Open:      open, High: high, Low: low, Close: close, Volume: volume,
+	}
+}
+
+// updates возвращает все изменения статуса ордера
+func updates(t *testing.T, sim *Simulator, cmdID exchange.CommandID) []exchange.Order {
+	var orders []exchange.Order
+	for {
+		order, ok, err := sim.PopOrder(cmdID)
+		assert.NoError(t, err)
+		if !ok {
+			return orders
+		}
+		orders = append(orders, order)
+	}
+}
+
+func last(orders []exchange.Order) exchange.Order {
+	return orders[len(orders)-1]
+}
+
+func TestMarketOrderFillsAtNextOpen(t *testing.T) {
+	sim := newSimulator(t, settings.MatchingPathOHLC, 0)
+	sim.MatchCandle(candle(0, 100, 101, 99, 100, 10))
+
+	cmdID := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeMarket, Amount: 1})
+	assert.Equal(t, exchange.OrderStatusOpen, last(updates(t, sim, cmdID)).Status)
+
+	sim.MatchCandle(candle(1, 102, 103, 101, 102, 10))
+	order := last(updates(t, sim, cmdID))
+	assert.Equal(t, exchange.OrderStatusFilled, order.Status)
+	assert.Equal(t, 102.0, order.AveragePrice)
+	assert.Equal(t, 1.0, order.Filled)
+	assert.Empty(t, sim.OpenOrders("BTCUSDT"))
+}
+
+func TestLimitOrderFillsOnAnyPath(t *testing.T) {
+	// свеча касается и лимита покупки 95, и лимита продажи 105: порядок high и low не важен
+	bar := candle(1, 100, 106, 94, 100, 10)
+
+	for _, path := range []string{settings.MatchingPathOHLC, settings.MatchingPathOLHC, settings.MatchingPathWorst} {
+		sim := newSimulator(t, path, 0)
+		sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+
+		buy := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeLimit, Price: 95, Amount: 1})
+		sell := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "sell", Type: exchange.OrderTypeLimit, Price: 105, Amount: 1})
+		sim.MatchCandle(bar)
+
+		assert.Equal(t, 95.0, last(updates(t, sim, buy)).AveragePrice, path)
+		assert.Equal(t, 105.0, last(updates(t, sim, sell)).AveragePrice, path)
+	}
+}
+
+func TestStopLimitDependsOnPath(t *testing.T) {
+	// стоп на покупку 105 с лимитом 103: после активации на high цена должна вернуться к 103
+	order := exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeStopLimit, StopPrice: 105, Price: 103, Amount: 1}
+	bar := candle(1, 100, 106, 98, 104, 10)
+
+	// open -> high -> low: после активации цена опускается до 98, лимит исполняется
+	sim := newSimulator(t, settings.MatchingPathOHLC, 0)
+	sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+	cmdID := sim.PlaceOrderAsync(order)
+	sim.MatchCandle(bar)
+	filled := last(updates(t, sim, cmdID))
+	assert.Equal(t, exchange.OrderStatusFilled, filled.Status)
+	assert.Equal(t, 103.0, filled.AveragePrice)
+
+	// open -> low -> high: активация на high, после неё цена не ниже 104
+	sim = newSimulator(t, settings.MatchingPathOLHC, 0)
+	sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+	cmdID = sim.PlaceOrderAsync(order)
+	sim.MatchCandle(bar)
+	assert.Equal(t, exchange.OrderStatusOpen, last(updates(t, sim, cmdID)).Status)
+
+	// активация сохраняется: следующая свеча ниже стопа исполняет лимит
+	sim.MatchCandle(candle(2, 103.5, 104, 102, 103, 10))
+	filled = last(updates(t, sim, cmdID))
+	assert.Equal(t, exchange.OrderStatusFilled, filled.Status)
+	assert.Equal(t, 103.0, filled.AveragePrice)
+
+	// худший случай требует исполнения на обоих путях
+	sim = newSimulator(t, settings.MatchingPathWorst, 0)
+	sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+	cmdID = sim.PlaceOrderAsync(order)
+	sim.MatchCandle(bar)
+	assert.Equal(t, exchange.OrderStatusOpen, last(updates(t, sim, cmdID)).Status)
+}
+
+func TestStopMarketGap(t *testing.T) {
+	sim := newSimulator(t, settings.MatchingPathOHLC, 0)
+	sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+
+	stop := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "sell", Type: exchange.OrderTypeStopMarket, StopPrice: 95, Amount: 1})
+	inside := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "sell", Type: exchange.OrderTypeStopMarket, StopPrice: 85, Amount: 1})
+
+	// гэп вниз через стоп 95: исполнение по открытию, стоп 85 исполняется по своей цене
+	sim.MatchCandle(candle(1, 90, 91, 80, 82, 10))
+	assert.Equal(t, 90.0, last(updates(t, sim, stop)).AveragePrice)
+	assert.Equal(t, 85.0, last(updates(t, sim, inside)).AveragePrice)
+}
+
+func TestPostOnly(t *testing.T) {
+	sim := newSimulator(t, settings.MatchingPathOHLC, 0)
+	sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+
+	crossing := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeLimit, Price: 101, Amount: 1, PostOnly: true})
+	assert.Equal(t, exchange.OrderStatusRejected, last(updates(t, sim, crossing)).Status)
+
+	resting := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeLimit, Price: 99, Amount: 1, PostOnly: true})
+	assert.Equal(t, exchange.OrderStatusOpen, last(updates(t, sim, resting)).Status)
+	assert.Len(t, sim.OpenOrders("BTCUSDT"), 1)
+}
+
+func TestMarketableLimitGetsBetterOpen(t *testing.T) {
+	sim := newSimulator(t, settings.MatchingPathOHLC, 0)
+	sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+
+	cmdID := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeLimit, Price: 101, Amount: 1})
+	sim.MatchCandle(candle(1, 99.5, 100, 99, 100, 10))
+	assert.Equal(t, 99.5, last(updates(t, sim, cmdID)).AveragePrice)
+}
+
+func TestPartialFills(t *testing.T) {
+	// за свечу исполняется не больше 10% объёма
+	sim := newSimulator(t, settings.MatchingPathOHLC, 0.1)
+	sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+
+	first := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeLimit, Price: 99, Amount: 1.5})
+	second := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeLimit, Price: 99, Amount: 1})
+
+	sim.MatchCandle(candle(1, 100, 100, 98, 99, 10))
+	order := last(updates(t, sim, first))
+	assert.Equal(t, exchange.OrderStatusPartiallyFilled, order.Status)
+	assert.InDelta(t, 1.0, order.Filled, 1e-9)
+	// первый ордер выбрал весь лимит свечи
+	assert.Equal(t, exchange.OrderStatusOpen, last(updates(t, sim, second)).Status)
+
+	sim.MatchCandle(candle(2, 99, 100, 98, 99, 10))
+	order = last(updates(t, sim, first))
+	assert.Equal(t, exchange.OrderStatusFilled, order.Status)
+	assert.InDelta(t, 1.5, order.Filled, 1e-9)
+	assert.InDelta(t, 99.0, order.AveragePrice, 1e-9)
+
+	order = last(updates(t, sim, second))
+	assert.Equal(t, exchange.OrderStatusPartiallyFilled, order.Status)
+	assert.InDelta(t, 0.5, order.Filled, 1e-9)
+}
+
+func TestPartialStopMarket(t *testing.T) {
+	cases := []struct {
+		side    string
+		stop    float64
+		trigger exchange.Candle
+		next    exchange.Candle
+		average float64
+	}{
+		// остаток исполняется по открытию следующей свечи, даже если цена вернулась за стоп
+		{"sell", 95, candle(1, 100, 100, 94, 96, 10), candle(2, 96, 97, 93, 96, 10), (5*95.0 + 3*96) / 8},
+		{"buy", 105, candle(1, 100, 106, 100, 104, 10), candle(2, 104, 105, 103, 104, 10), (5*105.0 + 3*104) / 8},
+	}
+
+	for _, c := range cases {
+		// за свечу исполняется не больше половины объёма
+		sim := newSimulator(t, settings.MatchingPathOHLC, 0.5)
+		sim.MatchCandle(candle(0, 100, 100, 100, 100, 10))
+
+		cmdID := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: c.side, Type: exchange.OrderTypeStopMarket, StopPrice: c.stop, Amount: 8})
+
+		sim.MatchCandle(c.trigger)
+		order := last(updates(t, sim, cmdID))
+		assert.Equal(t, exchange.OrderStatusPartiallyFilled, order.Status, c.side)
+		assert.InDelta(t, 5.0, order.Filled, 1e-9, c.side)
+		assert.InDelta(t, c.stop, order.AveragePrice, 1e-9, c.side)
+
+		sim.MatchCandle(c.next)
+		order = last(updates(t, sim, cmdID))
+		assert.Equal(t, exchange.OrderStatusFilled, order.Status, c.side)
+		assert.InDelta(t, c.average, order.AveragePrice, 1e-9, c.side)
+		assert.Empty(t, sim.OpenOrders("BTCUSDT"), c.side)
+	}
+}
+
+func TestCancelAndReject(t *testing.T) {
+	sim := newSimulator(t, settings.MatchingPathOHLC, 0)
+
+	invalid := sim.PlaceOrderAsync(exchange.Order{Symbol: "BTCUSDT", Side: "buy", Type: exchange.OrderTypeStopLimit, Price: 100, Amount: 1})
+	assert.Equal(t, exchange.OrderStatusRejected, last(updates(t, sim, invalid)).Status)
+
+	cmdID := sim.PlaceOrderAsync(exchange.Order{ID: "o1", Symbol: "BTCUSDT", Side: "sell", Type: exchange.OrderTypeLimit, Price: 110, Amount: 1})
+	assert.True(t, sim.CancelOrder("o1"))
+	assert.False(t, sim.CancelOrder("o1"))
+	assert.Equal(t, exchange.OrderStatusCanceled, last(updates(t, sim, cmdID)).Status)
+
+	sim.MatchCandle(candle(1, 100, 120, 100, 115, 10))
+	assert.Empty(t, updates(t, sim, cmdID))
+}
+
+func TestCandleStream(t *testing.T) {
+	sim := newSimulator(t, settings.MatchingPathOHLC, 0)
+	stream := sim.SubscribeCandles("BTCUSDT", "1m")
+
+	for i := range 3 {
+		sim.MatchCandle(candle(i, 100, 101, 99, 100, 10))
+	}
+
+	count := 0
+	for {
+		_, ok, err := sim.PopCandle(stream)
+		assert.NoError(t, err)
+		if !ok {
+			break
+		}
+		count++
+	}
+	assert.Equal(t, 3, count)
+
+	cmdID := sim.FetchCandlesAsync("BTCUSDT", "1m", 2)
+	first, ok, _ := sim.PopCandle(cmdID)
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(time.Minute), first.Timestamp)
+}
@@ -13,14 +13,36 @@ type Candle struct {
 	Volume    float64
 }
 
+// Статусы ордера
+const (
+	OrderStatusOpen            = "open"
+	OrderStatusPartiallyFilled = "partially_filled"
+	OrderStatusFilled          = "filled"
+	OrderStatusCanceled        = "canceled"
+	OrderStatusRejected        = "rejected"
+)
+
+// Типы ордера
+const (
+	OrderTypeMarket     = "market"
+	OrderTypeLimit      = "limit"
+	OrderTypeStopMarket = "stop_market"
+	OrderTypeStopLimit  = "stop_limit"
+)
+
 type Order struct {
-	ID     string
-	Symbol string
-	Side   string // "buy", "sell"
-	Type   string // "limit", "market"
-	Price  float64
-	Amount float64
-	Status string // "open", "filled", "canceled"
+	ID           string
+	Symbol       string
+	Side         string // "buy", "sell"
+	Type         string // "market", "limit", "stop_market", "stop_limit"
+	Price        float64
+	StopPrice    float64 // цена активации стоп-ордера
+	PostOnly     bool    // лимитный ордер отклоняется, если исполнился бы сразу
+	Amount       float64
+	Filled       float64   // исполненное количество
+	AveragePrice float64   // средняя цена исполнения
+	Status       string    // "open", "partially_filled", "filled", "canceled", "rejected"
+	Time         time.Time // время последнего изменения статуса
 }
 
 type Position struct {
@@ -0,0 +1,116 @@
+// Инкрементальные технические индикаторы.
+// Каждый индикатор обновляется по одной свече за O(1) (для Stochastic — амортизированно),
+// история цен целиком не хранится.
+package indicators
+
+import (
+	"crypto-trading-bot/internal/types"
+	"fmt"
+)
+
+// Indicator — инкрементальный технический индикатор
+type Indicator interface {
+	// Name возвращает имя индикатора вместе с параметрами, например "sma_14".
+	// Это же имя используется как ключ основного значения в Values().
+	Name() string
+
+	// WarmUp возвращает количество свечей, которое нужно подать до появления первого значения
+	WarmUp() int
+
+	// Update добавляет очередную свечу
+	Update(md *types.MarketData)
+
+	// Ready сообщает, что период прогрева пройден и значения достоверны
+	Ready() bool
+
+	// Value возвращает основное значение индикатора
+	Value() float64
+
+	// Values возвращает все выходы индикатора (например, для MACD - линия, сигнал и гистограмма)
+	Values() map[string]float64
+}
+
+// Source определяет, какое значение свечи используется для расчёта
+type Source string
+
+const (
+	SourceClose   Source = "close"
+	SourceOpen    Source = "open"
+	SourceHigh    Source = "high"
+	SourceLow     Source = "low"
+	SourceVolume  Source = "volume"
+	SourceTypical Source = "typical" // (high + low + close) / 3
+)
+
+// Value возвращает значение свечи для источника
+func (s Source) Value(md *types.MarketData) float64 {
+	switch s {
+	case SourceOpen:
+		return md.OpenPrice
+	case SourceHigh:
+		return md.HightPrice
+	case SourceLow:
+		return md.LowPrice
+	case SourceVolume:
+		return md.Volume
+	case SourceTypical:
+		return typicalPrice(md)
+	default:
+		return md.ClosePrice
+	}
+}
+
+// ParseSource разбирает имя источника
+func ParseSource(name string) (Source, error) {
+	switch s := Source(name); s {
+	case SourceClose, SourceOpen, SourceHigh, SourceLow, SourceVolume, SourceTypical:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown price source: %s", name)
+}
+
+func typicalPrice(md *types.MarketData) float64 {
+	return (md.HightPrice + md.LowPrice + md.ClosePrice) / 3
+}
+
+// indicatorName формирует имя индикатора с параметрами.
+// Источник close подразумевается по умолчанию и в имя не попадает.
+func indicatorName(kind string, src Source, params ...interface{}) string {
+	name := kind
+	if src != "" && src != SourceClose {
+		name += "_" + string(src)
+	}
+	for _, p := range params {
+		name += fmt.Sprintf("_%v", p)
+	}
+	return name
+}
+
+// ring — кольцевой буфер фиксированного размера
+type ring struct {
+	data  []float64
+	head  int
+	count int
+}
+
+func newRing(size int) *ring {
+	return &ring{data: make([]float64, size)}
+}
+
+// push добавляет значение. Если буфер был заполнен, возвращает вытесненное значение и true.
+func (r *ring) push(v float64) (float64, bool) {
+	if r.count < len(r.data) {
+		r.data[(r.head+r.count)%len(r.data)] = v
+		r.count++
+		return 0, false
+	}
+
+	old := r.data[r.head]
+	r.data[r.head] = v
+	r.head = (r.head + 1) % len(r.data)
+	return old, true
+}
+
+func (r *ring) full() bool {
+	return r.count == len(r.data)
+}
@@ -0,0 +1,157 @@
+package indicators
+
+import (
+	"crypto-trading-bot/internal/types"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func closes(values ...float64) []*types.MarketData {
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	data := make([]*types.MarketData, len(values))
+	for i, v := range values {
+		data[i] = &types.MarketData{
+			Timestamp:  now.Add(time.Duration(i) * time.Minute),
+			OpenPrice:  v,
+			HightPrice: v + 1,
+			LowPrice:   v - 1,
+			ClosePrice: v,
+			Volume:     10,
+		}
+	}
+	return data
+}
+
+func feed(ind Indicator, data []*types.MarketData) {
+	for _, md := range data {
+		ind.Update(md)
+	}
+}
+
+func TestSMA(t *testing.T) {
+	sma := NewSMA(3, SourceClose)
+	data := closes(1, 2, 3, 4, 5)
+
+	feed(sma, data[:2])
+	assert.False(t, sma.Ready())
+
+	sma.Update(data[2])
+	assert.True(t, sma.Ready())
+	assert.InDelta(t, 2.0, sma.Value(), 1e-9)
+
+	feed(sma, data[3:])
+	assert.InDelta(t, 4.0, sma.Value(), 1e-9)
+	assert.Equal(t, "sma_3", sma.Name())
+}
+
+func TestEMA(t *testing.T) {
+	ema := NewEMA(3, SourceClose)
+	feed(ema, closes(1, 2, 3))
+	assert.True(t, ema.Ready())
+	assert.InDelta(t, 2.0, ema.Value(), 1e-9)
+
+	// k = 2/(3+1) = 0.5
+	ema.Update(closes(6)[0])
+	assert.InDelta(t, 4.0, ema.Value(), 1e-9)
+}
+
+func TestWMA(t *testing.T) {
+	wma := NewWMA(3, SourceClose)
+	feed(wma, closes(1, 2, 3, 4))
+	// (2*1 + 3*2 + 4*3) / 6
+	assert.InDelta(t, 20.0/6, wma.Value(), 1e-9)
+}
+
+func TestRSI(t *testing.T) {
+	rsi := NewRSI(3, SourceClose)
+	data := closes(10, 11, 12, 13)
+
+	feed(rsi, data[:3])
+	assert.False(t, rsi.Ready())
+	assert.Equal(t, 4, rsi.WarmUp())
+
+	rsi.Update(data[3])
+	assert.True(t, rsi.Ready())
+	assert.InDelta(t, 100.0, rsi.Value(), 1e-9)
+
+	// gains 1,1,1 -> avgGain 1; loss 3 -> avgGain 2/3, avgLoss 1 -> RSI 40
+	rsi.Update(closes(10)[0])
+	assert.InDelta(t, 40.0, rsi.Value(), 1e-9)
+}
+
+func TestMACDWarmUp(t *testing.T) {
+	macd := NewMACD(3, 5, 2, SourceClose)
+	values := make([]float64, macd.WarmUp())
+	for i := range values {
+		values[i] = float64(100 + i)
+	}
+	data := closes(values...)
+
+	feed(macd, data[:len(data)-1])
+	assert.False(t, macd.Ready())
+	macd.Update(data[len(data)-1])
+	assert.True(t, macd.Ready())
+
+	// на линейном тренде быстрая EMA выше медленной
+	assert.Greater(t, macd.Value(), 0.0)
+	assert.Contains(t, macd.Values(), "macd_3_5_2_signal")
+}
+
+func TestBollinger(t *testing.T) {
+	bb := NewBollinger(4, 2, SourceClose)
+	feed(bb, closes(2, 4, 4, 6))
+	values := bb.Values()
+
+	// среднее 4, стандартное отклонение sqrt(2)
+	assert.InDelta(t, 4.0, values["bb_4_2"], 1e-9)
+	assert.InDelta(t, 4+2*math.Sqrt2, values["bb_4_2_upper"], 1e-9)
+	assert.InDelta(t, 4-2*math.Sqrt2, values["bb_4_2_lower"], 1e-9)
+}
+
+func TestATR(t *testing.T) {
+	atr := NewATR(2)
+	// high-low = 2 на каждой свече, гэпов нет
+	feed(atr, closes(10, 10.5, 11))
+	assert.True(t, atr.Ready())
+	assert.InDelta(t, 2.0, atr.Value(), 1e-9)
+}
+
+func TestStochastic(t *testing.T) {
+	stoch := NewStochastic(3, 2)
+	data := closes(10, 11, 12, 13)
+
+	feed(stoch, data[:3])
+	assert.False(t, stoch.Ready())
+
+	stoch.Update(data[3])
+	assert.True(t, stoch.Ready())
+	// окно: low 10, high 14, close 13 -> 75
+	assert.InDelta(t, 75.0, stoch.Value(), 1e-9)
+}
+
+func TestOBVAndVWAP(t *testing.T) {
+	obv := NewOBV()
+	feed(obv, closes(10, 11, 10, 10))
+	assert.InDelta(t, 0.0, obv.Value(), 1e-9)
+
+	vwap := NewVWAP(2)
+	feed(vwap, closes(10, 20, 30))
+	assert.InDelta(t, 25.0, vwap.Value(), 1e-9)
+}
+
+func TestParse(t *testing.T) {
+	for _, spec := range []string{"sma_14", "ema_volume_20", "wma_5", "rsi_14", "macd_12_26_9", "bb_20_2.5", "atr_14", "stoch_14_3", "obv", "vwap", "vwap_50"} {
+		ind, err := Parse(spec)
+		if assert.NoError(t, err, spec) {
+			assert.Equal(t, spec, ind.Name())
+		}
+	}
+
+	for _, spec := range []string{"sma", "sma_x", "macd_26_12_9", "foo_1", "rsi_0"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
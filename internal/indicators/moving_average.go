@@ -0,0 +1,144 @@
+package indicators
+
+import "crypto-trading-bot/internal/types"
+
+// SMA — простая скользящая средняя
+type SMA struct {
+	name   string
+	src    Source
+	period int
+	window *ring
+	sum    float64
+}
+
+func NewSMA(period int, src Source) *SMA {
+	return &SMA{
+		name:   indicatorName("sma", src, period),
+		src:    src,
+		period: period,
+		window: newRing(period),
+	}
+}
+
+func (i *SMA) Name() string { return i.name }
+func (i *SMA) WarmUp() int  { return i.period }
+func (i *SMA) Ready() bool  { return i.window.full() }
+
+func (i *SMA) Update(md *types.MarketData) {
+	i.add(i.src.Value(md))
+}
+
+func (i *SMA) add(v float64) {
+	i.sum += v
+	if old, evicted := i.window.push(v); evicted {
+		i.sum -= old
+	}
+}
+
+func (i *SMA) Value() float64 {
+	if i.window.count == 0 {
+		return 0
+	}
+	return i.sum / float64(i.window.count)
+}
+
+func (i *SMA) Values() map[string]float64 {
+	return map[string]float64{i.name: i.Value()}
+}
+
+// EMA — экспоненциальная скользящая средняя.
+// Первое значение — SMA за период, далее рекуррентно.
+type EMA struct {
+	name   string
+	src    Source
+	period int
+	k      float64
+	count  int
+	sum    float64
+	value  float64
+}
+
+func NewEMA(period int, src Source) *EMA {
+	return &EMA{
+		name:   indicatorName("ema", src, period),
+		src:    src,
+		period: period,
+		k:      2.0 / float64(period+1),
+	}
+}
+
+func (i *EMA) Name() string { return i.name }
+func (i *EMA) WarmUp() int  { return i.period }
+func (i *EMA) Ready() bool  { return i.count >= i.period }
+
+func (i *EMA) Update(md *types.MarketData) {
+	i.add(i.src.Value(md))
+}
+
+func (i *EMA) add(v float64) {
+	i.count++
+	if i.count <= i.period {
+		// период прогрева: накапливаем SMA для начального значения
+		i.sum += v
+		i.value = i.sum / float64(i.count)
+		return
+	}
+	i.value = (v-i.value)*i.k + i.value
+}
+
+func (i *EMA) Value() float64 { return i.value }
+
+func (i *EMA) Values() map[string]float64 {
+	return map[string]float64{i.name: i.value}
+}
+
+// WMA — линейно взвешенная скользящая средняя (вес последней свечи равен периоду)
+type WMA struct {
+	name      string
+	src       Source
+	period    int
+	window    *ring
+	sum       float64 // сумма значений в окне
+	numerator float64 // взвешенная сумма значений в окне
+}
+
+func NewWMA(period int, src Source) *WMA {
+	return &WMA{
+		name:   indicatorName("wma", src, period),
+		src:    src,
+		period: period,
+		window: newRing(period),
+	}
+}
+
+func (i *WMA) Name() string { return i.name }
+func (i *WMA) WarmUp() int  { return i.period }
+func (i *WMA) Ready() bool  { return i.window.full() }
+
+func (i *WMA) Update(md *types.MarketData) {
+	v := i.src.Value(md)
+
+	if i.window.full() {
+		// при сдвиге окна вес каждого значения уменьшается на 1, самое старое выпадает
+		i.numerator += float64(i.period)*v - i.sum
+		old, _ := i.window.push(v)
+		i.sum += v - old
+		return
+	}
+
+	i.window.push(v)
+	i.sum += v
+	i.numerator += float64(i.window.count) * v
+}
+
+func (i *WMA) Value() float64 {
+	n := float64(i.window.count)
+	if n == 0 {
+		return 0
+	}
+	return i.numerator / (n * (n + 1) / 2)
+}
+
+func (i *WMA) Values() map[string]float64 {
+	return map[string]float64{i.name: i.Value()}
+}
@@ -0,0 +1,173 @@
+package indicators
+
+import (
+	"crypto-trading-bot/internal/types"
+	"math"
+)
+
+// RSI — индекс относительной силы со сглаживанием Уайлдера
+type RSI struct {
+	name      string
+	src       Source
+	period    int
+	count     int // количество полученных изменений цены
+	prev      float64
+	hasPrev   bool
+	avgGain   float64
+	avgLoss   float64
+	sumGain   float64
+	sumLoss   float64
+	lastValue float64
+}
+
+func NewRSI(period int, src Source) *RSI {
+	return &RSI{
+		name:   indicatorName("rsi", src, period),
+		src:    src,
+		period: period,
+	}
+}
+
+func (i *RSI) Name() string { return i.name }
+
+// WarmUp - для первого значения нужно period изменений цены, т.е. period+1 свеча
+func (i *RSI) WarmUp() int { return i.period + 1 }
+func (i *RSI) Ready() bool { return i.count >= i.period }
+
+func (i *RSI) Update(md *types.MarketData) {
+	v := i.src.Value(md)
+	if !i.hasPrev {
+		i.prev = v
+		i.hasPrev = true
+		return
+	}
+
+	change := v - i.prev
+	i.prev = v
+	gain, loss := math.Max(change, 0), math.Max(-change, 0)
+
+	i.count++
+	n := float64(i.period)
+	switch {
+	case i.count < i.period:
+		i.sumGain += gain
+		i.sumLoss += loss
+		return
+	case i.count == i.period:
+		i.avgGain = (i.sumGain + gain) / n
+		i.avgLoss = (i.sumLoss + loss) / n
+	default:
+		i.avgGain = (i.avgGain*(n-1) + gain) / n
+		i.avgLoss = (i.avgLoss*(n-1) + loss) / n
+	}
+
+	if i.avgLoss == 0 {
+		i.lastValue = 100
+		return
+	}
+	rs := i.avgGain / i.avgLoss
+	i.lastValue = 100 - 100/(1+rs)
+}
+
+func (i *RSI) Value() float64 { return i.lastValue }
+
+func (i *RSI) Values() map[string]float64 {
+	return map[string]float64{i.name: i.lastValue}
+}
+
+// Stochastic — стохастический осциллятор: %K за kPeriod свечей и %D как SMA(%K) за dPeriod
+type Stochastic struct {
+	name    string
+	kPeriod int
+	dPeriod int
+	count   int
+	highs   monotonicQueue
+	lows    monotonicQueue
+	d       *SMA
+	k       float64
+}
+
+func NewStochastic(kPeriod, dPeriod int) *Stochastic {
+	return &Stochastic{
+		name:    indicatorName("stoch", "", kPeriod, dPeriod),
+		kPeriod: kPeriod,
+		dPeriod: dPeriod,
+		highs:   monotonicQueue{max: true},
+		lows:    monotonicQueue{max: false},
+		d:       NewSMA(dPeriod, SourceClose),
+	}
+}
+
+func (i *Stochastic) Name() string { return i.name }
+func (i *Stochastic) WarmUp() int  { return i.kPeriod + i.dPeriod - 1 }
+func (i *Stochastic) Ready() bool  { return i.d.Ready() }
+
+func (i *Stochastic) Update(md *types.MarketData) {
+	i.highs.push(i.count, md.HightPrice)
+	i.lows.push(i.count, md.LowPrice)
+	i.count++
+
+	// вытесняем значения, вышедшие за окно
+	oldest := i.count - i.kPeriod
+	i.highs.evictBefore(oldest)
+	i.lows.evictBefore(oldest)
+
+	if i.count < i.kPeriod {
+		return
+	}
+
+	highest, lowest := i.highs.front(), i.lows.front()
+	if highest == lowest {
+		i.k = 50
+	} else {
+		i.k = (md.ClosePrice - lowest) / (highest - lowest) * 100
+	}
+	i.d.add(i.k)
+}
+
+func (i *Stochastic) Value() float64 { return i.k }
+
+func (i *Stochastic) Values() map[string]float64 {
+	return map[string]float64{
+		i.name:        i.k,
+		i.name + "_d": i.d.Value(),
+	}
+}
+
+// monotonicQueue — очередь для скользящего максимума/минимума за амортизированное O(1)
+type monotonicQueue struct {
+	max   bool
+	head  int
+	index []int
+	value []float64
+}
+
+func (q *monotonicQueue) push(index int, v float64) {
+	for n := len(q.value); n > q.head; n = len(q.value) {
+		last := q.value[n-1]
+		if (q.max && last > v) || (!q.max && last < v) {
+			break
+		}
+		q.index = q.index[:n-1]
+		q.value = q.value[:n-1]
+	}
+	q.index = append(q.index, index)
+	q.value = append(q.value, v)
+}
+
+func (q *monotonicQueue) evictBefore(index int) {
+	for q.head < len(q.index) && q.index[q.head] < index {
+		q.head++
+	}
+
+	// периодически сдвигаем данные в начало, чтобы срезы не росли бесконечно
+	if q.head > 0 && q.head*2 >= len(q.index) {
+		q.index = append(q.index[:0], q.index[q.head:]...)
+		q.value = append(q.value[:0], q.value[q.head:]...)
+		q.head = 0
+	}
+}
+
+func (q *monotonicQueue) front() float64 {
+	return q.value[q.head]
+}
@@ -0,0 +1,159 @@
+package indicators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// New создаёт индикатор по типу, источнику цены и числовым параметрам.
+// Для индикаторов, которые не используют источник (atr, stoch, obv, vwap), src игнорируется.
+func New(kind string, src Source, params ...float64) (Indicator, error) {
+	if src == "" {
+		src = SourceClose
+	}
+
+	intParam := func(n int) (int, error) {
+		if n >= len(params) {
+			return 0, fmt.Errorf("indicator %s: missing parameter #%d", kind, n+1)
+		}
+		v := int(params[n])
+		if float64(v) != params[n] || v <= 0 {
+			return 0, fmt.Errorf("indicator %s: parameter #%d must be a positive integer, got %v", kind, n+1, params[n])
+		}
+		return v, nil
+	}
+	expect := func(n int) error {
+		if len(params) != n {
+			return fmt.Errorf("indicator %s: expected %d parameters, got %d", kind, n, len(params))
+		}
+		return nil
+	}
+
+	switch kind {
+	case "sma", "ema", "wma", "rsi":
+		if err := expect(1); err != nil {
+			return nil, err
+		}
+		period, err := intParam(0)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "sma":
+			return NewSMA(period, src), nil
+		case "ema":
+			return NewEMA(period, src), nil
+		case "wma":
+			return NewWMA(period, src), nil
+		default:
+			return NewRSI(period, src), nil
+		}
+
+	case "macd":
+		if err := expect(3); err != nil {
+			return nil, err
+		}
+		fast, err := intParam(0)
+		if err != nil {
+			return nil, err
+		}
+		slow, err := intParam(1)
+		if err != nil {
+			return nil, err
+		}
+		signal, err := intParam(2)
+		if err != nil {
+			return nil, err
+		}
+		if fast >= slow {
+			return nil, fmt.Errorf("indicator macd: fast period %d must be less than slow period %d", fast, slow)
+		}
+		return NewMACD(fast, slow, signal, src), nil
+
+	case "bb":
+		if err := expect(2); err != nil {
+			return nil, err
+		}
+		period, err := intParam(0)
+		if err != nil {
+			return nil, err
+		}
+		if params[1] <= 0 {
+			return nil, fmt.Errorf("indicator bb: width multiplier must be positive, got %v", params[1])
+		}
+		return NewBollinger(period, params[1], src), nil
+
+	case "atr":
+		if err := expect(1); err != nil {
+			return nil, err
+		}
+		period, err := intParam(0)
+		if err != nil {
+			return nil, err
+		}
+		return NewATR(period), nil
+
+	case "stoch":
+		if err := expect(2); err != nil {
+			return nil, err
+		}
+		k, err := intParam(0)
+		if err != nil {
+			return nil, err
+		}
+		d, err := intParam(1)
+		if err != nil {
+			return nil, err
+		}
+		return NewStochastic(k, d), nil
+
+	case "obv":
+		if err := expect(0); err != nil {
+			return nil, err
+		}
+		return NewOBV(), nil
+
+	case "vwap":
+		if len(params) == 0 {
+			return NewVWAP(0), nil
+		}
+		if err := expect(1); err != nil {
+			return nil, err
+		}
+		period, err := intParam(0)
+		if err != nil {
+			return nil, err
+		}
+		return NewVWAP(period), nil
+	}
+
+	return nil, fmt.Errorf("unknown indicator: %s", kind)
+}
+
+// Parse создаёт индикатор по строковому описанию в формате имени индикатора:
+// "sma_14", "ema_volume_20", "macd_12_26_9", "bb_20_2", "stoch_14_3", "atr_14", "obv", "vwap", "vwap_50".
+// Parse(ind.Name()) возвращает индикатор с теми же параметрами.
+func Parse(spec string) (Indicator, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(spec)), "_")
+	kind, rest := parts[0], parts[1:]
+
+	src := SourceClose
+	if len(rest) > 0 {
+		if s, err := ParseSource(rest[0]); err == nil {
+			src = s
+			rest = rest[1:]
+		}
+	}
+
+	params := make([]float64, len(rest))
+	for n, p := range rest {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, fmt.Errorf("indicator %q: invalid parameter %q", spec, p)
+		}
+		params[n] = v
+	}
+
+	return New(kind, src, params...)
+}
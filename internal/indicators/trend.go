@@ -0,0 +1,164 @@
+package indicators
+
+import (
+	"crypto-trading-bot/internal/types"
+	"math"
+)
+
+// MACD — схождение/расхождение скользящих средних.
+// Выходы: линия MACD, сигнальная линия (_signal) и гистограмма (_hist).
+type MACD struct {
+	name   string
+	src    Source
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	macd   float64
+}
+
+func NewMACD(fastPeriod, slowPeriod, signalPeriod int, src Source) *MACD {
+	return &MACD{
+		name:   indicatorName("macd", src, fastPeriod, slowPeriod, signalPeriod),
+		src:    src,
+		fast:   NewEMA(fastPeriod, src),
+		slow:   NewEMA(slowPeriod, src),
+		signal: NewEMA(signalPeriod, src),
+	}
+}
+
+func (i *MACD) Name() string { return i.name }
+func (i *MACD) WarmUp() int  { return i.slow.period + i.signal.period - 1 }
+func (i *MACD) Ready() bool  { return i.signal.Ready() }
+
+func (i *MACD) Update(md *types.MarketData) {
+	v := i.src.Value(md)
+	i.fast.add(v)
+	i.slow.add(v)
+
+	// сигнальную линию считаем только по достоверным значениям MACD
+	if !i.slow.Ready() {
+		return
+	}
+	i.macd = i.fast.Value() - i.slow.Value()
+	i.signal.add(i.macd)
+}
+
+func (i *MACD) Value() float64 { return i.macd }
+
+func (i *MACD) Values() map[string]float64 {
+	return map[string]float64{
+		i.name:             i.macd,
+		i.name + "_signal": i.signal.Value(),
+		i.name + "_hist":   i.macd - i.signal.Value(),
+	}
+}
+
+// Bollinger — полосы Боллинджера.
+// Выходы: средняя линия, верхняя (_upper) и нижняя (_lower) полосы, ширина (_width).
+type Bollinger struct {
+	name   string
+	src    Source
+	period int
+	k      float64
+	window *ring
+	sum    float64
+	sumSq  float64
+}
+
+func NewBollinger(period int, k float64, src Source) *Bollinger {
+	return &Bollinger{
+		name:   indicatorName("bb", src, period, k),
+		src:    src,
+		period: period,
+		k:      k,
+		window: newRing(period),
+	}
+}
+
+func (i *Bollinger) Name() string { return i.name }
+func (i *Bollinger) WarmUp() int  { return i.period }
+func (i *Bollinger) Ready() bool  { return i.window.full() }
+
+func (i *Bollinger) Update(md *types.MarketData) {
+	v := i.src.Value(md)
+	i.sum += v
+	i.sumSq += v * v
+	if old, evicted := i.window.push(v); evicted {
+		i.sum -= old
+		i.sumSq -= old * old
+	}
+}
+
+func (i *Bollinger) bands() (middle, upper, lower float64) {
+	n := float64(i.window.count)
+	if n == 0 {
+		return 0, 0, 0
+	}
+	middle = i.sum / n
+	// max защищает от отрицательной дисперсии из-за ошибок округления
+	std := math.Sqrt(math.Max(i.sumSq/n-middle*middle, 0))
+	return middle, middle + i.k*std, middle - i.k*std
+}
+
+func (i *Bollinger) Value() float64 {
+	middle, _, _ := i.bands()
+	return middle
+}
+
+func (i *Bollinger) Values() map[string]float64 {
+	middle, upper, lower := i.bands()
+	width := 0.0
+	if middle != 0 {
+		width = (upper - lower) / middle
+	}
+	return map[string]float64{
+		i.name:            middle,
+		i.name + "_upper": upper,
+		i.name + "_lower": lower,
+		i.name + "_width": width,
+	}
+}
+
+// ATR — средний истинный диапазон со сглаживанием Уайлдера
+type ATR struct {
+	name      string
+	period    int
+	count     int
+	prevClose float64
+	sum       float64
+	value     float64
+}
+
+func NewATR(period int) *ATR {
+	return &ATR{
+		name:   indicatorName("atr", "", period),
+		period: period,
+	}
+}
+
+func (i *ATR) Name() string { return i.name }
+func (i *ATR) WarmUp() int  { return i.period }
+func (i *ATR) Ready() bool  { return i.count >= i.period }
+
+func (i *ATR) Update(md *types.MarketData) {
+	tr := md.HightPrice - md.LowPrice
+	if i.count > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(md.HightPrice-i.prevClose), math.Abs(md.LowPrice-i.prevClose)))
+	}
+	i.prevClose = md.ClosePrice
+	i.count++
+
+	n := float64(i.period)
+	if i.count <= i.period {
+		i.sum += tr
+		i.value = i.sum / float64(i.count)
+		return
+	}
+	i.value = (i.value*(n-1) + tr) / n
+}
+
+func (i *ATR) Value() float64 { return i.value }
+
+func (i *ATR) Values() map[string]float64 {
+	return map[string]float64{i.name: i.value}
+}
@@ -0,0 +1,104 @@
+package indicators
+
+import "crypto-trading-bot/internal/types"
+
+// OBV — балансовый объём
+type OBV struct {
+	count     int
+	prevClose float64
+	value     float64
+}
+
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+func (i *OBV) Name() string { return "obv" }
+func (i *OBV) WarmUp() int  { return 1 }
+func (i *OBV) Ready() bool  { return i.count >= 1 }
+
+func (i *OBV) Update(md *types.MarketData) {
+	if i.count > 0 {
+		switch {
+		case md.ClosePrice > i.prevClose:
+			i.value += md.Volume
+		case md.ClosePrice < i.prevClose:
+			i.value -= md.Volume
+		}
+	}
+	i.prevClose = md.ClosePrice
+	i.count++
+}
+
+func (i *OBV) Value() float64 { return i.value }
+
+func (i *OBV) Values() map[string]float64 {
+	return map[string]float64{"obv": i.value}
+}
+
+// VWAP — средневзвешенная по объёму цена (по типичной цене свечи).
+// При period = 0 считается накопительно с начала потока, иначе — по скользящему окну.
+type VWAP struct {
+	name    string
+	period  int
+	count   int
+	pv      float64
+	volume  float64
+	pvs     *ring
+	volumes *ring
+	last    float64 // типичная цена последней свечи, если объёма нет
+}
+
+func NewVWAP(period int) *VWAP {
+	i := &VWAP{
+		name:   "vwap",
+		period: period,
+	}
+	if period > 0 {
+		i.name = indicatorName("vwap", "", period)
+		i.pvs = newRing(period)
+		i.volumes = newRing(period)
+	}
+	return i
+}
+
+func (i *VWAP) Name() string { return i.name }
+
+func (i *VWAP) WarmUp() int {
+	if i.period > 0 {
+		return i.period
+	}
+	return 1
+}
+
+func (i *VWAP) Ready() bool { return i.count >= i.WarmUp() }
+
+func (i *VWAP) Update(md *types.MarketData) {
+	price := typicalPrice(md)
+	pv := price * md.Volume
+	i.last = price
+	i.count++
+
+	i.pv += pv
+	i.volume += md.Volume
+	if i.period == 0 {
+		return
+	}
+	if old, evicted := i.pvs.push(pv); evicted {
+		i.pv -= old
+	}
+	if old, evicted := i.volumes.push(md.Volume); evicted {
+		i.volume -= old
+	}
+}
+
+func (i *VWAP) Value() float64 {
+	if i.volume <= 0 {
+		return i.last
+	}
+	return i.pv / i.volume
+}
+
+func (i *VWAP) Values() map[string]float64 {
+	return map[string]float64{i.name: i.Value()}
+}
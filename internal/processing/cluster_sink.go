@@ -0,0 +1,56 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+)
+
+var (
+	_ pipeline.Sink    = (*ClusterDataSink)(nil)
+	_ pipeline.Flusher = (*ClusterDataSink)(nil)
+)
+
+// ClusterDataSink сохраняет завершённые бары footprint в таблицу cluster_data.
+// Когда источник исчерпан, сохраняет и недостроенные бары процессора profiles.
+type ClusterDataSink struct {
+	repo     repositories.ClusterDataRepository
+	profiles *VolumeProfileProcessor
+}
+
+func NewClusterDataSink(repo repositories.ClusterDataRepository, profiles *VolumeProfileProcessor) *ClusterDataSink {
+	return &ClusterDataSink{repo: repo, profiles: profiles}
+}
+
+func (s *ClusterDataSink) Consume(_ context.Context, payload pipeline.Payload) error {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	if tradingPayload.Footprint == nil {
+		return nil
+	}
+
+	if err := s.repo.SaveClusterData(tradingPayload.Footprint.ClusterData()); err != nil {
+		return fmt.Errorf("failed to save footprint %s %s: %w", tradingPayload.Symbol, tradingPayload.Footprint.Start, err)
+	}
+
+	return nil
+}
+
+// Flush implements pipeline.Flusher.
+func (s *ClusterDataSink) Flush(context.Context) error {
+	if s.profiles == nil {
+		return nil
+	}
+
+	for _, bar := range s.profiles.Flush() {
+		if err := s.repo.SaveClusterData(bar.ClusterData()); err != nil {
+			return fmt.Errorf("failed to save footprint %s %s: %w", bar.Symbol, bar.Start, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/pkg/pipeline"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryClusterData struct {
+	saved []*types.ClusterData
+}
+
+func (r *memoryClusterData) SaveClusterData(data []*types.ClusterData) error {
+	r.saved = append(r.saved, data...)
+	return nil
+}
+
+func (r *memoryClusterData) GetClusterData(string, string, time.Time, time.Time) ([]*types.ClusterData, error) {
+	return r.saved, nil
+}
+
+type sliceSource struct {
+	data  []*types.MarketData
+	index int
+}
+
+func (s *sliceSource) Next(context.Context) bool {
+	if s.index == len(s.data) {
+		return false
+	}
+	s.index++
+	return true
+}
+
+func (s *sliceSource) Error() error { return nil }
+
+func (s *sliceSource) Payload() pipeline.Payload {
+	md := s.data[s.index-1]
+	return &TradingPayload{Symbol: md.Symbol, Interval: "1m", CurrentPrice: md.ClosePrice, MarketData: md}
+}
+
+func TestClusterDataSinkFlush(t *testing.T) {
+	proc, err := NewVolumeProfileProcessor(&settings.VolumeProfileSettings{TickSize: 1, Window: 10, FootprintInterval: "5m"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	repo := &memoryClusterData{}
+
+	// шесть минутных свечей: полный пятиминутный бар и начало следующего
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	source := &sliceSource{}
+	for i := range 6 {
+		source.data = append(source.data, &types.MarketData{
+			Symbol: "BTCUSDT", Timestamp: start.Add(time.Duration(i) * time.Minute),
+			OpenPrice: 100, HightPrice: 100, LowPrice: 100, ClosePrice: 100, Volume: 3, BuyVolume: 2, SellVolume: 1,
+		})
+	}
+
+	err = pipeline.New(pipeline.FIFO(proc)).Process(context.TODO(), source, NewClusterDataSink(repo, proc))
+	assert.NoError(t, err)
+
+	// последний бар сохранён при исчерпании источника
+	if assert.Len(t, repo.saved, 4) {
+		assert.Equal(t, start, repo.saved[0].Timestamp)
+		assert.InDelta(t, 10.0, repo.saved[0].Volume, 1e-9)
+		assert.Equal(t, start.Add(5*time.Minute), repo.saved[2].Timestamp)
+		assert.InDelta(t, 2.0, repo.saved[2].Volume, 1e-9)
+	}
+
+	// строящихся баров не осталось
+	assert.Empty(t, proc.Flush())
+}
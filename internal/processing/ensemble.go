@@ -0,0 +1,327 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	_ pipeline.Processor    = (*EnsembleProcessor)(nil)
+	_ settings.ConfigUpdate = (*EnsembleProcessor)(nil)
+)
+
+// EnsembleProcessor объединяет сигналы нескольких источников по символу.
+// Сигналы учитываемых источников убираются из payload.Signals и собираются в окне Tolerance,
+// от каждого источника учитывается последний сигнал. Голоса подводятся, когда набран кворум: Quorum,
+// по умолчанию — все перечисленные в Sources источники. Если кворум неизвестен (Quorum и Sources не заданы),
+// голоса подводятся, когда закрылось окно первого сигнала. Политика first кворума по умолчанию не ждёт.
+// Когда политика принимает решение, в payload.Signals добавляется один сигнал с источником "ensemble",
+// силой, равной уверенности решения, и перечнем голосов в Reason, а учтённые сигналы убираются из окна.
+type EnsembleProcessor struct {
+	settings  settings.EnsembleSettings
+	tolerance time.Duration
+	mu        sync.Mutex
+	windows   map[string][]*types.Signal // symbol -> сигналы в окне
+}
+
+// ensembleDecision — решение политики объединения
+type ensembleDecision struct {
+	side       string
+	confidence float64
+	votes      []*types.Signal // сигналы за решение
+	against    []*types.Signal // сигналы против
+}
+
+func NewEnsembleProcessor(comps ...settings.Settings) (*EnsembleProcessor, error) {
+	p := &EnsembleProcessor{}
+	p.UpdateConfig(comps...)
+
+	if p.settings.Policy == "" {
+		return nil, fmt.Errorf("ensemble settings are not set")
+	}
+	if p.tolerance <= 0 {
+		return nil, fmt.Errorf("invalid ensemble tolerance: %s", p.settings.Tolerance)
+	}
+
+	return p, nil
+}
+
+// UpdateConfig implements settings.ConfigUpdate.
+// Настройки с неверным окном сбора сигналов не применяются.
+func (p *EnsembleProcessor) UpdateConfig(comps ...settings.Settings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.EnsembleSettings); ok {
+			tolerance, err := time.ParseDuration(val.Tolerance)
+			if err != nil || tolerance <= 0 {
+				continue
+			}
+			p.settings = *val
+			p.tolerance = tolerance
+			p.windows = make(map[string][]*types.Signal)
+		}
+	}
+}
+
+// Process implements pipeline.Processor.
+func (p *EnsembleProcessor) Process(_ context.Context, payload pipeline.Payload) (pipeline.Payload, error) {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return nil, fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	var now time.Time
+	if tradingPayload.MarketData != nil {
+		now = tradingPayload.MarketData.Timestamp
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := tradingPayload.Symbol
+	window := p.windows[key]
+
+	var rest []*types.Signal
+	for _, signal := range tradingPayload.Signals {
+		if signal.Source == "ensemble" || !p.accepts(signal.Source) {
+			rest = append(rest, signal)
+			continue
+		}
+
+		vote := *signal
+		if vote.Time.IsZero() {
+			vote.Time = now
+		}
+		if vote.Time.After(now) {
+			now = vote.Time
+		}
+
+		window = slices.DeleteFunc(window, func(s *types.Signal) bool { return s.Source == vote.Source })
+		window = append(window, &vote)
+	}
+
+	if now.IsZero() {
+		return payload, nil
+	}
+
+	if p.quorum() == 0 && p.settings.Policy != settings.EnsembleFirst {
+		// кворум неизвестен: голоса окна первого сигнала подводятся после его закрытия
+		if len(window) > 0 {
+			closes := slices.MinFunc(window, func(a, b *types.Signal) int { return a.Time.Compare(b.Time) }).Time.Add(p.tolerance)
+			if !now.Before(closes) {
+				votes := slices.DeleteFunc(slices.Clone(window), func(s *types.Signal) bool { return s.Time.After(closes) })
+				window = slices.DeleteFunc(window, func(s *types.Signal) bool { return !s.Time.After(closes) })
+				if decision := p.decide(votes, now); decision != nil {
+					rest = append(rest, p.signal(tradingPayload, decision, now))
+				}
+			}
+		}
+	} else {
+		// сигналы старше окна не учитываются
+		window = slices.DeleteFunc(window, func(s *types.Signal) bool { return s.Time.Before(now.Add(-p.tolerance)) })
+
+		if decision := p.decide(window, now); decision != nil {
+			rest = append(rest, p.signal(tradingPayload, decision, now))
+			window = nil
+		}
+	}
+
+	p.windows[key] = window
+	tradingPayload.Signals = rest
+
+	return payload, nil
+}
+
+// quorum возвращает минимальное количество голосов: Quorum, а если он не задан —
+// количество перечисленных источников для всех политик, кроме first. 0 — кворум неизвестен.
+func (p *EnsembleProcessor) quorum() int {
+	if p.settings.Quorum > 0 || p.settings.Policy == settings.EnsembleFirst {
+		return p.settings.Quorum
+	}
+	return len(p.settings.Sources)
+}
+
+func (p *EnsembleProcessor) accepts(source string) bool {
+	return len(p.settings.Sources) == 0 || slices.Contains(p.settings.Sources, source)
+}
+
+// decide применяет политику к сигналам окна, nil — решения нет
+func (p *EnsembleProcessor) decide(window []*types.Signal, now time.Time) *ensembleDecision {
+	if len(window) == 0 || len(window) < p.quorum() {
+		return nil
+	}
+
+	var decision *ensembleDecision
+	switch p.settings.Policy {
+	case settings.EnsembleMajority:
+		buys := countSide(window, types.SideBuy)
+		sells := len(window) - buys
+		if buys == sells {
+			return nil
+		}
+		side := types.SideBuy
+		if sells > buys {
+			side = types.SideSell
+		}
+		decision = splitVotes(window, side)
+		decision.confidence = float64(max(buys, sells)) / float64(len(window))
+
+	case settings.EnsembleWeighted:
+		var score, total float64
+		for _, s := range window {
+			weight := p.weight(s.Source)
+			strength := s.Strength
+			if strength == 0 {
+				strength = 1
+			}
+			score += sideSign(s.Side) * weight * strength
+			total += weight
+		}
+		if total == 0 || score == 0 {
+			return nil
+		}
+		side := types.SideBuy
+		if score < 0 {
+			side = types.SideSell
+		}
+		decision = splitVotes(window, side)
+		// сила сигнала может быть больше 1
+		decision.confidence = math.Min(math.Abs(score)/total, 1)
+
+	case settings.EnsembleUnanimous:
+		// при заданном списке источников должны проголосовать все
+		if len(window) < len(p.settings.Sources) {
+			return nil
+		}
+		side := window[0].Side
+		if countSide(window, side) != len(window) {
+			return nil
+		}
+		decision = splitVotes(window, side)
+		decision.confidence = 1
+
+	case settings.EnsembleFirst:
+		first := window[0]
+		for _, s := range window {
+			if s.Time.Before(first.Time) {
+				first = s
+			}
+		}
+		decision = splitVotes(window, first.Side)
+		for _, s := range decision.against {
+			if slices.Contains(p.settings.Veto, s.Source) {
+				return nil
+			}
+		}
+		// источники с правом вето могут возразить, пока не истекло окно первого сигнала
+		for _, source := range p.settings.Veto {
+			if source != first.Source && now.Before(first.Time.Add(p.tolerance)) &&
+				!slices.ContainsFunc(window, func(s *types.Signal) bool { return s.Source == source }) {
+				return nil
+			}
+		}
+		decision.confidence = float64(len(decision.votes)) / float64(len(window))
+
+	default:
+		return nil
+	}
+
+	if decision.confidence < p.settings.Threshold {
+		return nil
+	}
+	return decision
+}
+
+func (p *EnsembleProcessor) weight(source string) float64 {
+	if weight, ok := p.settings.Weights[source]; ok {
+		return weight
+	}
+	return 1
+}
+
+// signal формирует итоговый сигнал решения
+func (p *EnsembleProcessor) signal(payload *TradingPayload, decision *ensembleDecision, now time.Time) *types.Signal {
+	var amount float64
+	var count int
+	for _, s := range decision.votes {
+		if s.Amount > 0 {
+			amount += s.Amount
+			count++
+		}
+	}
+	if count > 0 {
+		amount /= float64(count)
+	}
+
+	price := payload.CurrentPrice
+	if price == 0 && payload.MarketData != nil {
+		price = payload.MarketData.ClosePrice
+	}
+
+	return &types.Signal{
+		Source:   "ensemble",
+		Symbol:   payload.Symbol,
+		Side:     decision.side,
+		Type:     types.OrderTypeMarket,
+		Price:    price,
+		Amount:   amount,
+		Strength: decision.confidence,
+		Reason: fmt.Sprintf("%s %s, confidence %.2f: for [%s]; against [%s]",
+			p.settings.Policy, decision.side, decision.confidence, describeVotes(decision.votes), describeVotes(decision.against)),
+		Time: now,
+	}
+}
+
+func splitVotes(window []*types.Signal, side string) *ensembleDecision {
+	decision := &ensembleDecision{side: side}
+	for _, s := range window {
+		if s.Side == side {
+			decision.votes = append(decision.votes, s)
+		} else {
+			decision.against = append(decision.against, s)
+		}
+	}
+	return decision
+}
+
+func describeVotes(signals []*types.Signal) string {
+	parts := make([]string, 0, len(signals))
+	for _, s := range signals {
+		part := s.Source + " " + s.Side
+		if s.Strength > 0 {
+			part += fmt.Sprintf(" %.2f", s.Strength)
+		}
+		if s.Reason != "" {
+			part += " (" + s.Reason + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func countSide(signals []*types.Signal, side string) int {
+	count := 0
+	for _, s := range signals {
+		if s.Side == side {
+			count++
+		}
+	}
+	return count
+}
+
+func sideSign(side string) float64 {
+	if side == types.SideSell {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,120 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsembleProcessor(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+
+	newProcess := func(cfg *settings.EnsembleSettings) func(minute int, signals ...*types.Signal) []*types.Signal {
+		proc, err := NewEnsembleProcessor(cfg)
+		assert.NoError(t, err)
+		return func(minute int, signals ...*types.Signal) []*types.Signal {
+			p := &TradingPayload{
+				Symbol:       "BTCUSDT",
+				Interval:     "1m",
+				CurrentPrice: 100,
+				MarketData:   &types.MarketData{Timestamp: now.Add(time.Duration(minute) * time.Minute), ClosePrice: 100},
+				Signals:      signals,
+			}
+			out, err := proc.Process(context.TODO(), p)
+			assert.NoError(t, err)
+			return out.(*TradingPayload).Signals
+		}
+	}
+	vote := func(source string, side string, strength float64) *types.Signal {
+		return &types.Signal{Source: source, Side: side, Strength: strength, Amount: 1}
+	}
+
+	_, err := NewEnsembleProcessor(&settings.EnsembleSettings{Policy: "majority", Tolerance: "soon"})
+	assert.Error(t, err)
+
+	// большинство из трёх источников, сигнал вне списка источников проходит без изменений
+	process := newProcess(&settings.EnsembleSettings{Policy: "majority", Tolerance: "5m", Quorum: 3, Sources: []string{"a", "b", "c"}})
+	assert.Empty(t, process(0, vote("a", types.SideBuy, 0)))
+	signals := process(1, vote("b", types.SideSell, 0), vote("other", types.SideSell, 0))
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, "other", signals[0].Source)
+	}
+	signals = process(2, vote("c", types.SideBuy, 0))
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, "ensemble", signals[0].Source)
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.InDelta(t, 2.0/3, signals[0].Strength, 1e-9)
+		assert.Contains(t, signals[0].Reason, "against [b sell]")
+	}
+
+	// сигнал старше окна не учитывается
+	assert.Empty(t, process(3, vote("a", types.SideSell, 0)))
+	assert.Empty(t, process(10, vote("b", types.SideSell, 0), vote("c", types.SideBuy, 0)))
+
+	// кворум по умолчанию — все источники: первый сигнал один не решает
+	process = newProcess(&settings.EnsembleSettings{Policy: "majority", Tolerance: "5m", Sources: []string{"a", "b", "c"}})
+	assert.Empty(t, process(0, vote("a", types.SideBuy, 0)))
+	assert.Empty(t, process(1, vote("b", types.SideBuy, 0)))
+	signals = process(2, vote("c", types.SideSell, 0))
+	if assert.Len(t, signals, 1) {
+		assert.InDelta(t, 2.0/3, signals[0].Strength, 1e-9)
+	}
+
+	// взвешенная оценка без списка источников подводится по закрытии окна первого сигнала
+	process = newProcess(&settings.EnsembleSettings{
+		Policy: "weighted", Tolerance: "5m", Threshold: 0.5,
+		Weights: map[string]float64{"rules": 3, "level_signals": 1},
+	})
+	assert.Empty(t, process(0, vote("rules", types.SideSell, 1)))
+	assert.Empty(t, process(2, vote("level_signals", types.SideBuy, 1)))
+	assert.Empty(t, process(4))
+	signals = process(5)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.InDelta(t, 0.5, signals[0].Strength, 1e-9)
+	}
+	assert.Empty(t, process(10))
+
+	// сигнал после закрытия окна не попадает в его голоса и открывает новое окно
+	assert.Empty(t, process(11, vote("rules", types.SideBuy, 1)))
+	signals = process(17, vote("level_signals", types.SideSell, 1))
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.Contains(t, signals[0].Reason, "against []")
+	}
+	signals = process(22)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.Contains(t, signals[0].Reason, "for [level_signals sell 1.00]")
+	}
+
+	// уверенность не больше 1 при силе сигнала больше 1
+	process = newProcess(&settings.EnsembleSettings{Policy: "weighted", Tolerance: "5m", Sources: []string{"rules"}})
+	signals = process(0, vote("rules", types.SideBuy, 3))
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, 1.0, signals[0].Strength)
+	}
+
+	// единогласие всех источников
+	process = newProcess(&settings.EnsembleSettings{Policy: "unanimous", Tolerance: "5m", Sources: []string{"a", "b"}})
+	assert.Empty(t, process(0, vote("a", types.SideBuy, 0)))
+	assert.Empty(t, process(1, vote("b", types.SideSell, 0)))
+	assert.Len(t, process(2, vote("b", types.SideBuy, 0)), 1)
+
+	// первый сигнал ждёт источник с правом вето до конца окна
+	process = newProcess(&settings.EnsembleSettings{Policy: "first", Tolerance: "2m", Veto: []string{"risk"}})
+	assert.Empty(t, process(0, vote("a", types.SideBuy, 0)))
+	assert.Empty(t, process(1, vote("risk", types.SideSell, 0)))
+	assert.Empty(t, process(2))
+
+	assert.Empty(t, process(10, vote("a", types.SideBuy, 0)))
+	signals = process(12)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.Equal(t, now.Add(12*time.Minute), signals[0].Time)
+	}
+}
@@ -0,0 +1,38 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+)
+
+var _ pipeline.Sink = (*IndicatorSink)(nil)
+
+// IndicatorSink сохраняет рассчитанные индикаторы в таблицу indicators
+type IndicatorSink struct {
+	repo repositories.IndicatorRepository
+}
+
+func NewIndicatorSink(repo repositories.IndicatorRepository) *IndicatorSink {
+	return &IndicatorSink{repo: repo}
+}
+
+func (s *IndicatorSink) Consume(_ context.Context, payload pipeline.Payload) error {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	if tradingPayload.MarketData == nil {
+		return nil
+	}
+
+	for name, value := range tradingPayload.Indicators {
+		if err := s.repo.SaveIndicator(tradingPayload.Symbol, name, value, tradingPayload.MarketData.Timestamp); err != nil {
+			return fmt.Errorf("failed to save indicator %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
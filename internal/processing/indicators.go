@@ -0,0 +1,115 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/indicators"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+	"sync"
+)
+
+var (
+	_ pipeline.Processor    = (*IndicatorProcessor)(nil)
+	_ settings.ConfigUpdate = (*IndicatorProcessor)(nil)
+)
+
+// IndicatorProcessor рассчитывает индикаторы по свече из payload и добавляет их значения в payload.
+// Для каждой пары символ+интервал ведётся свой набор индикаторов.
+// Значения индикаторов, не прошедших период прогрева, в payload не попадают.
+type IndicatorProcessor struct {
+	settings settings.IndicatorSettings
+	mu       sync.Mutex
+	sets     map[string][]indicators.Indicator // symbol+interval -> набор индикаторов
+}
+
+func NewIndicatorProcessor(comps ...settings.Settings) (*IndicatorProcessor, error) {
+	p := &IndicatorProcessor{
+		sets: make(map[string][]indicators.Indicator),
+	}
+
+	p.UpdateConfig(comps...)
+
+	// проверяем описания индикаторов сразу, а не на первой свече
+	if _, err := p.newSet(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// UpdateConfig implements settings.ConfigUpdate.
+// После изменения настроек индикаторы рассчитываются заново, с периодом прогрева.
+func (p *IndicatorProcessor) UpdateConfig(comps ...settings.Settings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.IndicatorSettings); ok {
+			p.settings = *val
+			p.sets = make(map[string][]indicators.Indicator)
+		}
+	}
+}
+
+// WarmUp возвращает период прогрева (в свечах) для каждого индикатора
+func (p *IndicatorProcessor) WarmUp() map[string]int {
+	p.mu.Lock()
+	set, _ := p.newSet()
+	p.mu.Unlock()
+
+	warmUp := make(map[string]int, len(set))
+	for _, ind := range set {
+		warmUp[ind.Name()] = ind.WarmUp()
+	}
+	return warmUp
+}
+
+// Process implements pipeline.Processor.
+func (p *IndicatorProcessor) Process(_ context.Context, payload pipeline.Payload) (pipeline.Payload, error) {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return nil, fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	if tradingPayload.MarketData == nil {
+		return payload, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := tradingPayload.Symbol + tradingPayload.Interval
+	set, exists := p.sets[key]
+	if !exists {
+		var err error
+		if set, err = p.newSet(); err != nil {
+			return nil, err
+		}
+		p.sets[key] = set
+	}
+
+	for _, ind := range set {
+		ind.Update(tradingPayload.MarketData)
+		if !ind.Ready() {
+			continue
+		}
+		for name, value := range ind.Values() {
+			tradingPayload.SetIndicator(name, value)
+		}
+	}
+
+	return payload, nil
+}
+
+func (p *IndicatorProcessor) newSet() ([]indicators.Indicator, error) {
+	set := make([]indicators.Indicator, 0, len(p.settings.Indicators))
+	for _, spec := range p.settings.Indicators {
+		ind, err := indicators.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, ind)
+	}
+	return set, nil
+}
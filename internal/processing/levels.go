@@ -0,0 +1,320 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/service/clusters"
+	"crypto-trading-bot/internal/service/series"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/internal/utils"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	_ pipeline.Processor    = (*LevelSignalProcessor)(nil)
+	_ settings.ConfigUpdate = (*LevelSignalProcessor)(nil)
+)
+
+// ClusterLevelDistance — имя значения в payload.Indicators: относительное расстояние
+// от цены закрытия до ближайшего уровня, без знака
+const ClusterLevelDistance = "cluster_level_dist"
+
+// LevelSignalProcessor строит уровни поддержки и сопротивления из серий кластеров
+// и выдаёт сигналы пробоя, ретеста и отбоя, когда цена пересекает уровень или касается его.
+// Отбой выдаётся, когда после касания уровня цена закрытия отошла от него на RejectionMargin;
+// одно касание даёт один сигнал, пока цена держится у уровня, повторных сигналов нет.
+// Свечи кластеризуются по интервалу ClusterInterval и добавляются в SeriesBuilder после закрытия интервала,
+// поэтому сигналы свечи рассчитываются только по уровням из завершённых интервалов.
+// Сигналы добавляются в payload.Signals и сохраняются в level_signals.
+type LevelSignalProcessor struct {
+	settings settings.LevelSignalSettings
+	repo     repositories.LevelSignalRepository
+	mu       sync.Mutex
+	states   map[string]*levelState // symbol+interval -> состояние
+}
+
+type levelState struct {
+	builder   *series.SeriesBuilder
+	pending   []*types.MarketData         // свечи текущего интервала кластеризации
+	barStart  time.Time                   // начало текущего интервала кластеризации
+	prevClose float64                     // цена закрытия предыдущей свечи
+	breakouts map[*types.Series]*breakout // пробитые уровни в ожидании ретеста
+	touches   map[*types.Series]string    // уровни, которых коснулась цена, в ожидании отбоя: сторона сигнала
+}
+
+type breakout struct {
+	side string // сторона пробоя: buy — вверх, sell — вниз
+	bars int    // свечей после пробоя
+}
+
+// level — уровень, построенный по серии
+type level struct {
+	series   *types.Series
+	price    float64
+	volume   float64
+	age      time.Duration
+	strength float64
+}
+
+// NewLevelSignalProcessor создаёт процессор. Если repo равен nil, сигналы не сохраняются.
+func NewLevelSignalProcessor(repo repositories.LevelSignalRepository, comps ...settings.Settings) (*LevelSignalProcessor, error) {
+	p := &LevelSignalProcessor{repo: repo}
+	p.UpdateConfig(comps...)
+
+	if p.settings.ClusterInterval == "" || p.settings.Clusters <= 0 || p.settings.TouchTolerance <= 0 {
+		return nil, fmt.Errorf("level signal settings are not set")
+	}
+
+	if _, err := utils.IntervalDuration(p.settings.ClusterInterval); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// UpdateConfig implements settings.ConfigUpdate.
+func (p *LevelSignalProcessor) UpdateConfig(comps ...settings.Settings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.LevelSignalSettings); ok {
+			p.settings = *val
+			if p.settings.VolumeWeight == 0 {
+				p.settings.VolumeWeight = 0.5
+			}
+			if p.settings.RejectionMargin == 0 {
+				p.settings.RejectionMargin = 2 * p.settings.TouchTolerance
+			}
+			p.states = make(map[string]*levelState)
+		}
+	}
+}
+
+// Process implements pipeline.Processor.
+func (p *LevelSignalProcessor) Process(_ context.Context, payload pipeline.Payload) (pipeline.Payload, error) {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return nil, fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	md := tradingPayload.MarketData
+	if md == nil {
+		return payload, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := tradingPayload.Symbol + tradingPayload.Interval
+	state, exists := p.states[key]
+	if !exists {
+		builder, err := series.NewSeriesBuilder(map[string]interface{}{
+			"type":         string(series.SimpleAlgorithmType),
+			"value_factor": p.settings.ValueFactor,
+			"time_factor":  p.settings.TimeFactor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		state = &levelState{
+			builder:   builder,
+			breakouts: make(map[*types.Series]*breakout),
+			touches:   make(map[*types.Series]string),
+		}
+		p.states[key] = state
+	}
+
+	// закрытый интервал кластеризации дополняет серии до расчёта сигналов текущей свечи
+	start, _, _, err := utils.GetIntervalBounds(md.Timestamp, p.settings.ClusterInterval)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.pending) > 0 && !start.Equal(state.barStart) {
+		n := min(p.settings.Clusters, len(state.pending))
+		if clustered := clusters.ClusterMarketData(state.pending, p.settings.ClusterInterval, n); len(clustered) > 0 {
+			state.builder.AddClusteredData(clustered)
+		}
+		state.pending = state.pending[:0]
+	}
+	state.barStart = start
+	state.pending = append(state.pending, md)
+
+	if state.prevClose > 0 {
+		for _, signal := range p.evaluate(state, tradingPayload) {
+			if p.repo != nil {
+				if err := p.repo.SaveLevelSignal(signal); err != nil {
+					return nil, fmt.Errorf("failed to save level signal %s %s: %w", signal.Symbol, signal.Timestamp, err)
+				}
+			}
+
+			tradingPayload.Signals = append(tradingPayload.Signals, &types.Signal{
+				Source:   "level_signals",
+				Symbol:   signal.Symbol,
+				Side:     signal.Side,
+				Type:     types.OrderTypeMarket,
+				Price:    signal.Price,
+				Strength: signal.Strength,
+				Reason:   fmt.Sprintf("%s %.8g (distance %.4f%%)", signal.Kind, signal.Level, signal.Distance*100),
+				Time:     signal.Timestamp,
+			})
+		}
+	}
+	state.prevClose = md.ClosePrice
+
+	return payload, nil
+}
+
+// evaluate сравнивает свечу с активными уровнями
+func (p *LevelSignalProcessor) evaluate(state *levelState, payload *TradingPayload) []*types.LevelSignal {
+	md := payload.MarketData
+	levels := p.levels(state.builder.GetActiveSeries())
+
+	active := make(map[*types.Series]bool, len(levels))
+	var result []*types.LevelSignal
+	nearest := math.NaN()
+
+	for _, l := range levels {
+		active[l.series] = true
+		if distance := (md.ClosePrice - l.price) / l.price; math.IsNaN(nearest) || math.Abs(distance) < math.Abs(nearest) {
+			nearest = distance
+		}
+		if l.strength < p.settings.MinStrength {
+			continue
+		}
+
+		tolerance := l.price * p.settings.TouchTolerance
+		prev, price := state.prevClose, md.ClosePrice
+
+		var kind, side string
+		pending := state.breakouts[l.series]
+
+		switch {
+		case prev <= l.price && price > l.price:
+			kind, side = types.LevelBreakout, types.SideBuy
+			state.breakouts[l.series] = &breakout{side: side}
+			delete(state.touches, l.series)
+		case prev >= l.price && price < l.price:
+			kind, side = types.LevelBreakout, types.SideSell
+			state.breakouts[l.series] = &breakout{side: side}
+			delete(state.touches, l.series)
+		case pending != nil && pending.bars > 0 && pending.side == types.SideBuy && md.LowPrice <= l.price+tolerance:
+			kind, side = types.LevelRetest, types.SideBuy
+			delete(state.breakouts, l.series)
+		case pending != nil && pending.bars > 0 && pending.side == types.SideSell && md.HightPrice >= l.price-tolerance:
+			kind, side = types.LevelRetest, types.SideSell
+			delete(state.breakouts, l.series)
+		case pending == nil:
+			if kind, side = p.rejection(state, l, md, prev); kind == "" {
+				continue
+			}
+		default:
+			continue
+		}
+
+		result = append(result, &types.LevelSignal{
+			Symbol:    payload.Symbol,
+			TimeFrame: payload.Interval,
+			Timestamp: md.Timestamp,
+			Kind:      kind,
+			Side:      side,
+			Level:     l.price,
+			Price:     price,
+			Distance:  (price - l.price) / l.price,
+			Strength:  l.strength,
+			Volume:    l.volume,
+			Age:       int64(l.age.Seconds()),
+		})
+	}
+
+	// расстояние до ближайшего уровня доступно следующим этапам, например правилам на выражениях
+	if !math.IsNaN(nearest) {
+		payload.SetIndicator(ClusterLevelDistance, math.Abs(nearest))
+	}
+
+	// ретест ждём не дольше RetestBars свечей и только пока серия активна
+	for s, b := range state.breakouts {
+		b.bars++
+		if !active[s] || b.bars > p.settings.RetestBars {
+			delete(state.breakouts, s)
+		}
+	}
+	for s := range state.touches {
+		if !active[s] {
+			delete(state.touches, s)
+		}
+	}
+
+	return result
+}
+
+// rejection отмечает касание уровня без пробоя и возвращает отбой, когда цена закрытия отошла от уровня
+// на RejectionMargin в сторону, с которой пришла. После сигнала касание снимается: следующий отбой
+// возможен только после нового касания, то есть после возврата цены к уровню.
+func (p *LevelSignalProcessor) rejection(state *levelState, l level, md *types.MarketData, prev float64) (string, string) {
+	tolerance := l.price * p.settings.TouchTolerance
+
+	side, touched := state.touches[l.series]
+	if !touched {
+		switch {
+		case prev < l.price && md.HightPrice >= l.price-tolerance:
+			// касание сопротивления
+			side = types.SideSell
+		case prev > l.price && md.LowPrice <= l.price+tolerance:
+			// касание поддержки
+			side = types.SideBuy
+		default:
+			return "", ""
+		}
+		state.touches[l.series] = side
+	}
+
+	margin := l.price * p.settings.RejectionMargin
+	if side == types.SideSell && md.ClosePrice > l.price-margin || side == types.SideBuy && md.ClosePrice < l.price+margin {
+		return "", ""
+	}
+
+	delete(state.touches, l.series)
+	return types.LevelRejection, side
+}
+
+// levels рассчитывает уровни и их силу: долю объёма и возраста относительно самой объёмной и самой старой серии
+func (p *LevelSignalProcessor) levels(active []*types.Series) []level {
+	levels := make([]level, 0, len(active))
+	var maxVolume float64
+	var maxAge time.Duration
+
+	for _, s := range active {
+		first, last := s.First(), s.Last()
+		if last == nil || last.Value <= 0 {
+			continue
+		}
+
+		l := level{series: s, price: last.Value, age: last.Time.Sub(first.Time)}
+		for _, pt := range s.Points {
+			l.volume += pt.Weight
+		}
+
+		maxVolume = max(maxVolume, l.volume)
+		maxAge = max(maxAge, l.age)
+		levels = append(levels, l)
+	}
+
+	for i := range levels {
+		volumeScore, ageScore := 1.0, 1.0
+		if maxVolume > 0 {
+			volumeScore = levels[i].volume / maxVolume
+		}
+		if maxAge > 0 {
+			ageScore = float64(levels[i].age) / float64(maxAge)
+		}
+		levels[i].strength = p.settings.VolumeWeight*volumeScore + (1-p.settings.VolumeWeight)*ageScore
+	}
+
+	return levels
+}
@@ -0,0 +1,100 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newLevelTest создаёт процессор с уровнем около 100 и возвращает функцию обработки свечи и начало второго часа
+func newLevelTest(t *testing.T) (func(ts time.Time, low, high, close float64) []*types.Signal, time.Time) {
+	proc, err := NewLevelSignalProcessor(nil, &settings.LevelSignalSettings{
+		ClusterInterval: "1h",
+		Clusters:        1,
+		ValueFactor:     1,
+		TimeFactor:      0.0001,
+		TouchTolerance:  0.001,
+		RetestBars:      3,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	process := func(ts time.Time, low, high, close float64) []*types.Signal {
+		p := &TradingPayload{Symbol: "BTCUSDT", Interval: "1m", MarketData: &types.MarketData{
+			Timestamp: ts, OpenPrice: close, HightPrice: high, LowPrice: low, ClosePrice: close, Volume: 10,
+		}}
+		out, err := proc.Process(context.TODO(), p)
+		assert.NoError(t, err)
+		return out.(*TradingPayload).Signals
+	}
+
+	// первый час торгуется вокруг 100: уровень появится после закрытия часа
+	for i := 0; i < 60; i++ {
+		price := 99.0 + float64(i%2)*2
+		assert.Empty(t, process(start.Add(time.Duration(i)*time.Minute), price, price, price))
+	}
+
+	return process, start.Add(time.Hour)
+}
+
+func TestLevelSignalProcessor(t *testing.T) {
+	process, hour := newLevelTest(t)
+
+	// касание уровня сверху с закрытием выше — отбой от поддержки
+	signals := process(hour, 100.05, 101, 100.5)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.Contains(t, signals[0].Reason, types.LevelRejection)
+		assert.InDelta(t, 1.0, signals[0].Strength, 1e-9)
+	}
+
+	// закрытие ниже уровня — пробой вниз
+	signals = process(hour.Add(time.Minute), 99.4, 100.5, 99.5)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.Contains(t, signals[0].Reason, types.LevelBreakout)
+	}
+
+	// возврат к уровню снизу — ретест
+	signals = process(hour.Add(2*time.Minute), 99.5, 99.95, 99.6)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.Contains(t, signals[0].Reason, types.LevelRetest)
+	}
+
+	assert.Empty(t, process(hour.Add(3*time.Minute), 98.9, 99.2, 99))
+}
+
+func TestLevelRejectionHysteresis(t *testing.T) {
+	process, hour := newLevelTest(t)
+	bar := func(i int) time.Time { return hour.Add(time.Duration(i) * time.Minute) }
+
+	// цена держится у поддержки: касания есть, отхода нет
+	assert.Empty(t, process(bar(0), 100.05, 100.3, 100.15))
+	assert.Empty(t, process(bar(1), 100.02, 100.2, 100.1))
+	assert.Empty(t, process(bar(2), 100.05, 100.25, 100.12))
+
+	// отход от уровня после касания — один отбой
+	signals := process(bar(3), 100.1, 100.6, 100.5)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.Contains(t, signals[0].Reason, types.LevelRejection)
+	}
+
+	// цена остаётся выше уровня без нового касания
+	assert.Empty(t, process(bar(4), 100.3, 100.7, 100.6))
+	assert.Empty(t, process(bar(5), 100.4, 100.8, 100.7))
+
+	// новое касание и отход — новый отбой
+	assert.Empty(t, process(bar(6), 100.05, 100.6, 100.15))
+	signals = process(bar(7), 100.1, 100.6, 100.5)
+	if assert.Len(t, signals, 1) {
+		assert.Contains(t, signals[0].Reason, types.LevelRejection)
+	}
+}
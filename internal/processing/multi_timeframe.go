@@ -0,0 +1,130 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/internal/utils"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	_ pipeline.Processor    = (*MultiTimeframeProcessor)(nil)
+	_ settings.ConfigUpdate = (*MultiTimeframeProcessor)(nil)
+)
+
+// MultiTimeframeProcessor объединяет потоки нескольких интервалов одного символа.
+// Бары старших интервалов запоминаются и дальше по конвейеру не передаются.
+// На каждом баре базового интервала в payload.Timeframes добавляется последний закрытый бар
+// каждого старшего интервала. Бар считается закрытым, если его интервал закончился
+// не позже окончания текущего базового бара, поэтому данные из будущего в payload не попадают.
+type MultiTimeframeProcessor struct {
+	settings settings.MultiTimeframeSettings
+	mu       sync.Mutex
+	pending  map[string][]*types.MarketData // symbol+interval -> бары, ещё не закрытые относительно базового потока
+	closed   map[string]*types.MarketData   // symbol+interval -> последний закрытый бар
+}
+
+func NewMultiTimeframeProcessor(comps ...settings.Settings) (*MultiTimeframeProcessor, error) {
+	p := &MultiTimeframeProcessor{}
+	p.UpdateConfig(comps...)
+
+	if p.settings.BaseInterval == "" || len(p.settings.Intervals) == 0 {
+		return nil, fmt.Errorf("multi timeframe settings are not set")
+	}
+
+	for _, interval := range append([]string{p.settings.BaseInterval}, p.settings.Intervals...) {
+		if _, err := utils.IntervalDuration(interval); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// UpdateConfig implements settings.ConfigUpdate.
+func (p *MultiTimeframeProcessor) UpdateConfig(comps ...settings.Settings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.MultiTimeframeSettings); ok {
+			p.settings = *val
+			p.pending = make(map[string][]*types.MarketData)
+			p.closed = make(map[string]*types.MarketData)
+		}
+	}
+}
+
+// Process implements pipeline.Processor.
+func (p *MultiTimeframeProcessor) Process(_ context.Context, payload pipeline.Payload) (pipeline.Payload, error) {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return nil, fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	md := tradingPayload.MarketData
+	if md == nil {
+		return payload, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if tradingPayload.Interval != p.settings.BaseInterval {
+		if !slices.Contains(p.settings.Intervals, tradingPayload.Interval) {
+			return payload, nil
+		}
+		key := tradingPayload.Symbol + tradingPayload.Interval
+		p.pending[key] = append(p.pending[key], md)
+		// бар старшего интервала только запоминаем
+		return nil, nil
+	}
+
+	baseEnd, err := barEnd(md.Timestamp, p.settings.BaseInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, interval := range p.settings.Intervals {
+		key := tradingPayload.Symbol + interval
+
+		// переносим в закрытые все бары, чей интервал закончился к концу базового бара
+		pending := p.pending[key][:0]
+		for _, bar := range p.pending[key] {
+			end, err := barEnd(bar.Timestamp, interval)
+			if err != nil {
+				return nil, err
+			}
+			if end.After(baseEnd) {
+				pending = append(pending, bar)
+				continue
+			}
+			if last := p.closed[key]; last == nil || !bar.Timestamp.Before(last.Timestamp) {
+				p.closed[key] = bar
+			}
+		}
+		p.pending[key] = pending
+
+		if bar := p.closed[key]; bar != nil {
+			if tradingPayload.Timeframes == nil {
+				tradingPayload.Timeframes = make(map[string]*types.MarketData, len(p.settings.Intervals))
+			}
+			tradingPayload.Timeframes[interval] = bar
+		}
+	}
+
+	return payload, nil
+}
+
+// barEnd возвращает момент окончания бара, которому принадлежит отметка времени.
+// Отметка должна лежать внутри бара: время открытия или время закрытия в формате биржи
+// (открытие + интервал - 1 мс). Время закрытия, совпадающее с границей, относится к следующему бару.
+func barEnd(timestamp time.Time, interval string) (time.Time, error) {
+	_, _, nextStart, err := utils.GetIntervalBounds(timestamp, interval)
+	return nextStart, err
+}
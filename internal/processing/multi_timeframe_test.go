@@ -0,0 +1,64 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiTimeframeProcessor_NoLookAhead(t *testing.T) {
+	proc, err := NewMultiTimeframeProcessor(&settings.MultiTimeframeSettings{
+		BaseInterval: "1m",
+		Intervals:    []string{"1h"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T09:00:00Z")
+	process := func(interval string, ts time.Time) *TradingPayload {
+		p := &TradingPayload{Symbol: "BTCUSDT", Interval: interval, MarketData: &types.MarketData{Timestamp: ts}}
+		out, err := proc.Process(context.TODO(), p)
+		assert.NoError(t, err)
+		if out == nil {
+			return nil
+		}
+		return out.(*TradingPayload)
+	}
+
+	// часовой бар 09:00 приходит раньше минутных баров этого часа (время открытия бара)
+	assert.Nil(t, process("1h", start))
+
+	p := process("1m", start.Add(30*time.Minute))
+	assert.Empty(t, p.Timeframes, "часовой бар ещё не закрыт")
+
+	p = process("1m", start.Add(59*time.Minute))
+	if assert.Contains(t, p.Timeframes, "1h") {
+		assert.Equal(t, start, p.Timeframes["1h"].Timestamp)
+	}
+
+	// бар 10:00 не должен попасть в минутные бары 10-го часа
+	assert.Nil(t, process("1h", start.Add(time.Hour)))
+	p = process("1m", start.Add(time.Hour+5*time.Minute))
+	assert.Equal(t, start, p.Timeframes["1h"].Timestamp)
+}
+
+func TestBarEnd(t *testing.T) {
+	open, _ := time.Parse(time.RFC3339, "2025-01-05T09:00:00Z")
+	end := open.Add(time.Hour)
+
+	for _, ts := range []time.Time{open, open.Add(30 * time.Minute), end.Add(-time.Millisecond)} {
+		got, err := barEnd(ts, "1h")
+		assert.NoError(t, err)
+		assert.Equal(t, end, got, ts)
+	}
+
+	// отметка на границе — начало следующего бара
+	got, err := barEnd(end, "1h")
+	assert.NoError(t, err)
+	assert.Equal(t, end.Add(time.Hour), got)
+}
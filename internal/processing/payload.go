@@ -1,7 +1,11 @@
 package processing
 
 import (
+	"crypto-trading-bot/internal/service/volumeprofile"
+	"crypto-trading-bot/internal/types"
 	"crypto-trading-bot/pkg/pipeline"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 )
@@ -23,6 +27,15 @@ type TradingPayload struct {
 	EndTime   time.Time
 	// Этап выборки данных
 	CurrentPrice float64
+	MarketData   *types.MarketData // текущая свеча
+	// Этап анализа
+	Indicators    map[string]float64           // значения индикаторов, прошедших период прогрева
+	VolumeProfile *volumeprofile.Profile       // профиль объёма по скользящему окну
+	Footprint     *volumeprofile.FootprintBar  // завершённый на этой свече бар footprint
+	Timeframes    map[string]*types.MarketData // interval -> последний закрытый бар старшего интервала
+	Flags         []string                     // нарушенные правила проверки данных с действием flag
+	// Этап принятия решений
+	Signals []*types.Signal // сигналы источников на этой свече
 }
 
 // Реализация интерфейса pipeline.Payload
@@ -30,11 +43,30 @@ type TradingPayload struct {
 // Clone implements pipeline.Payload.
 func (p *TradingPayload) Clone() pipeline.Payload {
 	newP := PayloadPool.Get().(*TradingPayload)
+	newP.Symbol = p.Symbol
+	newP.Interval = p.Interval
+	newP.StartTime = p.StartTime
+	newP.EndTime = p.EndTime
 	newP.CurrentPrice = p.CurrentPrice
+	newP.MarketData = p.MarketData
+	newP.Indicators = maps.Clone(p.Indicators)
+	newP.VolumeProfile = p.VolumeProfile
+	newP.Footprint = p.Footprint
+	newP.Timeframes = maps.Clone(p.Timeframes)
+	newP.Flags = slices.Clone(p.Flags)
+	newP.Signals = slices.Clone(p.Signals)
 
 	return newP
 }
 
+// SetIndicator сохраняет значение индикатора в payload
+func (p *TradingPayload) SetIndicator(name string, value float64) {
+	if p.Indicators == nil {
+		p.Indicators = make(map[string]float64)
+	}
+	p.Indicators[name] = value
+}
+
 // MarkAsProcessed implements pipeline.Payload
 func (p *TradingPayload) MarkAsProcessed() {
 	// Очистка
@@ -43,5 +75,12 @@ func (p *TradingPayload) MarkAsProcessed() {
 	p.StartTime = time.Time{}
 	p.EndTime = time.Time{}
 	p.CurrentPrice = 0
+	p.MarketData = nil
+	p.Indicators = nil
+	p.VolumeProfile = nil
+	p.Footprint = nil
+	p.Timeframes = nil
+	p.Flags = nil
+	p.Signals = nil
 	PayloadPool.Put(p)
 }
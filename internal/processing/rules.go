@@ -0,0 +1,187 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/dsl"
+	"crypto-trading-bot/internal/indicators"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	_ pipeline.Processor    = (*RuleProcessor)(nil)
+	_ settings.ConfigUpdate = (*RuleProcessor)(nil)
+	_ dsl.Env               = payloadEnv{}
+)
+
+// RuleProcessor вычисляет правила входа и выхода, записанные выражениями, на каждой свече символа.
+// Без позиции проверяется правило входа, в позиции — правило выхода.
+// Правила могут ссылаться только на значения payload.Indicators процессоров, настройки которых
+// переданы вместе с правилами: IndicatorSettings — индикаторы, LevelSignalSettings — cluster_level_dist.
+// Сигналы добавляются в payload.Signals.
+type RuleProcessor struct {
+	settings settings.RuleSettings
+	mu       sync.Mutex
+	vars     []string // значения индикаторов из IndicatorSettings
+	levels   bool     // переданы LevelSignalSettings
+	entry    *dsl.Program
+	exit     *dsl.Program
+	position bool // позиция открыта по сигналу входа
+}
+
+func NewRuleProcessor(comps ...settings.Settings) (*RuleProcessor, error) {
+	p := &RuleProcessor{}
+	p.UpdateConfig(comps...)
+
+	if p.settings.Entry == "" {
+		return nil, fmt.Errorf("rule settings are not set")
+	}
+
+	// ошибки в правилах сообщаем при создании, а не на первой свече
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// UpdateConfig implements settings.ConfigUpdate.
+// Если новые правила не компилируются, продолжают работать прежние.
+func (p *RuleProcessor) UpdateConfig(comps ...settings.Settings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prev, prevVars, prevLevels := p.settings, p.vars, p.levels
+	changed := false
+	for _, c := range comps {
+		switch val := c.(type) {
+		case *settings.RuleSettings:
+			p.settings = *val
+			if p.settings.Side == "" {
+				p.settings.Side = types.SideBuy
+			}
+			changed = true
+		case *settings.IndicatorSettings:
+			p.vars = indicatorVars(val)
+			changed = true
+		case *settings.LevelSignalSettings:
+			p.levels = true
+			changed = true
+		}
+	}
+
+	if changed && p.entry != nil {
+		if err := p.compile(); err != nil {
+			p.settings, p.vars, p.levels = prev, prevVars, prevLevels
+		}
+	}
+}
+
+// indicatorVars возвращает имена значений, которые IndicatorProcessor добавляет в payload
+func indicatorVars(s *settings.IndicatorSettings) []string {
+	var vars []string
+	for _, spec := range s.Indicators {
+		ind, err := indicators.Parse(spec)
+		if err != nil {
+			continue
+		}
+		for name := range ind.Values() {
+			vars = append(vars, name)
+		}
+	}
+	return vars
+}
+
+func (p *RuleProcessor) compile() error {
+	vars := p.vars
+	if p.levels {
+		vars = append(slices.Clone(vars), ClusterLevelDistance)
+	}
+
+	entry, err := dsl.CompileCondition(p.settings.Entry, vars...)
+	if err != nil {
+		return fmt.Errorf("entry rule: %w", err)
+	}
+
+	var exit *dsl.Program
+	if p.settings.Exit != "" {
+		if exit, err = dsl.CompileCondition(p.settings.Exit, vars...); err != nil {
+			return fmt.Errorf("exit rule: %w", err)
+		}
+	}
+
+	p.entry, p.exit = entry, exit
+	return nil
+}
+
+// Process implements pipeline.Processor.
+func (p *RuleProcessor) Process(_ context.Context, payload pipeline.Payload) (pipeline.Payload, error) {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return nil, fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	md := tradingPayload.MarketData
+	if md == nil || tradingPayload.Symbol != p.settings.Symbol || tradingPayload.Interval != p.settings.Interval {
+		return payload, nil
+	}
+
+	p.entry.Update(md)
+	if p.exit != nil {
+		p.exit.Update(md)
+	}
+
+	env := payloadEnv{tradingPayload}
+	side, rule := "", ""
+	switch {
+	case !p.position && p.entry.Test(env):
+		side, rule = p.settings.Side, "entry: "+p.entry.String()
+		p.position = true
+	case p.position && p.exit != nil && p.exit.Test(env):
+		side, rule = oppositeSide(p.settings.Side), "exit: "+p.exit.String()
+		p.position = false
+	default:
+		return payload, nil
+	}
+
+	tradingPayload.Signals = append(tradingPayload.Signals, &types.Signal{
+		Source: "rules",
+		Symbol: tradingPayload.Symbol,
+		Side:   side,
+		Type:   types.OrderTypeMarket,
+		Price:  md.ClosePrice,
+		Amount: p.settings.Amount,
+		Reason: rule,
+		Time:   md.Timestamp,
+	})
+
+	return payload, nil
+}
+
+// payloadEnv даёт выражениям доступ к свече и индикаторам payload
+type payloadEnv struct {
+	payload *TradingPayload
+}
+
+func (e payloadEnv) MarketData() *types.MarketData {
+	return e.payload.MarketData
+}
+
+func (e payloadEnv) Lookup(name string) (float64, bool) {
+	value, ok := e.payload.Indicators[name]
+	return value, ok
+}
+
+func oppositeSide(side string) string {
+	if side == types.SideBuy {
+		return types.SideSell
+	}
+	return types.SideBuy
+}
@@ -0,0 +1,49 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleProcessor(t *testing.T) {
+	_, err := NewRuleProcessor(&settings.RuleSettings{Symbol: "BTCUSDT", Interval: "1m", Entry: "close >", Amount: 1})
+	assert.ErrorContains(t, err, "entry rule")
+
+	rules := &settings.RuleSettings{
+		Symbol:   "BTCUSDT",
+		Interval: "1m",
+		Entry:    "close < 100 and obv > 2",
+		Exit:     "close > 110",
+		Amount:   1,
+	}
+	// значение не объявлено: процессор индикаторов не настроен
+	_, err = NewRuleProcessor(rules)
+	assert.ErrorContains(t, err, "unknown identifier \"obv\"")
+
+	proc, err := NewRuleProcessor(rules, &settings.IndicatorSettings{Indicators: []string{"obv"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	process := func(price float64, ratio float64) []*types.Signal {
+		p := &TradingPayload{Symbol: "BTCUSDT", Interval: "1m", MarketData: &types.MarketData{ClosePrice: price}}
+		p.SetIndicator("obv", ratio)
+		out, err := proc.Process(context.TODO(), p)
+		assert.NoError(t, err)
+		return out.(*TradingPayload).Signals
+	}
+
+	assert.Empty(t, process(95, 1))
+	if signals := process(95, 3); assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+	}
+	// в позиции правило входа не проверяется
+	assert.Empty(t, process(90, 3))
+	if signals := process(111, 1); assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+	}
+}
@@ -23,6 +23,7 @@ func NewHistoricalSource(marketDataService marketdata.MarketDataService, comps .
 
 	s := &HistoricalSource{
 		marketDataService: &marketDataService,
+		index:             -1,
 	}
 
 	s.UpdateConfig(comps...)
@@ -37,6 +38,19 @@ func NewHistoricalSource(marketDataService marketdata.MarketDataService, comps .
 	return s, err
 }
 
+// NewHistoricalSourceFromData создаёт источник по уже загруженным свечам.
+// Свечи не копируются: несколько источников могут читать один срез одновременно.
+func NewHistoricalSourceFromData(data []*types.MarketData, comps ...settings.Settings) *HistoricalSource {
+	s := &HistoricalSource{
+		data:  data,
+		index: -1,
+	}
+
+	s.UpdateConfig(comps...)
+
+	return s
+}
+
 func (s *HistoricalSource) UpdateConfig(comps ...settings.Settings) {
 	for _, c := range comps {
 		if val, ok := c.(*settings.HistoricalSourceSettings); ok {
@@ -48,7 +62,7 @@ func (s *HistoricalSource) UpdateConfig(comps ...settings.Settings) {
 func (s *HistoricalSource) Next(context.Context) bool {
 	//fmt.Printf("data: %d\n", len(s.data))
 
-	if s.index == len(s.data) {
+	if s.index >= len(s.data)-1 {
 		return false
 	}
 
@@ -65,7 +79,10 @@ func (s *HistoricalSource) Error() error {
 func (s *HistoricalSource) Payload() pipeline.Payload {
 
 	p := processing.PayloadPool.Get().(*processing.TradingPayload)
+	p.Symbol = s.settings.Symbol
+	p.Interval = s.settings.Interval
 	p.CurrentPrice = s.data[s.index].ClosePrice
+	p.MarketData = s.data[s.index]
 
 	return p
 }
@@ -0,0 +1,73 @@
+package sampling
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/pkg/pipeline"
+	"sort"
+)
+
+var _ pipeline.Source = (*MultiSymbolSource)(nil)
+
+// MultiSymbolSource выдаёт исторические свечи нескольких символов одного интервала
+// единым потоком в порядке времени. Свечи с одинаковым временем идут подряд, упорядоченные по символу.
+type MultiSymbolSource struct {
+	interval string
+	items    []msItem
+	index    int
+}
+
+type msItem struct {
+	symbol string
+	data   *types.MarketData
+}
+
+// NewMultiSymbolSourceFromData создаёт источник по уже загруженным данным: symbol -> свечи
+func NewMultiSymbolSourceFromData(interval string, data map[string][]*types.MarketData) *MultiSymbolSource {
+	s := &MultiSymbolSource{
+		interval: interval,
+		index:    -1,
+	}
+
+	for symbol, list := range data {
+		for _, md := range list {
+			s.items = append(s.items, msItem{symbol: symbol, data: md})
+		}
+	}
+
+	sort.SliceStable(s.items, func(i, j int) bool {
+		a, b := s.items[i], s.items[j]
+		if !a.data.Timestamp.Equal(b.data.Timestamp) {
+			return a.data.Timestamp.Before(b.data.Timestamp)
+		}
+		return a.symbol < b.symbol
+	})
+
+	return s
+}
+
+func (s *MultiSymbolSource) Next(context.Context) bool {
+	if s.index == len(s.items)-1 {
+		return false
+	}
+
+	s.index++
+	return true
+}
+
+func (s *MultiSymbolSource) Error() error {
+	return nil
+}
+
+func (s *MultiSymbolSource) Payload() pipeline.Payload {
+	item := s.items[s.index]
+
+	p := processing.PayloadPool.Get().(*processing.TradingPayload)
+	p.Symbol = item.symbol
+	p.Interval = s.interval
+	p.CurrentPrice = item.data.ClosePrice
+	p.MarketData = item.data
+
+	return p
+}
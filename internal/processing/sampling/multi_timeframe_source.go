@@ -0,0 +1,95 @@
+package sampling
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/service/marketdata"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/internal/utils"
+	"crypto-trading-bot/pkg/pipeline"
+	"sort"
+	"time"
+)
+
+var _ pipeline.Source = (*MultiTimeframeSource)(nil)
+
+// MultiTimeframeSource выдаёт исторические свечи нескольких интервалов одного символа
+// единым потоком в порядке времени. При совпадении времени старший интервал идёт первым,
+// чтобы его закрытый бар был доступен базовому бару с тем же временем.
+type MultiTimeframeSource struct {
+	symbol string
+	items  []mtfItem
+	index  int
+}
+
+type mtfItem struct {
+	interval string
+	duration time.Duration
+	data     *types.MarketData
+}
+
+func NewMultiTimeframeSource(marketDataService marketdata.MarketDataService, symbol string, intervals []string, start time.Time, end time.Time) (*MultiTimeframeSource, error) {
+	data := make(map[string][]*types.MarketData, len(intervals))
+	for _, interval := range intervals {
+		md, err := marketDataService.GetMarketDataPeriod(symbol, interval, start, end)
+		if err != nil {
+			return nil, err
+		}
+		data[interval] = md
+	}
+
+	return NewMultiTimeframeSourceFromData(symbol, data)
+}
+
+// NewMultiTimeframeSourceFromData создаёт источник по уже загруженным данным: interval -> свечи
+func NewMultiTimeframeSourceFromData(symbol string, data map[string][]*types.MarketData) (*MultiTimeframeSource, error) {
+	s := &MultiTimeframeSource{
+		symbol: symbol,
+		index:  -1,
+	}
+
+	for interval, list := range data {
+		duration, err := utils.IntervalDuration(interval)
+		if err != nil {
+			return nil, err
+		}
+		for _, md := range list {
+			s.items = append(s.items, mtfItem{interval: interval, duration: duration, data: md})
+		}
+	}
+
+	sort.SliceStable(s.items, func(i, j int) bool {
+		a, b := s.items[i], s.items[j]
+		if !a.data.Timestamp.Equal(b.data.Timestamp) {
+			return a.data.Timestamp.Before(b.data.Timestamp)
+		}
+		return a.duration > b.duration
+	})
+
+	return s, nil
+}
+
+func (s *MultiTimeframeSource) Next(context.Context) bool {
+	if s.index == len(s.items)-1 {
+		return false
+	}
+
+	s.index++
+	return true
+}
+
+func (s *MultiTimeframeSource) Error() error {
+	return nil
+}
+
+func (s *MultiTimeframeSource) Payload() pipeline.Payload {
+	item := s.items[s.index]
+
+	p := processing.PayloadPool.Get().(*processing.TradingPayload)
+	p.Symbol = s.symbol
+	p.Interval = item.interval
+	p.CurrentPrice = item.data.ClosePrice
+	p.MarketData = item.data
+
+	return p
+}
@@ -0,0 +1,337 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	_ pipeline.Processor    = (*ValidationProcessor)(nil)
+	_ settings.ConfigUpdate = (*ValidationProcessor)(nil)
+)
+
+// Правила проверки качества данных
+const (
+	RuleOHLC       = "ohlc"
+	RuleOutlier    = "outlier"
+	RuleDuplicate  = "duplicate"
+	RuleOutOfOrder = "out_of_order"
+	RuleStale      = "stale"
+)
+
+// ValidationProcessor проверяет свечи из payload до кластеризации и построения рядов.
+// Для каждого нарушенного правила выполняется заданное действие: свеча отбрасывается,
+// исправляется или передаётся дальше с отметкой в payload.Flags.
+// Все нарушения сохраняются в market_data_violations.
+type ValidationProcessor struct {
+	settings settings.ValidationSettings
+	repo     repositories.MarketDataViolationRepository
+	mu       sync.Mutex
+	states   map[string]*validationState // symbol+interval -> состояние проверки
+}
+
+type validationState struct {
+	last    *types.MarketData // последняя принятая свеча
+	returns []float64         // логарифмические доходности последних свечей
+	volumes []float64         // объёмы последних свечей
+	repeats int               // количество свечей подряд с ценами предыдущей свечи
+}
+
+// NewValidationProcessor создаёт процессор. Если repo равен nil, нарушения не сохраняются.
+func NewValidationProcessor(repo repositories.MarketDataViolationRepository, comps ...settings.Settings) (*ValidationProcessor, error) {
+	p := &ValidationProcessor{repo: repo}
+	p.UpdateConfig(comps...)
+
+	s := p.settings
+	if s.OHLC == nil && s.Outlier == nil && s.Duplicate == nil && s.Stale == nil {
+		return nil, fmt.Errorf("validation settings are not set")
+	}
+
+	return p, nil
+}
+
+// UpdateConfig implements settings.ConfigUpdate.
+func (p *ValidationProcessor) UpdateConfig(comps ...settings.Settings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.ValidationSettings); ok {
+			p.settings = *val
+			p.states = make(map[string]*validationState)
+		}
+	}
+}
+
+// Process implements pipeline.Processor.
+func (p *ValidationProcessor) Process(_ context.Context, payload pipeline.Payload) (pipeline.Payload, error) {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return nil, fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	md := tradingPayload.MarketData
+	if md == nil {
+		return payload, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := tradingPayload.Symbol + tradingPayload.Interval
+	state, exists := p.states[key]
+	if !exists {
+		state = &validationState{}
+		p.states[key] = state
+	}
+
+	var violations []*types.MarketDataViolation
+	report := func(rule string, action string, details string) {
+		violations = append(violations, &types.MarketDataViolation{
+			Symbol:    tradingPayload.Symbol,
+			TimeFrame: tradingPayload.Interval,
+			Timestamp: md.Timestamp,
+			Rule:      rule,
+			Action:    action,
+			Details:   details,
+		})
+		if action == settings.ValidationActionFlag {
+			tradingPayload.Flags = append(tradingPayload.Flags, rule)
+		}
+	}
+
+	drop, accept := false, true
+
+	// повторы и нарушение порядка времени
+	if rule := p.settings.Duplicate; rule != nil && state.last != nil && !md.Timestamp.After(state.last.Timestamp) {
+		name := RuleDuplicate
+		if md.Timestamp.Before(state.last.Timestamp) {
+			name = RuleOutOfOrder
+		}
+		report(name, rule.Action, fmt.Sprintf("previous candle at %s", state.last.Timestamp))
+		drop = rule.Action == settings.ValidationActionDrop
+		// свеча не в своём месте ряда не должна влиять на статистику
+		accept = false
+	}
+
+	// согласованность OHLC
+	if rule := p.settings.OHLC; rule != nil && !drop {
+		if problems := checkOHLC(md); len(problems) > 0 {
+			action := rule.Action
+			if action == settings.ValidationActionRepair {
+				if repaired := repairOHLC(md, state.last); repaired != nil {
+					md = repaired
+				} else {
+					// исправить нечем: все цены нулевые и предыдущей свечи нет
+					action = settings.ValidationActionDrop
+				}
+			}
+			report(RuleOHLC, action, strings.Join(problems, "; "))
+			drop = action == settings.ValidationActionDrop
+		}
+	}
+
+	// выбросы цены и объёма
+	if rule := p.settings.Outlier; rule != nil && !drop && state.last != nil && state.last.ClosePrice > 0 && md.ClosePrice > 0 {
+		ret := math.Log(md.ClosePrice / state.last.ClosePrice)
+		if score, outlier := outlierScore(rule, state.returns, ret); outlier {
+			report(RuleOutlier, rule.Action, fmt.Sprintf("price return %.6f, score %.2f", ret, score))
+			switch rule.Action {
+			case settings.ValidationActionDrop:
+				drop = true
+			case settings.ValidationActionRepair:
+				// цена выброса заменяется ценой закрытия предыдущей свечи
+				fixed := *md
+				price := state.last.ClosePrice
+				fixed.OpenPrice, fixed.HightPrice, fixed.LowPrice, fixed.ClosePrice = price, price, price, price
+				md = &fixed
+				ret = 0
+			}
+		}
+
+		if score, outlier := outlierScore(rule, state.volumes, md.Volume); outlier && !drop {
+			report(RuleOutlier, rule.Action, fmt.Sprintf("volume %.6f, score %.2f", md.Volume, score))
+			switch rule.Action {
+			case settings.ValidationActionDrop:
+				drop = true
+			case settings.ValidationActionRepair:
+				// объём выброса заменяется медианой окна с сохранением доли покупок и продаж
+				fixed := *md
+				fixed.Volume = median(state.volumes)
+				if md.Volume > 0 {
+					ratio := fixed.Volume / md.Volume
+					fixed.BuyVolume *= ratio
+					fixed.SellVolume *= ratio
+				}
+				md = &fixed
+			}
+		}
+
+		if !drop && accept {
+			state.returns = pushWindow(state.returns, ret, rule.Window)
+		}
+	}
+
+	// цена не меняется
+	if rule := p.settings.Stale; rule != nil && !drop && accept && state.last != nil {
+		if sameOHLC(md, state.last) {
+			state.repeats++
+		} else {
+			state.repeats = 0
+		}
+		if state.repeats >= rule.MaxRepeats {
+			report(RuleStale, rule.Action, fmt.Sprintf("price %v unchanged for %d candles", md.ClosePrice, state.repeats+1))
+			drop = rule.Action == settings.ValidationActionDrop
+		}
+	}
+
+	for _, v := range violations {
+		if p.repo == nil {
+			break
+		}
+		if err := p.repo.SaveMarketDataViolation(v); err != nil {
+			return nil, fmt.Errorf("failed to save violation %s %s %s: %w", v.Symbol, v.Rule, v.Timestamp, err)
+		}
+	}
+
+	if drop {
+		return nil, nil
+	}
+
+	if accept {
+		state.last = md
+		if rule := p.settings.Outlier; rule != nil {
+			state.volumes = pushWindow(state.volumes, md.Volume, rule.Window)
+		}
+	}
+
+	tradingPayload.MarketData = md
+	tradingPayload.CurrentPrice = md.ClosePrice
+
+	return payload, nil
+}
+
+// checkOHLC возвращает список нарушений согласованности цен и объёмов свечи
+func checkOHLC(md *types.MarketData) []string {
+	var problems []string
+	if md.OpenPrice <= 0 || md.HightPrice <= 0 || md.LowPrice <= 0 || md.ClosePrice <= 0 {
+		problems = append(problems, "non-positive price")
+	}
+	if md.HightPrice < md.LowPrice {
+		problems = append(problems, "high < low")
+	}
+	if md.OpenPrice < md.LowPrice || md.OpenPrice > md.HightPrice {
+		problems = append(problems, "open outside [low, high]")
+	}
+	if md.ClosePrice < md.LowPrice || md.ClosePrice > md.HightPrice {
+		problems = append(problems, "close outside [low, high]")
+	}
+	if md.Volume < 0 || md.BuyVolume < 0 || md.SellVolume < 0 {
+		problems = append(problems, "negative volume")
+	}
+	return problems
+}
+
+// repairOHLC возвращает исправленную копию свечи: нулевые цены заменяются ценой закрытия
+// предыдущей свечи (или любой положительной ценой этой свечи), High и Low расширяются до Open и Close.
+func repairOHLC(md *types.MarketData, last *types.MarketData) *types.MarketData {
+	var ref float64
+	if last != nil && last.ClosePrice > 0 {
+		ref = last.ClosePrice
+	} else {
+		for _, price := range []float64{md.ClosePrice, md.OpenPrice, md.HightPrice, md.LowPrice} {
+			if price > 0 {
+				ref = price
+				break
+			}
+		}
+	}
+	if ref == 0 {
+		return nil
+	}
+
+	fixed := *md
+	for _, price := range []*float64{&fixed.OpenPrice, &fixed.HightPrice, &fixed.LowPrice, &fixed.ClosePrice} {
+		if *price <= 0 {
+			*price = ref
+		}
+	}
+	fixed.HightPrice, fixed.LowPrice =
+		max(fixed.OpenPrice, fixed.HightPrice, fixed.LowPrice, fixed.ClosePrice),
+		min(fixed.OpenPrice, fixed.HightPrice, fixed.LowPrice, fixed.ClosePrice)
+	fixed.Volume = max(fixed.Volume, 0)
+	fixed.BuyVolume = max(fixed.BuyVolume, 0)
+	fixed.SellVolume = max(fixed.SellVolume, 0)
+
+	return &fixed
+}
+
+func sameOHLC(a *types.MarketData, b *types.MarketData) bool {
+	return a.OpenPrice == b.OpenPrice && a.HightPrice == b.HightPrice &&
+		a.LowPrice == b.LowPrice && a.ClosePrice == b.ClosePrice
+}
+
+// outlierScore рассчитывает отклонение значения от окна и признак выброса.
+// Пока окно не заполнено или разброс в окне нулевой, выбросы не определяются.
+func outlierScore(rule *settings.OutlierRule, window []float64, value float64) (float64, bool) {
+	if len(window) < rule.Window {
+		return 0, false
+	}
+
+	var center, scale float64
+	switch rule.Method {
+	case "mad":
+		center = median(window)
+		deviations := make([]float64, len(window))
+		for i, v := range window {
+			deviations[i] = math.Abs(v - center)
+		}
+		// 1.4826 приводит MAD к стандартному отклонению нормального распределения
+		scale = 1.4826 * median(deviations)
+	default:
+		for _, v := range window {
+			center += v
+		}
+		center /= float64(len(window))
+		for _, v := range window {
+			scale += (v - center) * (v - center)
+		}
+		scale = math.Sqrt(scale / float64(len(window)))
+	}
+
+	if scale == 0 {
+		return 0, false
+	}
+
+	score := math.Abs(value-center) / scale
+	return score, score > rule.Threshold
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func pushWindow(window []float64, value float64, size int) []float64 {
+	window = append(window, value)
+	if len(window) > size {
+		window = window[len(window)-size:]
+	}
+	return window
+}
@@ -0,0 +1,75 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationProcessor(t *testing.T) {
+	proc, err := NewValidationProcessor(nil, &settings.ValidationSettings{
+		OHLC:      &settings.OHLCRule{Action: settings.ValidationActionRepair},
+		Outlier:   &settings.OutlierRule{Action: settings.ValidationActionFlag, Method: "mad", Window: 5, Threshold: 5},
+		Duplicate: &settings.DuplicateRule{Action: settings.ValidationActionDrop},
+		Stale:     &settings.StaleRule{Action: settings.ValidationActionFlag, MaxRepeats: 3},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	process := func(minute int, o, h, l, c, v float64) *TradingPayload {
+		p := &TradingPayload{Symbol: "BTCUSDT", Interval: "1m", MarketData: &types.MarketData{
+			Timestamp: start.Add(time.Duration(minute) * time.Minute),
+			OpenPrice: o, HightPrice: h, LowPrice: l, ClosePrice: c, Volume: v,
+		}}
+		out, err := proc.Process(context.TODO(), p)
+		assert.NoError(t, err)
+		if out == nil {
+			return nil
+		}
+		return out.(*TradingPayload)
+	}
+
+	for i := 0; i < 6; i++ {
+		price := 100 + float64(i%3)
+		p := process(i, price, price+1, price-1, price, 10+float64(i%2))
+		assert.Empty(t, p.Flags)
+	}
+
+	// повтор свечи отбрасывается
+	assert.Nil(t, process(5, 100, 101, 99, 100, 10))
+
+	// Close выше High исправляется расширением High
+	p := process(6, 100, 101, 99, 103, 10)
+	assert.Equal(t, 103.0, p.MarketData.HightPrice)
+	assert.Equal(t, 103.0, p.CurrentPrice)
+
+	// скачок цены отмечается
+	p = process(7, 103, 200, 103, 200, 11)
+	assert.Contains(t, p.Flags, RuleOutlier)
+
+	// цена не меняется четыре свечи подряд
+	for i := 8; i < 11; i++ {
+		assert.NotContains(t, process(i, 200, 200, 200, 200, 10).Flags, RuleStale)
+	}
+	assert.Contains(t, process(11, 200, 200, 200, 200, 10).Flags, RuleStale)
+}
+
+func TestRepairOHLC(t *testing.T) {
+	last := &types.MarketData{ClosePrice: 50}
+	fixed := repairOHLC(&types.MarketData{OpenPrice: 0, HightPrice: 48, LowPrice: 52, ClosePrice: 49, Volume: -1}, last)
+	if assert.NotNil(t, fixed) {
+		assert.Equal(t, 50.0, fixed.OpenPrice)
+		assert.Equal(t, 52.0, fixed.HightPrice)
+		assert.Equal(t, 48.0, fixed.LowPrice)
+		assert.Equal(t, 0.0, fixed.Volume)
+		assert.Empty(t, checkOHLC(fixed))
+	}
+
+	assert.Nil(t, repairOHLC(&types.MarketData{}, nil))
+}
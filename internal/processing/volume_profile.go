@@ -0,0 +1,116 @@
+package processing
+
+import (
+	"context"
+	"crypto-trading-bot/internal/service/volumeprofile"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/pkg/pipeline"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	_ pipeline.Processor    = (*VolumeProfileProcessor)(nil)
+	_ settings.ConfigUpdate = (*VolumeProfileProcessor)(nil)
+)
+
+// VolumeProfileProcessor строит по каждому символу скользящий профиль объёма и бары footprint.
+// Профиль добавляется в payload на каждой свече, бар footprint — когда он завершён.
+type VolumeProfileProcessor struct {
+	settings   settings.VolumeProfileSettings
+	mu         sync.Mutex
+	profiles   map[string]*volumeprofile.RollingProfile
+	footprints map[string]*volumeprofile.FootprintBuilder
+}
+
+func NewVolumeProfileProcessor(comps ...settings.Settings) (*VolumeProfileProcessor, error) {
+	p := &VolumeProfileProcessor{}
+	p.UpdateConfig(comps...)
+
+	if p.settings.TickSize <= 0 || p.settings.Window <= 0 || p.settings.FootprintInterval == "" {
+		return nil, fmt.Errorf("volume profile settings are not set")
+	}
+
+	return p, nil
+}
+
+// UpdateConfig implements settings.ConfigUpdate.
+func (p *VolumeProfileProcessor) UpdateConfig(comps ...settings.Settings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.VolumeProfileSettings); ok {
+			p.settings = *val
+			if p.settings.ValueAreaPercent == 0 {
+				p.settings.ValueAreaPercent = 0.7
+			}
+			p.profiles = make(map[string]*volumeprofile.RollingProfile)
+			p.footprints = make(map[string]*volumeprofile.FootprintBuilder)
+		}
+	}
+}
+
+// Process implements pipeline.Processor.
+func (p *VolumeProfileProcessor) Process(_ context.Context, payload pipeline.Payload) (pipeline.Payload, error) {
+	tradingPayload, ok := payload.(*TradingPayload)
+	if !ok {
+		return nil, fmt.Errorf("invalid payload type: %T", payload)
+	}
+
+	md := tradingPayload.MarketData
+	if md == nil {
+		return payload, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := tradingPayload.Symbol + tradingPayload.Interval
+
+	profile, exists := p.profiles[key]
+	if !exists {
+		opts := volumeprofile.DefaultOptions(p.settings.TickSize)
+		opts.ValueAreaPercent = p.settings.ValueAreaPercent
+		profile = volumeprofile.NewRollingProfile(p.settings.Window, opts)
+		p.profiles[key] = profile
+	}
+	profile.Add(md)
+	tradingPayload.VolumeProfile = profile.Profile()
+
+	footprint, exists := p.footprints[key]
+	if !exists {
+		footprint = volumeprofile.NewFootprintBuilder(p.settings.FootprintInterval, p.settings.TickSize)
+		p.footprints[key] = footprint
+	}
+	bar, err := footprint.Add(md)
+	if err != nil {
+		return nil, err
+	}
+	tradingPayload.Footprint = bar
+
+	return payload, nil
+}
+
+// Flush завершает строящиеся бары footprint всех символов, например когда источник исчерпан.
+// Бары возвращаются в порядке символа и интервала.
+func (p *VolumeProfileProcessor) Flush() []*volumeprofile.FootprintBar {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]string, 0, len(p.footprints))
+	for key := range p.footprints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var bars []*volumeprofile.FootprintBar
+	for _, key := range keys {
+		if bar := p.footprints[key].Flush(); bar != nil {
+			bars = append(bars, bar)
+		}
+	}
+
+	return bars
+}
@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+	"database/sql"
+	"errors"
+)
+
+type BacktestResultRepository interface {
+	SaveBacktestResult(result *types.BacktestResult) error
+	GetBacktestResult(id int) (*types.BacktestResult, error)
+	GetBacktestResults(strategyID int) ([]*types.BacktestResult, error)
+	SaveBacktestSeries(resultID int, equity []*types.BacktestEquity, trades []*types.BacktestTrade) error
+	GetBacktestEquity(resultID int) ([]*types.BacktestEquity, error)
+	GetBacktestTrades(resultID int) ([]*types.BacktestTrade, error)
+	SaveBacktestMonteCarlo(analyses []*types.BacktestMonteCarlo) error
+	GetBacktestMonteCarlo(resultID int) ([]*types.BacktestMonteCarlo, error)
+}
+
+type backtestResultRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewBacktestResultRepository(db *DB, logger *logger.Logger) BacktestResultRepository {
+	return &backtestResultRepository{db: db, logger: logger}
+}
+
+// SaveBacktestResult сохраняет итоги бэктеста
+func (r *backtestResultRepository) SaveBacktestResult(result *types.BacktestResult) error {
+	query := `
+        INSERT INTO backtest_results (strategy_id, start_time, end_time, initial_capital, final_capital,
+                                      total_profit, drawdown, successful_trades, failed_trades, config,
+                                      commission, spread_cost, slippage_cost, impact_cost, delayed_orders, average_latency)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query,
+		result.StrategyID,
+		result.StartTime,
+		result.EndTime,
+		result.InitialCapital,
+		result.FinalCapital,
+		result.TotalProfit,
+		result.Drawdown,
+		result.SuccessfulTrades,
+		result.FailedTrades,
+		string(result.Config),
+		result.Commission,
+		result.Spread,
+		result.Slippage,
+		result.Impact,
+		result.Delayed,
+		result.Latency,
+	).Scan(&result.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save backtest result of strategy %d: %v", result.StrategyID, err)
+		return err
+	}
+	return nil
+}
+
+// GetBacktestResult выбирает итоги бэктеста по ID. Если записи нет, возвращает nil.
+func (r *backtestResultRepository) GetBacktestResult(id int) (*types.BacktestResult, error) {
+	query := `
+        SELECT id, strategy_id, start_time, end_time, initial_capital, final_capital,
+               total_profit, drawdown, successful_trades, failed_trades, config,
+               commission, spread_cost, slippage_cost, impact_cost, delayed_orders, average_latency
+        FROM backtest_results
+        WHERE id = $1;
+    `
+
+	var result types.BacktestResult
+	err := r.db.Get(&result, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to get backtest result %d: %v", id, err)
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetBacktestResults выбирает итоги бэктестов стратегии, последние первыми
+func (r *backtestResultRepository) GetBacktestResults(strategyID int) ([]*types.BacktestResult, error) {
+	query := `
+        SELECT id, strategy_id, start_time, end_time, initial_capital, final_capital,
+               total_profit, drawdown, successful_trades, failed_trades, config,
+               commission, spread_cost, slippage_cost, impact_cost, delayed_orders, average_latency
+        FROM backtest_results
+        WHERE strategy_id = $1
+        ORDER BY id DESC;
+    `
+
+	var results []*types.BacktestResult
+	if err := r.db.Select(&results, query, strategyID); err != nil {
+		r.logger.Errorf("Failed to get backtest results of strategy %d: %v", strategyID, err)
+		return nil, err
+	}
+	return results, nil
+}
+
+// SaveBacktestSeries сохраняет кривую капитала и сделки прогона одной транзакцией
+func (r *backtestResultRepository) SaveBacktestSeries(resultID int, equity []*types.BacktestEquity, trades []*types.BacktestTrade) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+
+	for _, point := range equity {
+		_, err := tx.Exec("INSERT INTO backtest_equity (result_id, timestamp, equity) VALUES ($1, $2, $3)",
+			resultID, point.Timestamp, point.Equity)
+		if err != nil {
+			r.logger.Errorf("Failed to insert equity of backtest %d: %v", resultID, err)
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for _, trade := range trades {
+		_, err := tx.Exec(`
+            INSERT INTO backtest_trades (result_id, symbol, side, entry_time, exit_time, entry_price, exit_price, quantity, pnl)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			resultID, trade.Symbol, trade.Side, trade.EntryTime, trade.ExitTime,
+			trade.EntryPrice, trade.ExitPrice, trade.Quantity, trade.PnL)
+		if err != nil {
+			r.logger.Errorf("Failed to insert trade of backtest %d: %v", resultID, err)
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Errorf("Failed to commit transaction: %v", err)
+		tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+// GetBacktestEquity выбирает кривую капитала прогона по времени
+func (r *backtestResultRepository) GetBacktestEquity(resultID int) ([]*types.BacktestEquity, error) {
+	query := `
+        SELECT result_id, timestamp, equity
+        FROM backtest_equity
+        WHERE result_id = $1
+        ORDER BY timestamp;
+    `
+
+	var equity []*types.BacktestEquity
+	if err := r.db.Select(&equity, query, resultID); err != nil {
+		r.logger.Errorf("Failed to get equity of backtest %d: %v", resultID, err)
+		return nil, err
+	}
+	return equity, nil
+}
+
+// GetBacktestTrades выбирает сделки прогона в порядке закрытия
+func (r *backtestResultRepository) GetBacktestTrades(resultID int) ([]*types.BacktestTrade, error) {
+	query := `
+        SELECT result_id, symbol, side, entry_time, exit_time, entry_price, exit_price, quantity, pnl
+        FROM backtest_trades
+        WHERE result_id = $1
+        ORDER BY exit_time, id;
+    `
+
+	var trades []*types.BacktestTrade
+	if err := r.db.Select(&trades, query, resultID); err != nil {
+		r.logger.Errorf("Failed to get trades of backtest %d: %v", resultID, err)
+		return nil, err
+	}
+	return trades, nil
+}
+
+// SaveBacktestMonteCarlo сохраняет результаты Monte Carlo анализа прогона одной транзакцией
+func (r *backtestResultRepository) SaveBacktestMonteCarlo(analyses []*types.BacktestMonteCarlo) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+
+	for _, a := range analyses {
+		err := tx.QueryRow(`
+            INSERT INTO backtest_monte_carlo (result_id, method, simulations, seed, confidence, ruin_level, final_equity, max_drawdown, risk_of_ruin)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+            RETURNING id, created_at;`,
+			a.ResultID,
+			a.Method,
+			a.Simulations,
+			a.Seed,
+			a.Confidence,
+			a.RuinLevel,
+			string(a.FinalEquity),
+			string(a.MaxDrawdown),
+			string(a.RiskOfRuin),
+		).Scan(&a.ID, &a.CreatedAt)
+		if err != nil {
+			r.logger.Errorf("Failed to save monte carlo %s of backtest %d: %v", a.Method, a.ResultID, err)
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Errorf("Failed to commit transaction: %v", err)
+		tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+// GetBacktestMonteCarlo выбирает результаты Monte Carlo анализа прогона, последние первыми
+func (r *backtestResultRepository) GetBacktestMonteCarlo(resultID int) ([]*types.BacktestMonteCarlo, error) {
+	query := `
+        SELECT id, result_id, method, simulations, seed, confidence, ruin_level, final_equity, max_drawdown, risk_of_ruin, created_at
+        FROM backtest_monte_carlo
+        WHERE result_id = $1
+        ORDER BY id DESC;
+    `
+
+	var analyses []*types.BacktestMonteCarlo
+	if err := r.db.Select(&analyses, query, resultID); err != nil {
+		r.logger.Errorf("Failed to get monte carlo of backtest %d: %v", resultID, err)
+		return nil, err
+	}
+	return analyses, nil
+}
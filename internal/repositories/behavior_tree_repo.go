@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+	"database/sql"
+	"errors"
+)
+
+type BehaviorTreeRepository interface {
+	GetBehaviorTree(strategyID int) (*types.BehaviorTree, error)
+	SaveBehaviorTree(tree *types.BehaviorTree) error
+}
+
+type behaviorTreeRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewBehaviorTreeRepository(db *DB, logger *logger.Logger) BehaviorTreeRepository {
+	return &behaviorTreeRepository{db: db, logger: logger}
+}
+
+// GetBehaviorTree выбирает состояние дерева стратегии. Если состояние не сохранялось, возвращает nil.
+func (r *behaviorTreeRepository) GetBehaviorTree(strategyID int) (*types.BehaviorTree, error) {
+	query := `
+        SELECT id, strategy_id, state, last_executed
+        FROM behavior_trees
+        WHERE strategy_id = $1;
+    `
+
+	var tree types.BehaviorTree
+	err := r.db.Get(&tree, query, strategyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to get behavior tree of strategy %d: %v", strategyID, err)
+		return nil, err
+	}
+
+	return &tree, nil
+}
+
+// SaveBehaviorTree сохраняет состояние дерева стратегии.
+func (r *behaviorTreeRepository) SaveBehaviorTree(tree *types.BehaviorTree) error {
+	query := `
+        INSERT INTO behavior_trees (strategy_id, state, last_executed)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (strategy_id) DO UPDATE
+        SET state = EXCLUDED.state, last_executed = EXCLUDED.last_executed
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query, tree.StrategyID, string(tree.State), tree.LastExecuted).Scan(&tree.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save behavior tree of strategy %d: %v", tree.StrategyID, err)
+		return err
+	}
+	return nil
+}
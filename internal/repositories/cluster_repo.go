@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+	"time"
+)
+
+type ClusterDataRepository interface {
+	SaveClusterData(data []*types.ClusterData) error
+	GetClusterData(symbol string, timeFrame string, start time.Time, end time.Time) ([]*types.ClusterData, error)
+}
+
+type clusterDataRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewClusterDataRepository(db *DB, logger *logger.Logger) ClusterDataRepository {
+	return &clusterDataRepository{db: db, logger: logger}
+}
+
+// SaveClusterData сохраняет объёмы по ценовым уровням в базу данных.
+func (r *clusterDataRepository) SaveClusterData(data []*types.ClusterData) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO cluster_data (timestamp, symbol, time_frame, is_buysell, cluster_price, volume) VALUES ($1, $2, $3, $4, $5, $6)")
+	if err != nil {
+		r.logger.Errorf("Failed to prepare statement: %v", err)
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, d := range data {
+		_, err := stmt.Exec(d.Timestamp, d.Symbol, d.TimeFrame, d.IsBuySell, d.ClusterPrice, d.Volume)
+		if err != nil {
+			r.logger.Errorf("Failed to insert cluster data: %v", err)
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Errorf("Failed to commit transaction: %v", err)
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// GetClusterData выбирает объёмы по ценовым уровням за период.
+func (r *clusterDataRepository) GetClusterData(symbol string, timeFrame string, start time.Time, end time.Time) ([]*types.ClusterData, error) {
+	query := `
+        SELECT timestamp, symbol, time_frame, is_buysell, cluster_price, volume
+        FROM cluster_data
+        WHERE symbol = $1 AND time_frame = $2 AND timestamp >= $3 AND timestamp <= $4
+        ORDER BY timestamp ASC, cluster_price ASC;
+    `
+
+	var clusterData []*types.ClusterData
+	err := r.db.Select(&clusterData, query, symbol, timeFrame, start, end)
+	if err != nil {
+		r.logger.Errorf("Failed to get cluster data for symbol %s: %v", symbol, err)
+		return nil, err
+	}
+
+	return clusterData, nil
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+)
+
+type DCADealRepository interface {
+	GetDCADeals(strategyID int) ([]*types.DCADeal, error)
+	SaveDCADeal(deal *types.DCADeal) error
+}
+
+type dcaDealRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewDCADealRepository(db *DB, logger *logger.Logger) DCADealRepository {
+	return &dcaDealRepository{db: db, logger: logger}
+}
+
+// GetDCADeals выбирает сохранённое состояние сделок стратегии
+func (r *dcaDealRepository) GetDCADeals(strategyID int) ([]*types.DCADeal, error) {
+	query := `
+        SELECT id, strategy_id, deal_id, trailing_high, updated_at
+        FROM dca_deals
+        WHERE strategy_id = $1
+        ORDER BY id;
+    `
+
+	var deals []*types.DCADeal
+	if err := r.db.Select(&deals, query, strategyID); err != nil {
+		r.logger.Errorf("Failed to get dca deals of strategy %d: %v", strategyID, err)
+		return nil, err
+	}
+
+	return deals, nil
+}
+
+// SaveDCADeal сохраняет состояние сделки
+func (r *dcaDealRepository) SaveDCADeal(deal *types.DCADeal) error {
+	query := `
+        INSERT INTO dca_deals (strategy_id, deal_id, trailing_high, updated_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (strategy_id, deal_id) DO UPDATE
+        SET trailing_high = EXCLUDED.trailing_high, updated_at = EXCLUDED.updated_at
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query,
+		deal.StrategyID,
+		deal.DealID,
+		deal.TrailingHigh,
+		deal.UpdatedAt,
+	).Scan(&deal.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save dca deal %s of strategy %d: %v", deal.DealID, deal.StrategyID, err)
+		return err
+	}
+	return nil
+}
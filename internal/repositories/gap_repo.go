@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+)
+
+type MarketDataGapRepository interface {
+	SaveMarketDataGap(gap *types.MarketDataGap) error
+	GetMarketDataGapList(status string) ([]*types.MarketDataGap, error)
+	UpdateMarketDataGapStatus(id int, status string) error
+}
+
+type marketDataGapRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewMarketDataGapRepository(db *DB, logger *logger.Logger) MarketDataGapRepository {
+	return &marketDataGapRepository{db: db, logger: logger}
+}
+
+// SaveMarketDataGap сохраняет пропуск. Повторно обнаруженный пропуск обновляется.
+func (r *marketDataGapRepository) SaveMarketDataGap(gap *types.MarketDataGap) error {
+	query := `
+        INSERT INTO market_data_gaps (exchange, symbol, time_frame, gap_start, gap_end, missing, status)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (exchange, symbol, time_frame, gap_start) DO UPDATE
+        SET gap_end = EXCLUDED.gap_end, missing = EXCLUDED.missing, status = EXCLUDED.status, detected_at = CURRENT_TIMESTAMP
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query,
+		gap.Exchange,
+		gap.Symbol,
+		gap.TimeFrame,
+		gap.GapStart,
+		gap.GapEnd,
+		gap.Missing,
+		gap.Status,
+	).Scan(&gap.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save market data gap: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetMarketDataGapList выбирает пропуски с указанным статусом (все, если статус пустой).
+func (r *marketDataGapRepository) GetMarketDataGapList(status string) ([]*types.MarketDataGap, error) {
+	query := `
+        SELECT id, exchange, symbol, time_frame, gap_start, gap_end, missing, status, detected_at
+        FROM market_data_gaps
+        WHERE $1 = '' OR status = $1
+        ORDER BY exchange, symbol, time_frame, gap_start;
+    `
+
+	var gaps []*types.MarketDataGap
+	err := r.db.Select(&gaps, query, status)
+	if err != nil {
+		r.logger.Errorf("Failed to get data from market_data_gaps: %v", err)
+		return nil, err
+	}
+
+	return gaps, nil
+}
+
+// UpdateMarketDataGapStatus изменяет статус пропуска.
+func (r *marketDataGapRepository) UpdateMarketDataGapStatus(id int, status string) error {
+	_, err := r.db.Exec("UPDATE market_data_gaps SET status = $2 WHERE id = $1", id, status)
+	if err != nil {
+		r.logger.Errorf("Failed to update market data gap %d: %v", id, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+)
+
+type GridLevelRepository interface {
+	GetGridLevels(strategyID int) ([]*types.GridLevel, error)
+	SaveGridLevel(level *types.GridLevel) error
+}
+
+type gridLevelRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewGridLevelRepository(db *DB, logger *logger.Logger) GridLevelRepository {
+	return &gridLevelRepository{db: db, logger: logger}
+}
+
+// GetGridLevels выбирает уровни сетки стратегии в порядке возрастания номера
+func (r *gridLevelRepository) GetGridLevels(strategyID int) ([]*types.GridLevel, error) {
+	query := `
+        SELECT id, strategy_id, level_index, price, side, entry_price, order_id, command_id,
+               status, amount, realized_profit, trades, updated_at
+        FROM grid_levels
+        WHERE strategy_id = $1
+        ORDER BY level_index;
+    `
+
+	var levels []*types.GridLevel
+	if err := r.db.Select(&levels, query, strategyID); err != nil {
+		r.logger.Errorf("Failed to get grid levels of strategy %d: %v", strategyID, err)
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+// SaveGridLevel сохраняет состояние уровня сетки
+func (r *gridLevelRepository) SaveGridLevel(level *types.GridLevel) error {
+	query := `
+        INSERT INTO grid_levels (strategy_id, level_index, price, side, entry_price, order_id, command_id,
+                                 status, amount, realized_profit, trades, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+        ON CONFLICT (strategy_id, level_index) DO UPDATE
+        SET price = EXCLUDED.price, side = EXCLUDED.side, entry_price = EXCLUDED.entry_price,
+            order_id = EXCLUDED.order_id, command_id = EXCLUDED.command_id, status = EXCLUDED.status,
+            amount = EXCLUDED.amount, realized_profit = EXCLUDED.realized_profit,
+            trades = EXCLUDED.trades, updated_at = EXCLUDED.updated_at
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query,
+		level.StrategyID,
+		level.Index,
+		level.Price,
+		level.Side,
+		level.EntryPrice,
+		level.OrderID,
+		level.CommandID,
+		level.Status,
+		level.Amount,
+		level.RealizedProfit,
+		level.Trades,
+		level.UpdatedAt,
+	).Scan(&level.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save grid level %d of strategy %d: %v", level.Index, level.StrategyID, err)
+		return err
+	}
+	return nil
+}
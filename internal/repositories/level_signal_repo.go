@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+	"time"
+)
+
+type LevelSignalRepository interface {
+	SaveLevelSignal(signal *types.LevelSignal) error
+	GetLevelSignals(symbol string, start time.Time, end time.Time) ([]*types.LevelSignal, error)
+}
+
+type levelSignalRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewLevelSignalRepository(db *DB, logger *logger.Logger) LevelSignalRepository {
+	return &levelSignalRepository{db: db, logger: logger}
+}
+
+// SaveLevelSignal сохраняет сигнал по уровню.
+func (r *levelSignalRepository) SaveLevelSignal(signal *types.LevelSignal) error {
+	query := `
+        INSERT INTO level_signals (symbol, time_frame, timestamp, kind, side, level, price, distance, strength, volume, age)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query,
+		signal.Symbol,
+		signal.TimeFrame,
+		signal.Timestamp,
+		signal.Kind,
+		signal.Side,
+		signal.Level,
+		signal.Price,
+		signal.Distance,
+		signal.Strength,
+		signal.Volume,
+		signal.Age,
+	).Scan(&signal.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save level signal: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetLevelSignals выбирает сигналы по символу за период.
+func (r *levelSignalRepository) GetLevelSignals(symbol string, start time.Time, end time.Time) ([]*types.LevelSignal, error) {
+	query := `
+        SELECT id, symbol, time_frame, timestamp, kind, side, level, price, distance, strength, volume, age
+        FROM level_signals
+        WHERE symbol = $1 AND timestamp BETWEEN $2 AND $3
+        ORDER BY timestamp;
+    `
+
+	var signals []*types.LevelSignal
+	err := r.db.Select(&signals, query, symbol, start, end)
+	if err != nil {
+		r.logger.Errorf("Failed to get data from level_signals: %v", err)
+		return nil, err
+	}
+
+	return signals, nil
+}
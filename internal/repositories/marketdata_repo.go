@@ -12,9 +12,7 @@ type MarketDataRepository interface {
 	SaveMarketData(data []*types.MarketData) error
 	GetMarketData(symbol string, limit int) ([]*types.MarketData, error)
 	GetMarketDataPeriod(symbol string, interval string, start time.Time, end time.Time) ([]*types.MarketData, error)
-
-	// SaveClusterData(data []*models.ClusterData) error
-	// GetClusterData(symbol string, limit int) ([]*models.ClusterData, error)
+	GetMarketDataTimestamps(symbol string, interval string, start time.Time, end time.Time) ([]time.Time, error)
 
 	GetMarketDataStatus(id int) (*types.MarketDataStatus, error)
 	SaveMarketDataStatus(marketdatastatus *types.MarketDataStatus) error
@@ -64,40 +62,6 @@ func (r *marketDataRepository) SaveMarketData(data []*types.MarketData) error {
 	return nil
 }
 
-// // Сохраняет кластеры в базу данных.
-// func (r *marketDataRepository) SaveClusterData(data []*models.ClusterData) error {
-// 	tx, err := r.db.Begin()
-// 	if err != nil {
-// 		r.logger.Errorf("Failed to begin transaction: %v", err)
-// 		return err
-// 	}
-
-// 	stmt, err := tx.Prepare("INSERT INTO cluster_data (timestamp, symbol, time_frame, is_buysell, cluster_price, volume) VALUES ($1, $2, $3, $4, $5, $6)")
-// 	if err != nil {
-// 		r.logger.Errorf("Failed to prepare statement: %v", err)
-// 		tx.Rollback()
-// 		return err
-// 	}
-// 	defer stmt.Close()
-
-// 	for _, d := range data {
-// 		_, err := stmt.Exec(d.Timestamp, d.Symbol, d.TimeFrame, d.IsBuySell, d.ClusterPrice, d.Volume)
-// 		if err != nil {
-// 			r.logger.Errorf("Failed to insert market data: %v", err)
-// 			tx.Rollback()
-// 			return err
-// 		}
-// 	}
-
-// 	if err := tx.Commit(); err != nil {
-// 		r.logger.Errorf("Failed to commit transaction: %v", err)
-// 		tx.Rollback()
-// 		return err
-// 	}
-
-// 	return nil
-// }
-
 func (r *marketDataRepository) GetMarketData(symbol string, limit int) ([]*types.MarketData, error) {
 	query := `
         SELECT exchange, symbol, open_price, close_price, volume, buy_volume, sell_volume, time_frame, timestamp
@@ -137,30 +101,24 @@ func (r *marketDataRepository) GetMarketDataPeriod(symbol string, interval strin
 	return marketData, nil
 }
 
-// func (r *marketDataRepository) GetClusterData(symbol string, limit int) ([]*models.ClusterData, error) {
-// 	query := `
-//         SELECT timestamp, symbol, time_frame, is_buysell, cluster_price, volume
-//         FROM cluster_data
-//         WHERE symbol = $1
-//         ORDER BY timestamp DESC
-//         LIMIT $2;
-//     `
-
-// 	var clusterData []*models.ClusterData
-// 	err := r.db.Select(&clusterData, query, symbol, limit)
-// 	if err != nil {
-// 		r.logger.Errorf("Failed to get market data for symbol %s: %v", symbol, err)
-// 		return nil, err
-// 	}
-
-// 	// // Сортируем данные по возрастанию времени
-// 	// sort.Slice(clusterData, func(i, j int) bool {
-// 	// 	return clusterData[i].Timestamp.Before(clusterData[j].Timestamp)
-// 	// })
-
-// 	r.logger.Infof("Market data retrieved for symbol %s: %v", symbol, clusterData)
-// 	return clusterData, nil
-// }
+// GetMarketDataTimestamps возвращает отметки времени свечей за период (для поиска пропусков).
+func (r *marketDataRepository) GetMarketDataTimestamps(symbol string, interval string, start time.Time, end time.Time) ([]time.Time, error) {
+	query := `
+        SELECT DISTINCT timestamp
+        FROM market_data
+        WHERE symbol = $1 AND time_frame = $2 AND timestamp >= $3 AND timestamp <= $4
+        ORDER BY timestamp ASC;
+    `
+
+	var timestamps []time.Time
+	err := r.db.Select(&timestamps, query, symbol, interval, start, end)
+	if err != nil {
+		r.logger.Errorf("Ошибка получения market_data: %v", err)
+		return nil, err
+	}
+
+	return timestamps, nil
+}
 
 // GetMarketDataStatus находит marketdatastatus по ID.
 func (r *marketDataRepository) GetMarketDataStatus(id int) (*types.MarketDataStatus, error) {
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+)
+
+type OptimizationRepository interface {
+	SaveOptimizationRun(run *types.OptimizationRun) error
+	GetOptimizationRuns(strategyID int) ([]*types.OptimizationRun, error)
+	GetOptimizationResults(runID int) ([]*types.OptimizationResult, error)
+}
+
+type optimizationRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewOptimizationRepository(db *DB, logger *logger.Logger) OptimizationRepository {
+	return &optimizationRepository{db: db, logger: logger}
+}
+
+// SaveOptimizationRun сохраняет оптимизацию и её прогоны одной транзакцией
+func (r *optimizationRepository) SaveOptimizationRun(run *types.OptimizationRun) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+
+	err = tx.QueryRow(`
+        INSERT INTO optimization_runs (strategy_id, method, objective, start_time, end_time, settings)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id, created_at;`,
+		run.StrategyID,
+		run.Method,
+		run.Objective,
+		run.StartTime,
+		run.EndTime,
+		string(run.Settings),
+	).Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		r.logger.Errorf("Failed to save optimization run of strategy %d: %v", run.StrategyID, err)
+		tx.Rollback()
+		return err
+	}
+
+	for _, result := range run.Results {
+		result.RunID = run.ID
+		err := tx.QueryRow(`
+            INSERT INTO optimization_results (run_id, rank, params, objective, front, feasible, metrics, error)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            RETURNING id;`,
+			result.RunID,
+			result.Rank,
+			string(result.Params),
+			result.Objective,
+			result.Front,
+			result.Feasible,
+			string(result.Metrics),
+			result.Error,
+		).Scan(&result.ID)
+		if err != nil {
+			r.logger.Errorf("Failed to save optimization result %d of run %d: %v", result.Rank, run.ID, err)
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Errorf("Failed to commit transaction: %v", err)
+		tx.Rollback()
+		return err
+	}
+	return nil
+}
+
+// GetOptimizationRuns выбирает оптимизации стратегии без прогонов, последние первыми
+func (r *optimizationRepository) GetOptimizationRuns(strategyID int) ([]*types.OptimizationRun, error) {
+	query := `
+        SELECT id, strategy_id, method, objective, start_time, end_time, settings, created_at
+        FROM optimization_runs
+        WHERE strategy_id = $1
+        ORDER BY id DESC;
+    `
+
+	var runs []*types.OptimizationRun
+	if err := r.db.Select(&runs, query, strategyID); err != nil {
+		r.logger.Errorf("Failed to get optimization runs of strategy %d: %v", strategyID, err)
+		return nil, err
+	}
+	return runs, nil
+}
+
+// GetOptimizationResults выбирает прогоны оптимизации по месту
+func (r *optimizationRepository) GetOptimizationResults(runID int) ([]*types.OptimizationResult, error) {
+	query := `
+        SELECT id, run_id, rank, params, objective, front, feasible, metrics, error
+        FROM optimization_results
+        WHERE run_id = $1
+        ORDER BY rank;
+    `
+
+	var results []*types.OptimizationResult
+	if err := r.db.Select(&results, query, runID); err != nil {
+		r.logger.Errorf("Failed to get results of optimization run %d: %v", runID, err)
+		return nil, err
+	}
+	return results, nil
+}
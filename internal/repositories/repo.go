@@ -5,21 +5,39 @@ import (
 )
 
 type Repository struct {
-	db     *DB
-	logger *logger.Logger
-	//Strategy            StrategyRepository
+	db                  *DB
+	logger              *logger.Logger
+	Strategy            StrategyRepository
 	MarketData          MarketDataRepository
 	IndicatorRepository IndicatorRepository
-	//BehaviorTreeRepository BehaviorTreeRepository
+	ClusterData         ClusterDataRepository
+	MarketDataGaps      MarketDataGapRepository
+	Violations          MarketDataViolationRepository
+	BehaviorTrees       BehaviorTreeRepository
+	LevelSignals        LevelSignalRepository
+	GridLevels          GridLevelRepository
+	DCADeals            DCADealRepository
+	TradeOperations     TradeOperationRepository
+	BacktestResults     BacktestResultRepository
+	Optimizations       OptimizationRepository
 }
 
 func NewRepository(db *DB, logger *logger.Logger) *Repository {
 	return &Repository{
-		db:     db,
-		logger: logger,
-		//Strategy:            NewStrategyRepository(db, logger),
+		db:                  db,
+		logger:              logger,
+		Strategy:            NewStrategyRepository(db, logger),
 		MarketData:          NewMarketDataRepository(db, logger),
 		IndicatorRepository: NewIndicatorRepository(db, logger),
-		//BehaviorTreeRepository: NewBehaviorTreeRepositoryRepository(db, logger),
+		ClusterData:         NewClusterDataRepository(db, logger),
+		MarketDataGaps:      NewMarketDataGapRepository(db, logger),
+		Violations:          NewMarketDataViolationRepository(db, logger),
+		BehaviorTrees:       NewBehaviorTreeRepository(db, logger),
+		LevelSignals:        NewLevelSignalRepository(db, logger),
+		GridLevels:          NewGridLevelRepository(db, logger),
+		DCADeals:            NewDCADealRepository(db, logger),
+		TradeOperations:     NewTradeOperationRepository(db, logger),
+		BacktestResults:     NewBacktestResultRepository(db, logger),
+		Optimizations:       NewOptimizationRepository(db, logger),
 	}
 }
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+)
+
+type StrategyRepository interface {
+	GetStrategy(id int) (*types.Strategy, error)
+	GetActiveStrategyList() ([]*types.Strategy, error)
+	SaveStrategy(strategy *types.Strategy) error
+}
+
+type strategyRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewStrategyRepository(db *DB, logger *logger.Logger) StrategyRepository {
+	return &strategyRepository{db: db, logger: logger}
+}
+
+// GetStrategy выбирает стратегию по идентификатору.
+func (r *strategyRepository) GetStrategy(id int) (*types.Strategy, error) {
+	query := `
+        SELECT id, name, COALESCE(description, '') AS description, config, active
+        FROM strategies
+        WHERE id = $1;
+    `
+
+	var strategy types.Strategy
+	err := r.db.Get(&strategy, query, id)
+	if err != nil {
+		r.logger.Errorf("Failed to get strategy %d: %v", id, err)
+		return nil, err
+	}
+
+	return &strategy, nil
+}
+
+// GetActiveStrategyList выбирает все активные стратегии.
+func (r *strategyRepository) GetActiveStrategyList() ([]*types.Strategy, error) {
+	query := `
+        SELECT id, name, COALESCE(description, '') AS description, config, active
+        FROM strategies
+        WHERE active
+        ORDER BY id;
+    `
+
+	var strategies []*types.Strategy
+	err := r.db.Select(&strategies, query)
+	if err != nil {
+		r.logger.Errorf("Failed to get data from strategies: %v", err)
+		return nil, err
+	}
+
+	return strategies, nil
+}
+
+// SaveStrategy сохраняет стратегию. Стратегия с тем же именем обновляется.
+func (r *strategyRepository) SaveStrategy(strategy *types.Strategy) error {
+	query := `
+        INSERT INTO strategies (name, description, config, active)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (name) DO UPDATE
+        SET description = EXCLUDED.description, config = EXCLUDED.config, active = EXCLUDED.active
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query,
+		strategy.Name,
+		strategy.Description,
+		string(strategy.Config),
+		strategy.Active,
+	).Scan(&strategy.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save strategy %s: %v", strategy.Name, err)
+		return err
+	}
+	return nil
+}
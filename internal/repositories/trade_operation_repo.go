@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+)
+
+type TradeOperationRepository interface {
+	SaveTradeOperation(op *types.TradeOperation) error
+	GetTradeOperations(strategyID int) ([]*types.TradeOperation, error)
+	GetDealOperations(strategyID int, dealID string) ([]*types.TradeOperation, error)
+}
+
+type tradeOperationRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewTradeOperationRepository(db *DB, logger *logger.Logger) TradeOperationRepository {
+	return &tradeOperationRepository{db: db, logger: logger}
+}
+
+// SaveTradeOperation сохраняет торговую операцию
+func (r *tradeOperationRepository) SaveTradeOperation(op *types.TradeOperation) error {
+	query := `
+        INSERT INTO trade_operations (strategy_id, backtest_result_id, deal_id, symbol, order_type, side, quantity, price, timestamp, status, reason)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query,
+		op.StrategyID,
+		op.BacktestResultID,
+		op.DealID,
+		op.Symbol,
+		op.OrderType,
+		op.Side,
+		op.Quantity,
+		op.Price,
+		op.Timestamp,
+		op.Status,
+		op.Reason,
+	).Scan(&op.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save trade operation of strategy %d: %v", op.StrategyID, err)
+		return err
+	}
+	return nil
+}
+
+// GetTradeOperations выбирает операции живой торговли стратегии в порядке времени
+func (r *tradeOperationRepository) GetTradeOperations(strategyID int) ([]*types.TradeOperation, error) {
+	query := `
+        SELECT id, strategy_id, deal_id, symbol, order_type, side, quantity, price, timestamp, status, reason
+        FROM trade_operations
+        WHERE strategy_id = $1 AND backtest_result_id IS NULL
+        ORDER BY timestamp, id;
+    `
+
+	var ops []*types.TradeOperation
+	if err := r.db.Select(&ops, query, strategyID); err != nil {
+		r.logger.Errorf("Failed to get trade operations of strategy %d: %v", strategyID, err)
+		return nil, err
+	}
+	return ops, nil
+}
+
+// GetDealOperations выбирает операции одной сделки живой торговли в порядке времени
+func (r *tradeOperationRepository) GetDealOperations(strategyID int, dealID string) ([]*types.TradeOperation, error) {
+	query := `
+        SELECT id, strategy_id, deal_id, symbol, order_type, side, quantity, price, timestamp, status, reason
+        FROM trade_operations
+        WHERE strategy_id = $1 AND deal_id = $2 AND backtest_result_id IS NULL
+        ORDER BY timestamp, id;
+    `
+
+	var ops []*types.TradeOperation
+	if err := r.db.Select(&ops, query, strategyID, dealID); err != nil {
+		r.logger.Errorf("Failed to get operations of deal %s: %v", dealID, err)
+		return nil, err
+	}
+	return ops, nil
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+	"time"
+)
+
+type MarketDataViolationRepository interface {
+	SaveMarketDataViolation(violation *types.MarketDataViolation) error
+	GetMarketDataViolations(symbol string, start time.Time, end time.Time) ([]*types.MarketDataViolation, error)
+}
+
+type marketDataViolationRepository struct {
+	db     *DB
+	logger *logger.Logger
+}
+
+func NewMarketDataViolationRepository(db *DB, logger *logger.Logger) MarketDataViolationRepository {
+	return &marketDataViolationRepository{db: db, logger: logger}
+}
+
+// SaveMarketDataViolation сохраняет нарушение качества данных.
+func (r *marketDataViolationRepository) SaveMarketDataViolation(violation *types.MarketDataViolation) error {
+	query := `
+        INSERT INTO market_data_violations (symbol, time_frame, timestamp, rule, action, details)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id;
+    `
+
+	err := r.db.QueryRow(query,
+		violation.Symbol,
+		violation.TimeFrame,
+		violation.Timestamp,
+		violation.Rule,
+		violation.Action,
+		violation.Details,
+	).Scan(&violation.ID)
+	if err != nil {
+		r.logger.Errorf("Failed to save market data violation: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetMarketDataViolations выбирает нарушения по символу за период (по всем символам, если символ пустой).
+func (r *marketDataViolationRepository) GetMarketDataViolations(symbol string, start time.Time, end time.Time) ([]*types.MarketDataViolation, error) {
+	query := `
+        SELECT id, symbol, time_frame, timestamp, rule, action, details, created_at
+        FROM market_data_violations
+        WHERE ($1 = '' OR symbol = $1) AND timestamp BETWEEN $2 AND $3
+        ORDER BY timestamp;
+    `
+
+	var violations []*types.MarketDataViolation
+	err := r.db.Select(&violations, query, symbol, start, end)
+	if err != nil {
+		r.logger.Errorf("Failed to get data from market_data_violations: %v", err)
+		return nil, err
+	}
+
+	return violations, nil
+}
@@ -1,6 +1,8 @@
 package exchange
 
 import (
+	"context"
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/config"
 	"crypto-trading-bot/internal/logger"
 	"crypto-trading-bot/internal/repositories"
@@ -92,3 +94,40 @@ func Test_exchangeService_LoadData_Empty(t *testing.T) {
 	t.Logf("Loaded data %v to %v", len(marketData), lastTime)
 
 }
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewSimulated(start)
+	limiter := NewRateLimiter(clk, time.Second)
+
+	// первый запрос выполняется сразу
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	// второй ждёт интервал по модельному времени
+	done := make(chan error)
+	go func() { done <- limiter.Wait(context.Background()) }()
+
+	clk.BlockUntil(1)
+	clk.Advance(999 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Wait returned before the interval elapsed")
+	default:
+	}
+
+	clk.Advance(time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	// отмена контекста прерывает ожидание
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- limiter.Wait(ctx) }()
+	clk.BlockUntil(1)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package exchange
+
+import (
+	"context"
+	"crypto-trading-bot/internal/clock"
+	"sync"
+	"time"
+)
+
+// RateLimiter ограничивает частоту запросов к бирже: не чаще одного запроса за interval
+type RateLimiter struct {
+	clock    clock.Clock
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time // время, раньше которого следующий запрос выполнять нельзя
+}
+
+func NewRateLimiter(clk clock.Clock, interval time.Duration) *RateLimiter {
+	return &RateLimiter{clock: clk, interval: interval}
+}
+
+// Wait блокируется до момента, когда можно выполнить следующий запрос, или до отмены контекста
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.clock.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := l.clock.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
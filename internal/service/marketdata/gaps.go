@@ -0,0 +1,312 @@
+package marketdata
+
+import (
+	"context"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/service/exchange"
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/internal/utils"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// GapScanner ищет пропуски свечей в market_data и догружает их с биржи
+type GapScanner struct {
+	repo      *repositories.Repository
+	logger    *logger.Logger
+	clock     clock.Clock
+	exchanges []exchange.Exchange
+	limiter   *exchange.RateLimiter
+}
+
+// NewGapScanner создаёт сканер. requestInterval — минимальный интервал между запросами к бирже.
+func NewGapScanner(repo *repositories.Repository,
+	logger *logger.Logger,
+	clk clock.Clock,
+	exchanges []exchange.Exchange,
+	requestInterval time.Duration) *GapScanner {
+
+	return &GapScanner{
+		repo:      repo,
+		logger:    logger,
+		clock:     clk,
+		exchanges: exchanges,
+		limiter:   exchange.NewRateLimiter(clk, requestInterval),
+	}
+}
+
+// RunSchudeler раз в period ищет пропуски за последние lookback и догружает их.
+// Ошибки записываются в журнал, проверка повторяется в следующий раз.
+func (s *GapScanner) RunSchudeler(ctx context.Context, period time.Duration, lookback time.Duration) {
+	for {
+		now := s.clock.Now()
+		gaps, err := s.ScanAll(now.Add(-lookback), now)
+		if err != nil {
+			s.logger.Errorf("Failed to scan market data gaps: %v", err)
+		}
+		if err := s.Backfill(ctx, gaps); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("Failed to backfill market data gaps: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(period):
+		}
+	}
+}
+
+// Scan ищет пропуски для записи market_data_statuss за период.
+// Конец периода ограничивается временем актуальности данных, дальше данные догружает LoadData.
+func (s *GapScanner) Scan(status *types.MarketDataStatus, start time.Time, end time.Time) ([]*types.MarketDataGap, error) {
+	step, err := utils.IntervalDuration(status.TimeFrame)
+	if err != nil {
+		return nil, err
+	}
+
+	if !status.ActualTime.IsZero() && status.ActualTime.Before(end) {
+		end = status.ActualTime
+	}
+	if !end.After(start) {
+		return nil, nil
+	}
+
+	timestamps, err := s.repo.MarketData.GetMarketDataTimestamps(status.Symbol, status.TimeFrame, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	gaps := findGaps(timestamps, step, start, end)
+	for _, gap := range gaps {
+		gap.Exchange = status.Exchange
+		gap.Symbol = status.Symbol
+		gap.TimeFrame = status.TimeFrame
+		gap.Status = types.GapStatusOpen
+	}
+
+	return gaps, nil
+}
+
+// ScanAll ищет пропуски по всем активным записям market_data_statuss и сохраняет их
+func (s *GapScanner) ScanAll(start time.Time, end time.Time) ([]*types.MarketDataGap, error) {
+	statusList, err := s.repo.MarketData.GetMarketDataStatusList()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*types.MarketDataGap
+	for _, status := range statusList {
+		if !status.Active {
+			continue
+		}
+
+		gaps, err := s.Scan(status, start, end)
+		if err != nil {
+			s.logger.Errorf("Failed to scan gaps %s %s %s: %v", status.Exchange, status.Symbol, status.TimeFrame, err)
+			continue
+		}
+
+		for _, gap := range gaps {
+			if err := s.repo.MarketDataGaps.SaveMarketDataGap(gap); err != nil {
+				return result, err
+			}
+		}
+
+		if len(gaps) > 0 {
+			s.logger.Infof("Found %d gaps in %s %s %s", len(gaps), status.Exchange, status.Symbol, status.TimeFrame)
+		}
+		result = append(result, gaps...)
+	}
+
+	return result, nil
+}
+
+// Backfill догружает данные за периоды пропусков, соблюдая ограничение частоты запросов к бирже.
+// Статус каждого пропуска обновляется: filled — данные загружены полностью, failed — биржа вернула не всё.
+func (s *GapScanner) Backfill(ctx context.Context, gaps []*types.MarketDataGap) error {
+	for _, gap := range gaps {
+		if gap.Status == types.GapStatusFilled {
+			continue
+		}
+
+		ex := s.findExchange(gap.Exchange)
+		if ex == nil {
+			s.logger.Errorf("Exchange %s not found for gap %d", gap.Exchange, gap.ID)
+			continue
+		}
+
+		loaded, err := s.backfillGap(ctx, ex, gap)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			s.logger.Errorf("Failed to backfill gap %d %s %s: %v", gap.ID, gap.Symbol, gap.TimeFrame, err)
+		}
+
+		gap.Status = types.GapStatusFilled
+		if loaded < gap.Missing {
+			gap.Status = types.GapStatusFailed
+		}
+
+		s.logger.Infof("Backfill %s %s %s [%v - %v]: loaded %d of %d",
+			gap.Exchange, gap.Symbol, gap.TimeFrame, gap.GapStart, gap.GapEnd, loaded, gap.Missing)
+
+		if gap.ID != 0 {
+			if err := s.repo.MarketDataGaps.UpdateMarketDataGapStatus(gap.ID, gap.Status); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// backfillGap загружает свечи пропуска порциями, возвращает количество сохранённых свечей
+func (s *GapScanner) backfillGap(ctx context.Context, ex exchange.Exchange, gap *types.MarketDataGap) (int, error) {
+	step, err := utils.IntervalDuration(gap.TimeFrame)
+	if err != nil {
+		return 0, err
+	}
+
+	loaded := 0
+	// начинаем на свечу раньше: биржа фильтрует по времени открытия свечи, а в market_data время закрытия
+	from := gap.GapStart.Add(-step)
+
+	for {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return loaded, err
+		}
+
+		marketData, lastTime, err := ex.GetMarketData(gap.Symbol, gap.TimeFrame, from)
+		if err != nil {
+			return loaded, err
+		}
+
+		var missing []*types.MarketData
+		for _, md := range marketData {
+			if !md.Timestamp.Before(gap.GapStart) && !md.Timestamp.After(gap.GapEnd) {
+				missing = append(missing, md)
+			}
+		}
+
+		if len(missing) > 0 {
+			if err := s.repo.MarketData.SaveMarketData(missing); err != nil {
+				return loaded, err
+			}
+			loaded += len(missing)
+		}
+
+		// данных больше нет или пропуск закрыт полностью
+		if len(marketData) == 0 || !lastTime.After(from) || !lastTime.Before(gap.GapEnd) {
+			return loaded, nil
+		}
+		from = lastTime
+	}
+}
+
+// Coverage рассчитывает полноту данных по всем активным записям market_data_statuss за период
+func (s *GapScanner) Coverage(start time.Time, end time.Time) ([]*types.MarketDataCoverage, error) {
+	statusList, err := s.repo.MarketData.GetMarketDataStatusList()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*types.MarketDataCoverage
+	for _, status := range statusList {
+		if !status.Active {
+			continue
+		}
+
+		step, err := utils.IntervalDuration(status.TimeFrame)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", status.Symbol, status.TimeFrame, err)
+		}
+
+		timestamps, err := s.repo.MarketData.GetMarketDataTimestamps(status.Symbol, status.TimeFrame, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		coverage := &types.MarketDataCoverage{
+			Exchange:  status.Exchange,
+			Symbol:    status.Symbol,
+			TimeFrame: status.TimeFrame,
+			Start:     start,
+			End:       end,
+			Expected:  int(end.Sub(start) / step),
+			Actual:    len(timestamps),
+		}
+		for _, gap := range findGaps(timestamps, step, start, end) {
+			coverage.Gaps++
+			coverage.Missing += gap.Missing
+		}
+		if coverage.Expected > 0 {
+			coverage.Percent = math.Min(float64(coverage.Actual)/float64(coverage.Expected)*100, 100)
+		}
+
+		result = append(result, coverage)
+	}
+
+	return result, nil
+}
+
+func (s *GapScanner) findExchange(name string) exchange.Exchange {
+	for _, ex := range s.exchanges {
+		if strings.ToLower(ex.GetName()) == name {
+			return ex
+		}
+	}
+	return nil
+}
+
+// findGaps ищет пропуски в отсортированных отметках времени свечей с шагом step.
+// Пропуском считается расстояние между соседними свечами от полутора шагов.
+// В конце периода допускается одна незавершённая свеча.
+func findGaps(timestamps []time.Time, step time.Duration, start time.Time, end time.Time) []*types.MarketDataGap {
+	var gaps []*types.MarketDataGap
+
+	if len(timestamps) == 0 {
+		if n := int(end.Sub(start)/step) - 1; n > 0 {
+			gaps = append(gaps, &types.MarketDataGap{GapStart: start, GapEnd: start.Add(time.Duration(n-1) * step), Missing: n})
+		}
+		return gaps
+	}
+
+	// пропуск в начале периода
+	if n := int(timestamps[0].Sub(start) / step); n > 0 {
+		gaps = append(gaps, &types.MarketDataGap{
+			GapStart: timestamps[0].Add(-time.Duration(n) * step),
+			GapEnd:   timestamps[0].Add(-step),
+			Missing:  n,
+		})
+	}
+
+	for i := 1; i < len(timestamps); i++ {
+		diff := timestamps[i].Sub(timestamps[i-1])
+		if diff < step*3/2 {
+			continue
+		}
+		n := int(math.Round(float64(diff)/float64(step))) - 1
+		gaps = append(gaps, &types.MarketDataGap{
+			GapStart: timestamps[i-1].Add(step),
+			GapEnd:   timestamps[i-1].Add(time.Duration(n) * step),
+			Missing:  n,
+		})
+	}
+
+	// пропуск в конце периода, последняя свеча перед end может быть ещё не завершена
+	last := timestamps[len(timestamps)-1]
+	if n := int(end.Sub(last)/step) - 2; n > 0 {
+		gaps = append(gaps, &types.MarketDataGap{
+			GapStart: last.Add(step),
+			GapEnd:   last.Add(time.Duration(n) * step),
+			Missing:  n,
+		})
+	}
+
+	return gaps
+}
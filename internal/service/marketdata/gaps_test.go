@@ -0,0 +1,137 @@
+package marketdata
+
+import (
+	"context"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/service/exchange"
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindGaps(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	end := start.Add(10 * time.Minute)
+
+	// нет свечей 00:00, 00:03-00:04 и 00:08
+	var timestamps []time.Time
+	for _, m := range []int{1, 2, 5, 6, 7, 9} {
+		timestamps = append(timestamps, start.Add(time.Duration(m)*time.Minute))
+	}
+
+	gaps := findGaps(timestamps, time.Minute, start, end)
+	if assert.Len(t, gaps, 3) {
+		assert.Equal(t, start, gaps[0].GapStart)
+		assert.Equal(t, 1, gaps[0].Missing)
+
+		assert.Equal(t, start.Add(3*time.Minute), gaps[1].GapStart)
+		assert.Equal(t, start.Add(4*time.Minute), gaps[1].GapEnd)
+		assert.Equal(t, 2, gaps[1].Missing)
+
+		assert.Equal(t, start.Add(8*time.Minute), gaps[2].GapStart)
+		assert.Equal(t, 1, gaps[2].Missing)
+	}
+}
+
+func TestFindGapsEdges(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	end := start.Add(time.Hour)
+
+	// данных нет совсем
+	gaps := findGaps(nil, 15*time.Minute, start, end)
+	if assert.Len(t, gaps, 1) {
+		assert.Equal(t, 3, gaps[0].Missing)
+		assert.Equal(t, start.Add(30*time.Minute), gaps[0].GapEnd)
+	}
+
+	// последняя незавершённая свеча пропуском не считается
+	timestamps := []time.Time{start, start.Add(15 * time.Minute), start.Add(30 * time.Minute)}
+	assert.Empty(t, findGaps(timestamps, 15*time.Minute, start, end))
+
+	// в конце не хватает двух завершённых свечей
+	gaps = findGaps(timestamps[:1], 15*time.Minute, start, end)
+	if assert.Len(t, gaps, 1) {
+		assert.Equal(t, start.Add(15*time.Minute), gaps[0].GapStart)
+		assert.Equal(t, 2, gaps[0].Missing)
+	}
+}
+
+// fakeExchange отдаёт минутные свечи до until порциями по batch штук
+type fakeExchange struct {
+	until    time.Time
+	batch    int
+	requests int
+}
+
+func (e *fakeExchange) GetName() string { return "Fake" }
+
+func (e *fakeExchange) GetMarketData(symbol, interval string, startTime time.Time) ([]*types.MarketData, time.Time, error) {
+	e.requests++
+	var data []*types.MarketData
+	for ts := startTime.Add(time.Minute); !ts.After(e.until) && len(data) < e.batch; ts = ts.Add(time.Minute) {
+		data = append(data, &types.MarketData{Symbol: symbol, TimeFrame: interval, Timestamp: ts})
+	}
+	if len(data) == 0 {
+		return nil, startTime, nil
+	}
+	return data, data[len(data)-1].Timestamp, nil
+}
+
+// memoryMarketData запоминает сохранённые свечи
+type memoryMarketData struct {
+	repositories.MarketDataRepository
+	saved []*types.MarketData
+}
+
+func (r *memoryMarketData) SaveMarketData(data []*types.MarketData) error {
+	r.saved = append(r.saved, data...)
+	return nil
+}
+
+// memoryGaps запоминает обновлённые статусы пропусков
+type memoryGaps struct {
+	repositories.MarketDataGapRepository
+	statuses map[int]string
+}
+
+func (r *memoryGaps) UpdateMarketDataGapStatus(id int, status string) error {
+	r.statuses[id] = status
+	return nil
+}
+
+func TestBackfill(t *testing.T) {
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	ex := &fakeExchange{until: start.Add(10 * time.Minute), batch: 2}
+	marketData := &memoryMarketData{}
+	gaps := &memoryGaps{statuses: map[int]string{}}
+	repo := &repositories.Repository{MarketData: marketData, MarketDataGaps: gaps}
+
+	scanner := NewGapScanner(repo, logger.NewLogger("fatal"), clock.New(), []exchange.Exchange{ex}, 0)
+
+	list := []*types.MarketDataGap{
+		// догружается за два запроса
+		{ID: 1, Exchange: "fake", Symbol: "BTCUSDT", TimeFrame: "1m", GapStart: start.Add(3 * time.Minute), GapEnd: start.Add(5 * time.Minute), Missing: 3, Status: types.GapStatusOpen},
+		// у биржи данные только до 00:10
+		{ID: 2, Exchange: "fake", Symbol: "BTCUSDT", TimeFrame: "1m", GapStart: start.Add(9 * time.Minute), GapEnd: start.Add(12 * time.Minute), Missing: 4, Status: types.GapStatusOpen},
+		// уже заполнен
+		{ID: 3, Exchange: "fake", Symbol: "BTCUSDT", TimeFrame: "1m", GapStart: start, GapEnd: start, Missing: 1, Status: types.GapStatusFilled},
+	}
+	assert.NoError(t, scanner.Backfill(context.Background(), list))
+
+	var saved []time.Time
+	for _, md := range marketData.saved {
+		saved = append(saved, md.Timestamp)
+	}
+	assert.Equal(t, []time.Time{
+		start.Add(3 * time.Minute), start.Add(4 * time.Minute), start.Add(5 * time.Minute),
+		start.Add(9 * time.Minute), start.Add(10 * time.Minute),
+	}, saved)
+
+	assert.Equal(t, map[int]string{1: types.GapStatusFilled, 2: types.GapStatusFailed}, gaps.statuses)
+	assert.Equal(t, types.GapStatusFilled, list[0].Status)
+	assert.Equal(t, types.GapStatusFailed, list[1].Status)
+}
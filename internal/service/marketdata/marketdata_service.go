@@ -2,6 +2,7 @@ package marketdata
 
 import (
 	"context"
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/config"
 	"crypto-trading-bot/internal/logger"
 	"crypto-trading-bot/internal/repositories"
@@ -27,6 +28,7 @@ type marketDataService struct {
 	conf            *config.Config
 	repo            *repositories.Repository
 	logger          *logger.Logger
+	clock           clock.Clock
 	exchanges       []exchange.Exchange
 	exchangeService exchange.ExchangeService
 	mu              sync.Mutex
@@ -36,6 +38,7 @@ type marketDataService struct {
 func NewMarketDataService(conf *config.Config,
 	repo *repositories.Repository,
 	logger *logger.Logger,
+	clk clock.Clock,
 	exchanges []exchange.Exchange,
 	exchangeService exchange.ExchangeService) MarketDataService {
 
@@ -43,6 +46,7 @@ func NewMarketDataService(conf *config.Config,
 		conf:            conf,
 		repo:            repo,
 		logger:          logger,
+		clock:           clk,
 		exchanges:       exchanges,
 		exchangeService: exchangeService,
 	}
@@ -58,14 +62,14 @@ func (s *marketDataService) RunSchudeler(ctx context.Context) {
 			return
 		default:
 			// Проверяем время последнего запуска.
-			if time.Now().Add(-1 * time.Second).Before(s.lastTime) {
+			if s.clock.Now().Add(-1 * time.Second).Before(s.lastTime) {
 				s.logger.Debug("Выполняем не чаще 1 раза в секунду\n")
-				time.Sleep(1 * time.Second)
+				s.clock.Sleep(1 * time.Second)
 				continue
 			}
 			//s.logger.Debug("LoadData\n")
 			s.LoadData()
-			s.lastTime = time.Now()
+			s.lastTime = s.clock.Now()
 		}
 	}
 }
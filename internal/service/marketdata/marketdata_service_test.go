@@ -1,6 +1,7 @@
 package marketdata
 
 import (
+	"crypto-trading-bot/internal/clock"
 	"crypto-trading-bot/internal/config"
 	"crypto-trading-bot/internal/logger"
 	"crypto-trading-bot/internal/repositories"
@@ -39,7 +40,7 @@ func NewTestSetup() *TestSetup {
 
 	exchangeService := exchange.NewEchangeService(repo, logger, exchanges)
 
-	marketDataService := NewMarketDataService(cfg, repo, logger, exchanges, exchangeService)
+	marketDataService := NewMarketDataService(cfg, repo, logger, clock.New(), exchanges, exchangeService)
 
 	return &TestSetup{
 		exchanges:         exchanges,
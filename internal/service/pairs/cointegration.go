@@ -0,0 +1,155 @@
+package pairs
+
+import (
+	"fmt"
+	"math"
+)
+
+// Критические значения статистики ADF для остатков коинтеграционной регрессии со свободным членом
+// (MacKinnon, 2010) по количеству переменных: 1%, 5%, 10%.
+// Для одного ряда (переменных = 1) — обычный тест Дики — Фуллера со свободным членом.
+var criticalValues = map[int][3]float64{
+	1: {-3.43, -2.86, -2.57},
+	2: {-3.90, -3.34, -3.04},
+	3: {-4.29, -3.74, -3.45},
+	4: {-4.64, -4.10, -3.81},
+	5: {-4.96, -4.41, -4.13},
+}
+
+// ADFResult — результат расширенного теста Дики — Фуллера
+type ADFResult struct {
+	Statistic float64    // t-статистика коэффициента при y[t-1]
+	Lags      int        // количество лагов разностей
+	Critical  [3]float64 // критические значения 1%, 5%, 10%
+}
+
+// Stationary сообщает, отвергается ли гипотеза о единичном корне на уровне 5%
+func (r ADFResult) Stationary() bool {
+	return r.Statistic < r.Critical[1]
+}
+
+// Significance возвращает наименьший уровень значимости из 1%, 5%, 10%, на котором ряд стационарен, или 0
+func (r ADFResult) Significance() float64 {
+	for i, level := range []float64{0.01, 0.05, 0.10} {
+		if r.Statistic < r.Critical[i] {
+			return level
+		}
+	}
+	return 0
+}
+
+// ADF выполняет тест Дики — Фуллера со свободным членом:
+// Δy[t] = a + γ·y[t-1] + Σ φ[i]·Δy[t-i] + e[t]. Стационарность — значимо отрицательный γ.
+// variables задаёт набор критических значений: 1 для отдельного ряда, N для остатков регрессии N рядов.
+func ADF(series []float64, lags int, variables int) (ADFResult, error) {
+	critical, ok := criticalValues[variables]
+	if !ok {
+		return ADFResult{}, fmt.Errorf("adf: critical values for %d variables are not available", variables)
+	}
+	if len(series) < 2 {
+		// разностей нет, регрессию не на чем строить
+		return ADFResult{}, fmt.Errorf("adf: not enough observations: %d", len(series))
+	}
+
+	if lags < 0 {
+		// правило Шверта
+		lags = int(math.Floor(12 * math.Pow(float64(len(series))/100, 0.25)))
+	}
+
+	diff := make([]float64, len(series)-1)
+	for i := 1; i < len(series); i++ {
+		diff[i-1] = series[i] - series[i-1]
+	}
+
+	var x [][]float64
+	var y []float64
+	for t := lags; t < len(diff); t++ {
+		row := []float64{1, series[t]}
+		for i := 1; i <= lags; i++ {
+			row = append(row, diff[t-i])
+		}
+		x = append(x, row)
+		y = append(y, diff[t])
+	}
+
+	r, err := fitOLS(x, y)
+	if err != nil {
+		return ADFResult{}, fmt.Errorf("adf: %w", err)
+	}
+	if r.stdErr[1] == 0 {
+		return ADFResult{}, fmt.Errorf("adf: series is constant")
+	}
+
+	return ADFResult{Statistic: r.coef[1] / r.stdErr[1], Lags: lags, Critical: critical}, nil
+}
+
+// CointegrationResult — результат теста Энгла — Грейнджера
+type CointegrationResult struct {
+	Alpha    float64   // свободный член
+	Betas    []float64 // коэффициенты хеджирования для x
+	ADF      ADFResult // тест остатков
+	HalfLife float64   // период полураспада отклонения спреда, в барах; +Inf, если спред не возвращается к среднему
+	Spread   []float64 // остатки y - alpha - Σ beta·x
+}
+
+// Cointegrated сообщает, коинтегрированы ли ряды на уровне 5%
+func (r *CointegrationResult) Cointegrated() bool {
+	return r.ADF.Stationary()
+}
+
+// EngleGranger оценивает y = alpha + Σ beta[i]·x[i] и проверяет остатки на стационарность.
+// xs — ряды объясняющих переменных той же длины, что y. lags < 0 — выбор количества лагов по правилу Шверта.
+func EngleGranger(y []float64, xs [][]float64, lags int) (*CointegrationResult, error) {
+	if len(xs) == 0 {
+		return nil, fmt.Errorf("engle-granger: no explanatory series")
+	}
+	for _, x := range xs {
+		if len(x) != len(y) {
+			return nil, fmt.Errorf("engle-granger: series lengths differ: %d and %d", len(y), len(x))
+		}
+	}
+
+	rows := make([][]float64, len(y))
+	for t := range y {
+		rows[t] = make([]float64, 0, len(xs)+1)
+		rows[t] = append(rows[t], 1)
+		for _, x := range xs {
+			rows[t] = append(rows[t], x[t])
+		}
+	}
+
+	r, err := fitOLS(rows, y)
+	if err != nil {
+		return nil, fmt.Errorf("engle-granger: %w", err)
+	}
+
+	adf, err := ADF(r.residuals, lags, len(xs)+1)
+	if err != nil {
+		return nil, fmt.Errorf("engle-granger: %w", err)
+	}
+
+	return &CointegrationResult{
+		Alpha:    r.coef[0],
+		Betas:    r.coef[1:],
+		ADF:      adf,
+		HalfLife: HalfLife(r.residuals),
+		Spread:   r.residuals,
+	}, nil
+}
+
+// HalfLife оценивает период полураспада отклонения спреда по модели Орнштейна — Уленбека:
+// Δs[t] = a + λ·s[t-1], half-life = -ln 2 / λ
+func HalfLife(spread []float64) float64 {
+	var x [][]float64
+	var y []float64
+	for t := 1; t < len(spread); t++ {
+		x = append(x, []float64{1, spread[t-1]})
+		y = append(y, spread[t]-spread[t-1])
+	}
+
+	r, err := fitOLS(x, y)
+	if err != nil || r.coef[1] >= 0 {
+		return math.Inf(1)
+	}
+	return -math.Ln2 / r.coef[1]
+}
@@ -0,0 +1,197 @@
+package pairs
+
+import (
+	"fmt"
+	"math"
+)
+
+// HedgeModel оценивает коэффициенты хеджирования y = alpha + Σ beta[i]·x[i] по мере поступления цен
+type HedgeModel interface {
+	// Update добавляет наблюдение: цену y и цены x
+	Update(y float64, x []float64)
+	// Ready сообщает, что оценка достоверна
+	Ready() bool
+	Alpha() float64
+	Betas() []float64
+}
+
+// Spread возвращает отклонение y от оценки модели
+func Spread(model HedgeModel, y float64, x []float64) float64 {
+	spread := y - model.Alpha()
+	for i, beta := range model.Betas() {
+		spread -= beta * x[i]
+	}
+	return spread
+}
+
+// NewHedgeModel создаёт модель по имени: "ols" — регрессия по скользящему окну, "kalman" — фильтр Калмана
+func NewHedgeModel(kind string, legs int, window int) (HedgeModel, error) {
+	switch kind {
+	case "ols":
+		return NewRollingOLS(legs, window), nil
+	case "kalman":
+		return NewKalman(legs, 1e-4, 1e-3, window), nil
+	}
+	return nil, fmt.Errorf("unknown hedge model: %s", kind)
+}
+
+// RollingOLS — регрессия по последним window наблюдениям
+type RollingOLS struct {
+	legs   int
+	window int
+	ys     []float64
+	xs     [][]float64
+	coef   []float64
+}
+
+func NewRollingOLS(legs int, window int) *RollingOLS {
+	return &RollingOLS{legs: legs, window: window}
+}
+
+func (m *RollingOLS) Update(y float64, x []float64) {
+	m.ys = append(m.ys, y)
+	m.xs = append(m.xs, append([]float64{1}, x...))
+	if len(m.ys) > m.window {
+		m.ys = m.ys[1:]
+		m.xs = m.xs[1:]
+	}
+
+	if len(m.ys) < m.window {
+		return
+	}
+
+	// при вырожденном окне (например, цены не менялись) оставляем прежнюю оценку
+	if r, err := fitOLS(m.xs, m.ys); err == nil {
+		m.coef = r.coef
+	}
+}
+
+func (m *RollingOLS) Ready() bool {
+	return m.coef != nil
+}
+
+func (m *RollingOLS) Alpha() float64 {
+	if m.coef == nil {
+		return 0
+	}
+	return m.coef[0]
+}
+
+func (m *RollingOLS) Betas() []float64 {
+	if m.coef == nil {
+		return make([]float64, m.legs)
+	}
+	return m.coef[1:]
+}
+
+// Kalman оценивает коэффициенты как скрытое состояние, меняющееся случайным блужданием.
+// delta задаёт скорость изменения коэффициентов, observationVar — дисперсию шума наблюдения.
+type Kalman struct {
+	theta          []float64   // [alpha, beta...]
+	p              [][]float64 // ковариация оценки
+	q              float64     // дисперсия шума состояния
+	observationVar float64
+	warmUp         int
+	updates        int
+}
+
+func NewKalman(legs int, delta float64, observationVar float64, warmUp int) *Kalman {
+	n := legs + 1
+	k := &Kalman{
+		theta:          make([]float64, n),
+		p:              make([][]float64, n),
+		q:              delta / (1 - delta),
+		observationVar: observationVar,
+		warmUp:         warmUp,
+	}
+	for i := range k.p {
+		k.p[i] = make([]float64, n)
+		k.p[i][i] = 1
+	}
+	return k
+}
+
+func (k *Kalman) Update(y float64, x []float64) {
+	n := len(k.theta)
+	h := append([]float64{1}, x...)
+
+	// прогноз: P = P + Q
+	for i := 0; i < n; i++ {
+		k.p[i][i] += k.q
+	}
+
+	// ph = P·h, s = h'·P·h + R
+	ph := make([]float64, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			ph[i] += k.p[i][j] * h[j]
+		}
+	}
+	s := k.observationVar
+	predicted := 0.0
+	for i := 0; i < n; i++ {
+		s += h[i] * ph[i]
+		predicted += h[i] * k.theta[i]
+	}
+
+	// коррекция: theta += K·e, P = P - K·h'·P, K = P·h / s
+	e := y - predicted
+	for i := 0; i < n; i++ {
+		k.theta[i] += ph[i] / s * e
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			k.p[i][j] -= ph[i] * ph[j] / s
+		}
+	}
+
+	k.updates++
+}
+
+func (k *Kalman) Ready() bool {
+	return k.updates >= k.warmUp
+}
+
+func (k *Kalman) Alpha() float64 {
+	return k.theta[0]
+}
+
+func (k *Kalman) Betas() []float64 {
+	return k.theta[1:]
+}
+
+// ZScore — отклонение значения от среднего по скользящему окну в стандартных отклонениях
+type ZScore struct {
+	window int
+	values []float64
+}
+
+func NewZScore(window int) *ZScore {
+	return &ZScore{window: window}
+}
+
+// Update добавляет значение и возвращает его z-оценку относительно окна, включая само значение.
+// Пока окно не заполнено или разброс нулевой, возвращает NaN.
+func (z *ZScore) Update(value float64) float64 {
+	z.values = append(z.values, value)
+	if len(z.values) > z.window {
+		z.values = z.values[1:]
+	}
+	if len(z.values) < z.window {
+		return math.NaN()
+	}
+
+	var mean, variance float64
+	for _, v := range z.values {
+		mean += v
+	}
+	mean /= float64(len(z.values))
+	for _, v := range z.values {
+		variance += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(variance / float64(len(z.values)))
+	if std == 0 {
+		return math.NaN()
+	}
+	return (value - mean) / std
+}
@@ -0,0 +1,109 @@
+package pairs
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cointegratedPair строит x — случайное блуждание, y = 5 + 2·x + стационарный шум AR(1)
+func cointegratedPair(n int, seed int64) ([]float64, []float64) {
+	rnd := rand.New(rand.NewSource(seed))
+	x := make([]float64, n)
+	y := make([]float64, n)
+	price, noise := 100.0, 0.0
+	for i := 0; i < n; i++ {
+		price += rnd.NormFloat64()
+		noise = 0.5*noise + rnd.NormFloat64()
+		x[i] = price
+		y[i] = 5 + 2*price + noise
+	}
+	return y, x
+}
+
+func randomWalk(n int, seed int64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	series := make([]float64, n)
+	price := 100.0
+	for i := range series {
+		price += rnd.NormFloat64()
+		series[i] = price
+	}
+	return series
+}
+
+func TestEngleGranger(t *testing.T) {
+	y, x := cointegratedPair(500, 1)
+
+	result, err := EngleGranger(y, [][]float64{x}, -1)
+	assert.NoError(t, err)
+	assert.True(t, result.Cointegrated())
+	assert.InDelta(t, 2.0, result.Betas[0], 0.05)
+	assert.Less(t, result.HalfLife, 5.0)
+
+	// два независимых случайных блуждания не коинтегрированы
+	result, err = EngleGranger(randomWalk(500, 2), [][]float64{randomWalk(500, 3)}, -1)
+	assert.NoError(t, err)
+	assert.False(t, result.Cointegrated())
+
+	_, err = EngleGranger(y, [][]float64{x[:10]}, -1)
+	assert.Error(t, err)
+
+	for _, series := range [][]float64{nil, {1}, {1, 2}} {
+		_, err = ADF(series, -1, 1)
+		assert.Error(t, err, "%v", series)
+	}
+	_, err = EngleGranger(nil, [][]float64{nil}, -1)
+	assert.Error(t, err)
+}
+
+func TestHedgeModels(t *testing.T) {
+	y, x := cointegratedPair(1000, 4)
+
+	for _, kind := range []string{"ols", "kalman"} {
+		model, err := NewHedgeModel(kind, 1, 100)
+		assert.NoError(t, err)
+
+		for i := range y {
+			model.Update(y[i], []float64{x[i]})
+		}
+
+		assert.True(t, model.Ready(), kind)
+		assert.InDelta(t, 2.0, model.Betas()[0], 0.1, kind)
+		assert.Less(t, math.Abs(Spread(model, y[len(y)-1], []float64{x[len(x)-1]})), 5.0, kind)
+	}
+
+	_, err := NewHedgeModel("unknown", 1, 100)
+	assert.Error(t, err)
+}
+
+func TestZScore(t *testing.T) {
+	z := NewZScore(3)
+	assert.True(t, math.IsNaN(z.Update(1)))
+	assert.True(t, math.IsNaN(z.Update(2)))
+	assert.InDelta(t, 1.2247, z.Update(3), 1e-3)
+}
+
+func TestPairCandidate(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	y, x := cointegratedPair(300, 5)
+
+	pricesA := make(map[time.Time]float64)
+	pricesB := make(map[time.Time]float64)
+	for i := range y {
+		ts := now.Add(time.Duration(i) * time.Minute)
+		pricesA[ts] = y[i]
+		pricesB[ts] = x[i]
+	}
+	// свеча без пары не участвует в тесте
+	pricesA[now.Add(-time.Minute)] = 1
+
+	candidate, err := TestPair("ETHUSDT", pricesA, "BTCUSDT", pricesB)
+	assert.NoError(t, err)
+	assert.Equal(t, 300, candidate.Bars)
+	assert.Greater(t, candidate.Correlation, 0.9)
+	assert.LessOrEqual(t, candidate.Significance, 0.05)
+}
@@ -0,0 +1,113 @@
+package pairs
+
+import (
+	"fmt"
+	"math"
+)
+
+// regression — результат линейной регрессии методом наименьших квадратов
+type regression struct {
+	coef      []float64 // коэффициенты в порядке столбцов матрицы регрессоров
+	stdErr    []float64 // стандартные ошибки коэффициентов
+	residuals []float64
+}
+
+// fitOLS оценивает y = X·coef методом наименьших квадратов через нормальные уравнения.
+// Строки X — наблюдения, столбцы — регрессоры (для свободного члена нужен столбец из единиц).
+func fitOLS(x [][]float64, y []float64) (*regression, error) {
+	n := len(y)
+	if n == 0 || len(x) != n {
+		return nil, fmt.Errorf("regression: %d observations and %d rows", n, len(x))
+	}
+	k := len(x[0])
+	if n <= k {
+		return nil, fmt.Errorf("regression: not enough observations: %d for %d regressors", n, k)
+	}
+
+	xtx := make([][]float64, k)
+	xty := make([]float64, k)
+	for i := range xtx {
+		xtx[i] = make([]float64, k)
+	}
+	for row := 0; row < n; row++ {
+		for i := 0; i < k; i++ {
+			xty[i] += x[row][i] * y[row]
+			for j := 0; j < k; j++ {
+				xtx[i][j] += x[row][i] * x[row][j]
+			}
+		}
+	}
+
+	inv, err := invert(xtx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &regression{coef: make([]float64, k), stdErr: make([]float64, k), residuals: make([]float64, n)}
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			r.coef[i] += inv[i][j] * xty[j]
+		}
+	}
+
+	var rss float64
+	for row := 0; row < n; row++ {
+		fitted := 0.0
+		for i := 0; i < k; i++ {
+			fitted += x[row][i] * r.coef[i]
+		}
+		r.residuals[row] = y[row] - fitted
+		rss += r.residuals[row] * r.residuals[row]
+	}
+
+	sigma2 := rss / float64(n-k)
+	for i := 0; i < k; i++ {
+		r.stdErr[i] = math.Sqrt(sigma2 * inv[i][i])
+	}
+
+	return r, nil
+}
+
+// invert обращает матрицу методом Гаусса — Жордана с выбором главного элемента
+func invert(m [][]float64) ([][]float64, error) {
+	n := len(m)
+	a := make([][]float64, n)
+	for i := range m {
+		a[i] = make([]float64, 2*n)
+		copy(a[i], m[i])
+		a[i][n+i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, fmt.Errorf("regression: singular matrix, regressors are collinear")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		div := a[col][col]
+		for j := range a[col] {
+			a[col][j] /= div
+		}
+		for row := 0; row < n; row++ {
+			if row == col || a[row][col] == 0 {
+				continue
+			}
+			factor := a[row][col]
+			for j := range a[row] {
+				a[row][j] -= factor * a[col][j]
+			}
+		}
+	}
+
+	inv := make([][]float64, n)
+	for i := range a {
+		inv[i] = a[i][n:]
+	}
+	return inv, nil
+}
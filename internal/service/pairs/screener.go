@@ -0,0 +1,162 @@
+package pairs
+
+import (
+	"crypto-trading-bot/internal/service/marketdata"
+	"crypto-trading-bot/internal/types"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Candidate — пара символов, проверенная на коинтеграцию
+type Candidate struct {
+	Y            string  // символ, который покупается или продаётся на единицу спреда
+	X            string  // символ хеджирования
+	Beta         float64 // количество X на единицу Y
+	ADFStat      float64
+	Significance float64 // 0.01, 0.05, 0.10 или 0, если пара не коинтегрирована
+	HalfLife     float64 // в барах
+	Correlation  float64
+	Bars         int // количество совпавших по времени свечей
+}
+
+// Screener ищет коинтегрированные пары среди символов с историческими данными
+type Screener struct {
+	marketDataService marketdata.MarketDataService
+}
+
+func NewScreener(marketDataService marketdata.MarketDataService) *Screener {
+	return &Screener{marketDataService: marketDataService}
+}
+
+// ScreenActive проверяет все пары активных символов market_data_statuss с указанным интервалом
+func (s *Screener) ScreenActive(interval string, start time.Time, end time.Time) ([]*Candidate, error) {
+	statusList, err := s.marketDataService.GetMarketDataStatusList()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, status := range statusList {
+		if status.Active && status.TimeFrame == interval && !seen[status.Symbol] {
+			seen[status.Symbol] = true
+			symbols = append(symbols, status.Symbol)
+		}
+	}
+
+	return s.Screen(symbols, interval, start, end)
+}
+
+// Screen проверяет все пары символов и возвращает их в порядке убывания силы коинтеграции:
+// сначала по уровню значимости, затем по статистике ADF
+func (s *Screener) Screen(symbols []string, interval string, start time.Time, end time.Time) ([]*Candidate, error) {
+	prices := make(map[string]map[time.Time]float64, len(symbols))
+	for _, symbol := range symbols {
+		data, err := s.marketDataService.GetMarketDataPeriod(symbol, interval, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s %s: %w", symbol, interval, err)
+		}
+		prices[symbol] = closePrices(data)
+	}
+
+	var result []*Candidate
+	for i := 0; i < len(symbols); i++ {
+		for j := i + 1; j < len(symbols); j++ {
+			candidate, err := TestPair(symbols[i], prices[symbols[i]], symbols[j], prices[symbols[j]])
+			if err != nil {
+				continue
+			}
+			result = append(result, candidate)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Significance != b.Significance {
+			// 0 — не коинтегрирована, идёт в конец
+			if a.Significance == 0 || b.Significance == 0 {
+				return b.Significance == 0
+			}
+			return a.Significance < b.Significance
+		}
+		return a.ADFStat < b.ADFStat
+	})
+
+	return result, nil
+}
+
+// TestPair выравнивает цены двух символов по времени и выполняет тест Энгла — Грейнджера в обе стороны.
+// Возвращает направление с более сильной статистикой.
+func TestPair(symbolA string, pricesA map[time.Time]float64, symbolB string, pricesB map[time.Time]float64) (*Candidate, error) {
+	times := make([]time.Time, 0, len(pricesA))
+	for t := range pricesA {
+		if _, ok := pricesB[t]; ok {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	a := make([]float64, len(times))
+	b := make([]float64, len(times))
+	for i, t := range times {
+		a[i], b[i] = pricesA[t], pricesB[t]
+	}
+
+	var best *Candidate
+	for _, leg := range []struct {
+		y, x   string
+		ys, xs []float64
+	}{{symbolA, symbolB, a, b}, {symbolB, symbolA, b, a}} {
+		r, err := EngleGranger(leg.ys, [][]float64{leg.xs}, -1)
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", leg.y, leg.x, err)
+		}
+		if best != nil && r.ADF.Statistic >= best.ADFStat {
+			continue
+		}
+		best = &Candidate{
+			Y:            leg.y,
+			X:            leg.x,
+			Beta:         r.Betas[0],
+			ADFStat:      r.ADF.Statistic,
+			Significance: r.ADF.Significance(),
+			HalfLife:     r.HalfLife,
+			Correlation:  correlation(leg.ys, leg.xs),
+			Bars:         len(times),
+		}
+	}
+
+	return best, nil
+}
+
+func closePrices(data []*types.MarketData) map[time.Time]float64 {
+	prices := make(map[time.Time]float64, len(data))
+	for _, md := range data {
+		prices[md.Timestamp] = md.ClosePrice
+	}
+	return prices
+}
+
+func correlation(a []float64, b []float64) float64 {
+	n := float64(len(a))
+	var meanA, meanB float64
+	for i := range a {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= n
+	meanB /= n
+
+	var cov, varA, varB float64
+	for i := range a {
+		cov += (a[i] - meanA) * (b[i] - meanB)
+		varA += (a[i] - meanA) * (a[i] - meanA)
+		varB += (b[i] - meanB) * (b[i] - meanB)
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
@@ -29,8 +29,8 @@ func CollectMetrics(series []*types.Series) SeriesMetrics {
 	var totalValuesSum float64
 	m.MinValue = math.MaxFloat64
 	m.MaxValue = -math.MaxFloat64
-	m.StartTime = time.Now().Add(24 * time.Hour)           // будем искать минимум
-	m.EndTime, _ = time.Parse(time.DateOnly, "2000-01-01") // будем искать максимум
+	// период данных ищется от первой точки, а не от текущего времени: результат не зависит от момента вызова
+	first := true
 
 	for _, s := range series {
 		length := len(s.Points)
@@ -50,12 +50,13 @@ func CollectMetrics(series []*types.Series) SeriesMetrics {
 			if p.Value > m.MaxValue {
 				m.MaxValue = p.Value
 			}
-			if p.Time.Before(m.StartTime) {
+			if first || p.Time.Before(m.StartTime) {
 				m.StartTime = p.Time
 			}
-			if p.Time.After(m.EndTime) {
+			if first || p.Time.After(m.EndTime) {
 				m.EndTime = p.Time
 			}
+			first = false
 		}
 	}
 
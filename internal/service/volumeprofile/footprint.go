@@ -0,0 +1,125 @@
+package volumeprofile
+
+import (
+	"crypto-trading-bot/internal/types"
+	"crypto-trading-bot/internal/utils"
+	"sort"
+	"time"
+)
+
+// FootprintBar — распределение объёма покупок и продаж по ценам внутри бара
+type FootprintBar struct {
+	Symbol          string    `json:"symbol"`
+	TimeFrame       string    `json:"time_frame"`
+	Start           time.Time `json:"start"`
+	Open            float64   `json:"open"`
+	High            float64   `json:"high"`
+	Low             float64   `json:"low"`
+	Close           float64   `json:"close"`
+	Levels          []Level   `json:"levels"` // по возрастанию цены
+	BuyVolume       float64   `json:"buy_volume"`
+	SellVolume      float64   `json:"sell_volume"`
+	Delta           float64   `json:"delta"`            // покупки - продажи за бар
+	CumulativeDelta float64   `json:"cumulative_delta"` // накопленная дельта с начала потока
+}
+
+// ClusterData преобразует бар в строки таблицы cluster_data: по строке на каждую сторону уровня
+func (b *FootprintBar) ClusterData() []*types.ClusterData {
+	result := make([]*types.ClusterData, 0, len(b.Levels)*2)
+	for _, l := range b.Levels {
+		result = append(result,
+			&types.ClusterData{Timestamp: b.Start, Symbol: b.Symbol, TimeFrame: b.TimeFrame, IsBuySell: true, ClusterPrice: l.Price, Volume: l.BuyVolume},
+			&types.ClusterData{Timestamp: b.Start, Symbol: b.Symbol, TimeFrame: b.TimeFrame, IsBuySell: false, ClusterPrice: l.Price, Volume: l.SellVolume},
+		)
+	}
+	return result
+}
+
+// FootprintBuilder собирает бары старшего интервала из свечей младшего интервала
+type FootprintBuilder struct {
+	interval        string
+	tickSize        float64
+	current         *FootprintBar
+	bins            map[int64]*Level
+	cumulativeDelta float64
+}
+
+func NewFootprintBuilder(interval string, tickSize float64) *FootprintBuilder {
+	return &FootprintBuilder{
+		interval: interval,
+		tickSize: tickSize,
+	}
+}
+
+// Add добавляет свечу. Если свеча открывает новый бар, возвращает завершённый предыдущий бар.
+func (b *FootprintBuilder) Add(md *types.MarketData) (*FootprintBar, error) {
+	start, _, _, err := utils.GetIntervalBounds(md.Timestamp, b.interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var completed *FootprintBar
+	if b.current != nil && !b.current.Start.Equal(start) {
+		completed = b.Flush()
+	}
+
+	if b.current == nil {
+		b.current = &FootprintBar{
+			Symbol:    md.Symbol,
+			TimeFrame: b.interval,
+			Start:     start,
+			Open:      md.OpenPrice,
+			High:      md.HightPrice,
+			Low:       md.LowPrice,
+		}
+		b.bins = make(map[int64]*Level)
+	}
+
+	bar := b.current
+	if md.HightPrice > bar.High {
+		bar.High = md.HightPrice
+	}
+	if md.LowPrice > 0 && (bar.Low == 0 || md.LowPrice < bar.Low) {
+		bar.Low = md.LowPrice
+	}
+	bar.Close = md.ClosePrice
+
+	for _, bv := range distribute(md, b.tickSize) {
+		level, ok := b.bins[bv.bin]
+		if !ok {
+			level = &Level{Price: fromBin(bv.bin, b.tickSize)}
+			b.bins[bv.bin] = level
+		}
+		level.BuyVolume += bv.buy
+		level.SellVolume += bv.sell
+		level.Volume = level.BuyVolume + level.SellVolume
+
+		bar.BuyVolume += bv.buy
+		bar.SellVolume += bv.sell
+	}
+
+	return completed, nil
+}
+
+// Flush завершает текущий бар и возвращает его (nil, если бар пуст)
+func (b *FootprintBuilder) Flush() *FootprintBar {
+	bar := b.current
+	if bar == nil {
+		return nil
+	}
+
+	levels := make([]Level, 0, len(b.bins))
+	for _, level := range b.bins {
+		levels = append(levels, *level)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Price < levels[j].Price })
+	bar.Levels = levels
+
+	bar.Delta = bar.BuyVolume - bar.SellVolume
+	b.cumulativeDelta += bar.Delta
+	bar.CumulativeDelta = b.cumulativeDelta
+
+	b.current = nil
+	b.bins = nil
+	return bar
+}
@@ -0,0 +1,197 @@
+// Профиль объёма (volume at price) и footprint по объёмам покупок/продаж.
+package volumeprofile
+
+import (
+	"crypto-trading-bot/internal/types"
+	"math"
+	"sort"
+)
+
+// Level — объём на ценовом уровне
+type Level struct {
+	Price      float64 `json:"price"`
+	Volume     float64 `json:"volume"`
+	BuyVolume  float64 `json:"buy_volume"`
+	SellVolume float64 `json:"sell_volume"`
+}
+
+// Delta возвращает разницу объёма покупок и продаж на уровне
+func (l Level) Delta() float64 {
+	return l.BuyVolume - l.SellVolume
+}
+
+// Profile — профиль объёма за окно свечей
+type Profile struct {
+	Levels          []Level   `json:"levels"` // по возрастанию цены
+	TotalVolume     float64   `json:"total_volume"`
+	POC             float64   `json:"poc"` // уровень с максимальным объёмом (point of control)
+	ValueAreaHigh   float64   `json:"value_area_high"`
+	ValueAreaLow    float64   `json:"value_area_low"`
+	HighVolumeNodes []float64 `json:"high_volume_nodes"`
+	LowVolumeNodes  []float64 `json:"low_volume_nodes"`
+}
+
+// Options — параметры расчёта профиля
+type Options struct {
+	TickSize         float64 // шаг ценовой сетки
+	ValueAreaPercent float64 // доля объёма в зоне стоимости, обычно 0.7
+	HVNFactor        float64 // узел высокого объёма: локальный максимум не ниже HVNFactor * средний объём уровня
+	LVNFactor        float64 // узел низкого объёма: локальный минимум не выше LVNFactor * средний объём уровня
+}
+
+// DefaultOptions возвращает параметры по умолчанию для заданного шага цены
+func DefaultOptions(tickSize float64) Options {
+	return Options{
+		TickSize:         tickSize,
+		ValueAreaPercent: 0.7,
+		HVNFactor:        1.5,
+		LVNFactor:        0.5,
+	}
+}
+
+// binVolume — доля объёма свечи, попавшая в ценовой уровень
+type binVolume struct {
+	bin  int64
+	buy  float64
+	sell float64
+}
+
+// distribute распределяет объём свечи равномерно по уровням между Low и High.
+// Если диапазон свечи неизвестен, весь объём относится к уровню цены закрытия.
+func distribute(md *types.MarketData, tickSize float64) []binVolume {
+	buy, sell := md.BuyVolume, md.SellVolume
+	if buy+sell == 0 {
+		// разбивки по сторонам нет — считаем объём нейтральным
+		buy, sell = md.Volume/2, md.Volume/2
+	}
+
+	if md.LowPrice <= 0 || md.HightPrice < md.LowPrice {
+		return []binVolume{{bin: toBin(md.ClosePrice, tickSize), buy: buy, sell: sell}}
+	}
+
+	from, to := toBin(md.LowPrice, tickSize), toBin(md.HightPrice, tickSize)
+	n := float64(to - from + 1)
+	result := make([]binVolume, 0, to-from+1)
+	for bin := from; bin <= to; bin++ {
+		result = append(result, binVolume{bin: bin, buy: buy / n, sell: sell / n})
+	}
+	return result
+}
+
+func toBin(price, tickSize float64) int64 {
+	return int64(math.Floor(price/tickSize + 1e-9))
+}
+
+func fromBin(bin int64, tickSize float64) float64 {
+	return float64(bin) * tickSize
+}
+
+// RollingProfile — профиль объёма по скользящему окну из последних N свечей
+type RollingProfile struct {
+	opts   Options
+	window int
+	bars   [][]binVolume // вклад каждой свечи окна, для вычитания при сдвиге
+	bins   map[int64]*Level
+}
+
+func NewRollingProfile(window int, opts Options) *RollingProfile {
+	return &RollingProfile{
+		opts:   opts,
+		window: window,
+		bins:   make(map[int64]*Level),
+	}
+}
+
+// Add добавляет свечу в окно, при переполнении самая старая свеча вычитается
+func (p *RollingProfile) Add(md *types.MarketData) {
+	bar := distribute(md, p.opts.TickSize)
+	p.bars = append(p.bars, bar)
+	p.apply(bar, 1)
+
+	if len(p.bars) > p.window {
+		p.apply(p.bars[0], -1)
+		p.bars[0] = nil
+		p.bars = p.bars[1:]
+	}
+}
+
+func (p *RollingProfile) apply(bar []binVolume, sign float64) {
+	for _, bv := range bar {
+		level, ok := p.bins[bv.bin]
+		if !ok {
+			level = &Level{Price: fromBin(bv.bin, p.opts.TickSize)}
+			p.bins[bv.bin] = level
+		}
+		level.BuyVolume += sign * bv.buy
+		level.SellVolume += sign * bv.sell
+		level.Volume = level.BuyVolume + level.SellVolume
+
+		if level.Volume <= 1e-12 {
+			delete(p.bins, bv.bin)
+		}
+	}
+}
+
+// Profile рассчитывает профиль по текущему окну
+func (p *RollingProfile) Profile() *Profile {
+	levels := make([]Level, 0, len(p.bins))
+	for _, level := range p.bins {
+		levels = append(levels, *level)
+	}
+	return Build(levels, p.opts)
+}
+
+// Build рассчитывает POC, зону стоимости и узлы объёма по уровням профиля
+func Build(levels []Level, opts Options) *Profile {
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Price < levels[j].Price })
+
+	profile := &Profile{Levels: levels}
+	if len(levels) == 0 {
+		return profile
+	}
+
+	poc := 0
+	for i, l := range levels {
+		profile.TotalVolume += l.Volume
+		if l.Volume > levels[poc].Volume {
+			poc = i
+		}
+	}
+	profile.POC = levels[poc].Price
+
+	// зона стоимости: расширяемся от POC в сторону большего объёма
+	lo, hi := poc, poc
+	volume := levels[poc].Volume
+	for volume < profile.TotalVolume*opts.ValueAreaPercent && (lo > 0 || hi < len(levels)-1) {
+		switch {
+		case lo == 0:
+			hi++
+			volume += levels[hi].Volume
+		case hi == len(levels)-1:
+			lo--
+			volume += levels[lo].Volume
+		case levels[hi+1].Volume >= levels[lo-1].Volume:
+			hi++
+			volume += levels[hi].Volume
+		default:
+			lo--
+			volume += levels[lo].Volume
+		}
+	}
+	profile.ValueAreaLow = levels[lo].Price
+	profile.ValueAreaHigh = levels[hi].Price
+
+	// узлы объёма — локальные экстремумы гистограммы
+	mean := profile.TotalVolume / float64(len(levels))
+	for i := 1; i < len(levels)-1; i++ {
+		v, prev, next := levels[i].Volume, levels[i-1].Volume, levels[i+1].Volume
+		if v > prev && v >= next && v >= mean*opts.HVNFactor {
+			profile.HighVolumeNodes = append(profile.HighVolumeNodes, levels[i].Price)
+		}
+		if v < prev && v <= next && v <= mean*opts.LVNFactor {
+			profile.LowVolumeNodes = append(profile.LowVolumeNodes, levels[i].Price)
+		}
+	}
+
+	return profile
+}
@@ -0,0 +1,73 @@
+package volumeprofile
+
+import (
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	levels := []Level{
+		{Price: 100, Volume: 10},
+		{Price: 101, Volume: 5},
+		{Price: 102, Volume: 30},
+		{Price: 103, Volume: 40},
+		{Price: 104, Volume: 10},
+		{Price: 105, Volume: 2},
+		{Price: 106, Volume: 3},
+	}
+
+	profile := Build(levels, DefaultOptions(1))
+
+	assert.Equal(t, 100.0, profile.TotalVolume)
+	assert.Equal(t, 103.0, profile.POC)
+	// 40 + 30 = 70% объёма
+	assert.Equal(t, 102.0, profile.ValueAreaLow)
+	assert.Equal(t, 103.0, profile.ValueAreaHigh)
+	assert.Equal(t, []float64{103}, profile.HighVolumeNodes)
+	assert.Equal(t, []float64{101, 105}, profile.LowVolumeNodes)
+}
+
+func TestRollingProfile(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	profile := NewRollingProfile(2, DefaultOptions(1))
+
+	profile.Add(&types.MarketData{Timestamp: now, ClosePrice: 100, Volume: 10, BuyVolume: 6, SellVolume: 4})
+	profile.Add(&types.MarketData{Timestamp: now.Add(time.Second), ClosePrice: 101, Volume: 20, BuyVolume: 5, SellVolume: 15})
+	assert.Equal(t, 101.0, profile.Profile().POC)
+
+	// первая свеча выходит из окна
+	profile.Add(&types.MarketData{Timestamp: now.Add(2 * time.Second), ClosePrice: 102, Volume: 5, BuyVolume: 5})
+	result := profile.Profile()
+	assert.Len(t, result.Levels, 2)
+	assert.InDelta(t, 25.0, result.TotalVolume, 1e-9)
+}
+
+func TestFootprintBuilder(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	builder := NewFootprintBuilder("1m", 1)
+
+	bar, err := builder.Add(&types.MarketData{Symbol: "BTCUSDT", Timestamp: now, ClosePrice: 100, Volume: 10, BuyVolume: 8, SellVolume: 2})
+	assert.NoError(t, err)
+	assert.Nil(t, bar)
+
+	bar, _ = builder.Add(&types.MarketData{Symbol: "BTCUSDT", Timestamp: now.Add(30 * time.Second), ClosePrice: 101, Volume: 10, BuyVolume: 3, SellVolume: 7})
+	assert.Nil(t, bar)
+
+	// свеча следующей минуты завершает бар
+	bar, _ = builder.Add(&types.MarketData{Symbol: "BTCUSDT", Timestamp: now.Add(time.Minute), ClosePrice: 101, Volume: 4, BuyVolume: 1, SellVolume: 3})
+	if assert.NotNil(t, bar) {
+		assert.Len(t, bar.Levels, 2)
+		assert.InDelta(t, 2.0, bar.Delta, 1e-9)
+		assert.InDelta(t, 2.0, bar.CumulativeDelta, 1e-9)
+		assert.Len(t, bar.ClusterData(), 4)
+	}
+
+	bar = builder.Flush()
+	if assert.NotNil(t, bar) {
+		assert.InDelta(t, -2.0, bar.Delta, 1e-9)
+		assert.InDelta(t, 0.0, bar.CumulativeDelta, 1e-9)
+	}
+}
@@ -0,0 +1,14 @@
+package settings
+
+// Настройки симулированного брокера бэктеста
+type BacktestSettings struct {
+	InitialCapital float64 `json:"initial_capital" validate:"required,gt=0"`
+	Commission     float64 `json:"commission" validate:"gte=0,lt=1"` // доля от объёма сделки
+	Spread         float64 `json:"spread" validate:"gte=0,lt=1"`     // доля от цены, рыночный ордер исполняется хуже цены на половину спреда
+}
+
+func (d BacktestSettings) SettingsType() string {
+	return "backtest"
+}
+
+var _ Settings = BacktestSettings{}
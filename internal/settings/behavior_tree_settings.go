@@ -0,0 +1,17 @@
+package settings
+
+import "encoding/json"
+
+// Настройки стратегии на поведенческом дереве
+type BehaviorTreeSettings struct {
+	Symbol     string          `json:"symbol" validate:"required"`
+	Interval   string          `json:"interval" validate:"required"`
+	Indicators []string        `json:"indicators" validate:"dive,required"` // индикаторы для узлов condition, например "rsi_14"
+	Tree       json.RawMessage `json:"tree" validate:"required"`            // корневой узел дерева
+}
+
+func (d BehaviorTreeSettings) SettingsType() string {
+	return "behavior_tree"
+}
+
+var _ Settings = BehaviorTreeSettings{}
@@ -0,0 +1,64 @@
+package settings
+
+// Модели проскальзывания
+const (
+	SlippageFixed      = "fixed"      // постоянная доля цены
+	SlippageVolatility = "volatility" // доля цены, пропорциональная волатильности
+)
+
+// Модели задержки исполнения
+const (
+	LatencyFixed  = "fixed"  // постоянная задержка
+	LatencyRandom = "random" // равномерно распределённая задержка в диапазоне
+)
+
+// Настройки моделей издержек бэктеста. Модель без настроек не применяется.
+// Модель комиссий заменяет постоянную комиссию BacktestSettings.Commission.
+type CostSettings struct {
+	Slippage         *SlippageModel `json:"slippage"`
+	Impact           *ImpactModel   `json:"impact"`
+	Latency          *LatencyModel  `json:"latency"`
+	Fees             *FeeModel      `json:"fees"`
+	VolatilityWindow int            `json:"volatility_window" validate:"omitempty,min=2"` // количество свечей для оценки волатильности, по умолчанию 20
+}
+
+type SlippageModel struct {
+	Model  string  `json:"model" validate:"required,oneof=fixed volatility"`
+	Value  float64 `json:"value" validate:"gte=0,lt=1"` // fixed: доля цены
+	Factor float64 `json:"factor" validate:"gte=0"`     // volatility: множитель стандартного отклонения доходности свечи
+}
+
+// Влияние на рынок по закону квадратного корня: Coefficient * волатильность * sqrt(количество / объём свечи)
+type ImpactModel struct {
+	Coefficient float64 `json:"coefficient" validate:"required,gt=0"`
+}
+
+// Задержка от сигнала до исполнения. Ордер исполняется на первой свече, закрытой после окончания задержки.
+type LatencyModel struct {
+	Model string `json:"model" validate:"required,oneof=fixed random"`
+	Delay string `json:"delay"` // fixed: задержка, например "500ms"
+	Min   string `json:"min"`   // random: нижняя граница
+	Max   string `json:"max"`   // random: верхняя граница
+	Seed  int64  `json:"seed"`  // random: начальное значение генератора для воспроизводимости
+}
+
+// Комиссии мейкера (лимитные ордера) и тейкера (рыночные ордера) со скидками за объём торгов
+type FeeModel struct {
+	Maker  float64   `json:"maker" validate:"gte=0,lt=1"`
+	Taker  float64   `json:"taker" validate:"gte=0,lt=1"`
+	Period string    `json:"period"`                          // период расчёта объёма торгов для уровня скидки, по умолчанию "720h"
+	Tiers  []FeeTier `json:"tiers" validate:"omitempty,dive"` // уровни по возрастанию объёма
+}
+
+// Уровень комиссий, действующий от объёма торгов Volume в валюте котировки
+type FeeTier struct {
+	Volume float64 `json:"volume" validate:"gt=0"`
+	Maker  float64 `json:"maker" validate:"gte=0,lt=1"`
+	Taker  float64 `json:"taker" validate:"gte=0,lt=1"`
+}
+
+func (d CostSettings) SettingsType() string {
+	return "costs"
+}
+
+var _ Settings = CostSettings{}
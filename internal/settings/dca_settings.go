@@ -0,0 +1,26 @@
+package settings
+
+// Настройки стратегии усреднения (DCA). Сделка открывается базовым ордером, при падении цены
+// докупаются страховочные ордера. Отклонение i-го страховочного ордера от цены базового:
+// Deviation·(1 + StepScale + ... + StepScale^(i-1)) процентов, объём: SafetyOrder·VolumeScale^(i-1).
+// Сделка закрывается по TakeProfit процентов от средней цены входа; при заданном TrailingDeviation
+// после достижения TakeProfit выход выполняется при откате от максимума на TrailingDeviation процентов.
+type DCASettings struct {
+	Symbol            string  `json:"symbol" validate:"required"`
+	Interval          string  `json:"interval" validate:"required"`
+	BaseOrder         float64 `json:"base_order" validate:"required,gt=0"`                             // объём базового ордера в валюте котировки
+	SafetyOrder       float64 `json:"safety_order" validate:"required_with=SafetyOrders,gte=0"`        // объём первого страховочного ордера
+	SafetyOrders      int     `json:"safety_orders" validate:"gte=0,lte=100"`                          // количество страховочных ордеров
+	Deviation         float64 `json:"deviation" validate:"required_with=SafetyOrders,gte=0,lt=100"`    // отклонение первого страховочного ордера, %
+	StepScale         float64 `json:"step_scale" validate:"omitempty,gte=1"`                           // множитель шага отклонения, по умолчанию 1
+	VolumeScale       float64 `json:"volume_scale" validate:"omitempty,gte=1"`                         // множитель объёма, по умолчанию 1
+	TakeProfit        float64 `json:"take_profit" validate:"required,gt=0"`                            // %
+	TrailingDeviation float64 `json:"trailing_deviation" validate:"omitempty,gt=0,ltfield=TakeProfit"` // %
+	MaxActiveDeals    int     `json:"max_active_deals" validate:"required,min=1"`
+}
+
+func (d DCASettings) SettingsType() string {
+	return "dca"
+}
+
+var _ Settings = DCASettings{}
@@ -0,0 +1,26 @@
+package settings
+
+// Политики объединения сигналов
+const (
+	EnsembleMajority  = "majority"  // большинство голосов
+	EnsembleWeighted  = "weighted"  // взвешенная сумма голосов с учётом силы сигналов
+	EnsembleUnanimous = "unanimous" // все источники за одну сторону
+	EnsembleFirst     = "first"     // первый сигнал, если его не блокирует источник с правом вето
+)
+
+// Настройки объединения сигналов нескольких источников
+type EnsembleSettings struct {
+	Policy    string             `json:"policy" validate:"required,oneof=majority weighted unanimous first"`
+	Tolerance string             `json:"tolerance" validate:"required"`              // окно сбора сигналов, например "5m"
+	Sources   []string           `json:"sources" validate:"omitempty,dive,required"` // учитываемые источники, пусто — все
+	Weights   map[string]float64 `json:"weights" validate:"omitempty,dive,gte=0"`    // источник -> вес, по умолчанию 1
+	Quorum    int                `json:"quorum" validate:"gte=0"`                    // минимальное количество проголосовавших источников, по умолчанию — количество sources
+	Threshold float64            `json:"threshold" validate:"gte=0,lte=1"`           // минимальная уверенность решения
+	Veto      []string           `json:"veto" validate:"omitempty,dive,required"`    // источники с правом вето для политики first
+}
+
+func (d EnsembleSettings) SettingsType() string {
+	return "ensemble"
+}
+
+var _ Settings = EnsembleSettings{}
@@ -0,0 +1,19 @@
+package settings
+
+// Настройки сеточной стратегии. Уровни расставляются между Lower и Upper включительно:
+// arithmetic — с равным шагом цены, geometric — с равным шагом в процентах.
+type GridSettings struct {
+	Symbol   string  `json:"symbol" validate:"required"`
+	Interval string  `json:"interval" validate:"required"`
+	Lower    float64 `json:"lower" validate:"required,gt=0"`
+	Upper    float64 `json:"upper" validate:"required,gtfield=Lower"`
+	Levels   int     `json:"levels" validate:"required,min=2"`
+	Mode     string  `json:"mode" validate:"required,oneof=arithmetic geometric"`
+	Amount   float64 `json:"amount" validate:"required,gt=0"` // количество в ордере одного уровня
+}
+
+func (d GridSettings) SettingsType() string {
+	return "grid"
+}
+
+var _ Settings = GridSettings{}
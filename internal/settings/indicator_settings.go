@@ -0,0 +1,13 @@
+package settings
+
+// Настройки процессора индикаторов.
+// Индикаторы задаются строками вида "sma_14", "macd_12_26_9", "bb_20_2".
+type IndicatorSettings struct {
+	Indicators []string `json:"indicators" validate:"required,min=1,dive,required"`
+}
+
+func (d IndicatorSettings) SettingsType() string {
+	return "indicators"
+}
+
+var _ Settings = IndicatorSettings{}
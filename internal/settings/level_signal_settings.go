@@ -0,0 +1,20 @@
+package settings
+
+// Настройки сигналов по уровням поддержки и сопротивления из серий кластеров
+type LevelSignalSettings struct {
+	ClusterInterval string  `json:"cluster_interval" validate:"required"`            // интервал кластеризации свечей, например "1h"
+	Clusters        int     `json:"clusters" validate:"required,min=1"`              // количество кластеров на интервал
+	ValueFactor     float64 `json:"value_factor" validate:"required,gt=0"`           // вес отклонения цены при построении серий
+	TimeFactor      float64 `json:"time_factor" validate:"required,gt=0"`            // вес разрыва во времени при построении серий
+	TouchTolerance  float64 `json:"touch_tolerance" validate:"required,gt=0,lt=1"`   // касание уровня: доля цены уровня
+	RetestBars      int     `json:"retest_bars" validate:"min=0"`                    // сколько свечей после пробоя ждать ретест
+	RejectionMargin float64 `json:"rejection_margin" validate:"omitempty,gt=0,lt=1"` // отход цены закрытия от уровня после касания для отбоя: доля цены уровня; по умолчанию 2 * touch_tolerance
+	VolumeWeight    float64 `json:"volume_weight" validate:"omitempty,gte=0,lte=1"`  // вес объёма в силе уровня, остальное — возраст; по умолчанию 0.5
+	MinStrength     float64 `json:"min_strength" validate:"omitempty,gte=0,lte=1"`   // уровни слабее не дают сигналов
+}
+
+func (d LevelSignalSettings) SettingsType() string {
+	return "level_signals"
+}
+
+var _ Settings = LevelSignalSettings{}
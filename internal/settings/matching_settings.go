@@ -0,0 +1,20 @@
+package settings
+
+// Предположения о движении цены внутри свечи
+const (
+	MatchingPathOHLC  = "ohlc"  // open -> high -> low -> close
+	MatchingPathOLHC  = "olhc"  // open -> low -> high -> close
+	MatchingPathWorst = "worst" // для каждого ордера худший из двух путей
+)
+
+// Настройки симулятора исполнения ордеров внутри свечи
+type MatchingSettings struct {
+	Path          string  `json:"path" validate:"required,oneof=ohlc olhc worst"`
+	Participation float64 `json:"participation" validate:"gte=0,lte=1"` // доля объёма свечи, доступная ордерам, 0 — без ограничения
+}
+
+func (d MatchingSettings) SettingsType() string {
+	return "matching"
+}
+
+var _ Settings = MatchingSettings{}
@@ -0,0 +1,26 @@
+package settings
+
+// Методы Monte Carlo анализа прогона
+const (
+	MonteCarloShuffle   = "shuffle"   // случайный порядок сделок прогона
+	MonteCarloBootstrap = "bootstrap" // выборка сделок с возвращением
+	MonteCarloSkip      = "skip"      // случайный пропуск сделок
+	MonteCarloNoise     = "noise"     // повторные прогоны на свечах со случайным шумом цен
+)
+
+// Настройки Monte Carlo анализа устойчивости результатов бэктеста
+type MonteCarloSettings struct {
+	Methods     []string `json:"methods" validate:"required,min=1,dive,oneof=shuffle bootstrap skip noise"`
+	Simulations int      `json:"simulations" validate:"required,min=1"`     // количество симуляций каждого метода
+	Seed        int64    `json:"seed"`                                      // одинаковое значение даёт одинаковый результат
+	Confidence  float64  `json:"confidence" validate:"required,gt=0,lt=1"`  // уровень доверительных интервалов, например 0.95
+	RuinLevel   float64  `json:"ruin_level" validate:"required,gt=0,lte=1"` // разорение — потеря этой доли начального капитала
+	SkipRate    float64  `json:"skip_rate" validate:"gte=0,lt=1"`           // skip: вероятность пропуска сделки
+	Noise       float64  `json:"noise" validate:"gte=0"`                    // noise: стандартное отклонение относительного шума цен
+}
+
+func (d MonteCarloSettings) SettingsType() string {
+	return "monte_carlo"
+}
+
+var _ Settings = MonteCarloSettings{}
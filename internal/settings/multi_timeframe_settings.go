@@ -0,0 +1,13 @@
+package settings
+
+// Настройки объединения потоков нескольких интервалов одного символа
+type MultiTimeframeSettings struct {
+	BaseInterval string   `json:"base_interval" validate:"required"`                 // интервал, на каждом баре которого формируется payload
+	Intervals    []string `json:"intervals" validate:"required,min=1,dive,required"` // старшие интервалы
+}
+
+func (d MultiTimeframeSettings) SettingsType() string {
+	return "multi_timeframe"
+}
+
+var _ Settings = MultiTimeframeSettings{}
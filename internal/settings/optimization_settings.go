@@ -0,0 +1,70 @@
+package settings
+
+// Методы поиска параметров
+const (
+	OptimizationGrid     = "grid"     // перебор всех сочетаний значений
+	OptimizationRandom   = "random"   // случайные сочетания
+	OptimizationBayesian = "bayesian" // последовательный выбор по оценкам плотности удачных и неудачных прогонов (TPE)
+	OptimizationGenetic  = "genetic"  // эволюционный поиск: турнирный отбор, скрещивание и мутация, NSGA-II для нескольких целей
+)
+
+// Типы параметров пространства поиска
+const (
+	ParameterInt         = "int"
+	ParameterFloat       = "float"
+	ParameterCategorical = "categorical"
+)
+
+// Настройки оптимизации параметров стратегии по результатам бэктестов
+type OptimizationSettings struct {
+	Method      string                   `json:"method" validate:"required,oneof=grid random bayesian genetic"`
+	Parameters  []OptimizationParameter  `json:"parameters" validate:"omitempty,dive"` // пусто — параметры с границами из настроек стратегии
+	Iterations  int                      `json:"iterations" validate:"gte=0"`          // random, bayesian: количество прогонов
+	Workers     int                      `json:"workers" validate:"gte=0"`             // количество одновременных прогонов, 0 — по числу процессоров
+	Seed        int64                    `json:"seed"`
+	Objective   string                   `json:"objective" validate:"required"` // показатель отчёта бэктеста, например "sharpe" или "cagr"
+	Minimize    bool                     `json:"minimize"`
+	Objectives  []OptimizationObjective  `json:"objectives" validate:"omitempty,dive"` // genetic: дополнительные цели, отбор по фронтам Парето
+	Constraints []OptimizationConstraint `json:"constraints" validate:"omitempty,dive"`
+	Genetic     *GeneticSettings         `json:"genetic" validate:"required_if=Method genetic,omitempty"`
+	Top         int                      `json:"top" validate:"gte=0"` // количество сохраняемых лучших прогонов, 0 — все
+}
+
+// Дополнительная цель многокритериальной оптимизации
+type OptimizationObjective struct {
+	Metric   string `json:"metric" validate:"required"`
+	Minimize bool   `json:"minimize"`
+}
+
+// Настройки эволюционного поиска
+type GeneticSettings struct {
+	Population  int     `json:"population" validate:"required,min=2"`
+	Generations int     `json:"generations" validate:"required,min=1"`
+	Tournament  int     `json:"tournament" validate:"gte=0"`      // размер турнира, по умолчанию 2
+	Crossover   float64 `json:"crossover" validate:"gte=0,lte=1"` // вероятность скрещивания пары, по умолчанию 0.9
+	Mutation    float64 `json:"mutation" validate:"gte=0,lte=1"`  // вероятность мутации гена, по умолчанию 1 / количество параметров
+	Checkpoint  string  `json:"checkpoint"`                       // каталог для сохранения поколений, поиск продолжается с последнего сохранённого
+}
+
+// Параметр пространства поиска
+type OptimizationParameter struct {
+	Path   string  `json:"path" validate:"required"` // путь в настройках стратегии через точку, элементы массива по номеру: "indicators.0.period"
+	Type   string  `json:"type" validate:"required,oneof=int float categorical"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max" validate:"gtefield=Min"`
+	Step   float64 `json:"step" validate:"gte=0"` // шаг сетки, для int по умолчанию 1, для float в grid обязателен
+	Values []any   `json:"values"`                // categorical: допустимые значения
+}
+
+// Ограничение на показатель прогона: прогоны, нарушившие ограничение, ранжируются после остальных
+type OptimizationConstraint struct {
+	Metric string  `json:"metric" validate:"required"`
+	Op     string  `json:"op" validate:"required,oneof=lt lte gt gte"`
+	Value  float64 `json:"value"`
+}
+
+func (d OptimizationSettings) SettingsType() string {
+	return "optimization"
+}
+
+var _ Settings = OptimizationSettings{}
@@ -0,0 +1,20 @@
+package settings
+
+// Настройки парной торговли. Первый символ торгуется на единицу спреда,
+// остальные — ноги хеджирования в количестве beta на единицу первого.
+type PairsSettings struct {
+	Symbols  []string `json:"symbols" validate:"required,min=2,unique,dive,required"`
+	Interval string   `json:"interval" validate:"required"`
+	Hedge    string   `json:"hedge" validate:"required,oneof=ols kalman"` // оценка коэффициентов хеджирования
+	Window   int      `json:"window" validate:"required,min=10"`          // окно регрессии и z-оценки спреда
+	EntryZ   float64  `json:"entry_z" validate:"required,gt=0"`           // вход при |z| выше
+	ExitZ    float64  `json:"exit_z" validate:"gte=0,ltfield=EntryZ"`     // выход при возврате |z| ниже
+	StopZ    float64  `json:"stop_z" validate:"omitempty,gtfield=EntryZ"` // принудительный выход при |z| выше
+	Amount   float64  `json:"amount" validate:"required,gt=0"`            // количество первого символа
+}
+
+func (d PairsSettings) SettingsType() string {
+	return "pairs"
+}
+
+var _ Settings = PairsSettings{}
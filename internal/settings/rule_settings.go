@@ -0,0 +1,21 @@
+package settings
+
+// Настройки стратегии на правилах, записанных выражениями, например
+// "rsi(14) < 30 and close > sma(close, 50)"
+type RuleSettings struct {
+	Symbol   string  `json:"symbol" validate:"required"`
+	Interval string  `json:"interval" validate:"required"`
+	Entry    string  `json:"entry" validate:"required"`                // условие входа в позицию
+	Exit     string  `json:"exit"`                                     // условие выхода, без него позиция не закрывается
+	Side     string  `json:"side" validate:"omitempty,oneof=buy sell"` // сторона входа, по умолчанию buy
+	Amount   float64 `json:"amount" validate:"required,gt=0"`
+
+	Indicators []string             `json:"indicators" validate:"dive,required"` // индикаторы, значения которых доступны правилам, например "atr_14"
+	Levels     *LevelSignalSettings `json:"levels" validate:"omitempty"`         // уровни из серий кластеров, дают правилам cluster_level_dist
+}
+
+func (d RuleSettings) SettingsType() string {
+	return "rules"
+}
+
+var _ Settings = RuleSettings{}
@@ -0,0 +1,330 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema — JSON Schema настроек, построенная по типам полей и тегам json и validate.
+// Правила, которые в JSON Schema не выражаются (сравнение полей gtfield, required_with и т.п.),
+// в схему не попадают и проверяются только при Build.
+// Как и в validate, required для чисел и строк запрещает нулевое значение,
+// а правила после omitempty не проверяются для нулевого значения.
+type Schema struct {
+	Dialect              string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Const                any                `json:"const,omitempty"` // nil — нет ограничения, 0 и "" выводятся
+	Not                  *Schema            `json:"not,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// SchemaOf строит схему настроек по их структуре
+func SchemaOf(s Settings) *Schema {
+	schema := schemaOfType(reflect.TypeOf(s))
+	schema.Dialect = schemaDialect
+	schema.Title = s.SettingsType()
+	return schema
+}
+
+func schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		// произвольный JSON
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOfType(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		addFields(schema, t)
+		return schema
+	}
+
+	return &Schema{}
+}
+
+// addFields добавляет в схему объекта поля структуры, поля встроенных структур поднимаются на уровень выше
+func addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaOfType(field.Type)
+		if applyTags(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	sort.Strings(schema.Required)
+}
+
+// applyTags переносит правила validate в схему поля, возвращает признак обязательности поля.
+// Правила после dive относятся к элементам массива или значениям словаря.
+func applyTags(schema *Schema, tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		if rule == "dive" {
+			element := schema.Items
+			if element == nil {
+				element = schema.AdditionalProperties
+			}
+			if element != nil {
+				applyTags(element, strings.Join(rules[i+1:], ","))
+			}
+			rules = rules[:i]
+			break
+		}
+	}
+
+	required, omitempty := false, false
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "omitempty":
+			omitempty = true
+		case "min", "gte":
+			setLowerBound(schema, param, false)
+		case "gt":
+			setLowerBound(schema, param, true)
+		case "max", "lte":
+			setUpperBound(schema, param, false)
+		case "lt":
+			setUpperBound(schema, param, true)
+		case "len":
+			setLowerBound(schema, param, false)
+			setUpperBound(schema, param, false)
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(schema.Type, value))
+			}
+		case "unique":
+			schema.UniqueItems = true
+		case "email", "url", "uri", "uuid", "hostname", "ipv4", "ipv6":
+			schema.Format = name
+		}
+	}
+
+	if zero, ok := zeroValue(schema.Type); ok {
+		switch {
+		case omitempty:
+			allowZero(schema, zero)
+		case required && schema.Type == "string":
+			if schema.MinLength == nil || *schema.MinLength < 1 {
+				n := 1
+				schema.MinLength = &n
+			}
+		case required:
+			schema.Not = &Schema{Const: zero}
+		}
+	}
+
+	return required
+}
+
+// zeroValue возвращает нулевое значение для чисел и строк: его validate считает незаполненным полем
+func zeroValue(schemaType string) (any, bool) {
+	switch schemaType {
+	case "integer", "number":
+		return 0, true
+	case "string":
+		return "", true
+	}
+	return nil, false
+}
+
+// allowZero разрешает нулевое значение в обход остальных правил поля, как omitempty в validate
+func allowZero(schema *Schema, zero any) {
+	rules := *schema
+	rules.Type = ""
+	if reflect.DeepEqual(rules, Schema{}) {
+		return
+	}
+	*schema = Schema{Type: schema.Type, AnyOf: []*Schema{{Const: zero}, &rules}}
+}
+
+func setLowerBound(schema *Schema, param string, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		if exclusive {
+			schema.ExclusiveMinimum = &value
+		} else {
+			schema.Minimum = &value
+		}
+	default:
+		// для строк, массивов и словарей граница относится к длине
+		n := int(value)
+		if exclusive {
+			n++
+		}
+		switch schema.Type {
+		case "string":
+			schema.MinLength = &n
+		case "array":
+			schema.MinItems = &n
+		case "object":
+			schema.MinProperties = &n
+		}
+	}
+}
+
+func setUpperBound(schema *Schema, param string, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		if exclusive {
+			schema.ExclusiveMaximum = &value
+		} else {
+			schema.Maximum = &value
+		}
+	default:
+		n := int(value)
+		if exclusive {
+			n--
+		}
+		switch schema.Type {
+		case "string":
+			schema.MaxLength = &n
+		case "array":
+			schema.MaxItems = &n
+		case "object":
+			schema.MaxProperties = &n
+		}
+	}
+}
+
+// enumValue приводит значение oneof к типу поля
+func enumValue(schemaType string, value string) any {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// Types возвращает зарегистрированные типы настроек в алфавитном порядке
+func (r *SettingsRegistry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.factories))
+	for settingsType := range r.factories {
+		types = append(types, settingsType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Schema возвращает JSON Schema зарегистрированного типа настроек
+func (r *SettingsRegistry) Schema(settingsType string) (*Schema, error) {
+	r.mu.RLock()
+	factory, exists := r.factories[settingsType]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown component type: %s", settingsType)
+	}
+
+	schema := SchemaOf(factory())
+	// тип в реестре может отличаться от SettingsType, например "source" для "database"
+	schema.Title = settingsType
+	return schema, nil
+}
+
+// Schemas возвращает схемы всех зарегистрированных типов: тип -> схема
+func (r *SettingsRegistry) Schemas() map[string]*Schema {
+	schemas := make(map[string]*Schema)
+	for _, settingsType := range r.Types() {
+		schemas[settingsType], _ = r.Schema(settingsType)
+	}
+	return schemas
+}
+
+// Validate проверяет настройки так же, как Build, не возвращая результат
+func (r *SettingsRegistry) Validate(settingsType string, rawJSON json.RawMessage) error {
+	_, err := r.Build(settingsType, rawJSON)
+	return err
+}
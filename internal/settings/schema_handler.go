@@ -0,0 +1,62 @@
+package settings
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// NewSchemaHandler возвращает HTTP API схем настроек:
+//
+//	GET  /settings/types            — зарегистрированные типы
+//	GET  /settings/schema           — схемы всех типов
+//	GET  /settings/schema/{type}    — схема типа
+//	POST /settings/validate/{type}  — проверка настроек из тела запроса
+func NewSchemaHandler(reg *SettingsRegistry) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /settings/types", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, reg.Types())
+	})
+
+	mux.HandleFunc("GET /settings/schema", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, reg.Schemas())
+	})
+
+	mux.HandleFunc("GET /settings/schema/{type}", func(w http.ResponseWriter, r *http.Request) {
+		schema, err := reg.Schema(r.PathValue("type"))
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, schema)
+	})
+
+	mux.HandleFunc("POST /settings/validate/{type}", func(w http.ResponseWriter, r *http.Request) {
+		settingsType := r.PathValue("type")
+		if _, err := reg.Schema(settingsType); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		if err := reg.Validate(settingsType, body); err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"valid": false, "error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"valid": true})
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,126 @@
+package settings
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf(&GridSettings{})
+	assert.Equal(t, "grid", schema.Title)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"amount", "interval", "levels", "lower", "mode", "symbol", "upper"}, schema.Required)
+
+	assert.Equal(t, "integer", schema.Properties["levels"].Type)
+	assert.Equal(t, 2.0, *schema.Properties["levels"].Minimum)
+	assert.Equal(t, 0.0, *schema.Properties["lower"].ExclusiveMinimum)
+	assert.Equal(t, []any{"arithmetic", "geometric"}, schema.Properties["mode"].Enum)
+	// сравнение полей в схеме не выражается
+	assert.Nil(t, schema.Properties["upper"].ExclusiveMinimum)
+
+	schema = SchemaOf(&PairsSettings{})
+	symbols := schema.Properties["symbols"]
+	assert.Equal(t, "array", symbols.Type)
+	assert.Equal(t, 2, *symbols.MinItems)
+	assert.True(t, symbols.UniqueItems)
+	assert.Equal(t, "string", symbols.Items.Type)
+
+	schema = SchemaOf(&HistoricalSourceSettings{})
+	assert.Equal(t, "date-time", schema.Properties["start_time"].Format)
+
+	schema = SchemaOf(&ValidationSettings{})
+	assert.Empty(t, schema.Required)
+	outlier := schema.Properties["outlier"]
+	assert.Equal(t, "object", outlier.Type)
+	assert.Equal(t, []any{"zscore", "mad"}, outlier.Properties["method"].Enum)
+
+	schema = SchemaOf(&EnsembleSettings{})
+	assert.Equal(t, "number", schema.Properties["weights"].AdditionalProperties.Type)
+	assert.Equal(t, 0.0, *schema.Properties["weights"].AdditionalProperties.Minimum)
+}
+
+func TestSchemaZeroValues(t *testing.T) {
+	schema := SchemaOf(&DCASettings{})
+
+	// omitempty: 0 — значение по умолчанию, границы проверяются только для остальных значений
+	stepScale := schema.Properties["step_scale"]
+	assert.Equal(t, "number", stepScale.Type)
+	assert.Nil(t, stepScale.Minimum)
+	if assert.Len(t, stepScale.AnyOf, 2) {
+		assert.Equal(t, 0, stepScale.AnyOf[0].Const)
+		assert.Equal(t, 1.0, *stepScale.AnyOf[1].Minimum)
+	}
+
+	// required для чисел — не 0
+	takeProfit := schema.Properties["take_profit"]
+	assert.Contains(t, schema.Required, "take_profit")
+	assert.Equal(t, 0.0, *takeProfit.ExclusiveMinimum)
+	if assert.NotNil(t, takeProfit.Not) {
+		assert.Equal(t, 0, takeProfit.Not.Const)
+	}
+	assert.Equal(t, 1, *schema.Properties["symbol"].MinLength)
+
+	// поле без omitempty и required: 0 проверяется границами
+	safetyOrders := schema.Properties["safety_orders"]
+	assert.Empty(t, safetyOrders.AnyOf)
+	assert.Nil(t, safetyOrders.Not)
+
+	schema = SchemaOf(&RuleSettings{})
+	side := schema.Properties["side"]
+	if assert.Len(t, side.AnyOf, 2) {
+		assert.Equal(t, "", side.AnyOf[0].Const)
+		assert.Equal(t, []any{"buy", "sell"}, side.AnyOf[1].Enum)
+	}
+	assert.Nil(t, schema.Properties["exit"].AnyOf)
+
+	data, err := json.Marshal(SchemaOf(&DCASettings{}).Properties)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(data), `"step_scale":{"type":"number","anyOf":[{"const":0},{"minimum":1}]}`)
+		assert.Contains(t, string(data), `"max_active_deals":{"type":"integer","minimum":1,"not":{"const":0}}`)
+	}
+}
+
+func TestSchemaHandler(t *testing.T) {
+	reg := NewSettingsRegistry()
+	reg.Register("source", func() Settings { return &HistoricalSourceSettings{} })
+	reg.Register("grid", func() Settings { return &GridSettings{} })
+
+	assert.Equal(t, []string{"grid", "source"}, reg.Types())
+
+	server := httptest.NewServer(NewSchemaHandler(reg))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/settings/schema/source")
+	if assert.NoError(t, err) {
+		var schema Schema
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&schema))
+		resp.Body.Close()
+		assert.Equal(t, "source", schema.Title)
+		assert.Equal(t, schemaDialect, schema.Dialect)
+	}
+
+	resp, err = http.Get(server.URL + "/settings/schema/unknown")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	valid := `{"symbol":"BTCUSDT","interval":"1m","lower":100,"upper":120,"levels":5,"mode":"arithmetic","amount":1}`
+	resp, err = http.Post(server.URL+"/settings/validate/grid", "application/json", strings.NewReader(valid))
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	invalid := `{"symbol":"BTCUSDT","interval":"1m","lower":100,"upper":90,"levels":5,"mode":"arithmetic","amount":1}`
+	resp, err = http.Post(server.URL+"/settings/validate/grid", "application/json", strings.NewReader(invalid))
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+}
@@ -0,0 +1,43 @@
+package settings
+
+// Действия при нарушении правила проверки данных
+const (
+	ValidationActionDrop   = "drop"   // свеча отбрасывается
+	ValidationActionRepair = "repair" // свеча исправляется и передаётся дальше
+	ValidationActionFlag   = "flag"   // свеча передаётся дальше с отметкой о нарушении
+)
+
+// Настройки проверки качества рыночных данных. Правило без настроек не проверяется.
+type ValidationSettings struct {
+	OHLC      *OHLCRule      `json:"ohlc"`      // нулевые цены, High < Low, Open/Close вне [Low, High]
+	Outlier   *OutlierRule   `json:"outlier"`   // выбросы цены и объёма
+	Duplicate *DuplicateRule `json:"duplicate"` // повторы и нарушение порядка времени
+	Stale     *StaleRule     `json:"stale"`     // цена не меняется несколько свечей подряд
+}
+
+type OHLCRule struct {
+	Action string `json:"action" validate:"required,oneof=drop repair flag"`
+}
+
+type OutlierRule struct {
+	Action    string  `json:"action" validate:"required,oneof=drop repair flag"`
+	Method    string  `json:"method" validate:"required,oneof=zscore mad"` // z-оценка или медианное абсолютное отклонение
+	Window    int     `json:"window" validate:"required,min=3"`            // количество предыдущих свечей для статистики
+	Threshold float64 `json:"threshold" validate:"required,gt=0"`          // порог отклонения
+}
+
+// Повтор свечи исправить нельзя, поэтому доступны только drop и flag
+type DuplicateRule struct {
+	Action string `json:"action" validate:"required,oneof=drop flag"`
+}
+
+type StaleRule struct {
+	Action     string `json:"action" validate:"required,oneof=drop flag"`
+	MaxRepeats int    `json:"max_repeats" validate:"required,min=1"` // допустимое количество свечей подряд с той же ценой
+}
+
+func (d ValidationSettings) SettingsType() string {
+	return "validation"
+}
+
+var _ Settings = ValidationSettings{}
@@ -0,0 +1,15 @@
+package settings
+
+// Настройки профиля объёма и footprint
+type VolumeProfileSettings struct {
+	TickSize          float64 `json:"tick_size" validate:"required,gt=0"`                 // шаг ценовой сетки
+	Window            int     `json:"window" validate:"required,min=1"`                   // количество свечей в скользящем профиле
+	ValueAreaPercent  float64 `json:"value_area_percent" validate:"omitempty,gt=0,lte=1"` // по умолчанию 0.7
+	FootprintInterval string  `json:"footprint_interval" validate:"required"`             // интервал баров footprint, например "5m"
+}
+
+func (d VolumeProfileSettings) SettingsType() string {
+	return "volume_profile"
+}
+
+var _ Settings = VolumeProfileSettings{}
@@ -0,0 +1,22 @@
+package settings
+
+// Схемы окон walk-forward анализа
+const (
+	WalkForwardRolling  = "rolling"  // окно оптимизации постоянной длины сдвигается вместе с проверочным окном
+	WalkForwardAnchored = "anchored" // окно оптимизации всегда начинается с начала периода и растёт
+)
+
+// Настройки walk-forward анализа: оптимизация на окне in-sample и проверка выбранных параметров
+// на следующем за ним окне out-of-sample. Параметры оптимизации задаются настройками optimization.
+type WalkForwardSettings struct {
+	Mode        string `json:"mode" validate:"required,oneof=rolling anchored"`
+	InSample    string `json:"in_sample" validate:"required"`     // длина окна оптимизации, например "720h"
+	OutOfSample string `json:"out_of_sample" validate:"required"` // длина проверочного окна
+	Step        string `json:"step"`                              // сдвиг окон, по умолчанию длина проверочного окна
+}
+
+func (d WalkForwardSettings) SettingsType() string {
+	return "walk_forward"
+}
+
+var _ Settings = WalkForwardSettings{}
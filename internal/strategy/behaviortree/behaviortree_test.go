@@ -0,0 +1,146 @@
+package behaviortree
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRepo struct {
+	trees map[int]*types.BehaviorTree
+}
+
+func (r *testRepo) GetBehaviorTree(strategyID int) (*types.BehaviorTree, error) {
+	return r.trees[strategyID], nil
+}
+
+func (r *testRepo) SaveBehaviorTree(tree *types.BehaviorTree) error {
+	r.trees[tree.StrategyID] = tree
+	return nil
+}
+
+// покупка после двух свечей подряд с ценой ниже 100, закрытие позиции при цене выше 110
+const testTree = `{
+	"type": "selector",
+	"children": [
+		{"type": "sequence", "children": [
+			{"type": "condition", "params": {"left": "position", "op": ">", "right": 0}},
+			{"type": "condition", "params": {"left": "close", "op": ">", "right": 110}},
+			{"type": "action", "params": {"side": "close"}}
+		]},
+		{"type": "sequence", "children": [
+			{"type": "condition", "params": {"left": "position", "op": "==", "right": 0}},
+			{"type": "repeat", "params": {"count": 2}, "children": [
+				{"type": "condition", "params": {"left": "price", "op": "<", "right": 100}}
+			]},
+			{"type": "action", "params": {"side": "buy", "amount": 2}}
+		]}
+	]
+}`
+
+func newTestStrategy(t *testing.T, repo *testRepo) *Strategy {
+	s, err := NewStrategy(repo, nil, &settings.BehaviorTreeSettings{
+		Symbol:   "BTCUSDT",
+		Interval: "1m",
+		Tree:     json.RawMessage(testTree),
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, s.Restore(1))
+	return s
+}
+
+func candle(ts time.Time, price float64) *processing.TradingPayload {
+	return &processing.TradingPayload{
+		Symbol:       "BTCUSDT",
+		Interval:     "1m",
+		CurrentPrice: price,
+		MarketData:   &types.MarketData{Timestamp: ts, ClosePrice: price},
+	}
+}
+
+func TestStrategyResumesAfterRestart(t *testing.T) {
+	repo := &testRepo{trees: make(map[int]*types.BehaviorTree)}
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+
+	s := newTestStrategy(t, repo)
+	signals, err := s.OnCandle(context.TODO(), candle(start, 95))
+	assert.NoError(t, err)
+	assert.Empty(t, signals)
+	assert.Contains(t, repo.trees, 1)
+
+	// бот перезапущен: дерево продолжает с узла repeat, вторая свеча ниже 100 даёт покупку
+	s = newTestStrategy(t, repo)
+	signals, _ = s.OnCandle(context.TODO(), candle(start.Add(time.Minute), 90))
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.Equal(t, 2.0, signals[0].Amount)
+	}
+
+	s = newTestStrategy(t, repo)
+	assert.Equal(t, 2.0, s.State().Positions["BTCUSDT"])
+
+	signals, _ = s.OnCandle(context.TODO(), candle(start.Add(2*time.Minute), 111))
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.Equal(t, 2.0, signals[0].Amount)
+	}
+	assert.Equal(t, 0.0, s.State().Positions["BTCUSDT"])
+}
+
+func TestCooldownAndParallel(t *testing.T) {
+	nodes := NewNodeRegistry()
+	root, err := nodes.Build(json.RawMessage(`{
+		"type": "cooldown", "params": {"duration": "5m"},
+		"children": [{"type": "parallel", "params": {"success_threshold": 1}, "children": [
+			{"type": "condition", "params": {"left": "rsi_14", "op": "<", "right": 30}},
+			{"type": "inverter", "children": [{"type": "condition", "params": {"left": "volume", "op": ">", "right": 0}}]}
+		]}]
+	}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	state := NewState()
+	tick := func(ts time.Time, rsi float64) Status {
+		payload := candle(ts, 100)
+		payload.MarketData.Volume = 1
+		payload.SetIndicator("rsi_14", rsi)
+		return root.Tick(&Env{Payload: payload, Now: ts, State: state})
+	}
+
+	assert.Equal(t, Failure, tick(start, 50))
+	assert.Equal(t, Success, tick(start.Add(time.Minute), 25))
+	assert.Equal(t, Failure, tick(start.Add(2*time.Minute), 25), "cooldown")
+	assert.Equal(t, Success, tick(start.Add(6*time.Minute), 25))
+}
+
+func TestBuildErrors(t *testing.T) {
+	nodes := NewNodeRegistry()
+
+	_, err := nodes.Build(json.RawMessage(`{"type": "unknown"}`))
+	assert.ErrorContains(t, err, "unknown node type")
+
+	_, err = nodes.Build(json.RawMessage(`{"type": "inverter"}`))
+	assert.ErrorContains(t, err, "exactly one child")
+
+	_, err = nodes.Build(json.RawMessage(`{"type": "condition", "params": {"left": "close", "op": "=>", "right": 1}}`))
+	assert.ErrorContains(t, err, "unknown operator")
+
+	_, err = nodes.Build(json.RawMessage(`{"type": "sequence", "children": [
+		{"id": "a", "type": "action", "params": {"side": "close"}},
+		{"id": "a", "type": "action", "params": {"side": "close"}}
+	]}`))
+	assert.ErrorContains(t, err, "duplicate node id")
+
+	_, err = nodes.Build(json.RawMessage(`{"type": "sequence", "children": [null]}`))
+	assert.EqualError(t, err, "empty node definition: 0.0")
+}
@@ -0,0 +1,165 @@
+package behaviortree
+
+import (
+	"fmt"
+	"time"
+)
+
+type baseNode struct {
+	id string
+}
+
+func (n *baseNode) ID() string {
+	return n.id
+}
+
+// Sequence выполняет дочерние узлы по порядку, пока они успешны.
+// Узел в состоянии Running запоминается, на следующем тике выполнение продолжается с него.
+type Sequence struct {
+	baseNode
+	children []Node
+}
+
+func (n *Sequence) Tick(env *Env) Status {
+	state := env.node(n.id)
+	if state.Index >= len(n.children) {
+		state.Index = 0
+	}
+
+	for ; state.Index < len(n.children); state.Index++ {
+		switch n.children[state.Index].Tick(env) {
+		case Running:
+			return Running
+		case Failure:
+			state.Index = 0
+			return Failure
+		}
+	}
+
+	state.Index = 0
+	return Success
+}
+
+// Selector выполняет дочерние узлы по порядку до первого успешного
+type Selector struct {
+	baseNode
+	children []Node
+}
+
+func (n *Selector) Tick(env *Env) Status {
+	state := env.node(n.id)
+	if state.Index >= len(n.children) {
+		state.Index = 0
+	}
+
+	for ; state.Index < len(n.children); state.Index++ {
+		switch n.children[state.Index].Tick(env) {
+		case Running:
+			return Running
+		case Success:
+			state.Index = 0
+			return Success
+		}
+	}
+
+	state.Index = 0
+	return Failure
+}
+
+// Parallel выполняет все дочерние узлы на каждом тике.
+// Успех, если успешны не меньше threshold узлов; неудача, если успех уже недостижим.
+type Parallel struct {
+	baseNode
+	children  []Node
+	threshold int
+}
+
+func (n *Parallel) Tick(env *Env) Status {
+	successes, failures := 0, 0
+	for _, child := range n.children {
+		switch child.Tick(env) {
+		case Success:
+			successes++
+		case Failure:
+			failures++
+		}
+	}
+
+	if successes >= n.threshold {
+		return Success
+	}
+	if failures > len(n.children)-n.threshold {
+		return Failure
+	}
+	return Running
+}
+
+// Inverter меняет успех дочернего узла на неудачу и наоборот
+type Inverter struct {
+	baseNode
+	child Node
+}
+
+func (n *Inverter) Tick(env *Env) Status {
+	switch n.child.Tick(env) {
+	case Success:
+		return Failure
+	case Failure:
+		return Success
+	}
+	return Running
+}
+
+// Repeat выполняет дочерний узел count раз подряд, по одному успешному выполнению за тик.
+// При count = 0 повторяет бесконечно. Неудача дочернего узла прерывает повторы.
+type Repeat struct {
+	baseNode
+	child Node
+	count int
+}
+
+func (n *Repeat) Tick(env *Env) Status {
+	state := env.node(n.id)
+
+	switch n.child.Tick(env) {
+	case Running:
+		return Running
+	case Failure:
+		state.Count = 0
+		return Failure
+	}
+
+	state.Count++
+	if n.count > 0 && state.Count >= n.count {
+		state.Count = 0
+		return Success
+	}
+	return Running
+}
+
+// Cooldown не даёт выполнять дочерний узел чаще, чем раз в duration после успешного выполнения
+type Cooldown struct {
+	baseNode
+	child    Node
+	duration time.Duration
+}
+
+func (n *Cooldown) Tick(env *Env) Status {
+	state := env.node(n.id)
+	if !state.LastRun.IsZero() && env.Now.Sub(state.LastRun) < n.duration {
+		return Failure
+	}
+
+	status := n.child.Tick(env)
+	if status == Success {
+		state.LastRun = env.Now
+	}
+	return status
+}
+
+func singleChild(nodeType string, children []Node) (Node, error) {
+	if len(children) != 1 {
+		return nil, fmt.Errorf("%s node must have exactly one child, got %d", nodeType, len(children))
+	}
+	return children[0], nil
+}
@@ -0,0 +1,198 @@
+package behaviortree
+
+import (
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// operand — число или имя значения: цена, объём, позиция или индикатор
+type operand struct {
+	name  string
+	value float64
+}
+
+func (o *operand) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &o.value); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &o.name); err != nil || o.name == "" {
+		return fmt.Errorf("operand must be a number or a name: %s", data)
+	}
+	return nil
+}
+
+// resolve возвращает значение операнда; false, если значение ещё не рассчитано
+func (o *operand) resolve(env *Env) (float64, bool) {
+	if o.name == "" {
+		return o.value, true
+	}
+
+	md := env.Payload.MarketData
+	switch o.name {
+	case "price":
+		return env.Payload.CurrentPrice, true
+	case "position":
+		return env.position(), true
+	}
+
+	if md != nil {
+		switch o.name {
+		case "open":
+			return md.OpenPrice, true
+		case "high":
+			return md.HightPrice, true
+		case "low":
+			return md.LowPrice, true
+		case "close":
+			return md.ClosePrice, true
+		case "volume":
+			return md.Volume, true
+		}
+	}
+
+	value, ok := env.Payload.Indicators[o.name]
+	return value, ok
+}
+
+type conditionParams struct {
+	Left  operand `json:"left"`
+	Op    string  `json:"op"`
+	Right operand `json:"right"`
+}
+
+// Condition сравнивает два значения: цену, объём, позицию, индикатор или число.
+// Если индикатор ещё не прошёл прогрев, условие не выполнено.
+type Condition struct {
+	baseNode
+	params conditionParams
+}
+
+func newCondition(id string, raw json.RawMessage) (*Condition, error) {
+	n := &Condition{baseNode: baseNode{id: id}}
+	if err := json.Unmarshal(raw, &n.params); err != nil {
+		return nil, fmt.Errorf("condition %s: %w", id, err)
+	}
+	switch n.params.Op {
+	case "<", "<=", ">", ">=", "==", "!=":
+	default:
+		return nil, fmt.Errorf("condition %s: unknown operator: %q", id, n.params.Op)
+	}
+	return n, nil
+}
+
+func (n *Condition) Tick(env *Env) Status {
+	left, ok := n.params.Left.resolve(env)
+	if !ok {
+		return Failure
+	}
+	right, ok := n.params.Right.resolve(env)
+	if !ok {
+		return Failure
+	}
+
+	var result bool
+	switch n.params.Op {
+	case "<":
+		result = left < right
+	case "<=":
+		result = left <= right
+	case ">":
+		result = left > right
+	case ">=":
+		result = left >= right
+	case "==":
+		result = left == right
+	case "!=":
+		result = left != right
+	}
+
+	if result {
+		return Success
+	}
+	return Failure
+}
+
+const sideClose = "close"
+
+type actionParams struct {
+	Side   string  `json:"side"`   // buy, sell, close — закрыть позицию по символу
+	Type   string  `json:"type"`   // market (по умолчанию), limit
+	Amount float64 `json:"amount"` // для close не задаётся
+	Price  float64 `json:"price"`  // цена лимитного ордера, по умолчанию текущая цена
+}
+
+// Action выставляет ордер по символу текущей свечи.
+// Рыночный ордер считается исполненным сразу и меняет позицию в состоянии дерева.
+type Action struct {
+	baseNode
+	params actionParams
+}
+
+func newAction(id string, raw json.RawMessage) (*Action, error) {
+	n := &Action{baseNode: baseNode{id: id}}
+	if err := json.Unmarshal(raw, &n.params); err != nil {
+		return nil, fmt.Errorf("action %s: %w", id, err)
+	}
+	if n.params.Type == "" {
+		n.params.Type = types.OrderTypeMarket
+	}
+
+	switch n.params.Side {
+	case types.SideBuy, types.SideSell:
+		if n.params.Amount <= 0 {
+			return nil, fmt.Errorf("action %s: amount must be positive", id)
+		}
+	case sideClose:
+	default:
+		return nil, fmt.Errorf("action %s: unknown side: %q", id, n.params.Side)
+	}
+	if n.params.Type != types.OrderTypeMarket && n.params.Type != types.OrderTypeLimit {
+		return nil, fmt.Errorf("action %s: unknown order type: %q", id, n.params.Type)
+	}
+
+	return n, nil
+}
+
+func (n *Action) Tick(env *Env) Status {
+	side, amount := n.params.Side, n.params.Amount
+	if side == sideClose {
+		position := env.position()
+		if position == 0 {
+			return Failure
+		}
+		side, amount = types.SideSell, math.Abs(position)
+		if position < 0 {
+			side = types.SideBuy
+		}
+	}
+
+	price := n.params.Price
+	if price == 0 {
+		price = env.Payload.CurrentPrice
+	}
+
+	env.Signals = append(env.Signals, &types.Signal{
+		Symbol: env.Payload.Symbol,
+		Side:   side,
+		Type:   n.params.Type,
+		Price:  price,
+		Amount: amount,
+		Reason: "behavior tree node " + n.id,
+		Time:   env.Now,
+	})
+
+	if n.params.Type == types.OrderTypeMarket {
+		if env.State.Positions == nil {
+			env.State.Positions = make(map[string]float64)
+		}
+		if side == types.SideBuy {
+			env.State.Positions[env.Payload.Symbol] += amount
+		} else {
+			env.State.Positions[env.Payload.Symbol] -= amount
+		}
+	}
+
+	return Success
+}
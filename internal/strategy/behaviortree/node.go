@@ -0,0 +1,81 @@
+package behaviortree
+
+import (
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/types"
+	"time"
+)
+
+// Status — результат выполнения узла
+type Status int
+
+const (
+	Success Status = iota
+	Failure
+	Running
+)
+
+func (s Status) String() string {
+	switch s {
+	case Success:
+		return "success"
+	case Failure:
+		return "failure"
+	case Running:
+		return "running"
+	}
+	return "unknown"
+}
+
+// Node — узел поведенческого дерева.
+// Узел не хранит состояние в себе: всё, что должно пережить перезапуск бота, лежит в Env.State.
+type Node interface {
+	ID() string
+	Tick(env *Env) Status
+}
+
+// Env — окружение одного тика дерева
+type Env struct {
+	Payload *processing.TradingPayload // текущая свеча и индикаторы
+	Now     time.Time                  // время свечи, а не системное время, чтобы дерево работало в бэктесте
+	State   *State
+	Signals []*types.Signal // ордера, выставленные узлами action на этом тике
+}
+
+// State — сохраняемое состояние дерева
+type State struct {
+	Nodes     map[string]*NodeState `json:"nodes"`
+	Positions map[string]float64    `json:"positions"` // symbol -> позиция со знаком по исполненным рыночным ордерам
+}
+
+// NodeState — состояние узла, используются только нужные узлу поля
+type NodeState struct {
+	Index   int       `json:"index,omitempty"`    // текущий дочерний узел Sequence/Selector
+	Count   int       `json:"count,omitempty"`    // количество успешных повторов Repeat
+	LastRun time.Time `json:"last_run,omitempty"` // последнее успешное выполнение Cooldown
+}
+
+func NewState() *State {
+	return &State{
+		Nodes:     make(map[string]*NodeState),
+		Positions: make(map[string]float64),
+	}
+}
+
+// node возвращает состояние узла, создавая его при первом обращении
+func (e *Env) node(id string) *NodeState {
+	if e.State.Nodes == nil {
+		e.State.Nodes = make(map[string]*NodeState)
+	}
+	state, exists := e.State.Nodes[id]
+	if !exists {
+		state = &NodeState{}
+		e.State.Nodes[id] = state
+	}
+	return state
+}
+
+// position возвращает позицию по символу текущей свечи
+func (e *Env) position() float64 {
+	return e.State.Positions[e.Payload.Symbol]
+}
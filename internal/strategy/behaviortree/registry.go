@@ -0,0 +1,156 @@
+package behaviortree
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// NodeDef — описание узла в JSON
+type NodeDef struct {
+	ID       string          `json:"id,omitempty"` // по умолчанию путь узла в дереве: "0", "0.1", "0.1.2"
+	Type     string          `json:"type"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Children []*NodeDef      `json:"children,omitempty"`
+}
+
+// NodeFactory создаёт узел по параметрам и уже созданным дочерним узлам
+type NodeFactory func(id string, params json.RawMessage, children []Node) (Node, error)
+
+// NodeRegistry хранит фабрики узлов по имени типа
+type NodeRegistry struct {
+	factories map[string]NodeFactory
+	mu        sync.RWMutex
+}
+
+// NewNodeRegistry создаёт реестр со встроенными узлами
+func NewNodeRegistry() *NodeRegistry {
+	r := &NodeRegistry{factories: make(map[string]NodeFactory)}
+
+	r.Register("sequence", func(id string, _ json.RawMessage, children []Node) (Node, error) {
+		return &Sequence{baseNode: baseNode{id: id}, children: children}, nil
+	})
+	r.Register("selector", func(id string, _ json.RawMessage, children []Node) (Node, error) {
+		return &Selector{baseNode: baseNode{id: id}, children: children}, nil
+	})
+	r.Register("parallel", func(id string, raw json.RawMessage, children []Node) (Node, error) {
+		var params struct {
+			SuccessThreshold int `json:"success_threshold"` // по умолчанию все дочерние узлы
+		}
+		if err := unmarshalParams(raw, &params); err != nil {
+			return nil, fmt.Errorf("parallel %s: %w", id, err)
+		}
+		if params.SuccessThreshold <= 0 || params.SuccessThreshold > len(children) {
+			params.SuccessThreshold = len(children)
+		}
+		return &Parallel{baseNode: baseNode{id: id}, children: children, threshold: params.SuccessThreshold}, nil
+	})
+	r.Register("inverter", func(id string, _ json.RawMessage, children []Node) (Node, error) {
+		child, err := singleChild("inverter", children)
+		if err != nil {
+			return nil, err
+		}
+		return &Inverter{baseNode: baseNode{id: id}, child: child}, nil
+	})
+	r.Register("repeat", func(id string, raw json.RawMessage, children []Node) (Node, error) {
+		child, err := singleChild("repeat", children)
+		if err != nil {
+			return nil, err
+		}
+		var params struct {
+			Count int `json:"count"`
+		}
+		if err := unmarshalParams(raw, &params); err != nil {
+			return nil, fmt.Errorf("repeat %s: %w", id, err)
+		}
+		return &Repeat{baseNode: baseNode{id: id}, child: child, count: params.Count}, nil
+	})
+	r.Register("cooldown", func(id string, raw json.RawMessage, children []Node) (Node, error) {
+		child, err := singleChild("cooldown", children)
+		if err != nil {
+			return nil, err
+		}
+		var params struct {
+			Duration string `json:"duration"` // например "15m"
+		}
+		if err := unmarshalParams(raw, &params); err != nil {
+			return nil, fmt.Errorf("cooldown %s: %w", id, err)
+		}
+		duration, err := time.ParseDuration(params.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("cooldown %s: %w", id, err)
+		}
+		return &Cooldown{baseNode: baseNode{id: id}, child: child, duration: duration}, nil
+	})
+	r.Register("condition", func(id string, raw json.RawMessage, _ []Node) (Node, error) {
+		return newCondition(id, raw)
+	})
+	r.Register("action", func(id string, raw json.RawMessage, _ []Node) (Node, error) {
+		return newAction(id, raw)
+	})
+
+	return r
+}
+
+// Регистрация фабрики для типа узла
+func (r *NodeRegistry) Register(nodeType string, factory NodeFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.factories[nodeType]; exists {
+		panic(fmt.Sprintf("node type %q already registered", nodeType))
+	}
+	r.factories[nodeType] = factory
+}
+
+// Build создаёт дерево по описанию в JSON
+func (r *NodeRegistry) Build(rawJSON json.RawMessage) (Node, error) {
+	var def NodeDef
+	if err := json.Unmarshal(rawJSON, &def); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal behavior tree: %w", err)
+	}
+	return r.build(&def, "0", make(map[string]bool))
+}
+
+func (r *NodeRegistry) build(def *NodeDef, path string, ids map[string]bool) (Node, error) {
+	id := def.ID
+	if id == "" {
+		id = path
+	}
+	// по идентификатору узла хранится его состояние, поэтому он должен быть уникальным
+	if ids[id] {
+		return nil, fmt.Errorf("duplicate node id: %s", id)
+	}
+	ids[id] = true
+
+	r.mu.RLock()
+	factory, exists := r.factories[def.Type]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("unknown node type: %s", def.Type)
+	}
+
+	children := make([]Node, 0, len(def.Children))
+	for i, childDef := range def.Children {
+		childPath := path + "." + strconv.Itoa(i)
+		if childDef == nil {
+			return nil, fmt.Errorf("empty node definition: %s", childPath)
+		}
+		child, err := r.build(childDef, childPath, ids)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	return factory(id, def.Params, children)
+}
+
+func unmarshalParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
@@ -0,0 +1,135 @@
+package behaviortree
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"fmt"
+)
+
+var (
+	_ strategy.Strategy = (*Strategy)(nil)
+	_ strategy.Stateful = (*Strategy)(nil)
+)
+
+// Strategy выполняет поведенческое дерево на каждой свече.
+// Состояние дерева сохраняется в behavior_trees после каждого тика,
+// поэтому перезапущенный бот продолжает выполнение с того же узла.
+type Strategy struct {
+	settings   settings.BehaviorTreeSettings
+	repo       repositories.BehaviorTreeRepository
+	root       Node
+	indicators *processing.IndicatorProcessor
+	state      *State
+	strategyID int
+}
+
+// NewStrategy создаёт стратегию. Если nodes равен nil, используются встроенные узлы.
+// Если repo равен nil, состояние не сохраняется.
+func NewStrategy(repo repositories.BehaviorTreeRepository, nodes *NodeRegistry, comps ...settings.Settings) (*Strategy, error) {
+	s := &Strategy{repo: repo, state: NewState()}
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.BehaviorTreeSettings); ok {
+			s.settings = *val
+		}
+	}
+
+	if s.settings.Symbol == "" || len(s.settings.Tree) == 0 {
+		return nil, fmt.Errorf("behavior tree settings are not set")
+	}
+
+	if nodes == nil {
+		nodes = NewNodeRegistry()
+	}
+
+	var err error
+	if s.root, err = nodes.Build(s.settings.Tree); err != nil {
+		return nil, err
+	}
+
+	if len(s.settings.Indicators) > 0 {
+		s.indicators, err = processing.NewIndicatorProcessor(&settings.IndicatorSettings{Indicators: s.settings.Indicators})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Strategy) Subscriptions() []strategy.Subscription {
+	return []strategy.Subscription{{Symbol: s.settings.Symbol, Interval: s.settings.Interval}}
+}
+
+// Restore загружает сохранённое состояние дерева
+func (s *Strategy) Restore(strategyID int) error {
+	s.strategyID = strategyID
+	if s.repo == nil {
+		return nil
+	}
+
+	tree, err := s.repo.GetBehaviorTree(strategyID)
+	if err != nil || tree == nil {
+		return err
+	}
+
+	state := NewState()
+	if err := json.Unmarshal(tree.State, state); err != nil {
+		return fmt.Errorf("failed to unmarshal behavior tree state: %w", err)
+	}
+	s.state = state
+
+	return nil
+}
+
+// State возвращает текущее состояние дерева
+func (s *Strategy) State() *State {
+	return s.state
+}
+
+func (s *Strategy) OnCandle(ctx context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	if payload.Symbol != s.settings.Symbol || payload.Interval != s.settings.Interval {
+		return nil, nil
+	}
+
+	if s.indicators != nil {
+		if _, err := s.indicators.Process(ctx, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	env := &Env{Payload: payload, State: s.state}
+	if payload.MarketData != nil {
+		env.Now = payload.MarketData.Timestamp
+	}
+
+	s.root.Tick(env)
+
+	if err := s.save(env); err != nil {
+		return nil, err
+	}
+
+	return env.Signals, nil
+}
+
+func (s *Strategy) save(env *Env) error {
+	if s.repo == nil || s.strategyID == 0 {
+		return nil
+	}
+
+	state, err := json.Marshal(s.state)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SaveBehaviorTree(&types.BehaviorTree{
+		StrategyID:   s.strategyID,
+		State:        state,
+		LastExecuted: env.Now,
+	})
+}
@@ -0,0 +1,73 @@
+package dca
+
+import (
+	"crypto-trading-bot/internal/types"
+	"time"
+)
+
+// DealReport — итоги сделки по её операциям
+type DealReport struct {
+	DealID        string
+	Symbol        string
+	Opened        time.Time
+	Closed        time.Time // нулевое для открытой сделки
+	Active        bool
+	BasePrice     float64
+	SafetyOrders  int     // исполнено страховочных ордеров
+	Quantity      float64 // куплено
+	Invested      float64 // потрачено в валюте котировки
+	AveragePrice  float64
+	ExitPrice     float64
+	ExitReason    string
+	Profit        float64 // в валюте котировки
+	ProfitPercent float64 // от вложенного
+}
+
+// Report собирает отчёт по сделкам из операций, отсортированных по времени.
+// Отменённые операции и операции без сделки не учитываются.
+func Report(ops []*types.TradeOperation) []*DealReport {
+	var reports []*DealReport
+	index := make(map[string]*DealReport)
+
+	for _, op := range ops {
+		if op.DealID == "" || op.Status != types.TradeStatusFilled {
+			continue
+		}
+
+		report, exists := index[op.DealID]
+		if !exists {
+			report = &DealReport{DealID: op.DealID, Symbol: op.Symbol, Opened: op.Timestamp, Active: true}
+			index[op.DealID] = report
+			reports = append(reports, report)
+		}
+
+		switch op.Side {
+		case types.SideBuy:
+			switch op.Reason {
+			case ReasonBase:
+				report.BasePrice = op.Price
+			case ReasonSafety:
+				report.SafetyOrders++
+			}
+			report.Quantity += op.Quantity
+			report.Invested += op.Quantity * op.Price
+		case types.SideSell:
+			report.Active = false
+			report.Closed = op.Timestamp
+			report.ExitPrice = op.Price
+			report.ExitReason = op.Reason
+			report.Profit = op.Quantity*op.Price - report.Invested
+		}
+	}
+
+	for _, report := range reports {
+		if report.Quantity > 0 {
+			report.AveragePrice = report.Invested / report.Quantity
+		}
+		if !report.Active && report.Invested > 0 {
+			report.ProfitPercent = report.Profit / report.Invested * 100
+		}
+	}
+
+	return reports
+}
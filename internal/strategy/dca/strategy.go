@@ -0,0 +1,301 @@
+package dca
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	_ strategy.Strategy = (*Strategy)(nil)
+	_ strategy.Stateful = (*Strategy)(nil)
+)
+
+// Роли ордеров в сделке, записываются в trade_operations.reason
+const (
+	ReasonBase               = "base"
+	ReasonSafety             = "safety"
+	ReasonTakeProfit         = "take_profit"
+	ReasonTrailingTakeProfit = "trailing_take_profit"
+)
+
+// Deal — открытая сделка усреднения
+type Deal struct {
+	ID           string
+	Opened       time.Time
+	BasePrice    float64 // цена базового ордера, от неё считаются уровни страховочных ордеров
+	SafetyFilled int     // количество исполненных страховочных ордеров
+	Quantity     float64
+	Cost         float64
+	TrailingHigh float64 // максимум цены после достижения тейк-профита, 0 — трейлинг не активен
+}
+
+// AveragePrice возвращает среднюю цену входа
+func (d *Deal) AveragePrice() float64 {
+	if d.Quantity == 0 {
+		return 0
+	}
+	return d.Cost / d.Quantity
+}
+
+// Strategy — стратегия усреднения (только длинные сделки). Пока открыто меньше MaxActiveDeals сделок,
+// на каждой свече по цене закрытия открывается новая сделка базовым ордером.
+// Внутри свечи сначала исполняются страховочные ордера, цена которых не ниже Low свечи, затем проверяется тейк-профит.
+// Каждый исполненный ордер возвращается сигналом и записывается в trade_operations,
+// максимум цены активного трейлинг тейк-профита — в dca_deals.
+type Strategy struct {
+	settings   settings.DCASettings
+	repo       repositories.TradeOperationRepository
+	dealRepo   repositories.DCADealRepository
+	strategyID int
+	deals      []*Deal
+}
+
+// NewStrategy создаёт стратегию. Если repo или dealRepo равны nil, соответствующее состояние не сохраняется.
+func NewStrategy(repo repositories.TradeOperationRepository, dealRepo repositories.DCADealRepository, comps ...settings.Settings) (*Strategy, error) {
+	s := &Strategy{repo: repo, dealRepo: dealRepo}
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.DCASettings); ok {
+			s.settings = *val
+		}
+	}
+
+	if s.settings.Symbol == "" || s.settings.BaseOrder <= 0 || s.settings.TakeProfit <= 0 {
+		return nil, fmt.Errorf("dca settings are not set")
+	}
+	if s.settings.StepScale == 0 {
+		s.settings.StepScale = 1
+	}
+	if s.settings.VolumeScale == 0 {
+		s.settings.VolumeScale = 1
+	}
+	s.settings.MaxActiveDeals = max(s.settings.MaxActiveDeals, 1)
+
+	return s, nil
+}
+
+func (s *Strategy) Subscriptions() []strategy.Subscription {
+	return []strategy.Subscription{{Symbol: s.settings.Symbol, Interval: s.settings.Interval}}
+}
+
+// Restore восстанавливает открытые сделки по trade_operations и максимумы трейлинга по dca_deals
+func (s *Strategy) Restore(strategyID int) error {
+	s.strategyID = strategyID
+	if s.repo == nil {
+		return nil
+	}
+
+	ops, err := s.repo.GetTradeOperations(strategyID)
+	if err != nil {
+		return err
+	}
+
+	s.deals = nil
+	for _, report := range Report(ops) {
+		if !report.Active {
+			continue
+		}
+		s.deals = append(s.deals, &Deal{
+			ID:           report.DealID,
+			Opened:       report.Opened,
+			BasePrice:    report.BasePrice,
+			SafetyFilled: report.SafetyOrders,
+			Quantity:     report.Quantity,
+			Cost:         report.Invested,
+		})
+	}
+
+	if s.dealRepo == nil || len(s.deals) == 0 {
+		return nil
+	}
+	saved, err := s.dealRepo.GetDCADeals(strategyID)
+	if err != nil {
+		return err
+	}
+	trailing := make(map[string]float64, len(saved))
+	for _, deal := range saved {
+		trailing[deal.DealID] = deal.TrailingHigh
+	}
+	for _, deal := range s.deals {
+		deal.TrailingHigh = trailing[deal.ID]
+	}
+
+	return nil
+}
+
+// Deals возвращает открытые сделки
+func (s *Strategy) Deals() []*Deal {
+	return s.deals
+}
+
+// SafetyOrder возвращает цену и объём в валюте котировки i-го страховочного ордера (с 1)
+func (s *Strategy) SafetyOrder(basePrice float64, i int) (float64, float64) {
+	deviation := 0.0
+	for k := 0; k < i; k++ {
+		deviation += s.settings.Deviation * math.Pow(s.settings.StepScale, float64(k))
+	}
+	volume := s.settings.SafetyOrder * math.Pow(s.settings.VolumeScale, float64(i-1))
+	return basePrice * (1 - deviation/100), volume
+}
+
+func (s *Strategy) OnCandle(_ context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	md := payload.MarketData
+	if md == nil || payload.Symbol != s.settings.Symbol || payload.Interval != s.settings.Interval {
+		return nil, nil
+	}
+
+	var signals []*types.Signal
+
+	active := s.deals[:0]
+	for _, deal := range s.deals {
+		dealSignals, closed, err := s.update(deal, md)
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, dealSignals...)
+		if !closed {
+			active = append(active, deal)
+		}
+	}
+	s.deals = active
+
+	if len(s.deals) < s.settings.MaxActiveDeals {
+		deal := &Deal{
+			ID:        fmt.Sprintf("dca_%d_%d", s.strategyID, md.Timestamp.UnixMilli()),
+			Opened:    md.Timestamp,
+			BasePrice: md.ClosePrice,
+		}
+		signal, err := s.buy(deal, types.OrderTypeMarket, md.ClosePrice, s.settings.BaseOrder, ReasonBase, md.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		s.deals = append(s.deals, deal)
+		signals = append(signals, signal)
+	}
+
+	return signals, nil
+}
+
+// update исполняет страховочные ордера и тейк-профит сделки, возвращает сигналы и признак закрытия сделки
+func (s *Strategy) update(deal *Deal, md *types.MarketData) ([]*types.Signal, bool, error) {
+	var signals []*types.Signal
+
+	for deal.SafetyFilled < s.settings.SafetyOrders {
+		price, volume := s.SafetyOrder(deal.BasePrice, deal.SafetyFilled+1)
+		if md.LowPrice > price {
+			break
+		}
+		deal.SafetyFilled++
+		signal, err := s.buy(deal, types.OrderTypeLimit, price, volume, ReasonSafety, md.Timestamp)
+		if err != nil {
+			return nil, false, err
+		}
+		signals = append(signals, signal)
+	}
+
+	target := deal.AveragePrice() * (1 + s.settings.TakeProfit/100)
+
+	if s.settings.TrailingDeviation == 0 {
+		if md.HightPrice < target {
+			return signals, false, nil
+		}
+		signal, err := s.sell(deal, types.OrderTypeLimit, target, ReasonTakeProfit, md.Timestamp)
+		if err != nil {
+			return nil, false, err
+		}
+		return append(signals, signal), true, nil
+	}
+
+	if deal.TrailingHigh == 0 && md.HightPrice < target {
+		return signals, false, nil
+	}
+	if md.HightPrice > deal.TrailingHigh {
+		deal.TrailingHigh = md.HightPrice
+		if err := s.saveDeal(deal, md.Timestamp); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// порядок максимума и минимума внутри свечи неизвестен, поэтому откат проверяется по цене закрытия
+	if md.ClosePrice > deal.TrailingHigh*(1-s.settings.TrailingDeviation/100) {
+		return signals, false, nil
+	}
+	signal, err := s.sell(deal, types.OrderTypeMarket, md.ClosePrice, ReasonTrailingTakeProfit, md.Timestamp)
+	if err != nil {
+		return nil, false, err
+	}
+	return append(signals, signal), true, nil
+}
+
+func (s *Strategy) buy(deal *Deal, orderType string, price float64, volume float64, reason string, ts time.Time) (*types.Signal, error) {
+	quantity := volume / price
+	deal.Quantity += quantity
+	deal.Cost += volume
+
+	if reason == ReasonSafety {
+		return s.record(deal, types.SideBuy, orderType, price, quantity, reason, fmt.Sprintf("dca safety order %d", deal.SafetyFilled), ts)
+	}
+	return s.record(deal, types.SideBuy, orderType, price, quantity, reason, "dca base order", ts)
+}
+
+func (s *Strategy) sell(deal *Deal, orderType string, price float64, reason string, ts time.Time) (*types.Signal, error) {
+	profit := (price - deal.AveragePrice()) / deal.AveragePrice() * 100
+	return s.record(deal, types.SideSell, orderType, price, deal.Quantity, reason,
+		fmt.Sprintf("dca %s, profit %.2f%%", reason, profit), ts)
+}
+
+// saveDeal сохраняет состояние сделки, которое не выводится из её операций
+func (s *Strategy) saveDeal(deal *Deal, ts time.Time) error {
+	if s.dealRepo == nil {
+		return nil
+	}
+	err := s.dealRepo.SaveDCADeal(&types.DCADeal{
+		StrategyID:   s.strategyID,
+		DealID:       deal.ID,
+		TrailingHigh: deal.TrailingHigh,
+		UpdatedAt:    ts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save deal %s: %w", deal.ID, err)
+	}
+	return nil
+}
+
+// record сохраняет операцию сделки и возвращает сигнал на неё
+func (s *Strategy) record(deal *Deal, side string, orderType string, price float64, quantity float64, reason string, description string, ts time.Time) (*types.Signal, error) {
+	if s.repo != nil {
+		err := s.repo.SaveTradeOperation(&types.TradeOperation{
+			StrategyID: s.strategyID,
+			DealID:     deal.ID,
+			Symbol:     s.settings.Symbol,
+			OrderType:  orderType,
+			Side:       side,
+			Quantity:   quantity,
+			Price:      price,
+			Timestamp:  ts,
+			Status:     types.TradeStatusFilled,
+			Reason:     reason,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save operation of deal %s: %w", deal.ID, err)
+		}
+	}
+
+	return &types.Signal{
+		Source: "dca",
+		Symbol: s.settings.Symbol,
+		Side:   side,
+		Type:   orderType,
+		Price:  price,
+		Amount: quantity,
+		Reason: description,
+		Time:   ts,
+	}, nil
+}
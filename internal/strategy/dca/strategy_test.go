@@ -0,0 +1,175 @@
+package dca
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepo struct {
+	ops   []*types.TradeOperation
+	deals map[string]types.DCADeal
+}
+
+func (r *memoryRepo) SaveTradeOperation(op *types.TradeOperation) error {
+	op.ID = len(r.ops) + 1
+	r.ops = append(r.ops, op)
+	return nil
+}
+
+func (r *memoryRepo) GetTradeOperations(int) ([]*types.TradeOperation, error) {
+	return r.ops, nil
+}
+
+func (r *memoryRepo) GetDealOperations(_ int, dealID string) ([]*types.TradeOperation, error) {
+	var ops []*types.TradeOperation
+	for _, op := range r.ops {
+		if op.DealID == dealID {
+			ops = append(ops, op)
+		}
+	}
+	return ops, nil
+}
+
+func (r *memoryRepo) GetDCADeals(int) ([]*types.DCADeal, error) {
+	var deals []*types.DCADeal
+	for _, deal := range r.deals {
+		deal := deal
+		deals = append(deals, &deal)
+	}
+	return deals, nil
+}
+
+func (r *memoryRepo) SaveDCADeal(deal *types.DCADeal) error {
+	if r.deals == nil {
+		r.deals = make(map[string]types.DCADeal)
+	}
+	r.deals[deal.DealID] = *deal
+	return nil
+}
+
+type candles struct {
+	t    *testing.T
+	now  time.Time
+	step int
+}
+
+func (c *candles) next(s *Strategy, low float64, high float64, close float64) []*types.Signal {
+	c.step++
+	signals, err := s.OnCandle(context.Background(), &processing.TradingPayload{
+		Symbol:   "BTCUSDT",
+		Interval: "1h",
+		MarketData: &types.MarketData{
+			Timestamp:  c.now.Add(time.Duration(c.step) * time.Hour),
+			LowPrice:   low,
+			HightPrice: high,
+			ClosePrice: close,
+		},
+	})
+	assert.NoError(c.t, err)
+	return signals
+}
+
+func TestDCAStrategy(t *testing.T) {
+	cfg := &settings.DCASettings{
+		Symbol: "BTCUSDT", Interval: "1h",
+		BaseOrder: 100, SafetyOrder: 100, SafetyOrders: 2,
+		Deviation: 2, StepScale: 2, VolumeScale: 2,
+		TakeProfit: 1, MaxActiveDeals: 1,
+	}
+	repo := &memoryRepo{}
+	s, err := NewStrategy(repo, repo, cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Restore(3))
+
+	price, volume := s.SafetyOrder(100, 2)
+	assert.InDelta(t, 94.0, price, 1e-9)
+	assert.InDelta(t, 200.0, volume, 1e-9)
+
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	c := &candles{t: t, now: now}
+
+	signals := c.next(s, 99, 101, 100)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.InDelta(t, 1.0, signals[0].Amount, 1e-9)
+	}
+
+	// сделка уже открыта, новая не открывается
+	assert.Empty(t, c.next(s, 99, 100.5, 100))
+	// первый страховочный ордер на 98
+	assert.Len(t, c.next(s, 97, 99, 98), 1)
+	// второй страховочный ордер на 94
+	assert.Len(t, c.next(s, 93, 95, 94), 1)
+	deal := s.Deals()[0]
+	assert.Equal(t, 2, deal.SafetyFilled)
+	assert.InDelta(t, 400.0, deal.Cost, 1e-9)
+
+	// тейк-профит 1% от средней цены, затем открывается следующая сделка
+	signals = c.next(s, 95, 98, 97.5)
+	if assert.Len(t, signals, 2) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.InDelta(t, deal.AveragePrice()*1.01, signals[0].Price, 1e-9)
+		assert.Equal(t, types.SideBuy, signals[1].Side)
+	}
+
+	reports := Report(repo.ops)
+	if assert.Len(t, reports, 2) {
+		assert.False(t, reports[0].Active)
+		assert.Equal(t, 2, reports[0].SafetyOrders)
+		assert.Equal(t, ReasonTakeProfit, reports[0].ExitReason)
+		assert.InDelta(t, 4.0, reports[0].Profit, 1e-9)
+		assert.InDelta(t, 1.0, reports[0].ProfitPercent, 1e-9)
+		assert.True(t, reports[1].Active)
+	}
+
+	// после перезапуска открытая сделка восстанавливается из операций
+	restarted, _ := NewStrategy(repo, repo, cfg)
+	assert.NoError(t, restarted.Restore(3))
+	if assert.Len(t, restarted.Deals(), 1) {
+		assert.InDelta(t, 97.5, restarted.Deals()[0].BasePrice, 1e-9)
+	}
+}
+
+func TestDCATrailingTakeProfit(t *testing.T) {
+	cfg := &settings.DCASettings{
+		Symbol: "BTCUSDT", Interval: "1h",
+		BaseOrder: 100, TakeProfit: 1, TrailingDeviation: 0.5, MaxActiveDeals: 1,
+	}
+	repo := &memoryRepo{}
+	s, err := NewStrategy(repo, repo, cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Restore(3))
+
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	c := &candles{t: t, now: now}
+
+	c.next(s, 99, 100, 100)
+	// тейк-профит достигнут, трейлинг активирован
+	assert.Empty(t, c.next(s, 100, 101.5, 101.4))
+	// откат от максимума больше 0.5%
+	signals := c.next(s, 100.9, 101.6, 101)
+	if assert.Len(t, signals, 2) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.Equal(t, 101.0, signals[0].Price)
+	}
+
+	// после перезапуска активный трейлинг сохраняет максимум
+	assert.Empty(t, c.next(s, 100.5, 102.5, 102.4))
+	restarted, _ := NewStrategy(repo, repo, cfg)
+	assert.NoError(t, restarted.Restore(3))
+	if assert.Len(t, restarted.Deals(), 1) {
+		assert.Equal(t, 102.5, restarted.Deals()[0].TrailingHigh)
+	}
+	// максимум свечи ниже цели тейк-профита, но откат от сохранённого максимума больше 0.5%
+	signals = c.next(restarted, 101.6, 101.9, 101.8)
+	if assert.Len(t, signals, 2) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.Equal(t, ReasonTrailingTakeProfit, repo.ops[len(repo.ops)-2].Reason)
+	}
+}
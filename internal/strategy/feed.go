@@ -0,0 +1,94 @@
+package strategy
+
+import (
+	"context"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/types"
+	"sort"
+	"time"
+)
+
+// MarketDataLoader читает свечи за период, например marketdata.MarketDataService
+type MarketDataLoader interface {
+	GetMarketDataPeriod(symbol string, interval string, start time.Time, end time.Time) ([]*types.MarketData, error)
+}
+
+// pollingFeed — поставщик свечей из market_data. Свечи загружает в базу регламентная загрузка
+// (MarketDataService.RunSchudeler), поставщик периодически читает новые свечи подписок.
+type pollingFeed struct {
+	loader   MarketDataLoader
+	clock    clock.Clock
+	logger   *logger.Logger
+	interval time.Duration // период опроса
+	history  time.Duration // глубина истории, отдаваемой при подписке, для прогрева индикаторов
+}
+
+// NewPollingFeed создаёт поставщик свечей, опрашивающий loader каждые interval.
+// При подписке сначала отдаются свечи за последние history.
+func NewPollingFeed(loader MarketDataLoader, clk clock.Clock, logger *logger.Logger, interval time.Duration, history time.Duration) Feed {
+	return &pollingFeed{
+		loader:   loader,
+		clock:    clk,
+		logger:   logger,
+		interval: interval,
+		history:  history,
+	}
+}
+
+// Subscribe отдаёт новые свечи подписок в порядке времени. Ошибка чтения не прерывает поток:
+// она записывается в журнал, и чтение повторяется при следующем опросе.
+func (f *pollingFeed) Subscribe(ctx context.Context, subs []Subscription) (<-chan *types.MarketData, error) {
+	out := make(chan *types.MarketData, 100)
+
+	// время последней отданной свечи по подпискам
+	last := make([]time.Time, len(subs))
+	start := f.clock.Now().Add(-f.history)
+	for i := range last {
+		last[i] = start
+	}
+
+	go func() {
+		defer close(out)
+
+		for {
+			var candles []*types.MarketData
+			now := f.clock.Now()
+			for i, sub := range subs {
+				data, err := f.loader.GetMarketDataPeriod(sub.Symbol, sub.Interval, last[i], now)
+				if err != nil {
+					f.logger.Errorf("Failed to load market data %s %s: %v", sub.Symbol, sub.Interval, err)
+					continue
+				}
+				for _, md := range data {
+					// границы периода включаются, отданная свеча повторно не отдаётся
+					if md.Timestamp.After(last[i]) {
+						candles = append(candles, md)
+						last[i] = md.Timestamp
+					}
+				}
+			}
+
+			sort.SliceStable(candles, func(i, j int) bool {
+				return candles[i].Timestamp.Before(candles[j].Timestamp)
+			})
+			for _, md := range candles {
+				select {
+				case out <- md:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-f.clock.After(f.interval):
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+var _ Feed = (*pollingFeed)(nil)
@@ -0,0 +1,347 @@
+package grid
+
+import (
+	"context"
+	"crypto-trading-bot/internal/exchange"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	_ strategy.Strategy = (*Strategy)(nil)
+	_ strategy.Stateful = (*Strategy)(nil)
+)
+
+// Strategy — сеточная стратегия. Ниже текущей цены на уровнях выставляются лимитные покупки,
+// выше — лимитные продажи, ближайший к цене уровень остаётся свободным.
+// При исполнении покупки на уровне i выставляется продажа на уровне i+1, при исполнении продажи —
+// покупка на уровне i-1; разница цен встречных ордеров записывается в прибыль уровня.
+//
+// Ордера выставляются напрямую через exchange.Exchange. OnCandle возвращает лимитные сигналы по ценам
+// исполненных за свечу ордеров, чтобы брокер бэктеста учёл сделки сетки; на биржу их повторно не отправляют.
+// Состояние уровней сохраняется в grid_levels до отправки ордера на биржу, поэтому после перезапуска
+// уровни с записанным ордером не выставляются заново, а сверяются с биржей (см. Restore).
+// Если биржа реализует exchange.Simulator, ордера исполняются по свечам стратегии — так сетка
+// работает с mockexchange и в бэктестах.
+type Strategy struct {
+	settings   settings.GridSettings
+	exchange   exchange.Exchange
+	repo       repositories.GridLevelRepository
+	strategyID int
+	levels     []*types.GridLevel
+	fills      []*types.Signal // исполнения текущей свечи
+}
+
+// NewStrategy создаёт стратегию. Если repo равен nil, состояние не сохраняется.
+func NewStrategy(ex exchange.Exchange, repo repositories.GridLevelRepository, comps ...settings.Settings) (*Strategy, error) {
+	if ex == nil {
+		return nil, fmt.Errorf("grid strategy requires an exchange")
+	}
+
+	s := &Strategy{exchange: ex, repo: repo}
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.GridSettings); ok {
+			s.settings = *val
+		}
+	}
+
+	if s.settings.Symbol == "" || s.settings.Levels < 2 || s.settings.Upper <= s.settings.Lower {
+		return nil, fmt.Errorf("grid settings are not set")
+	}
+
+	return s, nil
+}
+
+func (s *Strategy) Subscriptions() []strategy.Subscription {
+	return []strategy.Subscription{{Symbol: s.settings.Symbol, Interval: s.settings.Interval}}
+}
+
+// Restore загружает сохранённые уровни сетки и сверяет их ордера с биржей
+func (s *Strategy) Restore(strategyID int) error {
+	s.strategyID = strategyID
+	if s.repo == nil {
+		return nil
+	}
+
+	levels, err := s.repo.GetGridLevels(strategyID)
+	if err != nil || len(levels) == 0 {
+		return err
+	}
+
+	prices := Prices(s.settings)
+	if len(levels) != len(prices) {
+		return fmt.Errorf("grid of strategy %d has %d saved levels, settings define %d", strategyID, len(levels), len(prices))
+	}
+	for i, level := range levels {
+		if level.Index != i || math.Abs(level.Price-prices[i]) > prices[i]*1e-9 {
+			return fmt.Errorf("grid of strategy %d: saved level %d does not match settings", strategyID, level.Index)
+		}
+	}
+	s.levels = levels
+
+	return s.reconcile()
+}
+
+// reconcile возобновляет отслеживание ордеров, записанных до перезапуска. ID команд существуют только
+// в памяти выдавшей их биржи: симулятор создаётся заново и старых ордеров не знает, поэтому ордера
+// уровней отправляются ему повторно с прежними ID. У биржи, реализующей exchange.OrderTracker,
+// ордер запрашивается по ID, исполнение или отмена обрабатываются при следующем опросе.
+func (s *Strategy) reconcile() error {
+	_, isSimulator := s.exchange.(exchange.Simulator)
+	tracker, isTracker := s.exchange.(exchange.OrderTracker)
+
+	for _, level := range s.levels {
+		if level.OrderID == "" {
+			continue
+		}
+
+		switch {
+		case isSimulator:
+			level.CommandID = string(s.exchange.PlaceOrderAsync(s.order(level)))
+		case isTracker:
+			level.CommandID = string(tracker.FetchOrderAsync(s.settings.Symbol, level.OrderID))
+		default:
+			return fmt.Errorf("grid of strategy %d: exchange cannot look up order %s of level %d", s.strategyID, level.OrderID, level.Index)
+		}
+		if err := s.save(level, level.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Levels возвращает текущее состояние уровней
+func (s *Strategy) Levels() []*types.GridLevel {
+	return s.levels
+}
+
+// RealizedProfit возвращает прибыль по всем уровням
+func (s *Strategy) RealizedProfit() float64 {
+	var profit float64
+	for _, level := range s.levels {
+		profit += level.RealizedProfit
+	}
+	return profit
+}
+
+func (s *Strategy) OnCandle(_ context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	md := payload.MarketData
+	if md == nil || payload.Symbol != s.settings.Symbol || payload.Interval != s.settings.Interval {
+		return nil, nil
+	}
+
+	if sim, ok := s.exchange.(exchange.Simulator); ok {
+		sim.MatchCandle(exchange.Candle{
+			Symbol:    payload.Symbol,
+			Interval:  payload.Interval,
+			Timestamp: md.Timestamp,
+			Open:      md.OpenPrice,
+			High:      md.HightPrice,
+			Low:       md.LowPrice,
+			Close:     md.ClosePrice,
+			Volume:    md.Volume,
+		})
+	}
+
+	if s.levels == nil {
+		if err := s.build(md.ClosePrice, md.Timestamp); err != nil {
+			return nil, err
+		}
+	}
+
+	s.fills = nil
+	if err := s.poll(md.Timestamp); err != nil {
+		return nil, err
+	}
+
+	return s.fills, s.place(md.Timestamp)
+}
+
+// build расставляет уровни относительно текущей цены
+func (s *Strategy) build(price float64, now time.Time) error {
+	prices := Prices(s.settings)
+
+	// ближайший к цене уровень остаётся свободным
+	free := 0
+	for i, p := range prices {
+		if math.Abs(p-price) < math.Abs(prices[free]-price) {
+			free = i
+		}
+	}
+
+	levels := make([]*types.GridLevel, len(prices))
+	for i, p := range prices {
+		levels[i] = &types.GridLevel{
+			StrategyID: s.strategyID,
+			Index:      i,
+			Price:      p,
+			Amount:     s.settings.Amount,
+		}
+		switch {
+		case i < free:
+			levels[i].Side = types.SideBuy
+		case i > free:
+			levels[i].Side = types.SideSell
+		}
+	}
+	s.levels = levels
+
+	for _, level := range levels {
+		if err := s.save(level, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// poll забирает с биржи изменения статусов выставленных ордеров
+func (s *Strategy) poll(now time.Time) error {
+	for _, level := range s.levels {
+		for level.CommandID != "" {
+			order, ok, err := s.exchange.PopOrder(exchange.CommandID(level.CommandID))
+			if err != nil {
+				return fmt.Errorf("failed to get order %s of grid level %d: %w", level.OrderID, level.Index, err)
+			}
+			if !ok {
+				break
+			}
+			if order.ID != level.OrderID {
+				continue
+			}
+
+			switch order.Status {
+			case exchange.OrderStatusOpen:
+				if level.Status != types.GridOrderOpen {
+					level.Status = types.GridOrderOpen
+					if err := s.save(level, now); err != nil {
+						return err
+					}
+				}
+			case exchange.OrderStatusFilled:
+				if err := s.fill(level, order.Price, now); err != nil {
+					return err
+				}
+			case exchange.OrderStatusCanceled, exchange.OrderStatusRejected:
+				// ордер уровня будет выставлен повторно
+				level.OrderID, level.CommandID, level.Status = "", "", types.GridOrderNone
+				if err := s.save(level, now); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fill обрабатывает исполнение ордера уровня: записывает прибыль и выставляет встречный ордер на соседнем уровне
+func (s *Strategy) fill(level *types.GridLevel, price float64, now time.Time) error {
+	side, entry := level.Side, level.EntryPrice
+
+	level.Trades++
+	if entry > 0 {
+		if side == types.SideSell {
+			level.RealizedProfit += (price - entry) * level.Amount
+		} else {
+			level.RealizedProfit += (entry - price) * level.Amount
+		}
+	}
+	level.Side, level.EntryPrice = "", 0
+	level.OrderID, level.CommandID, level.Status = "", "", types.GridOrderNone
+
+	s.fills = append(s.fills, &types.Signal{
+		Source: "grid",
+		Symbol: s.settings.Symbol,
+		Side:   side,
+		Type:   types.OrderTypeLimit,
+		Price:  price,
+		Amount: level.Amount,
+		Reason: fmt.Sprintf("grid level %d filled", level.Index),
+		Time:   now,
+	})
+
+	if err := s.save(level, now); err != nil {
+		return err
+	}
+
+	next := level.Index + 1
+	opposite := types.SideSell
+	if side == types.SideSell {
+		next = level.Index - 1
+		opposite = types.SideBuy
+	}
+	if next < 0 || next >= len(s.levels) || s.levels[next].Side != "" {
+		return nil
+	}
+
+	target := s.levels[next]
+	target.Side, target.EntryPrice = opposite, price
+	return s.save(target, now)
+}
+
+// place выставляет ордера на уровнях, где ордер нужен, но ещё не записан.
+// Уровень сохраняется со статусом placing до отправки ордера.
+func (s *Strategy) place(now time.Time) error {
+	for _, level := range s.levels {
+		if level.Side == "" || level.Status != types.GridOrderNone {
+			continue
+		}
+
+		// на уровне выставляется не больше одного ордера за свечу, поэтому ID уникален
+		level.OrderID = fmt.Sprintf("grid_%d_%d_%d", s.strategyID, level.Index, now.UnixMilli())
+		level.Status = types.GridOrderPlacing
+		if err := s.save(level, now); err != nil {
+			return err
+		}
+
+		level.CommandID = string(s.exchange.PlaceOrderAsync(s.order(level)))
+		if err := s.save(level, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// order возвращает лимитный ордер уровня
+func (s *Strategy) order(level *types.GridLevel) exchange.Order {
+	return exchange.Order{
+		ID:     level.OrderID,
+		Symbol: s.settings.Symbol,
+		Side:   level.Side,
+		Type:   types.OrderTypeLimit,
+		Price:  level.Price,
+		Amount: level.Amount,
+	}
+}
+
+func (s *Strategy) save(level *types.GridLevel, now time.Time) error {
+	level.StrategyID = s.strategyID
+	level.UpdatedAt = now
+	if s.repo == nil {
+		return nil
+	}
+	if err := s.repo.SaveGridLevel(level); err != nil {
+		return fmt.Errorf("failed to save grid level %d: %w", level.Index, err)
+	}
+	return nil
+}
+
+// Prices рассчитывает цены уровней сетки по возрастанию
+func Prices(s settings.GridSettings) []float64 {
+	prices := make([]float64, s.Levels)
+	n := float64(s.Levels - 1)
+	for i := range prices {
+		if s.Mode == "geometric" {
+			prices[i] = s.Lower * math.Pow(s.Upper/s.Lower, float64(i)/n)
+		} else {
+			prices[i] = s.Lower + (s.Upper-s.Lower)*float64(i)/n
+		}
+	}
+	return prices
+}
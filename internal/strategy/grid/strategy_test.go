@@ -0,0 +1,142 @@
+package grid
+
+import (
+	"context"
+	"crypto-trading-bot/internal/backtest"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/exchange/exchanges/mockexchange"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepo struct {
+	levels map[int]types.GridLevel
+}
+
+func (r *memoryRepo) GetGridLevels(strategyID int) ([]*types.GridLevel, error) {
+	levels := make([]*types.GridLevel, len(r.levels))
+	for i, level := range r.levels {
+		level := level
+		levels[i] = &level
+	}
+	return levels, nil
+}
+
+func (r *memoryRepo) SaveGridLevel(level *types.GridLevel) error {
+	r.levels[level.Index] = *level
+	return nil
+}
+
+func TestPrices(t *testing.T) {
+	assert.Equal(t, []float64{100, 110, 120}, Prices(settings.GridSettings{Lower: 100, Upper: 120, Levels: 3, Mode: "arithmetic"}))
+
+	prices := Prices(settings.GridSettings{Lower: 100, Upper: 400, Levels: 3, Mode: "geometric"})
+	assert.InDeltaSlice(t, []float64{100, 200, 400}, prices, 1e-9)
+}
+
+func TestGridStrategy(t *testing.T) {
+	cfg := &settings.GridSettings{Symbol: "BTCUSDT", Interval: "1m", Lower: 100, Upper: 104, Levels: 5, Mode: "arithmetic", Amount: 2}
+	ex := mockexchange.NewMockExchange(clock.New())
+	repo := &memoryRepo{levels: make(map[int]types.GridLevel)}
+
+	s, err := NewStrategy(ex, repo, cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Restore(7))
+
+	// сигналы исполнений передаются брокеру бэктеста
+	broker, err := backtest.NewBroker(&settings.BacktestSettings{InitialCapital: 1000})
+	assert.NoError(t, err)
+
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	step := 0
+	candle := func(s *Strategy, low float64, high float64, close float64) []*types.Signal {
+		step++
+		md := &types.MarketData{
+			Timestamp:  now.Add(time.Duration(step) * time.Minute),
+			HightPrice: high, LowPrice: low, ClosePrice: close,
+		}
+		broker.Mark("BTCUSDT", md)
+		signals, err := s.OnCandle(context.Background(), &processing.TradingPayload{
+			Symbol:     "BTCUSDT",
+			Interval:   "1m",
+			MarketData: md,
+		})
+		assert.NoError(t, err)
+		for _, signal := range signals {
+			_, err := broker.Execute(signal)
+			assert.NoError(t, err)
+		}
+		return signals
+	}
+
+	// покупки на 100 и 101, продажи на 103 и 104, уровень 102 свободен
+	assert.Empty(t, candle(s, 101.8, 102.2, 102))
+	assert.Len(t, ex.OpenOrders("BTCUSDT"), 4)
+	assert.Equal(t, "", s.Levels()[2].Side)
+
+	// исполняется покупка на 101, выставляется продажа на 102
+	signals := candle(s, 100.5, 102, 101)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.Equal(t, types.OrderTypeLimit, signals[0].Type)
+		assert.Equal(t, 101.0, signals[0].Price)
+		assert.Equal(t, 2.0, signals[0].Amount)
+		assert.Equal(t, now.Add(2*time.Minute), signals[0].Time)
+	}
+	assert.Equal(t, types.SideSell, s.Levels()[2].Side)
+	assert.Equal(t, 101.0, s.Levels()[2].EntryPrice)
+	assert.Len(t, ex.OpenOrders("BTCUSDT"), 4)
+
+	// исполняется продажа на 102: прибыль (102 - 101) * 2, покупка на 101 выставляется снова
+	signals = candle(s, 101.5, 102.5, 102.2)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+		assert.Equal(t, 102.0, signals[0].Price)
+	}
+	assert.InDelta(t, 2.0, s.Levels()[2].RealizedProfit, 1e-9)
+	assert.InDelta(t, 2.0, s.RealizedProfit(), 1e-9)
+	if trades := broker.Trades(); assert.Len(t, trades, 1) {
+		assert.InDelta(t, 2.0, trades[0].PnL, 1e-9)
+	}
+	assert.Equal(t, types.SideBuy, s.Levels()[1].Side)
+	assert.Equal(t, 7, repo.levels[2].StrategyID)
+	assert.InDelta(t, 2.0, repo.levels[2].RealizedProfit, 1e-9)
+
+	// после перезапуска симулятор создаётся заново: ордера уровней отправляются в него с прежними ID,
+	// новые ордера не выставляются
+	orderIDs := map[string]bool{}
+	for _, level := range s.Levels() {
+		orderIDs[level.OrderID] = level.OrderID != ""
+	}
+	restartedEx := mockexchange.NewMockExchange(clock.New())
+	restarted, err := NewStrategy(restartedEx, repo, cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, restarted.Restore(7))
+	assert.Empty(t, candle(restarted, 102, 102.3, 102.1))
+	assert.Len(t, restartedEx.OpenOrders("BTCUSDT"), 4)
+	for _, order := range restartedEx.OpenOrders("BTCUSDT") {
+		assert.True(t, orderIDs[order.ID], order.ID)
+	}
+	assert.InDelta(t, 2.0, restarted.RealizedProfit(), 1e-9)
+
+	// сетка продолжает торговать: исполняется покупка на 101, выставляется продажа на 102
+	signals = candle(restarted, 100.8, 102, 101.2)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+		assert.Equal(t, 101.0, signals[0].Price)
+	}
+	assert.Equal(t, types.SideSell, restarted.Levels()[2].Side)
+	assert.Len(t, restartedEx.OpenOrders("BTCUSDT"), 4)
+	assert.Equal(t, 2, repo.levels[1].Trades)
+
+	// сохранённая сетка не совпадает с настройками
+	changed := *cfg
+	changed.Levels = 6
+	other, _ := NewStrategy(ex, repo, &changed)
+	assert.Error(t, other.Restore(7))
+}
@@ -0,0 +1,247 @@
+package strategy
+
+import (
+	"context"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/repositories"
+	"crypto-trading-bot/internal/types"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	minRestartBackoff = time.Second
+	maxRestartBackoff = time.Minute
+)
+
+// Manager запускает активные стратегии из таблицы strategies.
+// Каждая стратегия работает в своей горутине под надзором: после паники или ошибки
+// она создаётся заново и перезапускается с нарастающей задержкой.
+type Manager struct {
+	repo     repositories.StrategyRepository
+	registry *Registry
+	feed     Feed
+	handler  SignalHandler
+	logger   *logger.Logger
+	clock    clock.Clock
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu      sync.Mutex
+	runners map[int]*runner // strategy id -> запущенная стратегия
+	wg      sync.WaitGroup
+}
+
+type runner struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewManager(repo repositories.StrategyRepository,
+	registry *Registry,
+	feed Feed,
+	handler SignalHandler,
+	logger *logger.Logger,
+	clk clock.Clock) *Manager {
+
+	return &Manager{
+		repo:       repo,
+		registry:   registry,
+		feed:       feed,
+		handler:    handler,
+		logger:     logger,
+		clock:      clk,
+		minBackoff: minRestartBackoff,
+		maxBackoff: maxRestartBackoff,
+		runners:    make(map[int]*runner),
+	}
+}
+
+// LoadAndStartAll запускает все активные стратегии.
+// Стратегии с ошибкой в настройках пропускаются, ошибки возвращаются вместе.
+func (m *Manager) LoadAndStartAll(ctx context.Context) error {
+	list, err := m.repo.GetActiveStrategyList()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, s := range list {
+		if err := m.Start(ctx, s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Start запускает стратегию. Настройки проверяются сразу, до запуска горутины.
+// Стратегия останавливается при отмене ctx или вызове Stop.
+func (m *Manager) Start(ctx context.Context, s *types.Strategy) error {
+	if _, err := m.registry.Build(s.Config); err != nil {
+		return fmt.Errorf("strategy %s: %w", s.Name, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.runners[s.ID]; exists {
+		return fmt.Errorf("strategy %s already running", s.Name)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	r := &runner{cancel: cancel, done: make(chan struct{})}
+	m.runners[s.ID] = r
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(r.done)
+		defer func() {
+			m.mu.Lock()
+			delete(m.runners, s.ID)
+			m.mu.Unlock()
+		}()
+
+		m.supervise(runCtx, s)
+	}()
+
+	m.logger.Infof("Strategy %s started", s.Name)
+	return nil
+}
+
+// Stop останавливает стратегию и ждёт завершения её горутины
+func (m *Manager) Stop(id int) {
+	m.mu.Lock()
+	r, exists := m.runners[id]
+	m.mu.Unlock()
+
+	if !exists {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+}
+
+// StopAll останавливает все стратегии и ждёт их завершения
+func (m *Manager) StopAll() {
+	m.mu.Lock()
+	for _, r := range m.runners {
+		r.cancel()
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+// Running возвращает идентификаторы запущенных стратегий
+func (m *Manager) Running() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]int, 0, len(m.runners))
+	for id := range m.runners {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// supervise выполняет стратегию и перезапускает её после сбоя.
+// Задержка удваивается после каждого сбоя и сбрасывается, если стратегия проработала дольше maxBackoff.
+func (m *Manager) supervise(ctx context.Context, s *types.Strategy) {
+	backoff := m.minBackoff
+
+	for {
+		started := m.clock.Now()
+		err := m.run(ctx, s)
+		if ctx.Err() != nil {
+			m.logger.Infof("Strategy %s stopped", s.Name)
+			return
+		}
+		if err == nil {
+			m.logger.Infof("Strategy %s finished: no more market data", s.Name)
+			return
+		}
+
+		if m.clock.Since(started) > m.maxBackoff {
+			backoff = m.minBackoff
+		}
+		m.logger.Errorf("Strategy %s failed: %v. Restart in %v", s.Name, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			m.logger.Infof("Strategy %s stopped", s.Name)
+			return
+		case <-m.clock.After(backoff):
+		}
+
+		backoff = min(backoff*2, m.maxBackoff)
+	}
+}
+
+// run создаёт экземпляр стратегии и передаёт ему свечи до закрытия канала, ошибки или паники
+func (m *Manager) run(ctx context.Context, s *types.Strategy) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	instance, err := m.registry.Build(s.Config)
+	if err != nil {
+		return err
+	}
+
+	if stateful, ok := instance.(Stateful); ok {
+		if err := stateful.Restore(s.ID); err != nil {
+			return fmt.Errorf("failed to restore state: %w", err)
+		}
+	}
+
+	// подписка освобождается при выходе, в том числе после паники
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	candles, err := m.feed.Subscribe(runCtx, instance.Subscriptions())
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case md, ok := <-candles:
+			if !ok {
+				return nil
+			}
+
+			payload := &processing.TradingPayload{
+				Symbol:       md.Symbol,
+				Interval:     md.TimeFrame,
+				CurrentPrice: md.ClosePrice,
+				MarketData:   md,
+			}
+
+			signals, err := instance.OnCandle(ctx, payload)
+			if err != nil {
+				return err
+			}
+
+			for _, signal := range signals {
+				signal.StrategyID = s.ID
+				signal.Strategy = s.Name
+				if err := m.handler.HandleSignal(ctx, signal); err != nil {
+					return fmt.Errorf("failed to handle signal %s %s: %w", signal.Symbol, signal.Side, err)
+				}
+			}
+		}
+	}
+}
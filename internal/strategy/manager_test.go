@@ -0,0 +1,196 @@
+package strategy
+
+import (
+	"context"
+	"crypto-trading-bot/internal/clock"
+	"crypto-trading-bot/internal/logger"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSettings struct {
+	Symbol string `json:"symbol" validate:"required"`
+}
+
+func (d testSettings) SettingsType() string { return "test" }
+
+// testStrategy покупает на каждой свече, на первой свече первого запуска паникует
+type testStrategy struct {
+	symbol string
+	panics *atomic.Int32
+}
+
+func (s *testStrategy) Subscriptions() []Subscription {
+	return []Subscription{{Symbol: s.symbol, Interval: "1m"}}
+}
+
+func (s *testStrategy) OnCandle(_ context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	if s.panics.Add(1) == 1 {
+		panic("boom")
+	}
+	return []*types.Signal{{Symbol: payload.Symbol, Side: types.SideBuy, Price: payload.CurrentPrice}}, nil
+}
+
+type testRepo struct{ list []*types.Strategy }
+
+func (r *testRepo) GetStrategy(int) (*types.Strategy, error)          { return r.list[0], nil }
+func (r *testRepo) GetActiveStrategyList() ([]*types.Strategy, error) { return r.list, nil }
+func (r *testRepo) SaveStrategy(*types.Strategy) error                { return nil }
+
+// testFeed выдаёт бесконечный поток свечей
+type testFeed struct{}
+
+func (testFeed) Subscribe(ctx context.Context, subs []Subscription) (<-chan *types.MarketData, error) {
+	ch := make(chan *types.MarketData)
+	go func() {
+		defer close(ch)
+		for i := 0; ; i++ {
+			md := &types.MarketData{Symbol: subs[0].Symbol, TimeFrame: subs[0].Interval, ClosePrice: float64(i)}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- md:
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func newTestRegistry(panics *atomic.Int32) *Registry {
+	settingsRegistry := settings.NewSettingsRegistry()
+	settingsRegistry.Register("test", func() settings.Settings { return &testSettings{} })
+
+	registry := NewRegistry(settingsRegistry)
+	registry.Register("test", func(comps ...settings.Settings) (Strategy, error) {
+		s := &testStrategy{panics: panics}
+		for _, c := range comps {
+			if val, ok := c.(*testSettings); ok {
+				s.symbol = val.Symbol
+			}
+		}
+		return s, nil
+	})
+	return registry
+}
+
+func TestRegistryBuild(t *testing.T) {
+	registry := newTestRegistry(&atomic.Int32{})
+
+	s, err := registry.Build(json.RawMessage(`{"type": "test", "settings": {"symbol": "BTCUSDT"}}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "BTCUSDT", s.Subscriptions()[0].Symbol)
+	}
+
+	_, err = registry.Build(json.RawMessage(`{"type": "unknown"}`))
+	assert.ErrorContains(t, err, "unknown strategy type")
+
+	_, err = registry.Build(json.RawMessage(`{"type": "test", "settings": {}}`))
+	assert.ErrorContains(t, err, "validation failed")
+}
+
+func TestManagerRestartsAfterPanic(t *testing.T) {
+	panics := &atomic.Int32{}
+	repo := &testRepo{list: []*types.Strategy{
+		{ID: 1, Name: "test", Config: json.RawMessage(`{"type": "test", "settings": {"symbol": "BTCUSDT"}}`), Active: true},
+	}}
+
+	var mu sync.Mutex
+	var signals []*types.Signal
+	handler := SignalHandlerFunc(func(_ context.Context, signal *types.Signal) error {
+		mu.Lock()
+		defer mu.Unlock()
+		signals = append(signals, signal)
+		return nil
+	})
+
+	manager := NewManager(repo, newTestRegistry(panics), testFeed{}, handler, logger.NewLogger("fatal"), clock.New())
+	manager.minBackoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.NoError(t, manager.LoadAndStartAll(ctx))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(signals) > 0
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, 1, signals[0].StrategyID)
+	assert.Equal(t, "test", signals[0].Strategy)
+	mu.Unlock()
+
+	cancel()
+	manager.StopAll()
+	assert.Empty(t, manager.Running())
+}
+
+// memoryLoader хранит свечи в памяти
+type memoryLoader struct {
+	mu   sync.Mutex
+	data []*types.MarketData
+}
+
+func (l *memoryLoader) add(md *types.MarketData) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.data = append(l.data, md)
+}
+
+func (l *memoryLoader) GetMarketDataPeriod(symbol string, interval string, start time.Time, end time.Time) ([]*types.MarketData, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var result []*types.MarketData
+	for _, md := range l.data {
+		if md.Symbol == symbol && md.TimeFrame == interval && !md.Timestamp.Before(start) && !md.Timestamp.After(end) {
+			result = append(result, md)
+		}
+	}
+	return result, nil
+}
+
+func TestPollingFeed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	candle := func(symbol string, at time.Duration) *types.MarketData {
+		return &types.MarketData{Symbol: symbol, TimeFrame: "1m", Timestamp: now.Add(at)}
+	}
+
+	loader := &memoryLoader{}
+	loader.add(candle("BTCUSDT", -3*time.Minute)) // старше истории
+	loader.add(candle("BTCUSDT", -time.Minute))
+	loader.add(candle("BTCUSDT", 0))
+	loader.add(candle("ETHUSDT", -30*time.Second))
+
+	clk := clock.NewSimulated(now)
+	feed := NewPollingFeed(loader, clk, logger.NewLogger("fatal"), time.Minute, 2*time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	candles, err := feed.Subscribe(ctx, []Subscription{{Symbol: "BTCUSDT", Interval: "1m"}, {Symbol: "ETHUSDT", Interval: "1m"}})
+	assert.NoError(t, err)
+
+	// история подписок в порядке времени
+	for _, want := range []*types.MarketData{candle("BTCUSDT", -time.Minute), candle("ETHUSDT", -30*time.Second), candle("BTCUSDT", 0)} {
+		assert.Equal(t, want, <-candles)
+	}
+
+	// новая свеча отдаётся при следующем опросе, уже отданные не повторяются
+	clk.BlockUntil(1)
+	loader.add(candle("BTCUSDT", time.Minute))
+	clk.Advance(time.Minute)
+	assert.Equal(t, candle("BTCUSDT", time.Minute), <-candles)
+
+	clk.BlockUntil(1)
+	cancel()
+	_, ok := <-candles
+	assert.False(t, ok)
+}
@@ -0,0 +1,214 @@
+package pairs
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/service/pairs"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/strategy"
+	"crypto-trading-bot/internal/types"
+	"fmt"
+	"math"
+	"time"
+)
+
+var _ strategy.Strategy = (*Strategy)(nil)
+
+// cointegrationLags — лаги разностей в тесте ADF остатков. По правилу Шверта на окне в десятки свечей
+// лагов получается около десяти, и тест теряет мощность даже на стационарном спреде.
+const cointegrationLags = 1
+
+// Strategy торгует спредом y - alpha - Σ beta·x между несколькими символами.
+// Свечи символов выравниваются по времени: решение принимается, когда пришли свечи всех символов
+// с одним временем. Спред текущей свечи считается по коэффициентам, оценённым на предыдущих свечах.
+// Все ноги входа и выхода выдаются одной пачкой сигналов на одной свече.
+// Коинтеграция символов проверяется тестом Энгла — Грейнджера по последним Window свечам:
+// первый раз после прогрева, затем каждые Window свечей. Пока символы не коинтегрированы,
+// новые позиции не открываются, открытая позиция закрывается по обычным правилам.
+type Strategy struct {
+	settings     settings.PairsSettings
+	model        pairs.HedgeModel
+	zscore       *pairs.ZScore
+	current      time.Time                    // время собираемой свечи
+	pending      map[string]*types.MarketData // символ -> свеча с временем current
+	holdings     map[string]float64           // символ -> позиция со знаком
+	side         int                          // +1 — куплен спред, -1 — продан, 0 — нет позиции
+	history      [][]float64                  // цены символов за последние Window свечей, в порядке Symbols
+	bars         int                          // собранных свечей
+	cointegrated bool                         // результат последней проверки коинтеграции
+}
+
+func NewStrategy(comps ...settings.Settings) (strategy.Strategy, error) {
+	s := &Strategy{
+		pending:  make(map[string]*types.MarketData),
+		holdings: make(map[string]float64),
+	}
+
+	for _, c := range comps {
+		if val, ok := c.(*settings.PairsSettings); ok {
+			s.settings = *val
+		}
+	}
+
+	if len(s.settings.Symbols) < 2 || s.settings.Window <= 0 {
+		return nil, fmt.Errorf("pairs settings are not set")
+	}
+
+	var err error
+	if s.model, err = pairs.NewHedgeModel(s.settings.Hedge, len(s.settings.Symbols)-1, s.settings.Window); err != nil {
+		return nil, err
+	}
+	s.zscore = pairs.NewZScore(s.settings.Window)
+	s.history = make([][]float64, len(s.settings.Symbols))
+
+	return s, nil
+}
+
+func (s *Strategy) Subscriptions() []strategy.Subscription {
+	subs := make([]strategy.Subscription, 0, len(s.settings.Symbols))
+	for _, symbol := range s.settings.Symbols {
+		subs = append(subs, strategy.Subscription{Symbol: symbol, Interval: s.settings.Interval})
+	}
+	return subs
+}
+
+func (s *Strategy) OnCandle(_ context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	md := payload.MarketData
+	if md == nil || payload.Interval != s.settings.Interval || !s.isLeg(payload.Symbol) {
+		return nil, nil
+	}
+
+	if md.Timestamp.Before(s.current) {
+		return nil, nil
+	}
+	if md.Timestamp.After(s.current) {
+		// началась новая свеча, незавершённый набор предыдущей отбрасываем
+		s.current = md.Timestamp
+		clear(s.pending)
+	}
+	s.pending[payload.Symbol] = md
+
+	if len(s.pending) < len(s.settings.Symbols) {
+		return nil, nil
+	}
+
+	prices := make([]float64, len(s.settings.Symbols))
+	for i, symbol := range s.settings.Symbols {
+		prices[i] = s.pending[symbol].ClosePrice
+	}
+	clear(s.pending)
+
+	s.test(prices)
+
+	y, x := prices[0], prices[1:]
+
+	z := math.NaN()
+	if s.model.Ready() {
+		z = s.zscore.Update(pairs.Spread(s.model, y, x))
+	}
+	betas := append([]float64(nil), s.model.Betas()...)
+	s.model.Update(y, x)
+
+	if math.IsNaN(z) {
+		return nil, nil
+	}
+
+	switch {
+	case s.side == 0 && !s.cointegrated:
+		return nil, nil
+	case s.side == 0 && z > s.settings.EntryZ:
+		return s.enter(-1, betas, prices, z), nil
+	case s.side == 0 && z < -s.settings.EntryZ:
+		return s.enter(1, betas, prices, z), nil
+	case s.side != 0 && s.settings.StopZ > 0 && math.Abs(z) > s.settings.StopZ:
+		return s.exit(prices, fmt.Sprintf("pairs stop z=%.2f", z)), nil
+	case s.side == 1 && z >= -s.settings.ExitZ, s.side == -1 && z <= s.settings.ExitZ:
+		return s.exit(prices, fmt.Sprintf("pairs exit z=%.2f", z)), nil
+	}
+
+	return nil, nil
+}
+
+// test добавляет цены свечи в историю и, когда собрано очередное окно, проверяет коинтеграцию.
+// Ошибка теста, например на постоянных ценах, считается отсутствием коинтеграции.
+func (s *Strategy) test(prices []float64) {
+	window := s.settings.Window
+	for i, price := range prices {
+		s.history[i] = append(s.history[i], price)
+		if len(s.history[i]) > window {
+			s.history[i] = s.history[i][1:]
+		}
+	}
+
+	s.bars++
+	if s.bars < window || s.bars%window != 0 {
+		return
+	}
+
+	result, err := pairs.EngleGranger(s.history[0], s.history[1:], cointegrationLags)
+	s.cointegrated = err == nil && result.Cointegrated()
+}
+
+// enter открывает позицию по спреду: side = +1 — покупка спреда (y дешёвый), -1 — продажа
+func (s *Strategy) enter(side int, betas []float64, prices []float64, z float64) []*types.Signal {
+	reason := fmt.Sprintf("pairs long spread z=%.2f", z)
+	if side < 0 {
+		reason = fmt.Sprintf("pairs short spread z=%.2f", z)
+	}
+
+	s.side = side
+	s.holdings[s.settings.Symbols[0]] = float64(side) * s.settings.Amount
+	for i, beta := range betas {
+		s.holdings[s.settings.Symbols[i+1]] = -float64(side) * beta * s.settings.Amount
+	}
+
+	return s.orders(prices, reason, false)
+}
+
+// exit закрывает все ноги
+func (s *Strategy) exit(prices []float64, reason string) []*types.Signal {
+	signals := s.orders(prices, reason, true)
+	s.side = 0
+	clear(s.holdings)
+	return signals
+}
+
+// orders формирует ордера по позициям ног: на открытие или на закрытие
+func (s *Strategy) orders(prices []float64, reason string, closing bool) []*types.Signal {
+	signals := make([]*types.Signal, 0, len(s.settings.Symbols))
+	for i, symbol := range s.settings.Symbols {
+		amount := s.holdings[symbol]
+		if closing {
+			amount = -amount
+		}
+		if amount == 0 {
+			continue
+		}
+
+		side := types.SideBuy
+		if amount < 0 {
+			side = types.SideSell
+		}
+
+		signals = append(signals, &types.Signal{
+			Source: "pairs",
+			Symbol: symbol,
+			Side:   side,
+			Type:   types.OrderTypeMarket,
+			Price:  prices[i],
+			Amount: math.Abs(amount),
+			Reason: reason,
+			Time:   s.current,
+		})
+	}
+	return signals
+}
+
+func (s *Strategy) isLeg(symbol string) bool {
+	for _, leg := range s.settings.Symbols {
+		if leg == symbol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package pairs
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPairsStrategy(t *testing.T) {
+	s, err := NewStrategy(&settings.PairsSettings{
+		Symbols:  []string{"ETHUSDT", "BTCUSDT"},
+		Interval: "1m",
+		Hedge:    "ols",
+		Window:   50,
+		EntryZ:   3,
+		ExitZ:    0.5,
+		Amount:   1,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, s.Subscriptions(), 2)
+
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	rnd := rand.New(rand.NewSource(1))
+	price := 100.0
+
+	candle := func(symbol string, ts time.Time, close float64) []*types.Signal {
+		signals, err := s.OnCandle(context.Background(), &processing.TradingPayload{
+			Symbol:     symbol,
+			Interval:   "1m",
+			MarketData: &types.MarketData{Symbol: symbol, Timestamp: ts, ClosePrice: close},
+		})
+		assert.NoError(t, err)
+		return signals
+	}
+
+	var entry, exit []*types.Signal
+	for i := 0; i < 200; i++ {
+		ts := now.Add(time.Duration(i) * time.Minute)
+		price += rnd.NormFloat64()
+		spread := rnd.NormFloat64() * 0.1
+		if i == 150 {
+			// резкое расширение спреда
+			spread = 10
+		}
+
+		// до прихода второй ноги решение не принимается
+		assert.Empty(t, candle("BTCUSDT", ts, price))
+		signals := candle("ETHUSDT", ts, 5+2*price+spread)
+
+		switch {
+		case entry == nil && len(signals) > 0:
+			entry = signals
+		case entry != nil && exit == nil && len(signals) > 0:
+			exit = signals
+		}
+	}
+
+	if assert.Len(t, entry, 2) && assert.Len(t, exit, 2) {
+		// спред дорогой: продаём ETH, покупаем BTC в количестве beta
+		assert.Equal(t, types.SideSell, entry[0].Side)
+		assert.Equal(t, "ETHUSDT", entry[0].Symbol)
+		assert.Equal(t, types.SideBuy, entry[1].Side)
+		assert.InDelta(t, 2.0, entry[1].Amount, 0.1)
+		assert.Equal(t, entry[0].Time, entry[1].Time)
+
+		assert.Equal(t, types.SideBuy, exit[0].Side)
+		assert.Equal(t, types.SideSell, exit[1].Side)
+		assert.Equal(t, entry[1].Amount, exit[1].Amount)
+		assert.Equal(t, exit[0].Time, exit[1].Time)
+	}
+}
+
+func TestPairsStrategyNotCointegrated(t *testing.T) {
+	s, err := NewStrategy(&settings.PairsSettings{
+		Symbols:  []string{"ETHUSDT", "BTCUSDT"},
+		Interval: "1m",
+		Hedge:    "ols",
+		Window:   50,
+		EntryZ:   3,
+		ExitZ:    0.5,
+		Amount:   1,
+	})
+	assert.NoError(t, err)
+
+	now, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	rnd := rand.New(rand.NewSource(1))
+	btc, eth := 100.0, 200.0
+
+	for i := 0; i < 200; i++ {
+		ts := now.Add(time.Duration(i) * time.Minute)
+		// независимые случайные блуждания
+		btc += rnd.NormFloat64()
+		eth += rnd.NormFloat64()
+		if i == 150 {
+			eth += 30
+		}
+
+		for _, leg := range []struct {
+			symbol string
+			price  float64
+		}{{"BTCUSDT", btc}, {"ETHUSDT", eth}} {
+			signals, err := s.OnCandle(context.Background(), &processing.TradingPayload{
+				Symbol:     leg.symbol,
+				Interval:   "1m",
+				MarketData: &types.MarketData{Symbol: leg.symbol, Timestamp: ts, ClosePrice: leg.price},
+			})
+			assert.NoError(t, err)
+			assert.Empty(t, signals, "i=%d", i)
+		}
+	}
+}
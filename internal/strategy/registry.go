@@ -0,0 +1,83 @@
+package strategy
+
+import (
+	"crypto-trading-bot/internal/settings"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Factory создаёт стратегию по настройкам, по аналогии с конструкторами процессоров
+type Factory func(comps ...settings.Settings) (Strategy, error)
+
+// Config — содержимое strategies.config
+type Config struct {
+	Type     string          `json:"type"`     // тип стратегии в реестре
+	Settings json.RawMessage `json:"settings"` // настройки, тип настроек совпадает с типом стратегии
+}
+
+// Registry хранит фабрики стратегий по имени типа.
+// Настройки стратегии собираются и проверяются через SettingsRegistry под тем же именем типа.
+type Registry struct {
+	settings  *settings.SettingsRegistry
+	factories map[string]Factory
+	mu        sync.RWMutex
+}
+
+func NewRegistry(settingsRegistry *settings.SettingsRegistry) *Registry {
+	return &Registry{
+		settings:  settingsRegistry,
+		factories: make(map[string]Factory),
+	}
+}
+
+// Регистрация фабрики для типа стратегии
+func (r *Registry) Register(strategyType string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.factories[strategyType]; exists {
+		panic(fmt.Sprintf("strategy type %q already registered", strategyType))
+	}
+	r.factories[strategyType] = factory
+}
+
+// Build создаёт стратегию по содержимому strategies.config
+func (r *Registry) Build(rawConfig json.RawMessage) (Strategy, error) {
+	factory, comp, err := r.build(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+	return factory(comp)
+}
+
+// Settings собирает и проверяет настройки стратегии из содержимого strategies.config
+func (r *Registry) Settings(rawConfig json.RawMessage) (settings.Settings, error) {
+	_, comp, err := r.build(rawConfig)
+	return comp, err
+}
+
+func (r *Registry) build(rawConfig json.RawMessage) (Factory, settings.Settings, error) {
+	var cfg Config
+	if err := json.Unmarshal(rawConfig, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal strategy config: %w", err)
+	}
+
+	r.mu.RLock()
+	factory, exists := r.factories[cfg.Type]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, nil, fmt.Errorf("unknown strategy type: %s", cfg.Type)
+	}
+
+	if len(cfg.Settings) == 0 {
+		cfg.Settings = json.RawMessage(`{}`)
+	}
+
+	comp, err := r.settings.Build(cfg.Type, cfg.Settings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return factory, comp, nil
+}
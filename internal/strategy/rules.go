@@ -0,0 +1,91 @@
+package strategy
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"fmt"
+)
+
+var _ Strategy = (*RuleStrategy)(nil)
+
+// RuleStrategy — стратегия на правилах входа и выхода, записанных выражениями (тип "rules").
+// Правила хранятся в strategies.config:
+//
+//	{"type": "rules", "settings": {"symbol": "BTCUSDT", "interval": "1m",
+//	 "entry": "rsi(14) < 30 and close > sma(close, 50)", "exit": "rsi(14) > 70", "amount": 0.01}}
+//
+// Значения для правил рассчитываются перед ними на той же свече: индикаторы из indicators
+// и расстояние до ближайшего уровня cluster_level_dist, если заданы levels.
+type RuleStrategy struct {
+	settings   settings.RuleSettings
+	levels     *processing.LevelSignalProcessor
+	indicators *processing.IndicatorProcessor
+	processor  *processing.RuleProcessor
+}
+
+func NewRuleStrategy(comps ...settings.Settings) (Strategy, error) {
+	s := &RuleStrategy{}
+	for _, c := range comps {
+		if val, ok := c.(*settings.RuleSettings); ok {
+			s.settings = *val
+		}
+	}
+
+	if s.settings.Symbol == "" || s.settings.Interval == "" {
+		return nil, fmt.Errorf("rule settings are not set")
+	}
+
+	ruleComps := []settings.Settings{&s.settings}
+	var err error
+	if s.settings.Levels != nil {
+		// сигналы уровней стратегии не нужны, поэтому они не сохраняются
+		if s.levels, err = processing.NewLevelSignalProcessor(nil, s.settings.Levels); err != nil {
+			return nil, err
+		}
+		ruleComps = append(ruleComps, s.settings.Levels)
+	}
+	if len(s.settings.Indicators) > 0 {
+		indicatorSettings := &settings.IndicatorSettings{Indicators: s.settings.Indicators}
+		if s.indicators, err = processing.NewIndicatorProcessor(indicatorSettings); err != nil {
+			return nil, err
+		}
+		ruleComps = append(ruleComps, indicatorSettings)
+	}
+
+	if s.processor, err = processing.NewRuleProcessor(ruleComps...); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *RuleStrategy) Subscriptions() []Subscription {
+	return []Subscription{{Symbol: s.settings.Symbol, Interval: s.settings.Interval}}
+}
+
+func (s *RuleStrategy) OnCandle(ctx context.Context, payload *processing.TradingPayload) ([]*types.Signal, error) {
+	if payload.Symbol != s.settings.Symbol || payload.Interval != s.settings.Interval {
+		return nil, nil
+	}
+
+	if s.levels != nil {
+		n := len(payload.Signals)
+		if _, err := s.levels.Process(ctx, payload); err != nil {
+			return nil, err
+		}
+		// сигналы уровней — только вход для правил, стратегия выдаёт сигналы правил
+		payload.Signals = payload.Signals[:n]
+	}
+	if s.indicators != nil {
+		if _, err := s.indicators.Process(ctx, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.processor.Process(ctx, payload); err != nil {
+		return nil, err
+	}
+	return payload.Signals, nil
+}
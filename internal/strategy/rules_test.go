@@ -0,0 +1,68 @@
+package strategy
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/settings"
+	"crypto-trading-bot/internal/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleStrategyLevelsAndIndicators(t *testing.T) {
+	s, err := NewRuleStrategy(&settings.RuleSettings{
+		Symbol:     "BTCUSDT",
+		Interval:   "1m",
+		Entry:      "cluster_level_dist < 0.2% and close > sma_3",
+		Exit:       "cluster_level_dist > 1%",
+		Amount:     1,
+		Indicators: []string{"sma_3"},
+		Levels: &settings.LevelSignalSettings{
+			ClusterInterval: "1h",
+			Clusters:        1,
+			ValueFactor:     1,
+			TimeFactor:      0.0001,
+			TouchTolerance:  0.001,
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start, _ := time.Parse(time.RFC3339, "2025-01-05T00:00:00Z")
+	process := func(ts time.Time, price float64) []*types.Signal {
+		signals, err := s.OnCandle(context.TODO(), &processing.TradingPayload{Symbol: "BTCUSDT", Interval: "1m", MarketData: &types.MarketData{
+			Timestamp: ts, OpenPrice: price, HightPrice: price, LowPrice: price, ClosePrice: price, Volume: 10,
+		}})
+		assert.NoError(t, err)
+		return signals
+	}
+
+	// первый час торгуется вокруг 100: уровней ещё нет, cluster_level_dist не рассчитан
+	for i := 0; i < 60; i++ {
+		assert.Empty(t, process(start.Add(time.Duration(i)*time.Minute), 99+float64(i%2)*2))
+	}
+
+	// уровень около 100 построен по закрытому часу, цена у уровня и выше sma_3
+	hour := start.Add(time.Hour)
+	signals := process(hour, 100.1)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, "rules", signals[0].Source)
+		assert.Equal(t, types.SideBuy, signals[0].Side)
+	}
+
+	// цена ушла от уровня больше чем на 1%
+	signals = process(hour.Add(time.Minute), 102)
+	if assert.Len(t, signals, 1) {
+		assert.Equal(t, types.SideSell, signals[0].Side)
+	}
+}
+
+func TestRuleStrategyUnknownIdentifier(t *testing.T) {
+	_, err := NewRuleStrategy(&settings.RuleSettings{
+		Symbol: "BTCUSDT", Interval: "1m", Entry: "cluster_level_dist < 0.2%", Amount: 1,
+	})
+	assert.ErrorContains(t, err, "unknown identifier \"cluster_level_dist\"")
+}
@@ -0,0 +1,45 @@
+package strategy
+
+import (
+	"context"
+	"crypto-trading-bot/internal/processing"
+	"crypto-trading-bot/internal/types"
+)
+
+// Subscription — символ и интервал свечей, на которые реагирует стратегия
+type Subscription struct {
+	Symbol   string
+	Interval string
+}
+
+// Strategy реагирует на свечи и принимает торговые решения.
+// Стратегия вызывается из одной горутины, синхронизация внутри не требуется.
+type Strategy interface {
+	// Subscriptions возвращает символы и интервалы, свечи которых нужны стратегии
+	Subscriptions() []Subscription
+	// OnCandle вызывается на каждой свече подписки и возвращает сигналы (ордера)
+	OnCandle(ctx context.Context, payload *processing.TradingPayload) ([]*types.Signal, error)
+}
+
+// Stateful — стратегия, которая хранит своё состояние в базе и продолжает работу после перезапуска.
+// Restore вызывается менеджером после создания экземпляра, до первой свечи.
+type Stateful interface {
+	Restore(strategyID int) error
+}
+
+// Feed поставляет свечи для стратегий. Канал закрывается по окончании данных или отмене контекста.
+type Feed interface {
+	Subscribe(ctx context.Context, subs []Subscription) (<-chan *types.MarketData, error)
+}
+
+// SignalHandler исполняет сигналы стратегий: выставляет ордера, сохраняет, логирует
+type SignalHandler interface {
+	HandleSignal(ctx context.Context, signal *types.Signal) error
+}
+
+// SignalHandlerFunc позволяет использовать функцию как SignalHandler
+type SignalHandlerFunc func(ctx context.Context, signal *types.Signal) error
+
+func (f SignalHandlerFunc) HandleSignal(ctx context.Context, signal *types.Signal) error {
+	return f(ctx, signal)
+}
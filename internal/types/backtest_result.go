@@ -0,0 +1,67 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BacktestResult — итоги прогона стратегии на исторических данных
+type BacktestResult struct {
+	ID               int             `db:"id"`
+	StrategyID       int             `db:"strategy_id"`
+	StartTime        time.Time       `db:"start_time"`
+	EndTime          time.Time       `db:"end_time"`
+	InitialCapital   float64         `db:"initial_capital"`
+	FinalCapital     float64         `db:"final_capital"`
+	TotalProfit      float64         `db:"total_profit"`
+	Drawdown         float64         `db:"drawdown"` // максимальная просадка капитала, доля от пика
+	SuccessfulTrades int             `db:"successful_trades"`
+	FailedTrades     int             `db:"failed_trades"`
+	Config           json.RawMessage `db:"config"` // настройки стратегии, источника данных и брокера
+	BacktestCosts
+}
+
+// BacktestCosts — издержки исполнения за прогон в валюте котировки
+type BacktestCosts struct {
+	Commission float64 `db:"commission"`
+	Spread     float64 `db:"spread_cost"`     // потери на половине спреда рыночных ордеров
+	Slippage   float64 `db:"slippage_cost"`   // потери на проскальзывании
+	Impact     float64 `db:"impact_cost"`     // потери на влиянии ордеров на рынок
+	Delayed    int     `db:"delayed_orders"`  // количество ордеров, исполненных с задержкой
+	Latency    float64 `db:"average_latency"` // средняя задержка исполнения отложенных ордеров, секунды
+}
+
+// BacktestEquity — оценка капитала прогона на время свечи
+type BacktestEquity struct {
+	ResultID  int       `db:"result_id"`
+	Timestamp time.Time `db:"timestamp"`
+	Equity    float64   `db:"equity"`
+}
+
+// BacktestTrade — закрытая сделка прогона
+type BacktestTrade struct {
+	ResultID   int       `db:"result_id"`
+	Symbol     string    `db:"symbol"`
+	Side       string    `db:"side"` // long, short
+	EntryTime  time.Time `db:"entry_time"`
+	ExitTime   time.Time `db:"exit_time"`
+	EntryPrice float64   `db:"entry_price"`
+	ExitPrice  float64   `db:"exit_price"`
+	Quantity   float64   `db:"quantity"`
+	PnL        float64   `db:"pnl"` // с учётом комиссий
+}
+
+// BacktestMonteCarlo — распределения показателей прогона по симуляциям одного метода Monte Carlo
+type BacktestMonteCarlo struct {
+	ID          int             `db:"id"`
+	ResultID    int             `db:"result_id"`
+	Method      string          `db:"method"` // shuffle, bootstrap, skip, noise
+	Simulations int             `db:"simulations"`
+	Seed        int64           `db:"seed"`
+	Confidence  float64         `db:"confidence"`   // уровень доверительных интервалов
+	RuinLevel   float64         `db:"ruin_level"`   // доля потери начального капитала, считающаяся разорением
+	FinalEquity json.RawMessage `db:"final_equity"` // распределение итогового капитала
+	MaxDrawdown json.RawMessage `db:"max_drawdown"` // распределение максимальной просадки
+	RiskOfRuin  json.RawMessage `db:"risk_of_ruin"` // доля симуляций с разорением и её доверительный интервал
+	CreatedAt   time.Time       `db:"created_at"`
+}
@@ -0,0 +1,14 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BehaviorTree — сохранённое состояние поведенческого дерева стратегии
+type BehaviorTree struct {
+	ID           int             `db:"id"`
+	StrategyID   int             `db:"strategy_id"`
+	State        json.RawMessage `db:"state"`
+	LastExecuted time.Time       `db:"last_executed"`
+}
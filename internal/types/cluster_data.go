@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// ClusterData — объём на ценовом уровне за бар (строка таблицы cluster_data).
+// IsBuySell = true для объёма покупок, false для объёма продаж.
+type ClusterData struct {
+	Timestamp    time.Time `db:"timestamp"`
+	Symbol       string    `db:"symbol"`
+	TimeFrame    string    `db:"time_frame"`
+	IsBuySell    bool      `db:"is_buysell"`
+	ClusterPrice float64   `db:"cluster_price"`
+	Volume       float64   `db:"volume"`
+}
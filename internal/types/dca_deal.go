@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+// DCADeal — состояние открытой сделки DCA, которое не восстанавливается по trade_operations
+type DCADeal struct {
+	ID           int       `db:"id"`
+	StrategyID   int       `db:"strategy_id"`
+	DealID       string    `db:"deal_id"`
+	TrailingHigh float64   `db:"trailing_high"` // максимум цены после достижения тейк-профита, 0 — трейлинг не активен
+	UpdatedAt    time.Time `db:"updated_at"`
+}
@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// Статусы ордера уровня сетки
+const (
+	GridOrderNone    = ""        // ордера нет
+	GridOrderPlacing = "placing" // ордер записан, но подтверждения от биржи ещё нет
+	GridOrderOpen    = "open"    // ордер выставлен
+)
+
+// GridLevel — уровень сеточной стратегии с ордером и накопленной прибылью
+type GridLevel struct {
+	ID             int       `db:"id"`
+	StrategyID     int       `db:"strategy_id"`
+	Index          int       `db:"level_index"`
+	Price          float64   `db:"price"`
+	Side           string    `db:"side"`        // сторона ордера уровня, пусто — уровень свободен
+	EntryPrice     float64   `db:"entry_price"` // цена исполненного встречного ордера, 0 — ордер начальной расстановки
+	OrderID        string    `db:"order_id"`
+	CommandID      string    `db:"command_id"`
+	Status         string    `db:"status"`
+	Amount         float64   `db:"amount"`
+	RealizedProfit float64   `db:"realized_profit"`
+	Trades         int       `db:"trades"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// Виды сигналов по уровням поддержки и сопротивления
+const (
+	LevelBreakout  = "breakout"  // закрытие по другую сторону уровня
+	LevelRetest    = "retest"    // возврат к пробитому уровню с закрытием в сторону пробоя
+	LevelRejection = "rejection" // касание уровня с закрытием на прежней стороне
+)
+
+// LevelSignal — сигнал по уровню, построенному из серии кластеров
+type LevelSignal struct {
+	ID        int       `db:"id"`
+	Symbol    string    `db:"symbol"`
+	TimeFrame string    `db:"time_frame"`
+	Timestamp time.Time `db:"timestamp"` // время свечи
+	Kind      string    `db:"kind"`      // breakout, retest, rejection
+	Side      string    `db:"side"`      // buy, sell
+	Level     float64   `db:"level"`     // цена уровня
+	Price     float64   `db:"price"`     // цена закрытия свечи
+	Distance  float64   `db:"distance"`  // (Price - Level) / Level
+	Strength  float64   `db:"strength"`  // сила уровня 0..1 с учётом объёма и возраста серии
+	Volume    float64   `db:"volume"`    // накопленный объём серии
+	Age       int64     `db:"age"`       // возраст серии в секундах
+}
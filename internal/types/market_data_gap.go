@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+const (
+	GapStatusOpen   = "open"   // пропуск обнаружен
+	GapStatusFilled = "filled" // данные догружены
+	GapStatusFailed = "failed" // биржа не вернула данные за период пропуска
+)
+
+// MarketDataGap — пропуск свечей в market_data
+type MarketDataGap struct {
+	ID         int       `db:"id"`
+	Exchange   string    `db:"exchange"`
+	Symbol     string    `db:"symbol"`
+	TimeFrame  string    `db:"time_frame"`
+	GapStart   time.Time `db:"gap_start"` // время первой пропущенной свечи
+	GapEnd     time.Time `db:"gap_end"`   // время последней пропущенной свечи
+	Missing    int       `db:"missing"`   // количество пропущенных свечей
+	Status     string    `db:"status"`
+	DetectedAt time.Time `db:"detected_at"`
+}
+
+// MarketDataCoverage — полнота данных по символу и интервалу за период
+type MarketDataCoverage struct {
+	Exchange  string
+	Symbol    string
+	TimeFrame string
+	Start     time.Time
+	End       time.Time
+	Expected  int     // ожидаемое количество свечей
+	Actual    int     // фактическое количество свечей
+	Gaps      int     // количество пропусков
+	Missing   int     // количество пропущенных свечей
+	Percent   float64 // Actual / Expected * 100
+}
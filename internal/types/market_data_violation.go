@@ -0,0 +1,15 @@
+package types
+
+import "time"
+
+// MarketDataViolation — нарушение правила проверки качества рыночных данных
+type MarketDataViolation struct {
+	ID        int       `db:"id"`
+	Symbol    string    `db:"symbol"`
+	TimeFrame string    `db:"time_frame"`
+	Timestamp time.Time `db:"timestamp"` // время свечи
+	Rule      string    `db:"rule"`      // ohlc, outlier, duplicate, out_of_order, stale
+	Action    string    `db:"action"`    // drop, repair, flag
+	Details   string    `db:"details"`
+	CreatedAt time.Time `db:"created_at"`
+}
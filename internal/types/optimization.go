@@ -0,0 +1,32 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OptimizationRun — оптимизация параметров стратегии: настройки поиска и период данных
+type OptimizationRun struct {
+	ID         int                   `db:"id"`
+	StrategyID int                   `db:"strategy_id"`
+	Method     string                `db:"method"`
+	Objective  string                `db:"objective"`
+	StartTime  time.Time             `db:"start_time"`
+	EndTime    time.Time             `db:"end_time"`
+	Settings   json.RawMessage       `db:"settings"` // настройки оптимизации и базовая конфигурация стратегии
+	CreatedAt  time.Time             `db:"created_at"`
+	Results    []*OptimizationResult `db:"-"`
+}
+
+// OptimizationResult — прогон бэктеста с одним набором параметров
+type OptimizationResult struct {
+	ID        int             `db:"id"`
+	RunID     int             `db:"run_id"`
+	Rank      int             `db:"rank"` // место по целевому показателю, с 1
+	Params    json.RawMessage `db:"params"`
+	Objective float64         `db:"objective"`
+	Front     int             `db:"front"`    // номер фронта Парето при нескольких целях, 0 — одна цель
+	Feasible  bool            `db:"feasible"` // выполнены все ограничения
+	Metrics   json.RawMessage `db:"metrics"`  // показатели отчёта бэктеста
+	Error     string          `db:"error"`
+}
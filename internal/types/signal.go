@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+
+	OrderTypeMarket = "market"
+	OrderTypeLimit  = "limit"
+)
+
+// Signal — торговое решение стратегии
+type Signal struct {
+	StrategyID int
+	Strategy   string
+	Source     string // источник сигнала: процессор или правило
+	Symbol     string
+	Side       string  // buy, sell
+	Type       string  // market, limit
+	Price      float64 // цена лимитного ордера или цена свечи, на которой принято решение
+	Amount     float64
+	Strength   float64 // сила сигнала 0..1, если источник её оценивает
+	Reason     string
+	Time       time.Time
+}
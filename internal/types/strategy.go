@@ -0,0 +1,12 @@
+package types
+
+import "encoding/json"
+
+// Strategy — запись таблицы strategies
+type Strategy struct {
+	ID          int             `db:"id"`
+	Name        string          `db:"name"`
+	Description string          `db:"description"`
+	Config      json.RawMessage `db:"config"` // {"type": "...", "settings": {...}}
+	Active      bool            `db:"active"`
+}
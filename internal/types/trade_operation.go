@@ -0,0 +1,27 @@
+package types
+
+import "time"
+
+// Статусы торговой операции
+const (
+	TradeStatusFilled   = "filled"
+	TradeStatusCanceled = "canceled"
+)
+
+// TradeOperation — исполненный или отменённый ордер стратегии.
+// Операции одной сделки объединяются DealID, Reason описывает роль ордера в сделке.
+// Исполнения бэктеста ссылаются на прогон BacktestResultID, у живой торговли он nil.
+type TradeOperation struct {
+	ID               int       `db:"id"`
+	StrategyID       int       `db:"strategy_id"`
+	BacktestResultID *int      `db:"backtest_result_id"`
+	DealID           string    `db:"deal_id"`
+	Symbol           string    `db:"symbol"`
+	OrderType        string    `db:"order_type"`
+	Side             string    `db:"side"`
+	Quantity         float64   `db:"quantity"`
+	Price            float64   `db:"price"`
+	Timestamp        time.Time `db:"timestamp"`
+	Status           string    `db:"status"`
+	Reason           string    `db:"reason"`
+}
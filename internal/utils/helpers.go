@@ -120,6 +120,11 @@ func getWeekBounds(t time.Time) (start, end, nextStart time.Time) {
 	return
 }
 
+// Возвращает длительность интервала ("1s", "5m", "1h" ...). Месячный интервал не поддерживается.
+func IntervalDuration(interval string) (time.Duration, error) {
+	return parseInterval(interval)
+}
+
 // Парсинг строки интервала в time.Duration
 func parseInterval(interval string) (time.Duration, error) {
 	// Словарь интервалов
@@ -0,0 +1,3 @@
+-- 000002_create_market_data_gaps.down.sql
+
+DROP TABLE IF EXISTS market_data_gaps CASCADE;
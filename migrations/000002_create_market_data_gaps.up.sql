@@ -0,0 +1,15 @@
+-- 000002_create_market_data_gaps.up.sql
+
+-- Таблица для хранения пропусков в рыночных данных
+CREATE TABLE IF NOT EXISTS market_data_gaps (
+    id SERIAL PRIMARY KEY,
+    exchange TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    time_frame TEXT NOT NULL,
+    gap_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    gap_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    missing INT NOT NULL,
+    status TEXT NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(exchange, symbol, time_frame, gap_start)
+);
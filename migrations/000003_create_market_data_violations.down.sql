@@ -0,0 +1,3 @@
+-- 000003_create_market_data_violations.down.sql
+
+DROP TABLE IF EXISTS market_data_violations CASCADE;
@@ -0,0 +1,15 @@
+-- 000003_create_market_data_violations.up.sql
+
+-- Таблица для хранения нарушений качества рыночных данных
+CREATE TABLE IF NOT EXISTS market_data_violations (
+    id SERIAL PRIMARY KEY,
+    symbol TEXT NOT NULL,
+    time_frame TEXT NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    rule TEXT NOT NULL,
+    action TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_data_violations_symbol_time ON market_data_violations (symbol, time_frame, timestamp);
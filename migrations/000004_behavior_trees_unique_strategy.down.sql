@@ -0,0 +1,3 @@
+-- 000004_behavior_trees_unique_strategy.down.sql
+
+ALTER TABLE behavior_trees DROP CONSTRAINT IF EXISTS behavior_trees_strategy_id_key;
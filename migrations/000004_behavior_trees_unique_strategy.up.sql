@@ -0,0 +1,4 @@
+-- 000004_behavior_trees_unique_strategy.up.sql
+
+-- У стратегии одно состояние поведенческого дерева
+ALTER TABLE behavior_trees ADD CONSTRAINT behavior_trees_strategy_id_key UNIQUE (strategy_id);
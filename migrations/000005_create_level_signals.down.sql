@@ -0,0 +1,3 @@
+-- 000005_create_level_signals.down.sql
+
+DROP TABLE IF EXISTS level_signals CASCADE;
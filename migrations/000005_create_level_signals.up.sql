@@ -0,0 +1,17 @@
+-- 000005_create_level_signals.up.sql
+
+-- Таблица для хранения сигналов по уровням поддержки и сопротивления
+CREATE TABLE IF NOT EXISTS level_signals (
+    id SERIAL PRIMARY KEY,
+    symbol TEXT NOT NULL,
+    time_frame TEXT NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    kind TEXT NOT NULL,
+    side TEXT NOT NULL,
+    level NUMERIC(20, 8) NOT NULL,
+    price NUMERIC(20, 8) NOT NULL,
+    distance DOUBLE PRECISION NOT NULL,
+    strength DOUBLE PRECISION NOT NULL,
+    volume NUMERIC(20, 8) NOT NULL,
+    age BIGINT NOT NULL
+);
@@ -0,0 +1,3 @@
+-- 000006_create_grid_levels.down.sql
+
+DROP TABLE IF EXISTS grid_levels CASCADE;
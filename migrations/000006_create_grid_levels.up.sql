@@ -0,0 +1,20 @@
+-- 000006_create_grid_levels.up.sql
+
+-- Таблица для хранения состояния сеточных стратегий: уровни, выставленные ордера и прибыль по уровням
+CREATE TABLE IF NOT EXISTS grid_levels (
+    id SERIAL PRIMARY KEY,
+    strategy_id INT NOT NULL,
+    level_index INT NOT NULL,
+    price NUMERIC(20, 8) NOT NULL,
+    side TEXT NOT NULL DEFAULT '',
+    entry_price NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    order_id TEXT NOT NULL DEFAULT '',
+    command_id TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT '',
+    amount NUMERIC(20, 8) NOT NULL,
+    realized_profit NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    trades INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (strategy_id, level_index),
+    FOREIGN KEY (strategy_id) REFERENCES strategies(id) ON DELETE CASCADE
+);
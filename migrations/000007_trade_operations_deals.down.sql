@@ -0,0 +1,5 @@
+-- 000007_trade_operations_deals.down.sql
+
+DROP INDEX IF EXISTS trade_operations_strategy_deal_idx;
+ALTER TABLE trade_operations DROP COLUMN IF EXISTS reason;
+ALTER TABLE trade_operations DROP COLUMN IF EXISTS deal_id;
@@ -0,0 +1,7 @@
+-- 000007_trade_operations_deals.up.sql
+
+-- Операции объединяются в сделки, reason — роль ордера в сделке
+ALTER TABLE trade_operations ADD COLUMN IF NOT EXISTS deal_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE trade_operations ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS trade_operations_strategy_deal_idx ON trade_operations (strategy_id, deal_id);
@@ -0,0 +1,8 @@
+-- 000008_backtest_results_costs.down.sql
+
+ALTER TABLE backtest_results DROP COLUMN IF EXISTS average_latency;
+ALTER TABLE backtest_results DROP COLUMN IF EXISTS delayed_orders;
+ALTER TABLE backtest_results DROP COLUMN IF EXISTS impact_cost;
+ALTER TABLE backtest_results DROP COLUMN IF EXISTS slippage_cost;
+ALTER TABLE backtest_results DROP COLUMN IF EXISTS spread_cost;
+ALTER TABLE backtest_results DROP COLUMN IF EXISTS commission;
@@ -0,0 +1,9 @@
+-- 000008_backtest_results_costs.up.sql
+
+-- Издержки исполнения бэктеста по моделям
+ALTER TABLE backtest_results ADD COLUMN IF NOT EXISTS commission NUMERIC(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE backtest_results ADD COLUMN IF NOT EXISTS spread_cost NUMERIC(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE backtest_results ADD COLUMN IF NOT EXISTS slippage_cost NUMERIC(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE backtest_results ADD COLUMN IF NOT EXISTS impact_cost NUMERIC(20, 8) NOT NULL DEFAULT 0;
+ALTER TABLE backtest_results ADD COLUMN IF NOT EXISTS delayed_orders INT NOT NULL DEFAULT 0;
+ALTER TABLE backtest_results ADD COLUMN IF NOT EXISTS average_latency NUMERIC(20, 8) NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- 000009_create_backtest_series.down.sql
+
+DROP TABLE IF EXISTS backtest_trades;
+DROP TABLE IF EXISTS backtest_equity;
@@ -0,0 +1,27 @@
+-- 000009_create_backtest_series.up.sql
+
+-- Кривая капитала прогона бэктеста для отчётов
+CREATE TABLE IF NOT EXISTS backtest_equity (
+    result_id INT NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    equity NUMERIC(20, 8) NOT NULL,
+    PRIMARY KEY (result_id, timestamp),
+    FOREIGN KEY (result_id) REFERENCES backtest_results(id) ON DELETE CASCADE
+);
+
+-- Закрытые сделки прогона бэктеста
+CREATE TABLE IF NOT EXISTS backtest_trades (
+    id SERIAL PRIMARY KEY,
+    result_id INT NOT NULL,
+    symbol TEXT NOT NULL,
+    side TEXT NOT NULL,
+    entry_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    exit_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    entry_price NUMERIC(20, 8) NOT NULL,
+    exit_price NUMERIC(20, 8) NOT NULL,
+    quantity NUMERIC(20, 8) NOT NULL,
+    pnl NUMERIC(20, 8) NOT NULL,
+    FOREIGN KEY (result_id) REFERENCES backtest_results(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS backtest_trades_result_idx ON backtest_trades (result_id);
@@ -0,0 +1,4 @@
+-- 000010_create_optimization_runs.down.sql
+
+DROP TABLE IF EXISTS optimization_results;
+DROP TABLE IF EXISTS optimization_runs;
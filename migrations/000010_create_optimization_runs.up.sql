@@ -0,0 +1,28 @@
+-- 000010_create_optimization_runs.up.sql
+
+-- Оптимизации параметров стратегий
+CREATE TABLE IF NOT EXISTS optimization_runs (
+    id SERIAL PRIMARY KEY,
+    strategy_id INT NOT NULL,
+    method TEXT NOT NULL,
+    objective TEXT NOT NULL,
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    settings JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (strategy_id) REFERENCES strategies(id) ON DELETE CASCADE
+);
+
+-- Прогоны оптимизации в порядке места по целевому показателю
+CREATE TABLE IF NOT EXISTS optimization_results (
+    id SERIAL PRIMARY KEY,
+    run_id INT NOT NULL,
+    rank INT NOT NULL,
+    params JSONB NOT NULL,
+    objective NUMERIC(30, 10) NOT NULL,
+    feasible BOOLEAN NOT NULL,
+    metrics JSONB NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    UNIQUE (run_id, rank),
+    FOREIGN KEY (run_id) REFERENCES optimization_runs(id) ON DELETE CASCADE
+);
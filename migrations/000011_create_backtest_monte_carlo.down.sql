@@ -0,0 +1,3 @@
+-- 000011_create_backtest_monte_carlo.down.sql
+
+DROP TABLE IF EXISTS backtest_monte_carlo;
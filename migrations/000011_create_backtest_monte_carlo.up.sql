@@ -0,0 +1,19 @@
+-- 000011_create_backtest_monte_carlo.up.sql
+
+-- Monte Carlo анализ прогонов бэктеста: одна строка на метод
+CREATE TABLE IF NOT EXISTS backtest_monte_carlo (
+    id SERIAL PRIMARY KEY,
+    result_id INT NOT NULL,
+    method TEXT NOT NULL,
+    simulations INT NOT NULL,
+    seed BIGINT NOT NULL,
+    confidence NUMERIC(10, 8) NOT NULL,
+    ruin_level NUMERIC(10, 8) NOT NULL,
+    final_equity JSONB NOT NULL,
+    max_drawdown JSONB NOT NULL,
+    risk_of_ruin JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (result_id) REFERENCES backtest_results(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS backtest_monte_carlo_result_idx ON backtest_monte_carlo (result_id);
@@ -0,0 +1,3 @@
+-- 000012_optimization_results_front.down.sql
+
+ALTER TABLE optimization_results DROP COLUMN IF EXISTS front;
@@ -0,0 +1,4 @@
+-- 000012_optimization_results_front.up.sql
+
+-- Номер фронта Парето прогона при многокритериальной оптимизации, 0 — одна цель
+ALTER TABLE optimization_results ADD COLUMN IF NOT EXISTS front INT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- 000013_trade_operations_backtest_result.down.sql
+
+DROP INDEX IF EXISTS trade_operations_backtest_result_idx;
+ALTER TABLE trade_operations DROP COLUMN IF EXISTS backtest_result_id;
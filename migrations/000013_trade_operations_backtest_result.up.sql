@@ -0,0 +1,6 @@
+-- 000013_trade_operations_backtest_result.up.sql
+
+-- Исполнения бэктеста относятся к прогону, у операций живой торговли поле пустое
+ALTER TABLE trade_operations ADD COLUMN IF NOT EXISTS backtest_result_id INT REFERENCES backtest_results(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS trade_operations_backtest_result_idx ON trade_operations (backtest_result_id);
@@ -0,0 +1,3 @@
+-- 000014_create_dca_deals.down.sql
+
+DROP TABLE IF EXISTS dca_deals CASCADE;
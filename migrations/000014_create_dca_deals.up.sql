@@ -0,0 +1,12 @@
+-- 000014_create_dca_deals.up.sql
+
+-- Состояние открытых сделок DCA, которое не выводится из trade_operations: максимум цены трейлинг тейк-профита
+CREATE TABLE IF NOT EXISTS dca_deals (
+    id SERIAL PRIMARY KEY,
+    strategy_id INT NOT NULL,
+    deal_id TEXT NOT NULL,
+    trailing_high NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (strategy_id, deal_id),
+    FOREIGN KEY (strategy_id) REFERENCES strategies(id) ON DELETE CASCADE
+);
@@ -85,3 +85,11 @@ type Sink interface {
 	// a Pipeline instance.
 	Consume(context.Context, Payload) error
 }
+
+// Flusher is optionally implemented by sinks that buffer data. Flush is
+// invoked once after the last Payload has been consumed and all workers have
+// exited, i.e. when the source is exhausted. It is not invoked when the
+// pipeline is aborted by an error or by the context being cancelled.
+type Flusher interface {
+	Flush(context.Context) error
+}
@@ -90,8 +90,9 @@ func (p *Pipeline) Process(ctx context.Context, source Source, sink Sink) error
 		wg.Done()
 	}()
 
+	var exhausted bool
 	go func() {
-		sinkWorker(pCtx, sink, stageCh[len(stageCh)-1], errCh)
+		exhausted = sinkWorker(pCtx, sink, stageCh[len(stageCh)-1], errCh)
 		wg.Done()
 	}()
 
@@ -108,6 +109,17 @@ func (p *Pipeline) Process(ctx context.Context, source Source, sink Sink) error
 		err = multierror.Append(err, pErr)
 		ctxCancelFn()
 	}
+
+	// All workers have exited at this point. A stage that fails closes its
+	// output before the error is collected, so the sink may observe a closed
+	// input channel on an aborted run; flush only if nothing went wrong.
+	if err == nil && exhausted && ctx.Err() == nil {
+		if flusher, isFlusher := sink.(Flusher); isFlusher {
+			if fErr := flusher.Flush(ctx); fErr != nil {
+				err = multierror.Append(err, xerrors.Errorf("pipeline sink: %w", fErr))
+			}
+		}
+	}
 	return err
 }
 
@@ -134,24 +146,24 @@ func sourceWorker(ctx context.Context, source Source, outCh chan<- Payload, errC
 
 // sinkWorker implements a worker that reads Payload instances from an input
 // channel (the output of the last pipeline stage) and passes them to the
-// provided sink.
-func sinkWorker(ctx context.Context, sink Sink, inCh <-chan Payload, errCh chan<- error) {
+// provided sink. It reports whether the input channel was drained.
+func sinkWorker(ctx context.Context, sink Sink, inCh <-chan Payload, errCh chan<- error) bool {
 	for {
 		select {
 		case payload, ok := <-inCh:
 			if !ok {
-				return
+				return true
 			}
 
 			if err := sink.Consume(ctx, payload); err != nil {
 				wrappedErr := xerrors.Errorf("pipeline sink: %w", err)
 				maybeEmitError(wrappedErr, errCh)
-				return
+				return false
 			}
 			payload.MarkAsProcessed()
 		case <-ctx.Done():
 			// Asked to shutdown
-			return
+			return false
 		}
 	}
 }
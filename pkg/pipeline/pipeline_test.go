@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/PacktPublishing/Hands-On-Software-Engineering-with-Golang/Chapter07/pipeline"
+	"crypto-trading-bot/pkg/pipeline"
 	"golang.org/x/xerrors"
 	gc "gopkg.in/check.v1"
 )
@@ -83,6 +83,47 @@ func (s *PipelineTestSuite) TestPayloadDiscarding(c *gc.C) {
 	assertAllProcessed(c, src.data)
 }
 
+func (s *PipelineTestSuite) TestSinkFlush(c *gc.C) {
+	src := &sourceStub{data: stringPayloads(3)}
+	sink := new(flushingSinkStub)
+
+	p := pipeline.New(testStage{c: c})
+	err := p.Process(context.TODO(), src, sink)
+	c.Assert(err, gc.IsNil)
+	c.Assert(sink.flushes, gc.Equals, 1)
+	c.Assert(sink.flushedAfter, gc.Equals, 3)
+}
+
+func (s *PipelineTestSuite) TestSinkFlushErrorHandling(c *gc.C) {
+	src := &sourceStub{data: stringPayloads(3)}
+	sink := &flushingSinkStub{err: xerrors.New("some error")}
+
+	p := pipeline.New(testStage{c: c})
+	err := p.Process(context.TODO(), src, sink)
+	c.Assert(err, gc.ErrorMatches, "(?s).*pipeline sink: some error.*")
+}
+
+func (s *PipelineTestSuite) TestNoFlushOnAbort(c *gc.C) {
+	expErr := xerrors.New("some error")
+	for i := 0; i < 50; i++ {
+		sink := new(flushingSinkStub)
+		err := pipeline.New(testStage{c: c, err: expErr}).Process(context.TODO(), &sourceStub{data: stringPayloads(3)}, sink)
+		c.Assert(err, gc.NotNil)
+		c.Assert(sink.flushes, gc.Equals, 0, gc.Commentf("flushed after a stage error"))
+
+		sink = new(flushingSinkStub)
+		err = pipeline.New(testStage{c: c}).Process(context.TODO(), &sourceStub{data: stringPayloads(3), err: expErr}, sink)
+		c.Assert(err, gc.NotNil)
+		c.Assert(sink.flushes, gc.Equals, 0, gc.Commentf("flushed after a source error"))
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	sink := new(flushingSinkStub)
+	_ = pipeline.New(testStage{c: c}).Process(ctx, &sourceStub{data: stringPayloads(3)}, sink)
+	c.Assert(sink.flushes, gc.Equals, 0, gc.Commentf("flushed after the context was cancelled"))
+}
+
 func assertAllProcessed(c *gc.C, payloads []pipeline.Payload) {
 	for i, p := range payloads {
 		payload := p.(*stringPayload)
@@ -160,6 +201,19 @@ func (s *sinkStub) Consume(_ context.Context, p pipeline.Payload) error {
 	return s.err
 }
 
+type flushingSinkStub struct {
+	sinkStub
+	flushes      int
+	flushedAfter int
+	err          error
+}
+
+func (s *flushingSinkStub) Flush(context.Context) error {
+	s.flushes++
+	s.flushedAfter = len(s.data)
+	return s.err
+}
+
 type stringPayload struct {
 	processed bool
 	val       string
@@ -6,7 +6,7 @@ import (
 	"sort"
 	"time"
 
-	"github.com/PacktPublishing/Hands-On-Software-Engineering-with-Golang/Chapter07/pipeline"
+	"crypto-trading-bot/pkg/pipeline"
 	gc "gopkg.in/check.v1"
 )
 